@@ -14,6 +14,12 @@ type ParseFeedsRequest struct {
 	// ItemsPerPage is the number of items per page
 	ItemsPerPage int `json:"items_per_page,omitempty" minimum:"1" maximum:"100" default:"50" doc:"Number of items per page"`
 	
+	// Languages keeps only items in one of these languages (e.g. "en", "de")
+	Languages []string `json:"languages,omitempty" maxItems:"20" doc:"Only include items in one of these languages; en also matches en-US"`
+	
+	// StrictLanguages also drops items whose language was not detected
+	StrictLanguages bool `json:"strict_languages,omitempty" doc:"With languages, also drop items whose language could not be detected"`
+	
 	// EnrichmentOptions controls which enrichment features are enabled
 	EnrichmentOptions *EnrichmentOptions `json:"enrichment,omitempty" doc:"Optional enrichment configuration"`
 }
@@ -25,6 +31,21 @@ type EnrichmentOptions struct {
 	
 	// ExtractColors enables color extraction from images (default: true)
 	ExtractColors *bool `json:"extract_colors,omitempty" default:"true" doc:"Extract dominant colors from images"`
+	
+	// Summarize adds an extractive summary of each item's content (default: false)
+	Summarize *bool `json:"summarize,omitempty" default:"false" doc:"Add the key sentences of each item's content"`
+	
+	// SummarySentences is how many sentences a summary has; 0 uses the server default
+	SummarySentences int `json:"summary_sentences,omitempty" minimum:"0" maximum:"10" doc:"Number of sentences per summary; 0 uses the server default"`
+	
+	// AutoTag adds computed keywords and key phrases to each item (default: false)
+	AutoTag *bool `json:"auto_tag,omitempty" default:"false" doc:"Add keywords and key phrases of each item as autoTags"`
+	
+	// MaxTags is how many auto-tags an item gets; 0 uses the server default
+	MaxTags int `json:"max_tags,omitempty" minimum:"0" maximum:"10" doc:"Number of auto-tags per item; 0 uses the server default"`
+	
+	// Cluster groups near-identical items of the requested feeds into stories (default: false)
+	Cluster *bool `json:"cluster,omitempty" default:"false" doc:"Group near-identical items across feeds into story clusters"`
 }
 
 // ApplyDefaults sets default values for optional fields
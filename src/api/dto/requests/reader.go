@@ -6,5 +6,21 @@ package requests
 // ReaderViewRequest represents a request to extract reader views from URLs
 type ReaderViewRequest struct {
 	// URLs to extract reader views from
-	URLs []string `json:"urls" required:"true" example:"[\"https://example.com/article\"]" doc:"List of URLs to extract reader views from"`
-}
\ No newline at end of file
+	URLs []string `json:"urls,omitempty" example:"[\"https://example.com/article\"]" doc:"List of URLs to extract reader views from"`
+
+	// Items are pages linked from feed items
+	Items []ReaderViewItem `json:"items,omitempty" doc:"Pages along with the feed item they were linked from; when the feed carries the full article it is used instead of fetching the page"`
+
+	// Summarize adds the key sentences of each article
+	Summarize bool `json:"summarize,omitempty" doc:"Add an extractive summary of each article"`
+
+	// SummarySentences is how many sentences a summary has
+	SummarySentences int `json:"summarySentences,omitempty" minimum:"0" maximum:"10" doc:"Number of sentences per summary; 0 uses the server default"`
+}
+
+// ReaderViewItem is a page along with the feed item it was linked from
+type ReaderViewItem struct {
+	URL     string `json:"url" doc:"URL of the page"`
+	FeedURL string `json:"feedUrl" doc:"URL of the feed the item belongs to"`
+	ItemID  string `json:"itemId,omitempty" doc:"ID of the feed item; the item is matched by its link when empty"`
+}
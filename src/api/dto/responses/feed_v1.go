@@ -29,6 +29,7 @@ type FeedV1Response struct {
 	Published    string          `json:"published,omitempty"`
 	Author       *AuthorV1       `json:"author,omitempty"`
 	Language     string          `json:"language,omitempty"`
+	LanguageConfidence float64   `json:"languageConfidence,omitempty"` // 1 when declared by the feed
 	Favicon      string          `json:"favicon,omitempty"`
 	Image        string          `json:"image,omitempty"`        // Feed image
 	Categories   string          `json:"categories,omitempty"`
@@ -56,10 +57,20 @@ type ItemV1Response struct {
 	Content        string       `json:"content,omitempty"`
 	ContentEncoded string       `json:"content_encoded,omitempty"`
 	Categories     []string     `json:"categories,omitempty"`
+	AutoTags       []string     `json:"autoTags,omitempty"`         // Computed keywords and key phrases
+	Language       string       `json:"language,omitempty"`         // Detected, or the feed's language
+	LanguageConfidence float64  `json:"languageConfidence,omitempty"`
+	Cluster        string       `json:"cluster,omitempty"`          // Story cluster of near-identical items
 	Duration       string       `json:"duration,omitempty"`         // e.g., "00:28:19"
 	Thumbnail      string       `json:"thumbnail,omitempty"`        // Image URL
 	ThumbnailColor *ColorV1     `json:"thumbnailColor,omitempty"`
 	ThumbnailColorComputed string `json:"thumbnailColorComputed,omitempty"`
+	ThumbnailPalette *PaletteV1 `json:"thumbnailPalette,omitempty"` // Set once the palette is computed
+	ThumbnailBlurHash string    `json:"thumbnailBlurHash,omitempty"` // Placeholder shown while the thumbnail loads
+	ThumbnailLQIP  string       `json:"thumbnailLqip,omitempty"`     // Tiny JPEG preview as a data URI
+	ThumbnailWidth int          `json:"thumbnailWidth,omitempty"`
+	ThumbnailHeight int         `json:"thumbnailHeight,omitempty"`
+	ThumbnailRejections []ThumbnailRejectionV1 `json:"thumbnailRejections,omitempty"` // Candidates skipped for the thumbnail
 	Enclosures     []EnclosureV1 `json:"enclosures,omitempty"`     // Media enclosures
 	
 	// Podcast metadata
@@ -70,6 +81,9 @@ type ItemV1Response struct {
 	Summary      string `json:"summary,omitempty"`          // Episode summary
 	Image        string `json:"image,omitempty"`            // Episode image
 	
+	// Extractive summary, when requested with the summarize enrichment
+	KeySentences []string `json:"keySentences,omitempty"`
+	
 	// Podcast specific fields (legacy, kept for compatibility)
 	URL      string `json:"url,omitempty"`              // Media URL
 	Length   string `json:"length,omitempty"`           // File size
@@ -90,10 +104,104 @@ type ColorV1 struct {
 	B int `json:"b"`
 }
 
+// ThumbnailRejectionV1 is a thumbnail candidate that was not used, and why
+type ThumbnailRejectionV1 struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason" enum:"invalid_url,unreachable,bad_status,undecodable,too_small,uniform,repeated"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// PaletteV1 is the color palette of an image. Swatches the image has no
+// suitable color for are omitted.
+type PaletteV1 struct {
+	Dominant     ColorV1  `json:"dominant"`
+	Vibrant      *ColorV1 `json:"vibrant,omitempty"`
+	Muted        *ColorV1 `json:"muted,omitempty"`
+	DarkVibrant  *ColorV1 `json:"darkVibrant,omitempty"`
+	LightMuted   *ColorV1 `json:"lightMuted,omitempty"`
+	TextColor    ColorV1  `json:"textColor" doc:"Color for text drawn over the image"`
+	TextContrast float64  `json:"textContrast" doc:"WCAG contrast ratio of textColor on the dominant color (4.5 meets AA)"`
+}
+
+// ConvertPaletteToV1 converts a domain palette to the v1 format
+func ConvertPaletteToV1(palette *domain.ColorPalette) *PaletteV1 {
+	if palette == nil {
+		return nil
+	}
+	swatch := func(color *domain.RGBColor) *ColorV1 {
+		if color == nil {
+			return nil
+		}
+		return &ColorV1{R: int(color.R), G: int(color.G), B: int(color.B)}
+	}
+	return &PaletteV1{
+		Dominant:     *swatch(&palette.Dominant),
+		Vibrant:      swatch(palette.Vibrant),
+		Muted:        swatch(palette.Muted),
+		DarkVibrant:  swatch(palette.DarkVibrant),
+		LightMuted:   swatch(palette.LightMuted),
+		TextColor:    *swatch(&palette.TextColor),
+		TextContrast: palette.TextContrast,
+	}
+}
+
 // ParseFeedsV1Response represents the v1 API response for parsing multiple feeds
 type ParseFeedsV1Response struct {
 	Feeds []FeedV1Response `json:"feeds"`
 	// Note: No $schema field to match current API exactly
+	
+	// Clusters groups near-identical items into stories, when requested
+	Clusters []StoryClusterV1 `json:"clusters,omitempty"`
+	
+	// EnrichmentJob is the job computing thumbnails missing from this
+	// response; poll /enrichment/{jobId} for its results
+	EnrichmentJob string `json:"enrichmentJob,omitempty"`
+}
+
+// StoryClusterV1 is a story covered by several items
+type StoryClusterV1 struct {
+	ID             string         `json:"id"`
+	Representative StoryItemV1    `json:"representative"`
+	Items          []StoryItemV1  `json:"items"`               // All items of the story, representative first
+}
+
+// StoryItemV1 points at an item of a story in the feeds of the response
+type StoryItemV1 struct {
+	FeedURL   string `json:"feedUrl"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Published string `json:"published,omitempty"`              // RFC3339 format
+}
+
+// ConvertStoryClustersToV1 converts story clusters to the v1 format
+func ConvertStoryClustersToV1(clusters []domain.StoryCluster) []StoryClusterV1 {
+	result := make([]StoryClusterV1, 0, len(clusters))
+	for _, cluster := range clusters {
+		v1Cluster := StoryClusterV1{
+			ID:             cluster.ID,
+			Representative: convertStoryMemberToV1(cluster.Representative),
+			Items:          make([]StoryItemV1, 0, len(cluster.Members)),
+		}
+		for _, member := range cluster.Members {
+			v1Cluster.Items = append(v1Cluster.Items, convertStoryMemberToV1(member))
+		}
+		result = append(result, v1Cluster)
+	}
+	return result
+}
+
+func convertStoryMemberToV1(member domain.StoryMember) StoryItemV1 {
+	item := StoryItemV1{
+		FeedURL: member.FeedURL,
+		ID:      member.ItemID,
+		Title:   member.Title,
+		Link:    member.Link,
+	}
+	if !member.Published.IsZero() {
+		item.Published = formatTimeWithOriginalZone(member.Published)
+	}
+	return item
 }
 
 // ConvertToV1Response converts from our internal format to v1 API format
@@ -115,8 +223,23 @@ func ConvertDomainFeedsToV1Response(feeds []*domain.Feed) ParseFeedsV1Response {
 	return ConvertDomainFeedsToV1ResponseWithColors(feeds, nil)
 }
 
+// ThumbnailEnrichment holds what has been computed from item thumbnails,
+// keyed by image URL
+type ThumbnailEnrichment struct {
+	Colors       map[string]*domain.RGBColor
+	Palettes     map[string]*domain.ColorPalette
+	Placeholders map[string]*domain.ImagePlaceholder
+}
+
 // ConvertDomainFeedsToV1ResponseWithColors converts domain feeds with thumbnail colors
 func ConvertDomainFeedsToV1ResponseWithColors(feeds []*domain.Feed, thumbnailColors map[string]*domain.RGBColor) ParseFeedsV1Response {
+	return ConvertDomainFeedsToV1ResponseWithThumbnails(feeds, ThumbnailEnrichment{Colors: thumbnailColors})
+}
+
+// ConvertDomainFeedsToV1ResponseWithThumbnails converts domain feeds with the
+// colors, palettes and placeholders of their thumbnails
+func ConvertDomainFeedsToV1ResponseWithThumbnails(feeds []*domain.Feed, thumbnails ThumbnailEnrichment) ParseFeedsV1Response {
+	thumbnailColors := thumbnails.Colors
 	v1Feeds := make([]FeedV1Response, 0, len(feeds))
 	
 	for _, feed := range feeds {
@@ -139,6 +262,7 @@ func ConvertDomainFeedsToV1ResponseWithColors(feeds []*domain.Feed, thumbnailCol
 			LastUpdated:   formatTimeWithOriginalZone(feed.LastUpdated),
 			LastRefreshed: time.Now().UTC().Format(time.RFC3339),
 			Language:      feed.Language,
+			LanguageConfidence: feed.LanguageConfidence,
 			Favicon:       feed.Favicon,
 			Categories:    feed.Categories,
 			Image:         feed.Image,
@@ -179,12 +303,18 @@ func ConvertDomainFeedsToV1ResponseWithColors(feeds []*domain.Feed, thumbnailCol
 				ContentEncoded: item.ContentEncoded,
 				Duration:       formatDuration(item.Duration),
 				Thumbnail:      item.Thumbnail,
+				ThumbnailRejections: convertThumbnailRejectionsToV1(item.ThumbnailRejections),
 				Subtitle:       item.Subtitle,
 				Summary:        item.Summary,
 				Image:          item.Image,
 				Episode:        item.Episode,
 				Season:         item.Season,
 				EpisodeType:    item.EpisodeType,
+				KeySentences:   item.KeySentences,
+				AutoTags:       item.AutoTags,
+				Language:       item.Language,
+				LanguageConfidence: item.LanguageConfidence,
+				Cluster:        item.ClusterID,
 			}
 			
 			// Set created
@@ -228,6 +358,15 @@ func ConvertDomainFeedsToV1ResponseWithColors(feeds []*domain.Feed, thumbnailCol
 				v1Item.ThumbnailColor = &ColorV1{R: 128, G: 128, B: 128}
 				v1Item.ThumbnailColorComputed = "no"
 			}
+			if v1Item.Thumbnail != "" {
+				v1Item.ThumbnailPalette = ConvertPaletteToV1(thumbnails.Palettes[v1Item.Thumbnail])
+				if placeholder := thumbnails.Placeholders[v1Item.Thumbnail]; placeholder != nil {
+					v1Item.ThumbnailBlurHash = placeholder.BlurHash
+					v1Item.ThumbnailLQIP = placeholder.LQIP
+					v1Item.ThumbnailWidth = placeholder.Width
+					v1Item.ThumbnailHeight = placeholder.Height
+				}
+			}
 			
 			v1Feed.Items = append(v1Feed.Items, v1Item)
 		}
@@ -325,4 +464,20 @@ func formatDuration(durationStr string) string {
 		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, secs)
 	}
 	return fmt.Sprintf("%02d:%02d", minutes, secs)
-}
\ No newline at end of file
+}
+
+// convertThumbnailRejectionsToV1 converts skipped thumbnail candidates to the v1 format
+func convertThumbnailRejectionsToV1(rejections []domain.ThumbnailRejection) []ThumbnailRejectionV1 {
+	if len(rejections) == 0 {
+		return nil
+	}
+	converted := make([]ThumbnailRejectionV1, 0, len(rejections))
+	for _, rejection := range rejections {
+		converted = append(converted, ThumbnailRejectionV1{
+			URL:    rejection.URL,
+			Reason: rejection.Reason,
+			Detail: rejection.Detail,
+		})
+	}
+	return converted
+}
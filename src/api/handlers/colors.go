@@ -0,0 +1,130 @@
+// ABOUTME: Colors handler extracts the color palettes of images
+// ABOUTME: Returns named swatches and a WCAG-checked text color for each image
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"digests-app-api/api/dto/responses"
+	"digests-app-api/core/interfaces"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const (
+	// maxColorURLs is the most images a palette request may name
+	maxColorURLs = 50
+
+	// colorBatchConcurrency is how many images of a batch are processed at once
+	colorBatchConcurrency = 5
+)
+
+// ColorsHandler handles image palette requests
+type ColorsHandler struct {
+	enrichmentService interfaces.ContentEnrichmentService
+}
+
+// NewColorsHandler creates a new colors handler
+func NewColorsHandler(enrichmentService interfaces.ContentEnrichmentService) *ColorsHandler {
+	return &ColorsHandler{enrichmentService: enrichmentService}
+}
+
+// RegisterRoutes registers all color routes
+func (h *ColorsHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "getColors",
+		Method:      http.MethodGet,
+		Path:        "/colors",
+		Summary:     "Get the color palette of an image",
+		Description: "Extracts the dominant, vibrant, muted, dark vibrant and light muted colors of an image, and a text color with enough contrast to be read over it. Palettes are cached.",
+		Tags:        []string{"Colors"},
+	}, h.GetColors)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getColorsBatch",
+		Method:      http.MethodPost,
+		Path:        "/colors",
+		Summary:     "Get the color palettes of several images",
+		Description: "Extracts the palettes of up to 50 images concurrently. Images that cannot be fetched or decoded have no palette and an error.",
+		Tags:        []string{"Colors"},
+	}, h.GetColorsBatch)
+}
+
+// ImagePalette is the palette of one image
+type ImagePalette struct {
+	URL     string               `json:"url"`
+	Palette *responses.PaletteV1 `json:"palette"`
+	Error   string               `json:"error,omitempty" doc:"Why the image could not be used, when it has no palette"`
+}
+
+// GetColorsInput defines the input for a single image palette
+type GetColorsInput struct {
+	URL string `query:"url" required:"true" format:"uri" doc:"Image URL"`
+}
+
+// GetColorsOutput defines the output for a single image palette
+type GetColorsOutput struct {
+	Body ImagePalette
+}
+
+// GetColorsBatchInput defines the input for several image palettes
+type GetColorsBatchInput struct {
+	Body struct {
+		URLs []string `json:"urls" minItems:"1" doc:"Image URLs (max 50)"`
+	}
+}
+
+// GetColorsBatchOutput defines the output for several image palettes
+type GetColorsBatchOutput struct {
+	Body struct {
+		Palettes []ImagePalette `json:"palettes"`
+	}
+}
+
+// GetColors handles the GET /colors endpoint
+func (h *ColorsHandler) GetColors(ctx context.Context, input *GetColorsInput) (*GetColorsOutput, error) {
+	palette, err := h.enrichmentService.ExtractPalette(ctx, input.URL)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &GetColorsOutput{Body: ImagePalette{
+		URL:     input.URL,
+		Palette: responses.ConvertPaletteToV1(palette),
+	}}, nil
+}
+
+// GetColorsBatch handles the POST /colors endpoint
+func (h *ColorsHandler) GetColorsBatch(ctx context.Context, input *GetColorsBatchInput) (*GetColorsBatchOutput, error) {
+	if len(input.Body.URLs) > maxColorURLs {
+		return nil, huma.Error400BadRequest("Too many URLs provided")
+	}
+
+	output := &GetColorsBatchOutput{}
+	output.Body.Palettes = make([]ImagePalette, len(input.Body.URLs))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, colorBatchConcurrency)
+	for i, imageURL := range input.Body.URLs {
+		wg.Add(1)
+		go func(index int, imageURL string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			result := ImagePalette{URL: imageURL}
+			palette, err := h.enrichmentService.ExtractPalette(ctx, imageURL)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Palette = responses.ConvertPaletteToV1(palette)
+			}
+			output.Body.Palettes[index] = result
+		}(i, imageURL)
+	}
+	wg.Wait()
+
+	return output, nil
+}
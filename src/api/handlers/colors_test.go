@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestColorsHandler(t *testing.T) {
+	vibrant := domain.RGBColor{R: 220, G: 40, B: 60}
+	enrichment := &mockEnrichmentService{palettes: map[string]*domain.ColorPalette{
+		"https://img.example.com/a.jpg": {
+			Dominant:     domain.RGBColor{R: 20, G: 30, B: 40},
+			Vibrant:      &vibrant,
+			TextColor:    domain.RGBColor{R: 255, G: 255, B: 255},
+			TextContrast: 15.8,
+		},
+	}}
+	_, api := humatest.New(t)
+	NewColorsHandler(enrichment).RegisterRoutes(api)
+
+	resp := api.Get("/colors?url=https://img.example.com/a.jpg")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var single ImagePalette
+	if err := json.Unmarshal(resp.Body.Bytes(), &single); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if single.Palette == nil || single.Palette.Vibrant == nil || single.Palette.Vibrant.R != 220 || single.Palette.Muted != nil {
+		t.Errorf("unexpected palette: %+v", single.Palette)
+	}
+	if single.Palette.TextColor.R != 255 || single.Palette.TextContrast != 15.8 {
+		t.Errorf("unexpected text color: %+v", single.Palette)
+	}
+
+	resp = api.Post("/colors", map[string]interface{}{
+		"urls": []string{"https://img.example.com/a.jpg", "https://img.example.com/missing.jpg"},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var batch struct {
+		Palettes []ImagePalette `json:"palettes"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &batch); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(batch.Palettes) != 2 || batch.Palettes[0].Palette == nil || batch.Palettes[1].Palette != nil {
+		t.Errorf("unexpected palettes: %+v", batch.Palettes)
+	}
+	if batch.Palettes[0].Error != "" || batch.Palettes[1].Error == "" {
+		t.Errorf("expected an error only for the missing image: %+v", batch.Palettes)
+	}
+
+	if resp := api.Get("/colors?url=https://img.example.com/missing.jpg"); resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an image that cannot be fetched, got %d", resp.Code)
+	}
+
+	urls := make([]string, maxColorURLs+1)
+	for i := range urls {
+		urls[i] = "https://img.example.com/a.jpg"
+	}
+	if resp := api.Post("/colors", map[string]interface{}{"urls": urls}); resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for too many URLs, got %d", resp.Code)
+	}
+	if resp := api.Get("/colors"); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 without a URL, got %d", resp.Code)
+	}
+}
@@ -8,23 +8,53 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 	
 	"digests-app-api/core/interfaces"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/danielgtaylor/huma/v2"
 )
 
+const (
+	// maxAnchorCandidates limits how many <a> links are probed per page
+	maxAnchorCandidates = 10
+
+	// candidateConcurrency limits concurrent candidate verifications per page
+	candidateConcurrency = 5
+
+	// discoveryTimeout bounds a whole discovery request, page fetches and
+	// candidate probes included, so it finishes within the write timeout;
+	// candidates still being verified when it expires are dropped
+	discoveryTimeout = 8 * time.Second
+)
+
+// wellKnownFeedPaths are probed when a page does not advertise its feed
+var wellKnownFeedPaths = []string{
+	"feed",
+	"rss",
+	"rss.xml",
+	"atom.xml",
+	"index.xml",
+	"feed.json",
+	"?feed=rss2",
+}
+
 // DiscoverHandler handles RSS feed discovery
 type DiscoverHandler struct {
-	httpClient interfaces.HTTPClient
+	httpClient  interfaces.HTTPClient
+	feedService interfaces.FeedService
 }
 
-// NewDiscoverHandler creates a new discover handler
-func NewDiscoverHandler(httpClient interfaces.HTTPClient) *DiscoverHandler {
+// NewDiscoverHandler creates a new discover handler.
+// The feed service is used to verify fallback candidates and may be nil,
+// in which case only advertised <link rel="alternate"> feeds are discovered.
+func NewDiscoverHandler(httpClient interfaces.HTTPClient, feedService interfaces.FeedService) *DiscoverHandler {
 	return &DiscoverHandler{
-		httpClient: httpClient,
+		httpClient:  httpClient,
+		feedService: feedService,
 	}
 }
 
@@ -49,10 +79,23 @@ type DiscoverFeedsInput struct {
 
 // FeedDiscoveryResult represents a single discovery result
 type FeedDiscoveryResult struct {
-	URL      string `json:"url" doc:"Original URL that was checked"`
-	Status   string `json:"status" doc:"Discovery status: 'ok' or 'error'"`
-	FeedLink string `json:"feedLink,omitempty" doc:"Discovered RSS feed URL"`
-	Error    string `json:"error,omitempty" doc:"Error message if discovery failed"`
+	URL        string          `json:"url" doc:"Original URL that was checked"`
+	Status     string          `json:"status" doc:"Discovery status: 'ok' or 'error'"`
+	FeedLink   string          `json:"feedLink,omitempty" doc:"Discovered RSS feed URL"`
+	Candidates []FeedCandidate `json:"candidates,omitempty" doc:"Verified feeds found by the fallback probe, best first"`
+	Error      string          `json:"error,omitempty" doc:"Error message if discovery failed"`
+}
+
+// FeedCandidate represents a verified feed found by probing a website
+type FeedCandidate struct {
+	URL         string `json:"url" doc:"Feed URL"`
+	Title       string `json:"title,omitempty" doc:"Feed title"`
+	ItemCount   int    `json:"itemCount" doc:"Number of items in the feed"`
+	LastUpdated string `json:"lastUpdated,omitempty" doc:"When the feed was last updated (RFC3339)"`
+	Source      string `json:"source" doc:"How the candidate was found: 'anchor' or 'probe'"`
+
+	score    int
+	identity string
 }
 
 // DiscoverFeedsOutput defines the output for feed discovery
@@ -68,6 +111,9 @@ func (h *DiscoverHandler) DiscoverFeeds(ctx context.Context, input *DiscoverFeed
 		return nil, huma.Error400BadRequest("No URLs provided")
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
 	// Process URLs concurrently
 	var wg sync.WaitGroup
 	results := make([]FeedDiscoveryResult, len(input.Body.URLs))
@@ -77,7 +123,7 @@ func (h *DiscoverHandler) DiscoverFeeds(ctx context.Context, input *DiscoverFeed
 		go func(idx int, siteURL string) {
 			defer wg.Done()
 			
-			feedURL, err := h.discoverFeedURL(ctx, siteURL)
+			feedURL, candidates, err := h.discoverFeedURL(ctx, siteURL)
 			if err != nil {
 				results[idx] = FeedDiscoveryResult{
 					URL:    siteURL,
@@ -86,9 +132,10 @@ func (h *DiscoverHandler) DiscoverFeeds(ctx context.Context, input *DiscoverFeed
 				}
 			} else {
 				results[idx] = FeedDiscoveryResult{
-					URL:      siteURL,
-					Status:   "ok",
-					FeedLink: feedURL,
+					URL:        siteURL,
+					Status:     "ok",
+					FeedLink:   feedURL,
+					Candidates: candidates,
 				}
 			}
 		}(i, url)
@@ -101,53 +148,263 @@ func (h *DiscoverHandler) DiscoverFeeds(ctx context.Context, input *DiscoverFeed
 	return output, nil
 }
 
-// discoverFeedURL attempts to discover RSS feed URL from a website
-func (h *DiscoverHandler) discoverFeedURL(ctx context.Context, siteURL string) (string, error) {
+// discoverFeedURL attempts to discover RSS feed URL from a website.
+// When the page does not advertise a feed, it falls back to probing
+// well-known paths and RSS-looking links, returning the verified candidates.
+func (h *DiscoverHandler) discoverFeedURL(ctx context.Context, siteURL string) (string, []FeedCandidate, error) {
 	// Handle special cases
 	if strings.HasPrefix(siteURL, "https://github.com") {
-		return h.generateGitHubFeedURL(siteURL), nil
+		return h.generateGitHubFeedURL(siteURL), nil, nil
 	}
 	
 	if strings.HasPrefix(siteURL, "https://www.reddit.com") || strings.HasPrefix(siteURL, "https://reddit.com") {
-		return h.generateRedditFeedURL(siteURL), nil
+		return h.generateRedditFeedURL(siteURL), nil, nil
 	}
 
 	// Fetch the page
 	resp, err := h.httpClient.Get(ctx, siteURL)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer resp.Body().Close()
 
-	if resp.StatusCode() != http.StatusOK {
-		return "", errors.New("failed to fetch page")
+	// A page we cannot read may still serve a feed at a well-known path
+	var doc *goquery.Document
+	if resp.StatusCode() == http.StatusOK {
+		doc, err = goquery.NewDocumentFromReader(resp.Body())
+		if err != nil {
+			return "", nil, err
+		}
+	} else if h.feedService == nil {
+		return "", nil, errors.New("failed to fetch page")
+	}
+
+	// Look for RSS feed links
+	var feedURL string
+	if doc != nil {
+		doc.Find(`link[type="application/rss+xml"], link[type="application/atom+xml"]`).Each(func(i int, s *goquery.Selection) {
+			if href, exists := s.Attr("href"); exists && feedURL == "" {
+				feedURL = href
+			}
+		})
+	}
+
+	if feedURL != "" {
+		// Ensure absolute URL
+		feedURL, err = h.ensureAbsoluteURL(siteURL, feedURL)
+		if err != nil {
+			return "", nil, err
+		}
+		return feedURL, nil, nil
+	}
+
+	// Fall back to probing candidates
+	if h.feedService == nil {
+		return "", nil, errors.New("no RSS feed found")
 	}
 
-	// Parse HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body())
+	candidates := h.verifyCandidates(ctx, h.collectCandidates(siteURL, doc))
+	if len(candidates) == 0 {
+		return "", nil, errors.New("no RSS feed found")
+	}
+
+	return candidates[0].URL, candidates, nil
+}
+
+// collectCandidates gathers possible feed URLs from RSS-looking links on the
+// page followed by well-known feed paths. Anchors come first since a site
+// linking to its own feed is a stronger signal than a guessed path.
+func (h *DiscoverHandler) collectCandidates(siteURL string, doc *goquery.Document) []FeedCandidate {
+	seen := make(map[string]bool)
+	candidates := make([]FeedCandidate, 0)
+
+	add := func(rawURL, source string) {
+		absURL, err := h.ensureAbsoluteURL(siteURL, rawURL)
+		if err != nil || seen[absURL] {
+			return
+		}
+		if u, err := url.Parse(absURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		seen[absURL] = true
+		candidates = append(candidates, FeedCandidate{URL: absURL, Source: source})
+	}
+
+	if doc != nil {
+		anchors := 0
+		doc.Find("a[href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			href := strings.TrimSpace(s.AttrOr("href", ""))
+			if href == "" || strings.HasPrefix(href, "#") {
+				return true
+			}
+			if isFeedLikeLink(href, s.Text()) {
+				before := len(candidates)
+				add(href, "anchor")
+				if len(candidates) > before {
+					anchors++
+				}
+			}
+			return anchors < maxAnchorCandidates
+		})
+	}
+
+	base, err := url.Parse(siteURL)
 	if err != nil {
-		return "", err
+		return candidates
 	}
 
-	// Look for RSS feed links
-	var feedURL string
-	doc.Find(`link[type="application/rss+xml"], link[type="application/atom+xml"]`).Each(func(i int, s *goquery.Selection) {
-		if href, exists := s.Attr("href"); exists && feedURL == "" {
-			feedURL = href
+	// Probe relative to the site root and, for sub-paths, the page itself
+	bases := []*url.URL{{Scheme: base.Scheme, Host: base.Host, Path: "/"}}
+	if base.Path != "" && base.Path != "/" {
+		pageBase := *base
+		pageBase.RawQuery = ""
+		pageBase.Fragment = ""
+		if !strings.HasSuffix(pageBase.Path, "/") {
+			pageBase.Path += "/"
+		}
+		bases = append(bases, &pageBase)
+	}
+
+	for _, b := range bases {
+		for _, p := range wellKnownFeedPaths {
+			ref, err := url.Parse(p)
+			if err != nil {
+				continue
+			}
+			add(b.ResolveReference(ref).String(), "probe")
+		}
+	}
+
+	return candidates
+}
+
+// verifyCandidates parses each candidate with the feed service, dropping the
+// ones that are not real feeds, and returns the rest ranked best first
+func (h *DiscoverHandler) verifyCandidates(ctx context.Context, candidates []FeedCandidate) []FeedCandidate {
+	verified := make([]*FeedCandidate, len(candidates))
+	semaphore := make(chan struct{}, candidateConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range candidates {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			feed, err := h.feedService.ParseSingleFeed(ctx, candidates[idx].URL)
+			if err != nil || feed == nil {
+				return
+			}
+
+			candidate := candidates[idx]
+			candidate.Title = feed.Title
+			candidate.ItemCount = len(feed.Items)
+			if !feed.LastUpdated.IsZero() {
+				candidate.LastUpdated = feed.LastUpdated.UTC().Format(time.RFC3339)
+			}
+			candidate.score = scoreCandidate(feed.Title, len(feed.Items), feed.LastUpdated)
+			candidate.identity = feedIdentity(candidate.URL, feed.SelfURL)
+			verified[idx] = &candidate
+		}(i)
+	}
+
+	wg.Wait()
+
+	results := make([]FeedCandidate, 0, len(candidates))
+	seenFeeds := make(map[string]bool)
+	for _, c := range verified {
+		if c == nil {
+			continue
 		}
+		// Several paths often serve the same feed (e.g. /feed and /rss)
+		if seenFeeds[c.identity] {
+			continue
+		}
+		seenFeeds[c.identity] = true
+		results = append(results, *c)
+	}
+
+	// Stable sort keeps anchors ahead of probes when scores tie
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
 	})
 
-	if feedURL == "" {
-		return "", errors.New("no RSS feed found")
+	return results
+}
+
+// feedIdentity returns where a verified feed ends up: the URL it declares
+// for itself, if any, otherwise the URL it was fetched from. The scheme and
+// trailing slashes are ignored.
+func feedIdentity(candidateURL, selfURL string) string {
+	final := candidateURL
+	if selfURL != "" {
+		if base, err := url.Parse(candidateURL); err == nil {
+			if self, err := base.Parse(selfURL); err == nil {
+				final = self.String()
+			}
+		}
 	}
 
-	// Ensure absolute URL
-	feedURL, err = h.ensureAbsoluteURL(siteURL, feedURL)
+	u, err := url.Parse(final)
 	if err != nil {
-		return "", err
+		return final
+	}
+	identity := strings.ToLower(u.Host) + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		identity += "?" + u.RawQuery
+	}
+	return identity
+}
+
+// scoreCandidate ranks a verified feed: titled, populated and recently
+// updated feeds are preferred over empty or stale ones
+func scoreCandidate(title string, itemCount int, lastUpdated time.Time) int {
+	score := 0
+	if title != "" {
+		score += 10
+	}
+
+	if itemCount > 20 {
+		score += 20
+	} else {
+		score += itemCount
+	}
+
+	if !lastUpdated.IsZero() {
+		age := time.Since(lastUpdated)
+		switch {
+		case age < 7*24*time.Hour:
+			score += 20
+		case age < 30*24*time.Hour:
+			score += 10
+		case age < 365*24*time.Hour:
+			score += 5
+		}
+	}
+
+	return score
+}
+
+// isFeedLikeLink reports whether an anchor looks like it points to a feed
+func isFeedLikeLink(href, text string) bool {
+	href = strings.ToLower(href)
+	text = strings.ToLower(text)
+
+	if strings.Contains(href, "rss") || strings.Contains(text, "rss") {
+		return true
+	}
+	if strings.Contains(href, "atom") || strings.Contains(text, "atom feed") {
+		return true
 	}
 
-	return feedURL, nil
+	path := strings.TrimSuffix(strings.SplitN(href, "?", 2)[0], "/")
+	return strings.HasSuffix(path, "/feed")
 }
 
 // generateGitHubFeedURL generates RSS feed URL for GitHub repositories
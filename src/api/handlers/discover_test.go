@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// mockHTTPClient is a mock implementation of the HTTPClient interface
+type mockHTTPClient struct {
+	getFunc func(ctx context.Context, url string) (interfaces.Response, error)
+}
+
+func (m *mockHTTPClient) Get(ctx context.Context, url string) (interfaces.Response, error) {
+	if m.getFunc != nil {
+		return m.getFunc(ctx, url)
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockHTTPClient) Post(ctx context.Context, url string, body io.Reader) (interfaces.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+// mockResponse is a mock implementation of the Response interface
+type mockResponse struct {
+	statusCode int
+	body       string
+	headers    map[string]string
+}
+
+func (m *mockResponse) StatusCode() int {
+	return m.statusCode
+}
+
+func (m *mockResponse) Body() io.ReadCloser {
+	return io.NopCloser(strings.NewReader(m.body))
+}
+
+func (m *mockResponse) Header(key string) string {
+	if m.headers != nil {
+		return m.headers[key]
+	}
+	return ""
+}
+
+func pageClient(body string) *mockHTTPClient {
+	return &mockHTTPClient{
+		getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+			return &mockResponse{statusCode: 200, body: body}, nil
+		},
+	}
+}
+
+func TestDiscoverFeedURL_AdvertisedLink(t *testing.T) {
+	page := `<html><head><link rel="alternate" type="application/rss+xml" href="/feed.xml"></head></html>`
+	handler := NewDiscoverHandler(pageClient(page), &mockFeedService{})
+
+	feedURL, candidates, err := handler.discoverFeedURL(context.Background(), "https://example.com/blog")
+	if err != nil {
+		t.Fatalf("discoverFeedURL returned error: %v", err)
+	}
+	if feedURL != "https://example.com/feed.xml" {
+		t.Errorf("feedURL = %q, want https://example.com/feed.xml", feedURL)
+	}
+	if candidates != nil {
+		t.Errorf("expected no fallback candidates, got %d", len(candidates))
+	}
+}
+
+func TestDiscoverFeedURL_FallbackProbesAndRanks(t *testing.T) {
+	page := `<html><body>
+		<a href="/subscribe/rss">Subscribe via RSS</a>
+		<a href="/about">About</a>
+	</body></html>`
+
+	feedService := &mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			switch url {
+			case "https://example.com/subscribe/rss":
+				return &domain.Feed{
+					Title:       "Old Feed",
+					Items:       make([]domain.FeedItem, 2),
+					LastUpdated: time.Now().Add(-400 * 24 * time.Hour),
+				}, nil
+			case "https://example.com/index.xml":
+				return &domain.Feed{
+					Title:       "Fresh Feed",
+					Items:       make([]domain.FeedItem, 15),
+					LastUpdated: time.Now().Add(-time.Hour),
+				}, nil
+			}
+			return nil, errors.New("not a feed")
+		},
+	}
+	handler := NewDiscoverHandler(pageClient(page), feedService)
+
+	feedURL, candidates, err := handler.discoverFeedURL(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("discoverFeedURL returned error: %v", err)
+	}
+	if feedURL != "https://example.com/index.xml" {
+		t.Errorf("feedURL = %q, want the fresh feed", feedURL)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2", len(candidates))
+	}
+	if candidates[0].Title != "Fresh Feed" || candidates[0].ItemCount != 15 || candidates[0].Source != "probe" {
+		t.Errorf("unexpected best candidate: %+v", candidates[0])
+	}
+	if candidates[1].Source != "anchor" {
+		t.Errorf("second candidate source = %q, want anchor", candidates[1].Source)
+	}
+	if candidates[0].LastUpdated == "" {
+		t.Error("expected lastUpdated to be set")
+	}
+}
+
+func TestDiscoverFeedURL_DeduplicatesByFeedURL(t *testing.T) {
+	page := `<html><body><a href="/rss">RSS</a><a href="/news/rss">News RSS</a></body></html>`
+	updated := time.Now().Add(-time.Hour)
+
+	feedService := &mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			switch url {
+			case "https://example.com/rss", "https://example.com/feed":
+				// Both paths serve the feed that lives at /feed/
+				return &domain.Feed{Title: "Blog", SelfURL: "/feed/", Items: make([]domain.FeedItem, 3), LastUpdated: updated}, nil
+			case "https://example.com/news/rss":
+				// A different feed that happens to share the title and date
+				return &domain.Feed{Title: "Blog", Items: make([]domain.FeedItem, 3), LastUpdated: updated}, nil
+			}
+			return nil, errors.New("not a feed")
+		},
+	}
+	handler := NewDiscoverHandler(pageClient(page), feedService)
+
+	_, candidates, err := handler.discoverFeedURL(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("discoverFeedURL returned error: %v", err)
+	}
+	var urls []string
+	for _, c := range candidates {
+		urls = append(urls, c.URL)
+	}
+	if strings.Join(urls, " ") != "https://example.com/rss https://example.com/news/rss" {
+		t.Errorf("candidates = %v, want one per feed URL", urls)
+	}
+}
+
+func TestDiscoverFeeds_SharesOneDeadline(t *testing.T) {
+	var mu sync.Mutex
+	var deadlines []time.Time
+	feedService := &mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Error("expected candidates to be verified under a deadline")
+			}
+			mu.Lock()
+			deadlines = append(deadlines, deadline)
+			mu.Unlock()
+			return nil, errors.New("not a feed")
+		},
+	}
+	handler := NewDiscoverHandler(pageClient(`<html><body>Nothing here</body></html>`), feedService)
+
+	input := &DiscoverFeedsInput{}
+	input.Body.URLs = []string{"https://example.com"}
+	if _, err := handler.DiscoverFeeds(context.Background(), input); err != nil {
+		t.Fatalf("DiscoverFeeds returned error: %v", err)
+	}
+	latest := time.Now().Add(discoveryTimeout)
+	if len(deadlines) == 0 {
+		t.Fatal("expected candidates to be probed")
+	}
+	for _, deadline := range deadlines {
+		if !deadline.Equal(deadlines[0]) || deadline.After(latest) {
+			t.Errorf("deadline %v is not the request deadline", deadline)
+		}
+	}
+}
+
+func TestDiscoverFeedURL_NoFeedFound(t *testing.T) {
+	feedService := &mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			return nil, errors.New("not a feed")
+		},
+	}
+	handler := NewDiscoverHandler(pageClient(`<html><body>Nothing here</body></html>`), feedService)
+
+	_, _, err := handler.discoverFeedURL(context.Background(), "https://example.com")
+	if err == nil || err.Error() != "no RSS feed found" {
+		t.Errorf("expected 'no RSS feed found', got %v", err)
+	}
+}
+
+func TestCollectCandidates_IncludesWellKnownPaths(t *testing.T) {
+	handler := NewDiscoverHandler(nil, nil)
+
+	candidates := handler.collectCandidates("https://example.com/blog/", nil)
+
+	want := map[string]bool{
+		"https://example.com/feed":            false,
+		"https://example.com/rss.xml":         false,
+		"https://example.com/atom.xml":        false,
+		"https://example.com/feed.json":       false,
+		"https://example.com/?feed=rss2":      false,
+		"https://example.com/blog/feed":       false,
+		"https://example.com/blog/index.xml":  false,
+		"https://example.com/blog/?feed=rss2": false,
+	}
+	for _, c := range candidates {
+		if _, ok := want[c.URL]; ok {
+			want[c.URL] = true
+		}
+	}
+	for u, found := range want {
+		if !found {
+			t.Errorf("expected candidate %s", u)
+		}
+	}
+}
+
+func TestIsFeedLikeLink(t *testing.T) {
+	tests := []struct {
+		href string
+		text string
+		want bool
+	}{
+		{"/rss.xml", "", true},
+		{"/subscribe", "RSS", true},
+		{"/blog/feed/", "Feed", true},
+		{"/feedback", "Feedback", false},
+		{"/about", "About us", false},
+	}
+
+	for _, tt := range tests {
+		if got := isFeedLikeLink(tt.href, tt.text); got != tt.want {
+			t.Errorf("isFeedLikeLink(%q, %q) = %v, want %v", tt.href, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Enrichment handler reports the progress of background enrichment jobs
+// ABOUTME: Returns the thumbnail results computed so far, with a status per URL
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"digests-app-api/api/dto/responses"
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// EnrichmentHandler handles enrichment job requests
+type EnrichmentHandler struct {
+	jobs interfaces.EnrichmentJobService
+}
+
+// NewEnrichmentHandler creates a new enrichment handler
+func NewEnrichmentHandler(jobs interfaces.EnrichmentJobService) *EnrichmentHandler {
+	return &EnrichmentHandler{jobs: jobs}
+}
+
+// RegisterRoutes registers all enrichment routes
+func (h *EnrichmentHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "getEnrichmentJob",
+		Method:      http.MethodGet,
+		Path:        "/enrichment/{jobId}",
+		Summary:     "Get the results of an enrichment job",
+		Description: "Returns the thumbnail colors, palettes and placeholders computed so far for a job started by /parse. Jobs can be polled for an hour.",
+		Tags:        []string{"Enrichment"},
+	}, h.GetEnrichmentJob)
+}
+
+// EnrichmentResult is the outcome for one image of a job
+type EnrichmentResult struct {
+	URL       string                          `json:"url"`
+	Status    string                          `json:"status" enum:"pending,done,failed"`
+	Color     *responses.ColorV1              `json:"color,omitempty"`
+	Palette   *responses.PaletteV1            `json:"palette,omitempty"`
+	BlurHash  string                          `json:"blurHash,omitempty"`
+	LQIP      string                          `json:"lqip,omitempty"`
+	Width     int                             `json:"width,omitempty"`
+	Height    int                             `json:"height,omitempty"`
+	Rejection *responses.ThumbnailRejectionV1 `json:"rejection,omitempty" doc:"Why the image is not used as a thumbnail, if it is not"`
+	Error     string                          `json:"error,omitempty"`
+}
+
+// GetEnrichmentJobInput defines the input for an enrichment job
+type GetEnrichmentJobInput struct {
+	JobID string `path:"jobId" doc:"Job ID returned as enrichmentJob by /parse"`
+}
+
+// GetEnrichmentJobOutput defines the output for an enrichment job
+type GetEnrichmentJobOutput struct {
+	Body struct {
+		ID        string             `json:"id"`
+		Status    string             `json:"status" enum:"pending,done" doc:"done once no image is pending"`
+		CreatedAt time.Time          `json:"createdAt"`
+		Results   []EnrichmentResult `json:"results"`
+	}
+}
+
+// GetEnrichmentJob handles the GET /enrichment/{jobId} endpoint
+func (h *EnrichmentHandler) GetEnrichmentJob(ctx context.Context, input *GetEnrichmentJobInput) (*GetEnrichmentJobOutput, error) {
+	job, err := h.jobs.GetJob(ctx, input.JobID)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	output := &GetEnrichmentJobOutput{}
+	output.Body.ID = job.ID
+	output.Body.Status = job.Status()
+	output.Body.CreatedAt = job.CreatedAt
+	output.Body.Results = make([]EnrichmentResult, 0, len(job.URLs))
+	for _, imageURL := range job.URLs {
+		output.Body.Results = append(output.Body.Results, toEnrichmentResult(imageURL, job.Results[imageURL]))
+	}
+
+	return output, nil
+}
+
+// toEnrichmentResult converts a job result into its API representation
+func toEnrichmentResult(imageURL string, result *domain.EnrichmentResult) EnrichmentResult {
+	converted := EnrichmentResult{URL: imageURL, Status: domain.EnrichmentPending}
+	if result == nil {
+		return converted
+	}
+
+	converted.Status = result.Status
+	converted.Error = result.Error
+	if result.Palette != nil {
+		converted.Palette = responses.ConvertPaletteToV1(result.Palette)
+		converted.Color = &converted.Palette.Dominant
+	}
+	if result.Placeholder != nil {
+		converted.BlurHash = result.Placeholder.BlurHash
+		converted.LQIP = result.Placeholder.LQIP
+		converted.Width = result.Placeholder.Width
+		converted.Height = result.Placeholder.Height
+	}
+	if result.Rejection != nil {
+		converted.Rejection = &responses.ThumbnailRejectionV1{
+			URL:    result.Rejection.URL,
+			Reason: result.Rejection.Reason,
+			Detail: result.Rejection.Detail,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/errors"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+type mockEnrichmentJobService struct {
+	jobs     map[string]*domain.EnrichmentJob
+	started  [][]string
+	startErr error
+}
+
+func (m *mockEnrichmentJobService) StartThumbnailJob(ctx context.Context, imageURLs []string) (*domain.EnrichmentJob, error) {
+	m.started = append(m.started, imageURLs)
+	if m.startErr != nil {
+		return nil, m.startErr
+	}
+	job := domain.NewEnrichmentJob("job-1", imageURLs)
+	if m.jobs == nil {
+		m.jobs = make(map[string]*domain.EnrichmentJob)
+	}
+	m.jobs[job.ID] = job
+	return job, nil
+}
+
+func (m *mockEnrichmentJobService) GetJob(ctx context.Context, id string) (*domain.EnrichmentJob, error) {
+	if job, ok := m.jobs[id]; ok {
+		return job, nil
+	}
+	return nil, &errors.NotFoundError{Resource: "enrichment job", ID: id}
+}
+
+func TestEnrichmentHandler_GetEnrichmentJob(t *testing.T) {
+	job := domain.NewEnrichmentJob("job-1", []string{
+		"https://img.example.com/a.jpg",
+		"https://img.example.com/b.jpg",
+		"https://img.example.com/c.jpg",
+	})
+	job.Results["https://img.example.com/a.jpg"] = &domain.EnrichmentResult{
+		Status:      domain.EnrichmentDone,
+		Palette:     &domain.ColorPalette{Dominant: domain.RGBColor{R: 20, G: 30, B: 40}},
+		Placeholder: &domain.ImagePlaceholder{BlurHash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj", Width: 1200, Height: 630},
+	}
+	job.Results["https://img.example.com/b.jpg"] = &domain.EnrichmentResult{
+		Status:    domain.EnrichmentFailed,
+		Rejection: &domain.ThumbnailRejection{URL: "https://img.example.com/b.jpg", Reason: domain.ThumbnailBadStatus, Detail: "status 404"},
+		Error:     "status 404",
+	}
+	jobs := &mockEnrichmentJobService{jobs: map[string]*domain.EnrichmentJob{job.ID: job}}
+
+	_, api := humatest.New(t)
+	NewEnrichmentHandler(jobs).RegisterRoutes(api)
+
+	resp := api.Get("/enrichment/job-1")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var body struct {
+		ID      string             `json:"id"`
+		Status  string             `json:"status"`
+		Results []EnrichmentResult `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.ID != "job-1" || body.Status != domain.EnrichmentPending || len(body.Results) != 3 {
+		t.Fatalf("unexpected job: %+v", body)
+	}
+	done, failed, pending := body.Results[0], body.Results[1], body.Results[2]
+	if done.Status != domain.EnrichmentDone || done.Color == nil || done.Color.R != 20 || done.BlurHash == "" || done.Width != 1200 {
+		t.Errorf("unexpected done result: %+v", done)
+	}
+	if failed.Status != domain.EnrichmentFailed || failed.Error != "status 404" || failed.Rejection == nil || failed.Palette != nil {
+		t.Errorf("unexpected failed result: %+v", failed)
+	}
+	if pending.Status != domain.EnrichmentPending || pending.Palette != nil {
+		t.Errorf("unexpected pending result: %+v", pending)
+	}
+
+	if resp := api.Get("/enrichment/unknown"); resp.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for an unknown job", resp.Code)
+	}
+}
@@ -0,0 +1,167 @@
+// ABOUTME: Export handler for the Huma API
+// ABOUTME: Bundles articles, or the latest items of a share, into an EPUB e-book
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/epub"
+	"digests-app-api/core/interfaces"
+	"digests-app-api/core/publish"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// defaultShareArticles is how many of a share's latest items an e-book holds by default
+const defaultShareArticles = 20
+
+// filenameUnsafe matches runs of characters left out of download filenames
+var filenameUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ExportHandler handles e-book export requests
+type ExportHandler struct {
+	epubService  interfaces.EPUBService
+	shareService interfaces.ShareService
+	feedService  interfaces.FeedService
+}
+
+// NewExportHandler creates a new export handler. The share and feed
+// services are used to export shares and may be nil.
+func NewExportHandler(epubService interfaces.EPUBService, shareService interfaces.ShareService, feedService interfaces.FeedService) *ExportHandler {
+	return &ExportHandler{
+		epubService:  epubService,
+		shareService: shareService,
+		feedService:  feedService,
+	}
+}
+
+// RegisterRoutes registers all export routes
+func (h *ExportHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "exportEPUB",
+		Method:      http.MethodPost,
+		Path:        "/export/epub",
+		Summary:     "Export articles as an EPUB",
+		Description: "Extracts reader views of articles, or of the latest items of a share, and bundles them into an EPUB 3 e-book with a cover, table of contents and embedded images",
+		Tags:        []string{"Export"},
+	}, h.ExportEPUB)
+}
+
+// ExportEPUBInput defines the input for the ExportEPUB operation
+type ExportEPUBInput struct {
+	Body struct {
+		URLs     []string `json:"urls,omitempty" maxItems:"20" doc:"Article URLs, in reading order"`
+		ShareID  string   `json:"shareId,omitempty" doc:"Share whose latest items are exported instead of urls"`
+		Limit    int      `json:"limit,omitempty" minimum:"0" maximum:"20" doc:"Number of share items to export (default 20)"`
+		Title    string   `json:"title,omitempty" maxLength:"200" doc:"Book title; defaults to the share title or a dated digest title"`
+		Author   string   `json:"author,omitempty" maxLength:"200" doc:"Book author"`
+		Language string   `json:"language,omitempty" maxLength:"35" doc:"Book language as a BCP 47 tag; defaults to the first article's language"`
+	}
+}
+
+// ExportDocumentOutput is a generated e-book download
+type ExportDocumentOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+// ExportEPUB handles the POST /export/epub endpoint
+func (h *ExportHandler) ExportEPUB(ctx context.Context, input *ExportEPUBInput) (*ExportDocumentOutput, error) {
+	opts := domain.EPUBOptions{
+		Title:    input.Body.Title,
+		Author:   input.Body.Author,
+		Language: input.Body.Language,
+	}
+
+	var targets []domain.ReaderTarget
+	switch {
+	case input.Body.ShareID != "" && len(input.Body.URLs) > 0:
+		return nil, huma.Error400BadRequest("Provide either urls or shareId, not both")
+	case input.Body.ShareID != "":
+		shareTargets, title, err := h.shareTargets(ctx, input.Body.ShareID, input.Body.Limit)
+		if err != nil {
+			return nil, err
+		}
+		targets = shareTargets
+		if opts.Title == "" {
+			opts.Title = title
+		}
+	case len(input.Body.URLs) > 0:
+		for _, url := range input.Body.URLs {
+			targets = append(targets, domain.ReaderTarget{URL: url})
+		}
+	default:
+		return nil, huma.Error400BadRequest("No URLs provided")
+	}
+
+	book, err := h.epubService.BuildEPUB(ctx, targets, opts)
+	if errors.Is(err, epub.ErrNoArticles) {
+		return nil, huma.Error422UnprocessableEntity("None of the articles could be extracted")
+	}
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &ExportDocumentOutput{
+		ContentType:        epub.MediaType,
+		ContentDisposition: fmt.Sprintf(`attachment; filename="%s.epub"`, epubFilename(opts.Title)),
+		Body:               book,
+	}, nil
+}
+
+// shareTargets returns the newest items of a share's feeds along with the
+// share title. Items carry their feed, so full-text feeds need no page fetch.
+func (h *ExportHandler) shareTargets(ctx context.Context, id string, limit int) ([]domain.ReaderTarget, string, error) {
+	if h.shareService == nil || h.feedService == nil {
+		return nil, "", huma.Error404NotFound("Share not found")
+	}
+
+	found, err := lookupShare(ctx, h.shareService, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	feeds, err := h.feedService.ParseFeeds(ctx, found.URLs)
+	if err != nil {
+		return nil, "", toHumaError(err)
+	}
+
+	if limit <= 0 {
+		limit = defaultShareArticles
+	}
+	channel, err := publish.BuildChannel(feeds, publish.Options{Title: found.Title, Limit: limit})
+	if err != nil {
+		return nil, "", huma.Error422UnprocessableEntity("Share has no feeds to export")
+	}
+
+	var targets []domain.ReaderTarget
+	for _, entry := range channel.Items {
+		if entry.Link != "" {
+			targets = append(targets, domain.ReaderTarget{URL: entry.Link, FeedURL: entry.FeedURL, ItemID: entry.ID})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, "", huma.Error422UnprocessableEntity("Share has no articles to export")
+	}
+
+	return targets, found.Title, nil
+}
+
+// epubFilename turns a book title into a safe download filename
+func epubFilename(title string) string {
+	name := strings.Trim(filenameUnsafe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(name) > 80 {
+		name = strings.TrimRight(name[:80], "-")
+	}
+	if name == "" {
+		return "digest"
+	}
+	return name
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/epub"
+	"digests-app-api/core/share"
+	"digests-app-api/infrastructure/storage/memory"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+// mockEPUBService records what it was asked to export
+type mockEPUBService struct {
+	targets []domain.ReaderTarget
+	opts    domain.EPUBOptions
+	err     error
+}
+
+func (m *mockEPUBService) BuildEPUB(ctx context.Context, targets []domain.ReaderTarget, opts domain.EPUBOptions) ([]byte, error) {
+	m.targets = targets
+	m.opts = opts
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte("PK epub"), nil
+}
+
+func TestExportHandler_URLs(t *testing.T) {
+	service := &mockEPUBService{}
+	handler := NewExportHandler(service, nil, nil)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/export/epub", map[string]interface{}{
+		"urls":   []string{"https://example.com/a", "https://example.com/b"},
+		"title":  "Weekend Reading: Vol. 2",
+		"author": "Sam",
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != epub.MediaType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := resp.Header().Get("Content-Disposition"); cd != `attachment; filename="weekend-reading-vol-2.epub"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if resp.Body.String() != "PK epub" {
+		t.Errorf("body = %q", resp.Body.String())
+	}
+	if len(service.targets) != 2 || service.targets[1].URL != "https://example.com/b" || service.opts.Author != "Sam" {
+		t.Errorf("unexpected export: %+v %+v", service.targets, service.opts)
+	}
+}
+
+func TestExportHandler_Share(t *testing.T) {
+	var requested []string
+	storage := memory.NewShareStorage()
+	shareService := share.NewShareService(storage)
+	created, err := shareService.CreateShareWithOptions(context.Background(), []string{"https://a.example.com/feed", "https://b.example.com/feed"}, domain.ShareOptions{Title: "Team picks"})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+
+	service := &mockEPUBService{}
+	handler := NewExportHandler(service, shareService, publishFeedService(&requested))
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/export/epub", map[string]interface{}{"shareId": created.ID, "limit": 3})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+
+	// The newest items come first and keep their feed for full-text content
+	if len(service.targets) != 3 {
+		t.Fatalf("Expected 3 articles, got %+v", service.targets)
+	}
+	first := service.targets[0]
+	if first.FeedURL == "" || first.ItemID != first.URL || first.URL != first.FeedURL+"/1" {
+		t.Errorf("unexpected first article: %+v", first)
+	}
+	if service.opts.Title != "Team picks" {
+		t.Errorf("Expected the share title, got %q", service.opts.Title)
+	}
+
+	if resp := api.Post("/export/epub", map[string]interface{}{"shareId": "550e8400-e29b-41d4-a716-446655440000"}); resp.Code != http.StatusNotFound {
+		t.Errorf("missing share status = %d, want 404", resp.Code)
+	}
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	service := &mockEPUBService{}
+	handler := NewExportHandler(service, nil, nil)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	if resp := api.Post("/export/epub", map[string]interface{}{}); resp.Code != http.StatusBadRequest {
+		t.Errorf("empty request status = %d, want 400", resp.Code)
+	}
+	if resp := api.Post("/export/epub", map[string]interface{}{"urls": []string{"https://example.com/a"}, "shareId": "abc"}); resp.Code != http.StatusBadRequest {
+		t.Errorf("urls and shareId status = %d, want 400", resp.Code)
+	}
+
+	service.err = epub.ErrNoArticles
+	if resp := api.Post("/export/epub", map[string]interface{}{"urls": []string{"https://example.com/a"}}); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("no articles status = %d, want 422", resp.Code)
+	}
+}
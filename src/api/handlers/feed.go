@@ -6,6 +6,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"digests-app-api/api/dto/mappers"
 	"digests-app-api/api/dto/requests"
@@ -13,6 +14,8 @@ import (
 	"digests-app-api/core/config"
 	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
+	"digests-app-api/core/language"
+	utiltime "digests-app-api/pkg/utils/time"
 	"github.com/danielgtaylor/huma/v2"
 )
 
@@ -20,8 +23,17 @@ import (
 type FeedHandler struct {
 	feedService      interfaces.FeedService
 	enrichmentService interfaces.ContentEnrichmentService
+	summarizer        interfaces.Summarizer
+	tagger            interfaces.Tagger
+	clusterer         interfaces.StoryClusterer
+	enrichmentJobs    interfaces.EnrichmentJobService
+	logger            interfaces.Logger
 }
 
+// backgroundPaletteTimeout bounds palettes computed outside a request when
+// no enrichment job could be started
+const backgroundPaletteTimeout = 2 * time.Minute
+
 // NewFeedHandler creates a new feed handler
 func NewFeedHandler(feedService interfaces.FeedService, enrichmentService interfaces.ContentEnrichmentService) *FeedHandler {
 	return &FeedHandler{
@@ -30,6 +42,32 @@ func NewFeedHandler(feedService interfaces.FeedService, enrichmentService interf
 	}
 }
 
+// SetSummarizer enables the summarize enrichment of /parse
+func (h *FeedHandler) SetSummarizer(summarizer interfaces.Summarizer) {
+	h.summarizer = summarizer
+}
+
+// SetTagger enables the auto_tag enrichment of /parse
+func (h *FeedHandler) SetTagger(tagger interfaces.Tagger) {
+	h.tagger = tagger
+}
+
+// SetClusterer enables the cluster enrichment of /parse
+func (h *FeedHandler) SetClusterer(clusterer interfaces.StoryClusterer) {
+	h.clusterer = clusterer
+}
+
+// SetEnrichmentJobs runs background thumbnail enrichment as jobs whose IDs
+// are returned, so clients can poll for the results
+func (h *FeedHandler) SetEnrichmentJobs(jobs interfaces.EnrichmentJobService) {
+	h.enrichmentJobs = jobs
+}
+
+// SetLogger sets the logger background enrichment failures are reported to
+func (h *FeedHandler) SetLogger(logger interfaces.Logger) {
+	h.logger = logger
+}
+
 // RegisterRoutes registers all feed-related routes
 func (h *FeedHandler) RegisterRoutes(api huma.API) {
 	huma.Register(api, huma.Operation{
@@ -75,10 +113,15 @@ func (h *FeedHandler) ParseFeeds(ctx context.Context, input *ParseFeedsInput) (*
 		return nil, toHumaError(err)
 	}
 
+	// Drop items in other languages before enriching them
+	for _, feed := range feeds {
+		filterLanguages(feed, input.Body.Languages, input.Body.StrictLanguages)
+	}
+
 	// Extract article URLs for metadata extraction (if enabled)
 	articleURLs := make([]string, 0)
 	urlToItemMap := make(map[string]*domain.FeedItem)
-	var metadataResults map[string]*interfaces.MetadataResult
+	var metadataResults map[string]*domain.PageMetadata
 	
 	if enrichmentConfig.ExtractMetadata {
 		for _, feed := range feeds {
@@ -97,71 +140,129 @@ func (h *FeedHandler) ParseFeeds(ctx context.Context, input *ParseFeedsInput) (*
 		}
 	}
 
-	// Update items with metadata thumbnails
-	thumbnailURLs := make([]string, 0)
+	// Metadata images are tried before the feed's own, as they are usually
+	// the article's lead image; the page also supplies the author and date
+	// when the feed leaves them out
 	for url, metadata := range metadataResults {
-		if item, exists := urlToItemMap[url]; exists && metadata != nil && metadata.Thumbnail != "" {
-			// Update the item's thumbnail with the one from metadata
-			item.Thumbnail = metadata.Thumbnail
-			thumbnailURLs = append(thumbnailURLs, metadata.Thumbnail)
+		if item, exists := urlToItemMap[url]; exists && metadata != nil {
+			item.ThumbnailCandidates = mergeThumbnailCandidates(item, metadata)
+			fillFromMetadata(item, metadata)
 		}
 	}
 
-	// Also collect any existing thumbnails from RSS feed
+	// Skip thumbnails already found broken, tiny, blank or repeated across a
+	// feed; new ones are checked with their palettes below
+	if h.enrichmentService != nil {
+		h.enrichmentService.SelectThumbnails(ctx, feeds)
+	}
+
+	// Collect the thumbnails picked for items
+	thumbnailURLs := make([]string, 0)
+	seenThumbnails := make(map[string]bool)
 	for _, feed := range feeds {
 		for i := range feed.Items {
 			item := &feed.Items[i]
-			if item.Thumbnail != "" {
-				// Check if we haven't already added this thumbnail
-				found := false
-				for _, url := range thumbnailURLs {
-					if url == item.Thumbnail {
-						found = true
-						break
-					}
-				}
-				if !found {
-					thumbnailURLs = append(thumbnailURLs, item.Thumbnail)
-				}
+			if item.Thumbnail != "" && !seenThumbnails[item.Thumbnail] {
+				seenThumbnails[item.Thumbnail] = true
+				thumbnailURLs = append(thumbnailURLs, item.Thumbnail)
 			}
 		}
 	}
 
 	// Check cache for already computed colors (if enabled)
 	thumbnailColors := make(map[string]*domain.RGBColor)
+	thumbnailPalettes := make(map[string]*domain.ColorPalette)
+	thumbnailPlaceholders := make(map[string]*domain.ImagePlaceholder)
+	var enrichmentJobID string
 	if enrichmentConfig.ExtractColors {
 		if h.enrichmentService != nil && len(thumbnailURLs) > 0 {
-			// First, check which colors are already in cache
+			// First, check which palettes are already in cache
 			for _, url := range thumbnailURLs {
 				// Try to get from cache without computing
-				if cached, err := h.enrichmentService.GetCachedColor(ctx, url); err == nil && cached != nil {
-					thumbnailColors[url] = cached
+				if cached, err := h.enrichmentService.GetCachedPalette(ctx, url); err == nil && cached != nil {
+					thumbnailPalettes[url] = cached
+					thumbnailColors[url] = &cached.Dominant
+					if placeholder, err := h.enrichmentService.GetCachedPlaceholder(ctx, url); err == nil && placeholder != nil {
+						thumbnailPlaceholders[url] = placeholder
+					}
+				} else if color, err := h.enrichmentService.GetCachedColor(ctx, url); err == nil && color != nil {
+					// Colors cached before palettes; the palette is computed below
+					thumbnailColors[url] = color
 				}
 			}
 			
-			// Collect URLs that need color extraction
+			// Collect URLs that need palette extraction
 			var urlsToProcess []string
 			for _, url := range thumbnailURLs {
-				if _, exists := thumbnailColors[url]; !exists {
+				if _, exists := thumbnailPalettes[url]; !exists {
 					urlsToProcess = append(urlsToProcess, url)
 				}
 			}
 			
-			// If we have URLs to process, do it in the background
-			if len(urlsToProcess) > 0 {
-				// Create a new context that won't be cancelled when request ends
-				backgroundCtx := context.Background()
-				
-				// Process colors in background
-				go func() {
-					h.enrichmentService.ExtractColorBatch(backgroundCtx, urlsToProcess)
-				}()
+			// If we have URLs to process, queue them as a job the client can
+			// poll for the results
+			if len(urlsToProcess) > 0 && h.enrichmentJobs != nil {
+				job, err := h.enrichmentJobs.StartThumbnailJob(ctx, urlsToProcess)
+				if err == nil {
+					enrichmentJobID = job.ID
+				} else if h.logger != nil {
+					h.logger.Warn("Failed to start thumbnail enrichment job", map[string]interface{}{
+						"images": len(urlsToProcess),
+						"error":  err.Error(),
+					})
+				}
+			}
+
+			// Without a job, compute the palettes in the background so a
+			// later request still finds them cached
+			if len(urlsToProcess) > 0 && enrichmentJobID == "" {
+				go func(urls []string) {
+					backgroundCtx, cancel := context.WithTimeout(context.Background(), backgroundPaletteTimeout)
+					defer cancel()
+					h.enrichmentService.ExtractPaletteBatch(backgroundCtx, urls)
+				}(urlsToProcess)
 			}
 		}
 	}
 
+	// Add extractive summaries of item content (if enabled)
+	if enrichmentConfig.Summarize && h.summarizer != nil {
+		var items []*domain.FeedItem
+		for _, feed := range feeds {
+			for i := range feed.Items {
+				items = append(items, &feed.Items[i])
+			}
+		}
+		h.summarizer.SummarizeItems(ctx, items, enrichmentConfig.SummarySentences)
+	}
+
+	// Add keywords and key phrases as auto-tags (if enabled)
+	if enrichmentConfig.AutoTag && h.tagger != nil {
+		var items []*domain.FeedItem
+		for _, feed := range feeds {
+			for i := range feed.Items {
+				items = append(items, &feed.Items[i])
+			}
+		}
+		h.tagger.TagItems(ctx, items, enrichmentConfig.MaxTags)
+	}
+
+	// Group near-identical items across feeds into stories (if enabled)
+	var clusters []domain.StoryCluster
+	if enrichmentConfig.Cluster && h.clusterer != nil {
+		clusters = h.clusterer.ClusterFeeds(ctx, feeds)
+	}
+
 	// Convert directly to V1 format for compatibility with colors
-	v1Response := responses.ConvertDomainFeedsToV1ResponseWithColors(feeds, thumbnailColors)
+	v1Response := responses.ConvertDomainFeedsToV1ResponseWithThumbnails(feeds, responses.ThumbnailEnrichment{
+		Colors:       thumbnailColors,
+		Palettes:     thumbnailPalettes,
+		Placeholders: thumbnailPlaceholders,
+	})
+	if enrichmentConfig.Cluster {
+		v1Response.Clusters = responses.ConvertStoryClustersToV1(clusters)
+	}
+	v1Response.EnrichmentJob = enrichmentJobID
 
 	return &ParseFeedsOutput{
 		Body: v1Response,
@@ -170,11 +271,13 @@ func (h *FeedHandler) ParseFeeds(ctx context.Context, input *ParseFeedsInput) (*
 
 // ParseSingleFeedInput defines the input for the ParseSingleFeed operation
 type ParseSingleFeedInput struct {
-	URL              string `query:"url" required:"true" format:"uri" doc:"Feed URL to parse"`
-	Page             int    `query:"page,omitempty" minimum:"1" default:"1" doc:"Page number for items"`
-	ItemsPerPage     int    `query:"items_per_page,omitempty" minimum:"1" maximum:"100" default:"50" doc:"Number of items per page"`
-	ExtractMetadata  bool   `query:"extract_metadata,omitempty" default:"true" doc:"Extract metadata from article URLs"`
-	ExtractColors    bool   `query:"extract_colors,omitempty" default:"true" doc:"Extract dominant colors from images"`
+	URL              string   `query:"url" required:"true" format:"uri" doc:"Feed URL to parse"`
+	Page             int      `query:"page,omitempty" minimum:"1" default:"1" doc:"Page number for items"`
+	ItemsPerPage     int      `query:"items_per_page,omitempty" minimum:"1" maximum:"100" default:"50" doc:"Number of items per page"`
+	ExtractMetadata  bool     `query:"extract_metadata,omitempty" default:"true" doc:"Extract metadata from article URLs"`
+	ExtractColors    bool     `query:"extract_colors,omitempty" default:"true" doc:"Extract dominant colors from images"`
+	Languages        []string `query:"language,explode" maxItems:"20" doc:"Only include items in one of these languages (repeat the parameter); en also matches en-US"`
+	StrictLanguages  bool     `query:"strict_languages,omitempty" doc:"With language, also drop items whose language could not be detected"`
 }
 
 // ParseSingleFeedOutput defines the output for the ParseSingleFeed operation
@@ -189,6 +292,9 @@ func (h *FeedHandler) ParseSingleFeed(ctx context.Context, input *ParseSingleFee
 	if err != nil {
 		return nil, toHumaError(err)
 	}
+	if feed != nil {
+		filterLanguages(feed, input.Languages, input.StrictLanguages)
+	}
 
 	// Convert to response DTO
 	feedResponse := mappers.ToFeedResponse(feed)
@@ -241,5 +347,74 @@ func (h *FeedHandler) requestOptionsToConfig(opts *requests.EnrichmentOptions) c
 		cfg.ExtractColors = true
 	}
 	
+	if opts.Summarize != nil {
+		cfg.Summarize = *opts.Summarize
+	}
+	cfg.SummarySentences = opts.SummarySentences
+	
+	if opts.AutoTag != nil {
+		cfg.AutoTag = *opts.AutoTag
+	}
+	cfg.MaxTags = opts.MaxTags
+	
+	if opts.Cluster != nil {
+		cfg.Cluster = *opts.Cluster
+	}
+	
 	return cfg
-}
\ No newline at end of file
+}
+
+// filterLanguages drops the items of a feed that are not in one of the
+// wanted languages; items whose language was not detected are only dropped
+// when strict
+func filterLanguages(feed *domain.Feed, wanted []string, strict bool) {
+	if feed == nil || len(wanted) == 0 {
+		return
+	}
+
+	items := make([]domain.FeedItem, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		if language.Keep(item.Language, wanted, strict) {
+			items = append(items, item)
+		}
+	}
+	feed.Items = items
+}
+
+// fillFromMetadata sets the author and publication date of an item that
+// has none from its page's metadata
+func fillFromMetadata(item *domain.FeedItem, metadata *domain.PageMetadata) {
+	if item.Author == "" {
+		item.Author = metadata.Author
+	}
+	if item.Published.IsZero() {
+		if published := utiltime.ParseFlexibleTime(metadata.Published); !published.IsZero() {
+			item.Published = published
+			if item.Created == nil || item.Created.IsZero() {
+				item.Created = &published
+			}
+		}
+	}
+}
+
+// mergeThumbnailCandidates lists an item's thumbnail candidates with the
+// metadata's thumbnail first and its other images last
+func mergeThumbnailCandidates(item *domain.FeedItem, metadata *domain.PageMetadata) []string {
+	existing := item.ThumbnailCandidates
+	if len(existing) == 0 && item.Thumbnail != "" {
+		existing = []string{item.Thumbnail}
+	}
+
+	images := metadata.ImageURLs()
+	candidates := make([]string, 0, len(existing)+len(images)+1)
+	seen := make(map[string]bool)
+	for _, group := range [][]string{{metadata.Thumbnail}, existing, images} {
+		for _, url := range group {
+			if url != "" && !seen[url] {
+				seen[url] = true
+				candidates = append(candidates, url)
+			}
+		}
+	}
+	return candidates
+}
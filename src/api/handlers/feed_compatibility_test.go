@@ -142,4 +142,8 @@ func (m *mockFeedServiceCompat) ParseSingleFeed(ctx context.Context, url string)
 		return nil, err
 	}
 	return feeds[0], nil
-}
\ No newline at end of file
+}
+
+func (m *mockFeedServiceCompat) ParseFeedsWithConfig(ctx context.Context, urls []string, config interface{}) ([]*domain.Feed, error) {
+	return m.ParseFeeds(ctx, urls)
+}
@@ -2,17 +2,24 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"digests-app-api/api/dto/responses"
 	"digests-app-api/core/domain"
-	"digests-app-api/core/interfaces"
+	coreerrors "digests-app-api/core/errors"
 	"github.com/danielgtaylor/huma/v2/humatest"
 )
 
 // mockFeedService is a mock implementation of the feed service
 type mockFeedService struct {
-	parseFeedsFunc func(ctx context.Context, urls []string) ([]*domain.Feed, error)
+	parseFeedsFunc      func(ctx context.Context, urls []string) ([]*domain.Feed, error)
+	parseSingleFeedFunc func(ctx context.Context, url string) (*domain.Feed, error)
 }
 
 func (m *mockFeedService) ParseFeeds(ctx context.Context, urls []string) ([]*domain.Feed, error) {
@@ -23,18 +30,39 @@ func (m *mockFeedService) ParseFeeds(ctx context.Context, urls []string) ([]*dom
 }
 
 func (m *mockFeedService) ParseSingleFeed(ctx context.Context, url string) (*domain.Feed, error) {
+	if m.parseSingleFeedFunc != nil {
+		return m.parseSingleFeedFunc(ctx, url)
+	}
 	return nil, nil
 }
 
+func (m *mockFeedService) ParseFeedsWithConfig(ctx context.Context, urls []string, config interface{}) ([]*domain.Feed, error) {
+	return m.ParseFeeds(ctx, urls)
+}
+
 // mockEnrichmentService is a mock implementation of the content enrichment service
-type mockEnrichmentService struct{}
+type mockEnrichmentService struct {
+	palettes     map[string]*domain.ColorPalette
+	placeholders map[string]*domain.ImagePlaceholder
+	metadata     map[string]*domain.PageMetadata
+	rejections   map[string]string
 
-func (m *mockEnrichmentService) ExtractMetadata(ctx context.Context, url string) (*interfaces.MetadataResult, error) {
+	// paletteBatches receives the images of each ExtractPaletteBatch call
+	paletteBatches chan []string
+}
+
+func (m *mockEnrichmentService) ExtractMetadata(ctx context.Context, url string) (*domain.PageMetadata, error) {
 	return nil, nil
 }
 
-func (m *mockEnrichmentService) ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*interfaces.MetadataResult {
-	return make(map[string]*interfaces.MetadataResult)
+func (m *mockEnrichmentService) ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*domain.PageMetadata {
+	results := make(map[string]*domain.PageMetadata)
+	for _, url := range urls {
+		if metadata, ok := m.metadata[url]; ok {
+			results[url] = metadata
+		}
+	}
+	return results
 }
 
 func (m *mockEnrichmentService) ExtractColor(ctx context.Context, imageURL string) (*domain.RGBColor, error) {
@@ -49,6 +77,61 @@ func (m *mockEnrichmentService) GetCachedColor(ctx context.Context, imageURL str
 	return nil, nil
 }
 
+func (m *mockEnrichmentService) ExtractPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	if palette, ok := m.palettes[imageURL]; ok {
+		return palette, nil
+	}
+	return nil, &coreerrors.ExternalAPIError{StatusCode: http.StatusNotFound, Message: "image cannot be used: bad_status (status 404)", API: "image"}
+}
+
+func (m *mockEnrichmentService) ExtractPaletteBatch(ctx context.Context, imageURLs []string) map[string]*domain.ColorPalette {
+	if m.paletteBatches != nil {
+		m.paletteBatches <- imageURLs
+	}
+	results := make(map[string]*domain.ColorPalette)
+	for _, imageURL := range imageURLs {
+		if palette, ok := m.palettes[imageURL]; ok {
+			results[imageURL] = palette
+		}
+	}
+	return results
+}
+
+func (m *mockEnrichmentService) GetCachedPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	if palette, ok := m.palettes[imageURL]; ok {
+		return palette, nil
+	}
+	return nil, errors.New("not cached")
+}
+
+func (m *mockEnrichmentService) GetCachedPlaceholder(ctx context.Context, imageURL string) (*domain.ImagePlaceholder, error) {
+	if placeholder, ok := m.placeholders[imageURL]; ok {
+		return placeholder, nil
+	}
+	return nil, errors.New("not cached")
+}
+
+func (m *mockEnrichmentService) SelectThumbnails(ctx context.Context, feeds []*domain.Feed) {
+	for _, feed := range feeds {
+		for i := range feed.Items {
+			item := &feed.Items[i]
+			candidates := item.ThumbnailCandidates
+			if len(candidates) == 0 && item.Thumbnail != "" {
+				candidates = []string{item.Thumbnail}
+			}
+			item.Thumbnail = ""
+			for _, candidate := range candidates {
+				if reason, rejected := m.rejections[candidate]; rejected {
+					item.ThumbnailRejections = append(item.ThumbnailRejections, domain.ThumbnailRejection{URL: candidate, Reason: reason})
+					continue
+				}
+				item.Thumbnail = candidate
+				break
+			}
+		}
+	}
+}
+
 func TestNewFeedHandler(t *testing.T) {
 	mockService := &mockFeedService{}
 	mockEnrichment := &mockEnrichmentService{}
@@ -173,4 +256,450 @@ func TestFeedHandler_ParseFeeds_ServiceError(t *testing.T) {
 	if resp.Code != 500 {
 		t.Errorf("Expected status 500 for service error, got %d", resp.Code)
 	}
-}
\ No newline at end of file
+}
+// mockSummarizer returns the first sentence of each text as its summary
+type mockSummarizer struct {
+	sentences int
+	calls     int
+}
+
+func (m *mockSummarizer) Summarize(ctx context.Context, text string, sentences int) []string {
+	m.calls++
+	m.sentences = sentences
+	return []string{strings.SplitAfter(text, ".")[0]}
+}
+
+func (m *mockSummarizer) SummarizeItems(ctx context.Context, items []*domain.FeedItem, sentences int) {
+	for _, item := range items {
+		item.KeySentences = m.Summarize(ctx, item.Content, sentences)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_Summarize(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    "feed1",
+				Title: "Feed 1",
+				URL:   urls[0],
+				Items: []domain.FeedItem{
+					{ID: "1", Title: "Item", Link: "https://example.com/1", Content: "Key sentence. Other sentence."},
+				},
+			}}, nil
+		},
+	}
+	summarizer := &mockSummarizer{}
+	handler := NewFeedHandler(mockService, &mockEnrichmentService{})
+	handler.SetSummarizer(summarizer)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	// Summaries are off by default
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       []string{"https://example.com/feed.xml"},
+		"enrichment": map[string]interface{}{"extract_metadata": false, "extract_colors": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if summarizer.calls != 0 || strings.Contains(resp.Body.String(), "keySentences") {
+		t.Errorf("Expected no summaries unless requested, got %s", resp.Body.String())
+	}
+
+	resp = api.Post("/parse", map[string]interface{}{
+		"urls": []string{"https://example.com/feed.xml"},
+		"enrichment": map[string]interface{}{
+			"extract_metadata":  false,
+			"extract_colors":    false,
+			"summarize":         true,
+			"summary_sentences": 2,
+		},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if summarizer.sentences != 2 {
+		t.Errorf("Expected 2 sentences to be requested, got %d", summarizer.sentences)
+	}
+	if !strings.Contains(resp.Body.String(), `"keySentences":["Key sentence."]`) {
+		t.Errorf("Expected key sentences in the response, got %s", resp.Body.String())
+	}
+}
+
+// mockClusterer puts all items of the feeds into one story
+type mockClusterer struct{}
+
+func (m *mockClusterer) ClusterFeeds(ctx context.Context, feeds []*domain.Feed) []domain.StoryCluster {
+	story := domain.StoryCluster{ID: "story-1"}
+	for _, feed := range feeds {
+		for i := range feed.Items {
+			feed.Items[i].ClusterID = story.ID
+			story.Members = append(story.Members, domain.StoryMember{FeedURL: feed.URL, ItemID: feed.Items[i].ID, Title: feed.Items[i].Title})
+		}
+	}
+	story.Representative = story.Members[0]
+	return []domain.StoryCluster{story}
+}
+
+func TestFeedHandler_ParseFeeds_Cluster(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			feeds := make([]*domain.Feed, 0, len(urls))
+			for _, u := range urls {
+				feeds = append(feeds, &domain.Feed{
+					ID:    u,
+					Title: "Feed",
+					URL:   u,
+					Items: []domain.FeedItem{{ID: u + "#1", Title: "Launch", Link: u + "/1"}},
+				})
+			}
+			return feeds, nil
+		},
+	}
+	handler := NewFeedHandler(mockService, &mockEnrichmentService{})
+	handler.SetClusterer(&mockClusterer{})
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	urls := []string{"https://a.example.com/feed", "https://b.example.com/feed"}
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       urls,
+		"enrichment": map[string]interface{}{"extract_metadata": false, "extract_colors": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if strings.Contains(resp.Body.String(), "cluster") {
+		t.Errorf("Expected no clusters unless requested, got %s", resp.Body.String())
+	}
+
+	resp = api.Post("/parse", map[string]interface{}{
+		"urls":       urls,
+		"enrichment": map[string]interface{}{"extract_metadata": false, "extract_colors": false, "cluster": true},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Feeds[1].Items[0].Cluster != "story-1" {
+		t.Errorf("Expected items to carry their cluster, got %+v", body.Feeds[1].Items[0])
+	}
+	if len(body.Clusters) != 1 || len(body.Clusters[0].Items) != 2 || body.Clusters[0].Representative.ID != "https://a.example.com/feed#1" {
+		t.Errorf("Expected a grouped view of the story, got %+v", body.Clusters)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_Languages(t *testing.T) {
+	newFeed := func(url string) *domain.Feed {
+		return &domain.Feed{
+			ID:                 url,
+			Title:              "Feed",
+			URL:                url,
+			Language:           "en-US",
+			LanguageConfidence: 1,
+			Items: []domain.FeedItem{
+				{ID: "1", Title: "Launch", Link: "https://example.com/1", Language: "en", LanguageConfidence: 0.97},
+				{ID: "2", Title: "Start", Link: "https://example.com/2", Language: "de", LanguageConfidence: 0.88},
+				{ID: "3", Title: "Ok", Link: "https://example.com/3"},
+			},
+		}
+	}
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{newFeed(urls[0])}, nil
+		},
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			return newFeed(url), nil
+		},
+	}
+	handler := NewFeedHandler(mockService, &mockEnrichmentService{})
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       []string{"https://example.com/feed"},
+		"languages":  []string{"DE-AT"},
+		"enrichment": map[string]interface{}{"extract_metadata": false, "extract_colors": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	feed := body.Feeds[0]
+	if feed.Language != "en-US" || feed.LanguageConfidence != 1 {
+		t.Errorf("Expected the feed language, got %q (%v)", feed.Language, feed.LanguageConfidence)
+	}
+	if len(feed.Items) != 2 || feed.Items[0].ID != "2" || feed.Items[0].Language != "de" || feed.Items[0].LanguageConfidence != 0.88 {
+		t.Errorf("Expected the German item, got %+v", feed.Items)
+	}
+	if len(feed.Items) == 2 && feed.Items[1].ID != "3" {
+		t.Errorf("Expected the item without a detected language to be kept, got %+v", feed.Items[1])
+	}
+
+	// Strict filters drop items whose language is unknown
+	resp = api.Post("/parse", map[string]interface{}{
+		"urls":             []string{"https://example.com/feed"},
+		"languages":        []string{"de"},
+		"strict_languages": true,
+		"enrichment":       map[string]interface{}{"extract_metadata": false, "extract_colors": false},
+	})
+	body = responses.ParseFeedsV1Response{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if items := body.Feeds[0].Items; len(items) != 1 || items[0].ID != "2" {
+		t.Errorf("Expected only the German item, got %+v", items)
+	}
+
+	// The single feed endpoint filters the same way
+	resp = api.Get("/feed?url=https://example.com/feed&language=de&strict_languages=true")
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var single responses.FeedResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &single); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(single.Items) != 1 || single.Items[0].ID != "2" {
+		t.Errorf("Expected only the German item from GET /feed, got %+v", single.Items)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_Thumbnails(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    urls[0],
+				Title: "Feed",
+				URL:   urls[0],
+				Items: []domain.FeedItem{
+					{ID: "1", Title: "Cached", Link: "https://example.com/1", Thumbnail: "https://img.example.com/a.jpg"},
+					{ID: "2", Title: "Not cached", Link: "https://example.com/2", Thumbnail: "https://img.example.com/b.jpg"},
+				},
+			}}, nil
+		},
+	}
+	enrichment := &mockEnrichmentService{
+		palettes: map[string]*domain.ColorPalette{
+			"https://img.example.com/a.jpg": {Dominant: domain.RGBColor{R: 20, G: 30, B: 40}, TextColor: domain.RGBColor{R: 255, G: 255, B: 255}, TextContrast: 15.8},
+		},
+		placeholders: map[string]*domain.ImagePlaceholder{
+			"https://img.example.com/a.jpg": {BlurHash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj", LQIP: "data:image/jpeg;base64,/9j/", Width: 1200, Height: 630},
+		},
+	}
+	handler := NewFeedHandler(mockService, enrichment)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       []string{"https://example.com/feed"},
+		"enrichment": map[string]interface{}{"extract_metadata": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	cached, missing := body.Feeds[0].Items[0], body.Feeds[0].Items[1]
+	if cached.ThumbnailPalette == nil || cached.ThumbnailPalette.TextColor.R != 255 || cached.ThumbnailColor.R != 20 || cached.ThumbnailColorComputed != "yes" {
+		t.Errorf("Expected the cached palette, got %+v", cached)
+	}
+	if cached.ThumbnailBlurHash != "LEHV6nWB2yk8pyo0adR*.7kCMdnj" || cached.ThumbnailLQIP == "" || cached.ThumbnailWidth != 1200 || cached.ThumbnailHeight != 630 {
+		t.Errorf("Expected the cached placeholder, got %+v", cached)
+	}
+	if missing.ThumbnailPalette != nil || missing.ThumbnailBlurHash != "" || missing.ThumbnailColorComputed != "no" {
+		t.Errorf("Expected no palette before it is computed, got %+v", missing)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_MetadataFillsItems(t *testing.T) {
+	published := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    urls[0],
+				Title: "Feed",
+				URL:   urls[0],
+				Items: []domain.FeedItem{
+					{ID: "1", Title: "Bare", Link: "https://example.com/1"},
+					{ID: "2", Title: "Complete", Link: "https://example.com/2", Author: "Feed Author", Published: published},
+				},
+			}}, nil
+		},
+	}
+	enrichment := &mockEnrichmentService{
+		metadata: map[string]*domain.PageMetadata{
+			"https://example.com/1": {URL: "https://example.com/1", Author: "Jane Doe", Published: "2024-01-15T10:00:00Z"},
+			"https://example.com/2": {URL: "https://example.com/2", Author: "Page Author", Published: "2024-01-15T10:00:00Z"},
+		},
+	}
+	_, api := humatest.New(t)
+	NewFeedHandler(mockService, enrichment).RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{"urls": []string{"https://example.com/feed"}})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	bare, complete := body.Feeds[0].Items[0], body.Feeds[0].Items[1]
+	if bare.Author != "Jane Doe" || !strings.HasPrefix(bare.Published, "2024-01-15T10:00:00") {
+		t.Errorf("Expected the page's author and date, got %q, %q", bare.Author, bare.Published)
+	}
+	if complete.Author != "Feed Author" || !strings.HasPrefix(complete.Published, "2024-01-10T08:00:00") {
+		t.Errorf("Expected the feed's own author and date to be kept, got %q, %q", complete.Author, complete.Published)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_EnrichmentJob(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    urls[0],
+				Title: "Feed",
+				URL:   urls[0],
+				Items: []domain.FeedItem{
+					{ID: "1", Title: "Cached", Link: "https://example.com/1", Thumbnail: "https://img.example.com/a.jpg"},
+					{ID: "2", Title: "Not cached", Link: "https://example.com/2", Thumbnail: "https://img.example.com/b.jpg"},
+				},
+			}}, nil
+		},
+	}
+	enrichment := &mockEnrichmentService{
+		palettes: map[string]*domain.ColorPalette{
+			"https://img.example.com/a.jpg": {Dominant: domain.RGBColor{R: 20, G: 30, B: 40}},
+		},
+	}
+	jobs := &mockEnrichmentJobService{}
+	handler := NewFeedHandler(mockService, enrichment)
+	handler.SetEnrichmentJobs(jobs)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       []string{"https://example.com/feed"},
+		"enrichment": map[string]interface{}{"extract_metadata": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.EnrichmentJob != "job-1" {
+		t.Errorf("Expected the job ID in the response, got %q", body.EnrichmentJob)
+	}
+	if len(jobs.started) != 1 || !reflect.DeepEqual(jobs.started[0], []string{"https://img.example.com/b.jpg"}) {
+		t.Errorf("Expected a job for the uncached thumbnail only, got %v", jobs.started)
+	}
+}
+
+func TestFeedHandler_ParseFeeds_EnrichmentJobFailure(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    urls[0],
+				Title: "Feed",
+				URL:   urls[0],
+				Items: []domain.FeedItem{{ID: "1", Title: "Article", Link: "https://example.com/1", Thumbnail: "https://img.example.com/a.jpg"}},
+			}}, nil
+		},
+	}
+	enrichment := &mockEnrichmentService{paletteBatches: make(chan []string, 1)}
+	jobs := &mockEnrichmentJobService{startErr: errors.New("cache unavailable")}
+	handler := NewFeedHandler(mockService, enrichment)
+	handler.SetEnrichmentJobs(jobs)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls":       []string{"https://example.com/feed"},
+		"enrichment": map[string]interface{}{"extract_metadata": false},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if strings.Contains(resp.Body.String(), "enrichmentJob") {
+		t.Errorf("Expected no job ID when the job could not start: %s", resp.Body.String())
+	}
+
+	// The palettes are still computed in the background
+	select {
+	case urls := <-enrichment.paletteBatches:
+		if !reflect.DeepEqual(urls, []string{"https://img.example.com/a.jpg"}) {
+			t.Errorf("Expected the uncached thumbnail to be processed, got %v", urls)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected palettes to be computed in the background")
+	}
+}
+
+func TestFeedHandler_ParseFeeds_ThumbnailFallback(t *testing.T) {
+	mockService := &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			return []*domain.Feed{{
+				ID:    urls[0],
+				Title: "Feed",
+				URL:   urls[0],
+				Items: []domain.FeedItem{{
+					ID:                  "1",
+					Title:               "Article",
+					Link:                "https://example.com/1",
+					Thumbnail:           "https://img.example.com/spacer.gif",
+					ThumbnailCandidates: []string{"https://img.example.com/spacer.gif", "https://img.example.com/lead.jpg"},
+				}},
+			}}, nil
+		},
+	}
+	enrichment := &mockEnrichmentService{
+		metadata: map[string]*domain.PageMetadata{
+			"https://example.com/1": {Thumbnail: "https://img.example.com/og.jpg"},
+		},
+		rejections: map[string]string{
+			"https://img.example.com/og.jpg":     domain.ThumbnailBadStatus,
+			"https://img.example.com/spacer.gif": domain.ThumbnailTooSmall,
+		},
+	}
+	handler := NewFeedHandler(mockService, enrichment)
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/parse", map[string]interface{}{
+		"urls": []string{"https://example.com/feed"},
+	})
+	if resp.Code != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var body responses.ParseFeedsV1Response
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	item := body.Feeds[0].Items[0]
+	if item.Thumbnail != "https://img.example.com/lead.jpg" {
+		t.Errorf("Expected the first usable candidate, got %q", item.Thumbnail)
+	}
+	want := []responses.ThumbnailRejectionV1{
+		{URL: "https://img.example.com/og.jpg", Reason: domain.ThumbnailBadStatus},
+		{URL: "https://img.example.com/spacer.gif", Reason: domain.ThumbnailTooSmall},
+	}
+	if !reflect.DeepEqual(item.ThumbnailRejections, want) {
+		t.Errorf("Expected metadata candidate first and rejections recorded, got %+v", item.ThumbnailRejections)
+	}
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Image handler proxies thumbnails resized and re-encoded
+// ABOUTME: Serves processed images with long-lived cache headers and ETags
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"digests-app-api/core/publish"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ImageHandler handles image proxy requests
+type ImageHandler struct {
+	imageService interfaces.ImageProxyService
+}
+
+// NewImageHandler creates a new image handler
+func NewImageHandler(imageService interfaces.ImageProxyService) *ImageHandler {
+	return &ImageHandler{imageService: imageService}
+}
+
+// RegisterRoutes registers all image routes
+func (h *ImageHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "getImage",
+		Method:      http.MethodGet,
+		Path:        "/image",
+		Summary:     "Proxy an image",
+		Description: "Fetches an image, scales it down to fit (contain) or fill (cover) the requested size, strips its metadata and re-encodes it as JPEG or PNG. Processed images are cached and served with long-lived cache headers.",
+		Tags:        []string{"Image"},
+	}, h.GetImage)
+}
+
+// GetImageInput defines the input for a proxied image
+type GetImageInput struct {
+	URL         string `query:"url" required:"true" format:"uri" doc:"Image URL"`
+	Width       int    `query:"w" minimum:"0" maximum:"2000" doc:"Maximum width in pixels (0 keeps the original)"`
+	Height      int    `query:"h" minimum:"0" maximum:"2000" doc:"Maximum height in pixels (0 keeps the original)"`
+	Fit         string `query:"fit" enum:"contain,cover" default:"contain" doc:"contain fits the image inside the size; cover fills it and crops the overflow"`
+	Format      string `query:"format" enum:"jpeg,png" doc:"Output format (default: PNG for transparent images, JPEG otherwise)"`
+	Quality     int    `query:"q" minimum:"0" maximum:"100" doc:"JPEG quality (default: 80)"`
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag of a previously fetched copy"`
+}
+
+// GetImageOutput is a processed image
+type GetImageOutput struct {
+	Status       int
+	ContentType  string `header:"Content-Type"`
+	ETag         string `header:"ETag"`
+	CacheControl string `header:"Cache-Control"`
+	Body         []byte
+}
+
+// GetImage handles the GET /image endpoint
+func (h *ImageHandler) GetImage(ctx context.Context, input *GetImageInput) (*GetImageOutput, error) {
+	processed, err := h.imageService.ProcessImage(ctx, input.URL, domain.ImageOptions{
+		Width:   input.Width,
+		Height:  input.Height,
+		Fit:     input.Fit,
+		Format:  input.Format,
+		Quality: input.Quality,
+	})
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	// The URL and options fully determine the output, so it never changes
+	output := &GetImageOutput{
+		Status:       http.StatusOK,
+		ContentType:  processed.ContentType,
+		ETag:         publish.ETag(processed.Data),
+		CacheControl: "public, max-age=31536000, immutable",
+		Body:         processed.Data,
+	}
+	if publish.MatchesETag(input.IfNoneMatch, output.ETag) {
+		output.Status = http.StatusNotModified
+		output.Body = nil
+	}
+
+	return output, nil
+}
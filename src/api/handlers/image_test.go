@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/errors"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+type mockImageProxyService struct {
+	requests []domain.ImageOptions
+}
+
+func (m *mockImageProxyService) ProcessImage(ctx context.Context, imageURL string, opts domain.ImageOptions) (*domain.ProcessedImage, error) {
+	m.requests = append(m.requests, opts)
+	if imageURL == "https://img.example.com/missing.jpg" {
+		return nil, &errors.ExternalAPIError{StatusCode: http.StatusNotFound, Message: "image could not be fetched", API: "image"}
+	}
+	return &domain.ProcessedImage{Data: []byte("jpeg bytes"), ContentType: "image/jpeg"}, nil
+}
+
+func TestImageHandler(t *testing.T) {
+	service := &mockImageProxyService{}
+	_, api := humatest.New(t)
+	NewImageHandler(service).RegisterRoutes(api)
+
+	resp := api.Get("/image?url=https://img.example.com/a.jpg&w=320&h=180&fit=cover&q=70")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	if resp.Body.String() != "jpeg bytes" {
+		t.Errorf("body = %q", resp.Body.String())
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := resp.Header().Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	want := domain.ImageOptions{Width: 320, Height: 180, Fit: domain.ImageFitCover, Quality: 70}
+	if len(service.requests) != 1 || service.requests[0] != want {
+		t.Errorf("options = %+v, want %+v", service.requests, want)
+	}
+
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+	resp = api.Get("/image?url=https://img.example.com/a.jpg&w=320&h=180&fit=cover&q=70", "If-None-Match: "+etag)
+	if resp.Code != http.StatusNotModified || resp.Body.Len() != 0 {
+		t.Errorf("status = %d, want 304 for matching ETag", resp.Code)
+	}
+
+	if resp := api.Get("/image?url=https://img.example.com/a.jpg&fit=stretch"); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422 for an unknown fit", resp.Code)
+	}
+	if resp := api.Get("/image?url=https://img.example.com/missing.jpg"); resp.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for an unfetchable image", resp.Code)
+	}
+}
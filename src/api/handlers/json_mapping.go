@@ -0,0 +1,225 @@
+// ABOUTME: JSON mapping handler for the Huma API
+// ABOUTME: Provides endpoints to manage JSON API to feed mappings and preview their output
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"digests-app-api/api/dto/responses"
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// JSONMappingHandler handles JSON mapping requests
+type JSONMappingHandler struct {
+	mappingService interfaces.JSONMappingService
+}
+
+// NewJSONMappingHandler creates a new JSON mapping handler
+func NewJSONMappingHandler(mappingService interfaces.JSONMappingService) *JSONMappingHandler {
+	return &JSONMappingHandler{
+		mappingService: mappingService,
+	}
+}
+
+// RegisterRoutes registers all JSON mapping routes
+func (h *JSONMappingHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "createJSONMapping",
+		Method:        http.MethodPost,
+		Path:          "/json-mappings",
+		Summary:       "Create a JSON mapping",
+		Description:   "Stores a mapping that turns a JSON API into a feed, parseable as jsonapi://<id>",
+		Tags:          []string{"JSON Mappings"},
+		DefaultStatus: http.StatusCreated,
+	}, h.CreateMapping)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listJSONMappings",
+		Method:      http.MethodGet,
+		Path:        "/json-mappings",
+		Summary:     "List JSON mappings",
+		Description: "Lists all stored JSON mappings",
+		Tags:        []string{"JSON Mappings"},
+	}, h.ListMappings)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getJSONMapping",
+		Method:      http.MethodGet,
+		Path:        "/json-mappings/{id}",
+		Summary:     "Get a JSON mapping",
+		Description: "Retrieves a stored JSON mapping",
+		Tags:        []string{"JSON Mappings"},
+	}, h.GetMapping)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "deleteJSONMapping",
+		Method:        http.MethodDelete,
+		Path:          "/json-mappings/{id}",
+		Summary:       "Delete a JSON mapping",
+		Description:   "Deletes a stored JSON mapping",
+		Tags:          []string{"JSON Mappings"},
+		DefaultStatus: http.StatusNoContent,
+	}, h.DeleteMapping)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "previewJSONMapping",
+		Method:      http.MethodPost,
+		Path:        "/json-mappings/preview",
+		Summary:     "Preview a JSON mapping",
+		Description: "Applies a mapping to the live JSON document and returns the extracted feed without storing it",
+		Tags:        []string{"JSON Mappings"},
+	}, h.PreviewMapping)
+}
+
+// JSONMappingBody is the request body describing a JSON mapping
+type JSONMappingBody struct {
+	Name        string `json:"name,omitempty" doc:"Feed title; defaults to the API host"`
+	URL         string `json:"url" doc:"JSON document to fetch" example:"https://api.example.com/posts"`
+	ItemsPath   string `json:"itemsPath" doc:"Path selecting the items in the document" example:"$.data.posts[*]"`
+	IDPath      string `json:"idPath,omitempty" doc:"Path to the item ID, relative to the item; defaults to the link"`
+	TitlePath   string `json:"titlePath,omitempty" doc:"Path to the item title" example:"$.title"`
+	LinkPath    string `json:"linkPath,omitempty" doc:"Path to the item link; relative links are resolved against the document URL" example:"$.url"`
+	DatePath    string `json:"datePath,omitempty" doc:"Path to the publication date (date string or Unix timestamp)"`
+	ContentPath string `json:"contentPath,omitempty" doc:"Path to the item content"`
+	SummaryPath string `json:"summaryPath,omitempty" doc:"Path to the item summary; defaults to the content"`
+	AuthorPath  string `json:"authorPath,omitempty" doc:"Path to the item author"`
+	ImagePath   string `json:"imagePath,omitempty" doc:"Path to the item image"`
+}
+
+// toDomain converts the request body into a JSON mapping
+func (b JSONMappingBody) toDomain() domain.JSONMapping {
+	return domain.JSONMapping{
+		Name:        b.Name,
+		URL:         b.URL,
+		ItemsPath:   b.ItemsPath,
+		IDPath:      b.IDPath,
+		TitlePath:   b.TitlePath,
+		LinkPath:    b.LinkPath,
+		DatePath:    b.DatePath,
+		ContentPath: b.ContentPath,
+		SummaryPath: b.SummaryPath,
+		AuthorPath:  b.AuthorPath,
+		ImagePath:   b.ImagePath,
+	}
+}
+
+// JSONMappingResponse represents a stored JSON mapping
+type JSONMappingResponse struct {
+	ID      string `json:"id" doc:"Mapping ID"`
+	FeedURL string `json:"feedUrl" doc:"Source URL to pass to /parse" example:"jsonapi://3f1c..."`
+	JSONMappingBody
+	CreatedAt string `json:"createdAt" doc:"Creation time (RFC3339)"`
+}
+
+// newJSONMappingResponse converts a domain mapping into a response
+func newJSONMappingResponse(mapping *domain.JSONMapping) JSONMappingResponse {
+	return JSONMappingResponse{
+		ID:      mapping.ID,
+		FeedURL: mapping.FeedURL(),
+		JSONMappingBody: JSONMappingBody{
+			Name:        mapping.Name,
+			URL:         mapping.URL,
+			ItemsPath:   mapping.ItemsPath,
+			IDPath:      mapping.IDPath,
+			TitlePath:   mapping.TitlePath,
+			LinkPath:    mapping.LinkPath,
+			DatePath:    mapping.DatePath,
+			ContentPath: mapping.ContentPath,
+			SummaryPath: mapping.SummaryPath,
+			AuthorPath:  mapping.AuthorPath,
+			ImagePath:   mapping.ImagePath,
+		},
+		CreatedAt: mapping.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// JSONMappingInput defines the input for creating or previewing a mapping
+type JSONMappingInput struct {
+	Body JSONMappingBody
+}
+
+// JSONMappingIDInput defines the input for operations on a stored mapping
+type JSONMappingIDInput struct {
+	ID string `path:"id" doc:"Mapping ID"`
+}
+
+// JSONMappingOutput defines the output for a single mapping
+type JSONMappingOutput struct {
+	Body JSONMappingResponse
+}
+
+// ListJSONMappingsOutput defines the output for listing mappings
+type ListJSONMappingsOutput struct {
+	Body struct {
+		Mappings []JSONMappingResponse `json:"mappings" doc:"Stored JSON mappings"`
+	}
+}
+
+// PreviewJSONMappingOutput defines the output for a mapping preview
+type PreviewJSONMappingOutput struct {
+	Body responses.FeedV1Response
+}
+
+// CreateMapping handles the POST /json-mappings endpoint
+func (h *JSONMappingHandler) CreateMapping(ctx context.Context, input *JSONMappingInput) (*JSONMappingOutput, error) {
+	mapping, err := h.mappingService.CreateMapping(ctx, input.Body.toDomain())
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &JSONMappingOutput{Body: newJSONMappingResponse(mapping)}, nil
+}
+
+// ListMappings handles the GET /json-mappings endpoint
+func (h *JSONMappingHandler) ListMappings(ctx context.Context, input *struct{}) (*ListJSONMappingsOutput, error) {
+	mappings, err := h.mappingService.ListMappings(ctx)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	output := &ListJSONMappingsOutput{}
+	output.Body.Mappings = make([]JSONMappingResponse, 0, len(mappings))
+	for _, mapping := range mappings {
+		output.Body.Mappings = append(output.Body.Mappings, newJSONMappingResponse(mapping))
+	}
+
+	return output, nil
+}
+
+// GetMapping handles the GET /json-mappings/{id} endpoint
+func (h *JSONMappingHandler) GetMapping(ctx context.Context, input *JSONMappingIDInput) (*JSONMappingOutput, error) {
+	mapping, err := h.mappingService.GetMapping(ctx, input.ID)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &JSONMappingOutput{Body: newJSONMappingResponse(mapping)}, nil
+}
+
+// DeleteMapping handles the DELETE /json-mappings/{id} endpoint
+func (h *JSONMappingHandler) DeleteMapping(ctx context.Context, input *JSONMappingIDInput) (*struct{}, error) {
+	if err := h.mappingService.DeleteMapping(ctx, input.ID); err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return nil, nil
+}
+
+// PreviewMapping handles the POST /json-mappings/preview endpoint
+func (h *JSONMappingHandler) PreviewMapping(ctx context.Context, input *JSONMappingInput) (*PreviewJSONMappingOutput, error) {
+	feed, err := h.mappingService.PreviewMapping(ctx, input.Body.toDomain())
+	if err != nil {
+		if humaErr := toHumaError(err); !isInternalError(humaErr) {
+			return nil, humaErr
+		}
+		return nil, huma.Error422UnprocessableEntity("Failed to map items from document: " + err.Error())
+	}
+
+	converted := responses.ConvertDomainFeedsToV1Response([]*domain.Feed{feed})
+	return &PreviewJSONMappingOutput{Body: converted.Feeds[0]}, nil
+}
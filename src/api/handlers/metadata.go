@@ -5,36 +5,33 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
-	"net/url"
-	"strconv"
-	"strings"
-	"sync"
-	
-	"github.com/PuerkitoBio/goquery"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
 	"github.com/danielgtaylor/huma/v2"
-	"github.com/gocolly/colly"
 )
 
 // MetadataHandler handles metadata extraction
 type MetadataHandler struct {
-	// We'll use colly for metadata extraction like the old implementation
+	enrichmentService interfaces.ContentEnrichmentService
 }
 
 // NewMetadataHandler creates a new metadata handler
-func NewMetadataHandler() *MetadataHandler {
-	return &MetadataHandler{}
+func NewMetadataHandler(enrichmentService interfaces.ContentEnrichmentService) *MetadataHandler {
+	return &MetadataHandler{
+		enrichmentService: enrichmentService,
+	}
 }
 
 // RegisterRoutes registers metadata routes
 func (h *MetadataHandler) RegisterRoutes(api huma.API) {
 	huma.Register(api, huma.Operation{
-		OperationID: "extractMetadata", 
+		OperationID: "extractMetadata",
 		Method:      http.MethodPost,
 		Path:        "/metadata",
 		Summary:     "Extract metadata from web pages",
-		Description: "Extracts Open Graph tags, JSON-LD data, and other metadata from provided URLs",
+		Description: "Extracts Open Graph, Twitter Card, JSON-LD and microdata metadata from the provided URLs. Pages that cannot be read are listed with an error.",
 		Tags:        []string{"Metadata"},
 	}, h.ExtractMetadata)
 }
@@ -61,26 +58,33 @@ type WebMedia struct {
 
 // MetadataItem represents extracted metadata
 type MetadataItem struct {
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Images      []WebMedia  `json:"images"`
-	Type        string      `json:"type"`
-	Sitename    string      `json:"sitename"`
-	Favicon     string      `json:"favicon"`
-	Duration    int         `json:"duration"`
-	Domain      string      `json:"domain"`
-	URL         string      `json:"url"`
-	Videos      []WebMedia  `json:"videos"`
-	Locale      string      `json:"locale,omitempty"`
-	Determiner  string      `json:"determiner,omitempty"`
-	Raw         interface{} `json:"raw,omitempty"`
-	ThemeColor  string      `json:"themeColor,omitempty"`
+	Title          string        `json:"title"`
+	Description    string        `json:"description"`
+	Images         []WebMedia    `json:"images"`
+	Thumbnail      string        `json:"thumbnail,omitempty"`
+	Type           string        `json:"type"`
+	Sitename       string        `json:"sitename"`
+	Favicon        string        `json:"favicon"`
+	Duration       int           `json:"duration"`
+	Domain         string        `json:"domain"`
+	URL            string        `json:"url"`
+	CanonicalURL   string        `json:"canonicalUrl,omitempty"`
+	Videos         []WebMedia    `json:"videos"`
+	Locale         string        `json:"locale,omitempty"`
+	Determiner     string        `json:"determiner,omitempty"`
+	Author         string        `json:"author,omitempty"`
+	Published      string        `json:"published,omitempty"`
+	Modified       string        `json:"modified,omitempty"`
+	Raw            interface{}   `json:"raw,omitempty" doc:"The page's first JSON-LD document"`
+	StructuredData []interface{} `json:"structuredData,omitempty" doc:"Every JSON-LD document on the page"`
+	ThemeColor     string        `json:"themeColor,omitempty"`
+	Error          string        `json:"error,omitempty" doc:"Why the page could not be read"`
 }
 
 // MetadataOutput defines the output for metadata extraction
 type MetadataOutput struct {
 	Body struct {
-		Metadata []MetadataItem `json:"metadata" doc:"Extracted metadata for each URL"`
+		Metadata []MetadataItem `json:"metadata" doc:"Extracted metadata for each URL, in request order"`
 	}
 }
 
@@ -90,179 +94,69 @@ func (h *MetadataHandler) ExtractMetadata(ctx context.Context, input *MetadataIn
 		return nil, huma.Error400BadRequest("No URLs provided")
 	}
 
-	// Process URLs concurrently
-	var wg sync.WaitGroup
-	results := make([]MetadataItem, len(input.Body.URLs))
-	
-	for i, url := range input.Body.URLs {
-		wg.Add(1)
-		go func(idx int, targetURL string) {
-			defer wg.Done()
-			metadata := h.extractMetadataFromURL(targetURL)
-			results[idx] = metadata
-		}(i, url)
-	}
-	
-	wg.Wait()
+	metadata := h.enrichmentService.ExtractMetadataBatch(ctx, input.Body.URLs)
 
 	output := &MetadataOutput{}
-	output.Body.Metadata = results
+	output.Body.Metadata = make([]MetadataItem, len(input.Body.URLs))
+	for i, targetURL := range input.Body.URLs {
+		page, ok := metadata[targetURL]
+		if !ok || page == nil {
+			page = &domain.PageMetadata{URL: targetURL}
+		}
+		output.Body.Metadata[i] = toMetadataItem(page)
+	}
+
 	return output, nil
 }
 
-// extractMetadataFromURL extracts metadata from a single URL
-func (h *MetadataHandler) extractMetadataFromURL(targetURL string) MetadataItem {
-	// Basic validation
-	if targetURL == "" || targetURL == "http://" || targetURL == "://" || targetURL == "about:blank" {
-		return MetadataItem{URL: targetURL}
+// toMetadataItem converts page metadata into its API representation
+func toMetadataItem(page *domain.PageMetadata) MetadataItem {
+	item := MetadataItem{
+		Title:        page.Title,
+		Description:  page.Description,
+		Images:       toWebMedia(page.Images),
+		Thumbnail:    page.Thumbnail,
+		Type:         page.Type,
+		Sitename:     page.SiteName,
+		Favicon:      page.Favicon,
+		Duration:     page.Duration,
+		Domain:       page.Domain,
+		URL:          page.URL,
+		CanonicalURL: page.CanonicalURL,
+		Videos:       toWebMedia(page.Videos),
+		Locale:       page.Locale,
+		Determiner:   page.Determiner,
+		Author:       page.Author,
+		Published:    page.Published,
+		Modified:     page.Modified,
+		ThemeColor:   page.ThemeColor,
+		Error:        page.Error,
 	}
 
-	// Use colly like the old implementation
-	c := colly.NewCollector(
-		colly.UserAgent("facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"),
-	)
-
-	metadata := MetadataItem{
-		URL:    targetURL,
-		Images: []WebMedia{},
-		Videos: []WebMedia{},
+	// raw keeps one shape whatever the page declares
+	if len(page.StructuredData) > 0 {
+		item.Raw = page.StructuredData[0]
+		item.StructuredData = page.StructuredData
 	}
 
-	// Extract Open Graph tags
-	c.OnHTML("meta", func(e *colly.HTMLElement) {
-		property := e.Attr("property")
-		content := e.Attr("content")
-		name := e.Attr("name")
-		
-		if (property == "" && name == "") || content == "" {
-			return
-		}
-
-		// Theme color
-		if name == "theme-color" {
-			metadata.ThemeColor = content
-		}
-
-		// Open Graph tags
-		parts := strings.Split(property, ":")
-		if len(parts) < 2 || parts[0] != "og" {
-			return
-		}
-
-		switch property {
-		case "og:title":
-			metadata.Title = content
-		case "og:description":
-			metadata.Description = content
-		case "og:site_name":
-			metadata.Sitename = content
-		case "og:url":
-			metadata.URL = content
-		case "og:type":
-			metadata.Type = content
-		case "og:locale":
-			metadata.Locale = content
-		case "og:determiner":
-			metadata.Determiner = content
-			
-		// Images
-		case "og:image":
-			metadata.Images = append(metadata.Images, WebMedia{URL: content})
-		case "og:image:width", "og:image:height", "og:image:alt", "og:image:type", "og:image:secure_url":
-			if len(metadata.Images) > 0 {
-				idx := len(metadata.Images) - 1
-				switch property {
-				case "og:image:width":
-					if w, err := strconv.Atoi(content); err == nil {
-						metadata.Images[idx].Width = w
-					}
-				case "og:image:height":
-					if h, err := strconv.Atoi(content); err == nil {
-						metadata.Images[idx].Height = h
-					}
-				case "og:image:alt":
-					metadata.Images[idx].Alt = content
-				case "og:image:type":
-					metadata.Images[idx].Type = content
-				case "og:image:secure_url":
-					metadata.Images[idx].SecureURL = content
-				}
-			}
-			
-		// Videos
-		case "og:video:url":
-			metadata.Videos = append(metadata.Videos, WebMedia{URL: content})
-		case "og:video:width", "og:video:height", "og:video:type", "og:video:secure_url", "og:video:duration", "og:video:release_date", "og:video:tag":
-			if len(metadata.Videos) > 0 {
-				idx := len(metadata.Videos) - 1
-				switch property {
-				case "og:video:width":
-					if w, err := strconv.Atoi(content); err == nil {
-						metadata.Videos[idx].Width = w
-					}
-				case "og:video:height":
-					if h, err := strconv.Atoi(content); err == nil {
-						metadata.Videos[idx].Height = h
-					}
-				case "og:video:type":
-					metadata.Videos[idx].Type = content
-				case "og:video:secure_url":
-					metadata.Videos[idx].SecureURL = content
-				case "og:video:duration":
-					if d, err := strconv.Atoi(content); err == nil {
-						metadata.Videos[idx].Duration = d
-					}
-				case "og:video:release_date":
-					metadata.Videos[idx].ReleaseDate = content
-				case "og:video:tag":
-					tags := strings.Split(content, ",")
-					for _, t := range tags {
-						metadata.Videos[idx].Tags = append(metadata.Videos[idx].Tags, strings.TrimSpace(t))
-					}
-				}
-			}
-		}
-	})
-
-	// Extract JSON-LD
-	c.OnHTML("script[type='application/ld+json']", func(e *colly.HTMLElement) {
-		rawJSON := e.Text
-		var ldData interface{}
-		if err := json.Unmarshal([]byte(rawJSON), &ldData); err == nil {
-			metadata.Raw = ldData
-		}
-	})
-
-	// Domain detection
-	c.OnRequest(func(r *colly.Request) {
-		if metadata.Domain == "" {
-			if parsedURL, err := url.Parse(r.URL.String()); err == nil {
-				metadata.Domain = parsedURL.Host
-			}
-		}
-	})
-
-	// Favicon discovery
-	c.OnHTML("head", func(e *colly.HTMLElement) {
-		if metadata.Favicon == "" {
-			e.DOM.Find("link[rel]").Each(func(_ int, s *goquery.Selection) {
-				rel := s.AttrOr("rel", "")
-				href := s.AttrOr("href", "")
-				relValues := strings.Fields(rel)
-				for _, rv := range relValues {
-					if rv == "icon" || rv == "shortcut" || rv == "apple-touch-icon" {
-						if href != "" {
-							metadata.Favicon = e.Request.AbsoluteURL(href)
-							return
-						}
-					}
-				}
-			})
-		}
-	})
-
-	// Visit the page
-	_ = c.Visit(targetURL)
+	return item
+}
 
-	return metadata
-}
\ No newline at end of file
+// toWebMedia converts images or videos into their API representation
+func toWebMedia(media []domain.MediaObject) []WebMedia {
+	result := make([]WebMedia, 0, len(media))
+	for _, m := range media {
+		result = append(result, WebMedia{
+			URL:         m.URL,
+			Alt:         m.Alt,
+			Type:        m.Type,
+			Width:       m.Width,
+			Height:      m.Height,
+			Tags:        m.Tags,
+			SecureURL:   m.SecureURL,
+			Duration:    m.Duration,
+			ReleaseDate: m.ReleaseDate,
+		})
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestMetadataHandler_ExtractMetadata(t *testing.T) {
+	enrichment := &mockEnrichmentService{metadata: map[string]*domain.PageMetadata{
+		"https://example.com/post": {
+			URL:          "https://example.com/post",
+			CanonicalURL: "https://example.com/posts/1",
+			Domain:       "example.com",
+			Title:        "A post",
+			Author:       "Jane Doe",
+			Published:    "2024-01-15T10:00:00Z",
+			SiteName:     "Example",
+			Thumbnail:    "https://example.com/lead.jpg",
+			Images:       []domain.MediaObject{{URL: "https://example.com/lead.jpg", Width: 1200, Alt: "Lead"}},
+			Videos:       []domain.MediaObject{{URL: "https://example.com/clip.mp4", Duration: 90}},
+			Duration:     90,
+			StructuredData: []interface{}{
+				map[string]interface{}{"@type": "NewsArticle"},
+				map[string]interface{}{"@type": "BreadcrumbList"},
+			},
+		},
+		"https://example.com/missing": {
+			URL:   "https://example.com/missing",
+			Error: "status 404",
+		},
+	}}
+
+	_, api := humatest.New(t)
+	NewMetadataHandler(enrichment).RegisterRoutes(api)
+
+	resp := api.Post("/metadata", map[string]interface{}{
+		"urls": []string{"https://example.com/missing", "https://example.com/post"},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+
+	var body MetadataOutput
+	if err := json.Unmarshal(resp.Body.Bytes(), &body.Body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Body.Metadata) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(body.Body.Metadata))
+	}
+
+	missing, post := body.Body.Metadata[0], body.Body.Metadata[1]
+	if missing.URL != "https://example.com/missing" || missing.Error != "status 404" {
+		t.Errorf("unexpected failed result: %+v", missing)
+	}
+	if post.URL != "https://example.com/post" || post.CanonicalURL != "https://example.com/posts/1" {
+		t.Errorf("unexpected URLs: %q, %q", post.URL, post.CanonicalURL)
+	}
+	if post.Title != "A post" || post.Author != "Jane Doe" || post.Published != "2024-01-15T10:00:00Z" || post.Sitename != "Example" {
+		t.Errorf("unexpected metadata: %+v", post)
+	}
+	if len(post.Images) != 1 || post.Images[0].Width != 1200 || post.Images[0].Alt != "Lead" {
+		t.Errorf("unexpected images: %+v", post.Images)
+	}
+	if len(post.Videos) != 1 || post.Duration != 90 {
+		t.Errorf("unexpected videos: %+v", post.Videos)
+	}
+	if raw, ok := post.Raw.(map[string]interface{}); !ok || raw["@type"] != "NewsArticle" {
+		t.Errorf("expected the first JSON-LD document as raw, got %#v", post.Raw)
+	}
+	if len(post.StructuredData) != 2 {
+		t.Errorf("expected every JSON-LD document in structuredData, got %#v", post.StructuredData)
+	}
+}
+
+func TestMetadataHandler_RequiresURLs(t *testing.T) {
+	_, api := humatest.New(t)
+	NewMetadataHandler(&mockEnrichmentService{}).RegisterRoutes(api)
+
+	resp := api.Post("/metadata", map[string]interface{}{"urls": []string{}})
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.Code)
+	}
+}
@@ -0,0 +1,279 @@
+// ABOUTME: Publish handler renders parsed feeds back out as RSS, Atom or JSON Feed
+// ABOUTME: Supports filtered digests, shares and ETag revalidation for feed readers
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"digests-app-api/core/publish"
+	utiltime "digests-app-api/pkg/utils/time"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxPublishURLs limits how many feeds a published digest aggregates
+const maxPublishURLs = 100
+
+// PublishHandler handles feed publishing requests
+type PublishHandler struct {
+	feedService  interfaces.FeedService
+	shareService interfaces.ShareService
+	tagger       interfaces.Tagger
+	clusterer    interfaces.StoryClusterer
+}
+
+// NewPublishHandler creates a new publish handler.
+// The share service may be nil, in which case share feeds are not served.
+func NewPublishHandler(feedService interfaces.FeedService, shareService interfaces.ShareService) *PublishHandler {
+	return &PublishHandler{
+		feedService:  feedService,
+		shareService: shareService,
+	}
+}
+
+// SetTagger lets category filters match auto-tags as well as publisher categories
+func (h *PublishHandler) SetTagger(tagger interfaces.Tagger) {
+	h.tagger = tagger
+}
+
+// SetClusterer lets published feeds collapse near-identical items into stories
+func (h *PublishHandler) SetClusterer(clusterer interfaces.StoryClusterer) {
+	h.clusterer = clusterer
+}
+
+// RegisterRoutes registers all publish routes
+func (h *PublishHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "publishFeed",
+		Method:      http.MethodGet,
+		Path:        "/publish/{format}",
+		Summary:     "Publish feeds",
+		Description: "Renders one or more parsed feeds as a single RSS 2.0, Atom 1.0 or JSON Feed 1.1 document. Filters are read from the query string on every request and are not stored, so a GET URL can be subscribed to in a feed reader.",
+		Tags:        []string{"Publish"},
+	}, h.PublishFeed)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "publishFeedPost",
+		Method:      http.MethodPost,
+		Path:        "/publish/{format}",
+		Summary:     "Publish feeds (POST)",
+		Description: "Same as GET /publish/{format} for feed lists too long for a query string",
+		Tags:        []string{"Publish"},
+	}, h.PublishFeedPost)
+
+	if h.shareService != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "publishShareFeed",
+			Method:      http.MethodGet,
+			Path:        "/share/{id}/feed/{format}",
+			Summary:     "Publish a share as a feed",
+			Description: "Renders the feeds of a share as a single RSS 2.0, Atom 1.0 or JSON Feed 1.1 document",
+			Tags:        []string{"Publish"},
+		}, h.PublishShareFeed)
+	}
+}
+
+// PublishRequest captures what every publish operation needs from the request
+type PublishRequest struct {
+	Format      string `path:"format" enum:"rss,atom,json" doc:"Output format"`
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag of a previously fetched copy"`
+
+	selfURL string
+}
+
+// Resolve records the URL the feed is served from, used for self links
+func (r *PublishRequest) Resolve(ctx huma.Context) []error {
+	r.selfURL = requestURL(ctx).String()
+	return nil
+}
+
+// requestURL returns the absolute URL of the current request
+func requestURL(ctx huma.Context) *url.URL {
+	u := ctx.URL()
+	scheme := "http"
+	if ctx.TLS() != nil || strings.EqualFold(ctx.Header("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	u.Scheme = scheme
+	u.Host = ctx.Host()
+	return &u
+}
+
+// PublishFeedInput defines the query-string input for publishing feeds
+type PublishFeedInput struct {
+	PublishRequest
+	URLs        []string `query:"url,explode" doc:"Feed URLs to aggregate (repeat the parameter)"`
+	Title       string   `query:"title" doc:"Title of the published feed"`
+	Description string   `query:"description" doc:"Description of the published feed"`
+	Query       string   `query:"q" doc:"Only include items containing every term"`
+	Categories  []string `query:"category,explode" doc:"Only include items in one of these categories"`
+	Languages   []string `query:"language,explode" doc:"Only include items in one of these languages"`
+	Since       string   `query:"since" doc:"Only include items published at or after this date"`
+	Limit       int      `query:"limit" minimum:"0" maximum:"500" doc:"Maximum number of items (default 50)"`
+	Cluster     bool     `query:"cluster" doc:"Show each story covered by several feeds once"`
+}
+
+// PublishFeedPostInput defines the body input for publishing feeds
+type PublishFeedPostInput struct {
+	PublishRequest
+	Body struct {
+		URLs        []string `json:"urls" doc:"Feed URLs to aggregate"`
+		Title       string   `json:"title,omitempty" doc:"Title of the published feed"`
+		Description string   `json:"description,omitempty" doc:"Description of the published feed"`
+		Filter      struct {
+			Query      string   `json:"q,omitempty" doc:"Only include items containing every term"`
+			Categories []string `json:"categories,omitempty" doc:"Only include items in one of these categories"`
+			Languages  []string `json:"languages,omitempty" doc:"Only include items in one of these languages"`
+			Since      string   `json:"since,omitempty" doc:"Only include items published at or after this date"`
+		} `json:"filter,omitempty" doc:"Item filter"`
+		Limit   int  `json:"limit,omitempty" minimum:"0" maximum:"500" doc:"Maximum number of items (default 50)"`
+		Cluster bool `json:"cluster,omitempty" doc:"Show each story covered by several feeds once"`
+	}
+}
+
+// PublishShareFeedInput defines the input for publishing a share
+type PublishShareFeedInput struct {
+	PublishRequest
+	ID      string `path:"id" doc:"Share ID"`
+	Limit   int    `query:"limit" minimum:"0" maximum:"500" doc:"Maximum number of items (default 50)"`
+	Cluster bool   `query:"cluster" doc:"Show each story covered by several feeds once"`
+}
+
+// PublishFeedOutput is a rendered feed document
+type PublishFeedOutput struct {
+	Status       int
+	ContentType  string `header:"Content-Type"`
+	ETag         string `header:"ETag"`
+	CacheControl string `header:"Cache-Control"`
+	Body         []byte
+}
+
+// PublishFeed handles the GET /publish/{format} endpoint
+func (h *PublishHandler) PublishFeed(ctx context.Context, input *PublishFeedInput) (*PublishFeedOutput, error) {
+	filter, err := buildPublishFilter(input.Query, input.Categories, input.Languages, input.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.publish(ctx, &input.PublishRequest, input.URLs, publish.Options{
+		Title:       input.Title,
+		Description: input.Description,
+		Filter:      filter,
+		Limit:       input.Limit,
+	}, input.Cluster)
+}
+
+// PublishFeedPost handles the POST /publish/{format} endpoint
+func (h *PublishHandler) PublishFeedPost(ctx context.Context, input *PublishFeedPostInput) (*PublishFeedOutput, error) {
+	filter, err := buildPublishFilter(input.Body.Filter.Query, input.Body.Filter.Categories, input.Body.Filter.Languages, input.Body.Filter.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.publish(ctx, &input.PublishRequest, input.Body.URLs, publish.Options{
+		Title:       input.Body.Title,
+		Description: input.Body.Description,
+		Filter:      filter,
+		Limit:       input.Body.Limit,
+	}, input.Body.Cluster)
+}
+
+// PublishShareFeed handles the GET /share/{id}/feed/{format} endpoint
+func (h *PublishHandler) PublishShareFeed(ctx context.Context, input *PublishShareFeedInput) (*PublishFeedOutput, error) {
+	share, err := lookupShare(ctx, h.shareService, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.publish(ctx, &input.PublishRequest, share.URLs, publish.Options{
+		Limit: input.Limit,
+	}, input.Cluster)
+}
+
+// publish parses the feeds, renders them and applies ETag revalidation.
+// With cluster set, each story is published once.
+func (h *PublishHandler) publish(ctx context.Context, req *PublishRequest, urls []string, opts publish.Options, cluster bool) (*PublishFeedOutput, error) {
+	format, err := publish.ParseFormat(req.Format)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if len(urls) == 0 {
+		return nil, huma.Error400BadRequest("No URLs provided")
+	}
+	if len(urls) > maxPublishURLs {
+		return nil, huma.Error400BadRequest("Too many URLs provided")
+	}
+
+	feeds, err := h.feedService.ParseFeeds(ctx, urls)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	// Items are only tagged when filtering needs it; tags are not published
+	if len(opts.Filter.Categories) > 0 && h.tagger != nil {
+		var items []*domain.FeedItem
+		for _, feed := range feeds {
+			for i := range feed.Items {
+				items = append(items, &feed.Items[i])
+			}
+		}
+		h.tagger.TagItems(ctx, items, 0)
+	}
+
+	if cluster && h.clusterer != nil {
+		opts.Stories = h.clusterer.ClusterFeeds(ctx, feeds)
+	}
+
+	opts.SelfURL = req.selfURL
+	channel, err := publish.BuildChannel(feeds, opts)
+	if err != nil {
+		return nil, huma.Error502BadGateway("None of the feeds could be parsed")
+	}
+
+	body, err := publish.Render(channel, format)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	output := &PublishFeedOutput{
+		Status:       http.StatusOK,
+		ContentType:  format.ContentType(),
+		ETag:         publish.ETag(body),
+		CacheControl: "public, max-age=900",
+		Body:         body,
+	}
+	if publish.MatchesETag(req.IfNoneMatch, output.ETag) {
+		output.Status = http.StatusNotModified
+		output.Body = nil
+	}
+
+	return output, nil
+}
+
+// buildPublishFilter converts request filter parameters into a publish filter
+func buildPublishFilter(query string, categories, languages []string, since string) (publish.Filter, error) {
+	filter := publish.Filter{
+		Query:      query,
+		Categories: categories,
+		Languages:  languages,
+	}
+
+	if since != "" {
+		filter.Since = utiltime.ParseFlexibleTime(since)
+		if filter.Since.IsZero() {
+			if d, err := time.ParseDuration(since); err == nil {
+				filter.Since = time.Now().Add(-d)
+			} else {
+				return filter, huma.Error400BadRequest("Invalid since value: use a date or a duration such as 72h")
+			}
+		}
+	}
+
+	return filter, nil
+}
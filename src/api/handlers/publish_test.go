@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func publishFeedService(requested *[]string) *mockFeedService {
+	now := time.Now()
+	return &mockFeedService{
+		parseFeedsFunc: func(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+			*requested = append(*requested, urls...)
+			feeds := make([]*domain.Feed, 0, len(urls))
+			for _, u := range urls {
+				feeds = append(feeds, &domain.Feed{
+					Title: "Feed " + u,
+					URL:   u,
+					Items: []domain.FeedItem{
+						{ID: u + "/1", Title: "Go release", Link: u + "/1", Published: now.Add(-time.Hour)},
+						{ID: u + "/2", Title: "Weekly notes", Link: u + "/2", Published: now.Add(-200 * time.Hour)},
+					},
+				})
+			}
+			return feeds, nil
+		},
+	}
+}
+
+func TestPublishHandler_RSSWithFilter(t *testing.T) {
+	var requested []string
+	handler := NewPublishHandler(publishFeedService(&requested), nil)
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Get("/publish/rss?url=https://a.example.com/feed&url=https://b.example.com/feed?x=1,2&q=go&title=Digest")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	if len(requested) != 2 || requested[1] != "https://b.example.com/feed?x=1,2" {
+		t.Errorf("unexpected requested URLs: %v", requested)
+	}
+	if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/rss+xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body := resp.Body.String()
+	if strings.Count(body, "<item>") != 2 || strings.Contains(body, "Weekly notes") {
+		t.Errorf("expected only the matching items:\n%s", body)
+	}
+	if !strings.Contains(body, `<atom:link href="http://`) || !strings.Contains(body, "<title>Digest</title>") {
+		t.Errorf("expected self link and title override:\n%s", body)
+	}
+
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	resp = api.Get("/publish/rss?url=https://a.example.com/feed&url=https://b.example.com/feed?x=1,2&q=go&title=Digest", "If-None-Match: "+etag)
+	if resp.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304 for matching ETag", resp.Code)
+	}
+}
+
+func TestPublishHandler_PostJSONFeed(t *testing.T) {
+	var requested []string
+	handler := NewPublishHandler(publishFeedService(&requested), nil)
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/publish/json", map[string]interface{}{
+		"urls":   []string{"https://a.example.com/feed"},
+		"filter": map[string]interface{}{"since": "72h"},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	body := resp.Body.String()
+	if !strings.Contains(body, `"version": "https://jsonfeed.org/version/1.1"`) || strings.Contains(body, "Weekly notes") {
+		t.Errorf("unexpected JSON Feed:\n%s", body)
+	}
+}
+
+func TestPublishHandler_ClusterCollapsesStories(t *testing.T) {
+	var requested []string
+	handler := NewPublishHandler(publishFeedService(&requested), nil)
+	handler.SetClusterer(&mockClusterer{})
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Get("/publish/json?url=https://a.example.com/feed&url=https://b.example.com/feed&cluster=true")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var feed struct {
+		Items []struct {
+			Story *struct {
+				ID      string            `json:"id"`
+				Related []json.RawMessage `json:"related"`
+			} `json:"_digests_story"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid JSON Feed: %v", err)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Story == nil || feed.Items[0].Story.ID != "story-1" || len(feed.Items[0].Story.Related) != 3 {
+		t.Errorf("expected one item standing for the story:\n%s", resp.Body.String())
+	}
+
+	// Without cluster every item is published
+	resp = api.Get("/publish/json?url=https://a.example.com/feed&url=https://b.example.com/feed")
+	if strings.Count(resp.Body.String(), `"id": "https://`) != 4 || strings.Contains(resp.Body.String(), "_digests_story") {
+		t.Errorf("expected all items without stories:\n%s", resp.Body.String())
+	}
+}
+
+// mockTagger tags every item with the first word of its title
+type mockTagger struct {
+	calls int
+}
+
+func (m *mockTagger) TagItems(ctx context.Context, items []*domain.FeedItem, tags int) {
+	m.calls++
+	for _, item := range items {
+		item.AutoTags = strings.Fields(item.Title)[:1]
+	}
+}
+
+func TestPublishHandler_CategoryFilterMatchesAutoTags(t *testing.T) {
+	var requested []string
+	tagger := &mockTagger{}
+	handler := NewPublishHandler(publishFeedService(&requested), nil)
+	handler.SetTagger(tagger)
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Get("/publish/json?url=https://a.example.com/feed&category=weekly")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	body := resp.Body.String()
+	if !strings.Contains(body, "Weekly notes") || strings.Contains(body, "Go release") {
+		t.Errorf("expected only the auto-tagged item:\n%s", body)
+	}
+
+	// Items are not tagged when no category filter needs it
+	api.Get("/publish/json?url=https://a.example.com/feed")
+	if tagger.calls != 1 {
+		t.Errorf("tagger called %d times, want 1", tagger.calls)
+	}
+}
+
+func TestPublishHandler_Errors(t *testing.T) {
+	var requested []string
+	handler := NewPublishHandler(publishFeedService(&requested), nil)
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	if resp := api.Get("/publish/atom"); resp.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 without URLs", resp.Code)
+	}
+	if resp := api.Get("/publish/atom?url=https://a.example.com/feed&since=yesterday"); resp.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for invalid since", resp.Code)
+	}
+	if resp := api.Get("/publish/csv?url=https://a.example.com/feed"); resp.Code == http.StatusOK {
+		t.Error("expected unsupported format to be rejected")
+	}
+}
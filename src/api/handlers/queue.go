@@ -0,0 +1,110 @@
+// ABOUTME: Queue handler reports the state of the background enrichment job queue
+// ABOUTME: Lists queue depth per job type and the jobs that failed permanently
+
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// QueueHandler handles job queue administration requests
+type QueueHandler struct {
+	queue      interfaces.JobQueue
+	adminToken string
+}
+
+// NewQueueHandler creates a new queue handler. Requests must send the admin
+// token as a bearer token; an empty token refuses every request.
+func NewQueueHandler(queue interfaces.JobQueue, adminToken string) *QueueHandler {
+	return &QueueHandler{queue: queue, adminToken: adminToken}
+}
+
+// RegisterRoutes registers all queue routes
+func (h *QueueHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "getQueueStats",
+		Method:      http.MethodGet,
+		Path:        "/admin/queue",
+		Summary:     "Get background job queue stats",
+		Description: "Returns how many metadata, color and reader jobs are pending, running and dead, and the most recent dead jobs with their last error. Requires the admin token as a bearer token.",
+		Tags:        []string{"Admin"},
+	}, h.GetQueueStats)
+}
+
+// QueueFailure is a job that failed every attempt
+type QueueFailure struct {
+	Type       string    `json:"type" enum:"metadata,color,reader"`
+	URL        string    `json:"url"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"lastError"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	FailedAt   time.Time `json:"failedAt"`
+}
+
+// GetQueueStatsInput defines the input for queue stats
+type GetQueueStatsInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer admin token"`
+
+	Limit int `query:"limit" minimum:"1" maximum:"500" default:"50" doc:"Maximum number of failures to list"`
+}
+
+// GetQueueStatsOutput defines the output for queue stats
+type GetQueueStatsOutput struct {
+	Body struct {
+		Depth    map[string]map[string]int `json:"depth" doc:"Number of jobs by type and status (pending, running, dead)"`
+		Failures []QueueFailure            `json:"failures" doc:"Dead jobs, most recent first"`
+	}
+}
+
+// GetQueueStats handles the GET /admin/queue endpoint
+func (h *QueueHandler) GetQueueStats(ctx context.Context, input *GetQueueStatsInput) (*GetQueueStatsOutput, error) {
+	if !h.authorized(input.Authorization) {
+		return nil, huma.Error401Unauthorized("A valid admin token is required")
+	}
+
+	stats, err := h.queue.Stats(ctx)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	output := &GetQueueStatsOutput{}
+	output.Body.Depth = stats.Depth
+	output.Body.Failures = make([]QueueFailure, 0, len(stats.Failures))
+	for _, job := range stats.Failures {
+		if len(output.Body.Failures) == input.Limit {
+			break
+		}
+		output.Body.Failures = append(output.Body.Failures, toQueueFailure(job))
+	}
+
+	return output, nil
+}
+
+// authorized reports whether an Authorization header carries the admin token
+func (h *QueueHandler) authorized(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(h.adminToken)) == 1
+}
+
+// toQueueFailure converts a dead job into its API representation
+func toQueueFailure(job *domain.QueuedJob) QueueFailure {
+	return QueueFailure{
+		Type:       job.Type,
+		URL:        job.URL,
+		Attempts:   job.Attempts,
+		LastError:  job.LastError,
+		EnqueuedAt: job.EnqueuedAt,
+		FailedAt:   job.UpdatedAt,
+	}
+}
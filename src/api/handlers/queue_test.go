@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+type mockJobQueue struct {
+	stats *domain.QueueStats
+}
+
+func (m *mockJobQueue) Enqueue(ctx context.Context, jobType, url string) error {
+	return nil
+}
+
+func (m *mockJobQueue) Job(ctx context.Context, jobType, url string) (*domain.QueuedJob, error) {
+	return nil, nil
+}
+
+func (m *mockJobQueue) Stats(ctx context.Context) (*domain.QueueStats, error) {
+	return m.stats, nil
+}
+
+func TestQueueHandler_GetQueueStats(t *testing.T) {
+	now := time.Now()
+	queue := &mockJobQueue{stats: &domain.QueueStats{
+		Depth: map[string]map[string]int{
+			domain.JobTypeColor:    {domain.JobPending: 4, domain.JobRunning: 1, domain.JobDead: 2},
+			domain.JobTypeMetadata: {domain.JobPending: 0, domain.JobRunning: 0, domain.JobDead: 0},
+		},
+		Failures: []*domain.QueuedJob{
+			{Type: domain.JobTypeColor, URL: "https://img.example.com/b.jpg", Status: domain.JobDead, Attempts: 5, LastError: "status 503", UpdatedAt: now},
+			{Type: domain.JobTypeColor, URL: "https://img.example.com/a.jpg", Status: domain.JobDead, Attempts: 5, LastError: "timeout", UpdatedAt: now.Add(-time.Hour)},
+		},
+	}}
+
+	_, api := humatest.New(t)
+	NewQueueHandler(queue, "secret").RegisterRoutes(api)
+
+	if resp := api.Get("/admin/queue"); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.Code)
+	}
+	if resp := api.Get("/admin/queue", "Authorization: Bearer wrong"); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", resp.Code)
+	}
+
+	resp := api.Get("/admin/queue?limit=1", "Authorization: Bearer secret")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var body struct {
+		Depth    map[string]map[string]int `json:"depth"`
+		Failures []QueueFailure            `json:"failures"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Depth[domain.JobTypeColor][domain.JobPending] != 4 || body.Depth[domain.JobTypeColor][domain.JobDead] != 2 {
+		t.Errorf("unexpected depth: %+v", body.Depth)
+	}
+	if len(body.Failures) != 1 || body.Failures[0].URL != "https://img.example.com/b.jpg" || body.Failures[0].LastError != "status 503" || body.Failures[0].Attempts != 5 {
+		t.Errorf("unexpected failures: %+v", body.Failures)
+	}
+}
@@ -16,6 +16,7 @@ import (
 // ReaderHandler handles reader view extraction requests
 type ReaderHandler struct {
 	readerService interfaces.ReaderService
+	summarizer    interfaces.Summarizer
 }
 
 // NewReaderHandler creates a new reader handler
@@ -25,6 +26,11 @@ func NewReaderHandler(readerService interfaces.ReaderService) *ReaderHandler {
 	}
 }
 
+// SetSummarizer enables the summarize option of /getreaderview
+func (h *ReaderHandler) SetSummarizer(summarizer interfaces.Summarizer) {
+	h.summarizer = summarizer
+}
+
 // RegisterRoutes registers all reader-related routes
 func (h *ReaderHandler) RegisterRoutes(api huma.API) {
 	huma.Register(api, huma.Operation{
@@ -49,12 +55,30 @@ type GetReaderViewOutput struct {
 
 // GetReaderView handles reader view extraction
 func (h *ReaderHandler) GetReaderView(ctx context.Context, input *GetReaderViewInput) (*GetReaderViewOutput, error) {
-	if len(input.Body.URLs) == 0 {
+	if len(input.Body.URLs) == 0 && len(input.Body.Items) == 0 {
 		return nil, huma.Error400BadRequest("No URLs provided")
 	}
 
+	// Pages linked from feed items carry their feed context
+	targets := make([]domain.ReaderTarget, 0, len(input.Body.URLs)+len(input.Body.Items))
+	for _, url := range input.Body.URLs {
+		targets = append(targets, domain.ReaderTarget{URL: url})
+	}
+	for _, item := range input.Body.Items {
+		targets = append(targets, domain.ReaderTarget{URL: item.URL, FeedURL: item.FeedURL, ItemID: item.ItemID})
+	}
+
 	// Extract reader views
-	views := h.readerService.ExtractReaderViews(ctx, input.Body.URLs)
+	views := h.readerService.ExtractReaderViewsFor(ctx, targets)
+
+	// Summaries depend on the requested length, so they are not part of the cached view
+	if input.Body.Summarize && h.summarizer != nil {
+		for i := range views {
+			if views[i].Status == "ok" {
+				views[i].KeySentences = h.summarizer.Summarize(ctx, views[i].TextContent, input.Body.SummarySentences)
+			}
+		}
+	}
 
 	return &GetReaderViewOutput{
 		Body: views,
@@ -0,0 +1,225 @@
+// ABOUTME: Scraper handler for the Huma API
+// ABOUTME: Provides endpoints to manage CSS-selector scrapers and preview their output
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"digests-app-api/api/dto/responses"
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ScraperHandler handles scraper definition requests
+type ScraperHandler struct {
+	scraperService interfaces.ScraperService
+}
+
+// NewScraperHandler creates a new scraper handler
+func NewScraperHandler(scraperService interfaces.ScraperService) *ScraperHandler {
+	return &ScraperHandler{
+		scraperService: scraperService,
+	}
+}
+
+// RegisterRoutes registers all scraper-related routes
+func (h *ScraperHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "createScraper",
+		Method:        http.MethodPost,
+		Path:          "/scrapers",
+		Summary:       "Create a scraper",
+		Description:   "Stores a scraper definition that turns a web page into a feed, parseable as scraper://<id>",
+		Tags:          []string{"Scrapers"},
+		DefaultStatus: http.StatusCreated,
+	}, h.CreateScraper)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listScrapers",
+		Method:      http.MethodGet,
+		Path:        "/scrapers",
+		Summary:     "List scrapers",
+		Description: "Lists all stored scraper definitions",
+		Tags:        []string{"Scrapers"},
+	}, h.ListScrapers)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getScraper",
+		Method:      http.MethodGet,
+		Path:        "/scrapers/{id}",
+		Summary:     "Get a scraper",
+		Description: "Retrieves a stored scraper definition",
+		Tags:        []string{"Scrapers"},
+	}, h.GetScraper)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "deleteScraper",
+		Method:        http.MethodDelete,
+		Path:          "/scrapers/{id}",
+		Summary:       "Delete a scraper",
+		Description:   "Deletes a stored scraper definition",
+		Tags:          []string{"Scrapers"},
+		DefaultStatus: http.StatusNoContent,
+	}, h.DeleteScraper)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "previewScraper",
+		Method:      http.MethodPost,
+		Path:        "/scrapers/preview",
+		Summary:     "Preview a scraper",
+		Description: "Applies a scraper definition to the live page and returns the extracted feed without storing it",
+		Tags:        []string{"Scrapers"},
+	}, h.PreviewScraper)
+}
+
+// ScraperDefinitionBody is the request body describing a scraper
+type ScraperDefinitionBody struct {
+	Name            string `json:"name,omitempty" doc:"Feed title; defaults to the page title"`
+	URL             string `json:"url" doc:"Page to scrape" example:"https://example.com/changelog"`
+	ItemSelector    string `json:"itemSelector" doc:"CSS selector matching one element per item" example:"li.release"`
+	TitleSelector   string `json:"titleSelector,omitempty" doc:"Selector for the title, relative to the item; defaults to the link text"`
+	LinkSelector    string `json:"linkSelector,omitempty" doc:"Selector for the link element, relative to the item; defaults to the first a[href]"`
+	LinkAttribute   string `json:"linkAttribute,omitempty" doc:"Attribute holding the link; defaults to href"`
+	DateSelector    string `json:"dateSelector,omitempty" doc:"Selector for the date; datetime attributes are preferred over text"`
+	SummarySelector string `json:"summarySelector,omitempty" doc:"Selector for the summary"`
+	ImageSelector   string `json:"imageSelector,omitempty" doc:"Selector for the image; defaults to the first img"`
+}
+
+// toDomain converts the request body into a scraper definition
+func (b ScraperDefinitionBody) toDomain() domain.ScraperDefinition {
+	return domain.ScraperDefinition{
+		Name:            b.Name,
+		URL:             b.URL,
+		ItemSelector:    b.ItemSelector,
+		TitleSelector:   b.TitleSelector,
+		LinkSelector:    b.LinkSelector,
+		LinkAttribute:   b.LinkAttribute,
+		DateSelector:    b.DateSelector,
+		SummarySelector: b.SummarySelector,
+		ImageSelector:   b.ImageSelector,
+	}
+}
+
+// ScraperResponse represents a stored scraper definition
+type ScraperResponse struct {
+	ID      string `json:"id" doc:"Scraper ID"`
+	FeedURL string `json:"feedUrl" doc:"Source URL to pass to /parse" example:"scraper://3f1c..."`
+	ScraperDefinitionBody
+	CreatedAt string `json:"createdAt" doc:"Creation time (RFC3339)"`
+}
+
+// newScraperResponse converts a domain definition into a response
+func newScraperResponse(def *domain.ScraperDefinition) ScraperResponse {
+	return ScraperResponse{
+		ID:      def.ID,
+		FeedURL: def.FeedURL(),
+		ScraperDefinitionBody: ScraperDefinitionBody{
+			Name:            def.Name,
+			URL:             def.URL,
+			ItemSelector:    def.ItemSelector,
+			TitleSelector:   def.TitleSelector,
+			LinkSelector:    def.LinkSelector,
+			LinkAttribute:   def.LinkAttribute,
+			DateSelector:    def.DateSelector,
+			SummarySelector: def.SummarySelector,
+			ImageSelector:   def.ImageSelector,
+		},
+		CreatedAt: def.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ScraperDefinitionInput defines the input for creating or previewing a scraper
+type ScraperDefinitionInput struct {
+	Body ScraperDefinitionBody
+}
+
+// ScraperIDInput defines the input for operations on a stored scraper
+type ScraperIDInput struct {
+	ID string `path:"id" doc:"Scraper ID"`
+}
+
+// ScraperOutput defines the output for a single scraper
+type ScraperOutput struct {
+	Body ScraperResponse
+}
+
+// ListScrapersOutput defines the output for listing scrapers
+type ListScrapersOutput struct {
+	Body struct {
+		Scrapers []ScraperResponse `json:"scrapers" doc:"Stored scraper definitions"`
+	}
+}
+
+// PreviewScraperOutput defines the output for a scraper preview
+type PreviewScraperOutput struct {
+	Body responses.FeedV1Response
+}
+
+// CreateScraper handles the POST /scrapers endpoint
+func (h *ScraperHandler) CreateScraper(ctx context.Context, input *ScraperDefinitionInput) (*ScraperOutput, error) {
+	def, err := h.scraperService.CreateScraper(ctx, input.Body.toDomain())
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &ScraperOutput{Body: newScraperResponse(def)}, nil
+}
+
+// ListScrapers handles the GET /scrapers endpoint
+func (h *ScraperHandler) ListScrapers(ctx context.Context, input *struct{}) (*ListScrapersOutput, error) {
+	defs, err := h.scraperService.ListScrapers(ctx)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	output := &ListScrapersOutput{}
+	output.Body.Scrapers = make([]ScraperResponse, 0, len(defs))
+	for _, def := range defs {
+		output.Body.Scrapers = append(output.Body.Scrapers, newScraperResponse(def))
+	}
+
+	return output, nil
+}
+
+// GetScraper handles the GET /scrapers/{id} endpoint
+func (h *ScraperHandler) GetScraper(ctx context.Context, input *ScraperIDInput) (*ScraperOutput, error) {
+	def, err := h.scraperService.GetScraper(ctx, input.ID)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &ScraperOutput{Body: newScraperResponse(def)}, nil
+}
+
+// DeleteScraper handles the DELETE /scrapers/{id} endpoint
+func (h *ScraperHandler) DeleteScraper(ctx context.Context, input *ScraperIDInput) (*struct{}, error) {
+	if err := h.scraperService.DeleteScraper(ctx, input.ID); err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return nil, nil
+}
+
+// PreviewScraper handles the POST /scrapers/preview endpoint
+func (h *ScraperHandler) PreviewScraper(ctx context.Context, input *ScraperDefinitionInput) (*PreviewScraperOutput, error) {
+	feed, err := h.scraperService.PreviewScraper(ctx, input.Body.toDomain())
+	if err != nil {
+		if humaErr := toHumaError(err); !isInternalError(humaErr) {
+			return nil, humaErr
+		}
+		return nil, huma.Error422UnprocessableEntity("Failed to extract items from page: " + err.Error())
+	}
+
+	converted := responses.ConvertDomainFeedsToV1Response([]*domain.Feed{feed})
+	return &PreviewScraperOutput{Body: converted.Feeds[0]}, nil
+}
+
+// isInternalError reports whether a Huma error maps to a 500 response
+func isInternalError(err error) bool {
+	statusErr, ok := err.(huma.StatusError)
+	return ok && statusErr.GetStatus() == http.StatusInternalServerError
+}
@@ -0,0 +1,256 @@
+// ABOUTME: Search handler exposes local full-text search and feed directory search
+// ABOUTME: Returns ranked, highlighted item hits with facets and merged directory results
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	utiltime "digests-app-api/pkg/utils/time"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SearchHandler handles search requests
+type SearchHandler struct {
+	searchService     interfaces.ItemSearchService
+	feedSearchService interfaces.SearchService
+}
+
+// NewSearchHandler creates a new search handler.
+// The feed search service may be nil, in which case directory search is not served.
+func NewSearchHandler(searchService interfaces.ItemSearchService, feedSearchService interfaces.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService:     searchService,
+		feedSearchService: feedSearchService,
+	}
+}
+
+// RegisterRoutes registers all search routes
+func (h *SearchHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "searchItems",
+		Method:      http.MethodGet,
+		Path:        "/search",
+		Summary:     "Search parsed items",
+		Description: "Full-text search over every feed and item the service has parsed. Terms are combined with AND, \"quoted phrases\" must match in order and -terms exclude items.",
+		Tags:        []string{"Search"},
+	}, h.SearchItems)
+
+	if h.feedSearchService != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "searchFeeds",
+			Method:      http.MethodGet,
+			Path:        "/search/feeds",
+			Summary:     "Search feed directories",
+			Description: "Searches the configured feed directories (iTunes, Podcast Index and the local curated directory) and returns merged, deduplicated feeds ranked by relevance",
+			Tags:        []string{"Search"},
+		}, h.SearchFeeds)
+	}
+}
+
+// SearchItemsInput defines the input for searching items
+type SearchItemsInput struct {
+	Query    string   `query:"q" required:"true" minLength:"1" doc:"Search query"`
+	FeedURLs []string `query:"feed,explode" doc:"Only return items from these feeds (repeat the parameter)"`
+	From     string   `query:"from" doc:"Only return items published at or after this date"`
+	To       string   `query:"to" doc:"Only return items published at or before this date"`
+	Limit    int      `query:"limit" minimum:"0" maximum:"100" doc:"Maximum number of items (default 20)"`
+	Offset   int      `query:"offset" minimum:"0" doc:"Number of ranked items to skip"`
+}
+
+// SearchFeedsInput defines the input for searching feed directories
+type SearchFeedsInput struct {
+	Query string `query:"q" required:"true" minLength:"2" maxLength:"100" doc:"Search query"`
+	Limit int    `query:"limit" minimum:"0" maximum:"100" doc:"Maximum number of feeds (default 20)"`
+}
+
+// SearchFeedResult is a directory feed in the search response
+type SearchFeedResult struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url"`
+	SiteURL     string   `json:"siteUrl,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Score       int      `json:"score" doc:"Relevance score (0-100)"`
+	Providers   []string `json:"providers" doc:"Directories that returned this feed"`
+}
+
+// SearchFeedsOutput defines the output for searching feed directories
+type SearchFeedsOutput struct {
+	Body struct {
+		Query   string             `json:"query"`
+		Total   int                `json:"total"`
+		Results []SearchFeedResult `json:"results"`
+	}
+}
+
+// SearchItemHit is a ranked item in the search response
+type SearchItemHit struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Link           string    `json:"link"`
+	Author         string    `json:"author,omitempty"`
+	Published      time.Time `json:"published"`
+	Thumbnail      string    `json:"thumbnail,omitempty"`
+	FeedURL        string    `json:"feedUrl"`
+	FeedTitle      string    `json:"feedTitle"`
+	Score          float64   `json:"score"`
+	TitleHighlight string    `json:"titleHighlight" doc:"HTML-escaped title with matches wrapped in <mark>"`
+	Snippet        string    `json:"snippet" doc:"HTML-escaped excerpt with matches wrapped in <mark>"`
+}
+
+// SearchFeedHit is a matching feed in the search response
+type SearchFeedHit struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	URL         string  `json:"url"`
+	Link        string  `json:"link,omitempty"`
+	ItemCount   int     `json:"itemCount"`
+	Score       float64 `json:"score"`
+}
+
+// SearchFacetValue is a facet bucket in the search response
+type SearchFacetValue struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// SearchItemsOutput defines the output for searching items
+type SearchItemsOutput struct {
+	Body struct {
+		Query  string          `json:"query"`
+		Total  int             `json:"total"`
+		Items  []SearchItemHit `json:"items"`
+		Feeds  []SearchFeedHit `json:"feeds"`
+		Facets struct {
+			Feeds []SearchFacetValue `json:"feeds"`
+			Dates []SearchFacetValue `json:"dates" doc:"Matches per publication month (YYYY-MM)"`
+		} `json:"facets"`
+	}
+}
+
+// SearchItems handles the GET /search endpoint
+func (h *SearchHandler) SearchItems(ctx context.Context, input *SearchItemsInput) (*SearchItemsOutput, error) {
+	query := domain.ItemSearchQuery{
+		Text:     input.Query,
+		FeedURLs: input.FeedURLs,
+		Limit:    input.Limit,
+		Offset:   input.Offset,
+	}
+
+	if input.From != "" {
+		query.From = utiltime.ParseFlexibleTime(input.From)
+		if query.From.IsZero() {
+			return nil, huma.Error400BadRequest("Invalid from date")
+		}
+	}
+	if input.To != "" {
+		query.To = utiltime.ParseFlexibleTime(input.To)
+		if query.To.IsZero() {
+			return nil, huma.Error400BadRequest("Invalid to date")
+		}
+		// A bare date includes the whole day
+		if len(input.To) == len("2006-01-02") {
+			query.To = query.To.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	results, err := h.searchService.SearchItems(ctx, query)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	output := &SearchItemsOutput{}
+	output.Body.Query = input.Query
+	output.Body.Total = results.Total
+	output.Body.Items = make([]SearchItemHit, 0, len(results.Items))
+	output.Body.Feeds = make([]SearchFeedHit, 0, len(results.Feeds))
+	output.Body.Facets.Feeds = convertSearchFacets(results.FeedFacets)
+	output.Body.Facets.Dates = convertSearchFacets(results.DateFacets)
+
+	for _, hit := range results.Items {
+		output.Body.Items = append(output.Body.Items, SearchItemHit{
+			ID:             hit.Item.ID,
+			Title:          hit.Item.Title,
+			Link:           hit.Item.Link,
+			Author:         hit.Item.Author,
+			Published:      hit.Item.Published,
+			Thumbnail:      hit.Item.Thumbnail,
+			FeedURL:        hit.FeedURL,
+			FeedTitle:      hit.FeedTitle,
+			Score:          hit.Score,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+		})
+	}
+
+	for _, feed := range results.Feeds {
+		output.Body.Feeds = append(output.Body.Feeds, SearchFeedHit{
+			Title:       feed.Title,
+			Description: feed.Description,
+			URL:         feed.URL,
+			Link:        feed.Link,
+			ItemCount:   feed.ItemCount,
+			Score:       feed.Score,
+		})
+	}
+
+	return output, nil
+}
+
+// convertSearchFacets converts domain facets into response facets
+func convertSearchFacets(facets []domain.SearchFacet) []SearchFacetValue {
+	values := make([]SearchFacetValue, 0, len(facets))
+	for _, facet := range facets {
+		values = append(values, SearchFacetValue{
+			Value: facet.Value,
+			Label: facet.Label,
+			Count: facet.Count,
+		})
+	}
+	return values
+}
+
+// SearchFeeds handles the GET /search/feeds endpoint
+func (h *SearchHandler) SearchFeeds(ctx context.Context, input *SearchFeedsInput) (*SearchFeedsOutput, error) {
+	results, err := h.feedSearchService.SearchRSSFeeds(ctx, input.Query)
+	if err != nil {
+		return nil, huma.Error502BadGateway("Feed search failed", err)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	output := &SearchFeedsOutput{}
+	output.Body.Query = input.Query
+	output.Body.Total = len(results)
+	output.Body.Results = make([]SearchFeedResult, 0, limit)
+
+	for i, result := range results {
+		if i >= limit {
+			break
+		}
+		output.Body.Results = append(output.Body.Results, SearchFeedResult{
+			Title:       result.Title,
+			Description: result.Description,
+			URL:         result.URL,
+			SiteURL:     result.SiteURL,
+			Language:    result.Language,
+			Author:      result.Author,
+			Image:       result.Image,
+			Score:       result.Score,
+			Providers:   result.Providers,
+		})
+	}
+
+	return output, nil
+}
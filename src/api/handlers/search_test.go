@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/search"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestSearchHandler_SearchItems(t *testing.T) {
+	index := search.NewIndex(0)
+	_ = index.IndexFeed(context.Background(), &domain.Feed{
+		Title: "Go Blog",
+		URL:   "https://go.example.com/feed",
+		Items: []domain.FeedItem{
+			{ID: "1", Title: "Go generics", Published: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: "2", Title: "More generics", Published: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+
+	_, api := humatest.New(t)
+	NewSearchHandler(index, nil).RegisterRoutes(api)
+
+	resp := api.Get("/search?q=generics&to=2024-03-01")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+
+	var body struct {
+		Total int `json:"total"`
+		Items []struct {
+			ID             string `json:"id"`
+			TitleHighlight string `json:"titleHighlight"`
+		} `json:"items"`
+		Facets struct {
+			Dates []struct {
+				Value string `json:"value"`
+			} `json:"dates"`
+		} `json:"facets"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if body.Total != 2 || len(body.Facets.Dates) != 2 {
+		t.Errorf("a bare to date should include the whole day: %s", resp.Body.String())
+	}
+	if body.Items[0].TitleHighlight == "" {
+		t.Error("expected highlighted title")
+	}
+
+	if resp := api.Get("/search?q=generics&from=yesterday-ish"); resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid date status = %d, want 400", resp.Code)
+	}
+}
+
+// stubFeedSearchService returns fixed directory results
+type stubFeedSearchService struct {
+	results []domain.SearchResult
+	err     error
+}
+
+func (s *stubFeedSearchService) SearchRSSFeeds(ctx context.Context, query string) ([]domain.SearchResult, error) {
+	return s.results, s.err
+}
+
+func TestSearchHandler_SearchFeeds(t *testing.T) {
+	feedSearch := &stubFeedSearchService{results: []domain.SearchResult{
+		{Title: "Go Time", URL: "https://changelog.com/gotime/feed", Score: 95, Providers: []string{"itunes", "directory"}},
+		{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", Score: 80, Providers: []string{"directory"}},
+	}}
+
+	_, api := humatest.New(t)
+	NewSearchHandler(search.NewIndex(0), feedSearch).RegisterRoutes(api)
+
+	resp := api.Get("/search/feeds?q=go&limit=1")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+
+	var body struct {
+		Total   int `json:"total"`
+		Results []struct {
+			Score     int      `json:"score"`
+			Providers []string `json:"providers"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if body.Total != 2 || len(body.Results) != 1 || body.Results[0].Score != 95 || len(body.Results[0].Providers) != 2 {
+		t.Errorf("unexpected response: %s", resp.Body.String())
+	}
+
+	feedSearch.err = errors.New("providers down")
+	if resp := api.Get("/search/feeds?q=go"); resp.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", resp.Code)
+	}
+}
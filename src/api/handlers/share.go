@@ -0,0 +1,355 @@
+// ABOUTME: Share handler for the Huma API
+// ABOUTME: Creates shareable feed collections and serves them as JSON, HTML pages and OPML
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"digests-app-api/api/dto/responses"
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"digests-app-api/core/publish"
+	"digests-app-api/core/share"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxShareExpiry is the longest expiry a share can be created with
+const maxShareExpiry = 365 * 24 * time.Hour
+
+// sharePageCacheControl lets shared pages and OPML exports be cached by link unfurlers
+const sharePageCacheControl = "public, max-age=900"
+
+// ShareHandler handles share requests
+type ShareHandler struct {
+	shareService      interfaces.ShareService
+	feedService       interfaces.FeedService
+	enrichmentService interfaces.ContentEnrichmentService
+}
+
+// NewShareHandler creates a new share handler. The enrichment service is
+// optional and only used to find favicons for the rendered share page.
+func NewShareHandler(shareService interfaces.ShareService, feedService interfaces.FeedService, enrichmentService interfaces.ContentEnrichmentService) *ShareHandler {
+	return &ShareHandler{
+		shareService:      shareService,
+		feedService:       feedService,
+		enrichmentService: enrichmentService,
+	}
+}
+
+// RegisterRoutes registers all share routes
+func (h *ShareHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "createShare",
+		Method:        http.MethodPost,
+		Path:          "/share",
+		Summary:       "Create a share",
+		Description:   "Stores a collection of feed URLs under a shareable ID, with an optional title, description and expiry",
+		Tags:          []string{"Share"},
+		DefaultStatus: http.StatusCreated,
+	}, h.CreateShare)
+
+	// The share link itself unfurls in chat apps and opens in browsers, so
+	// it serves the HTML page to clients asking for HTML
+	registry := api.OpenAPI().Components.Schemas
+	huma.Register(api, huma.Operation{
+		OperationID: "getShare",
+		Method:      http.MethodGet,
+		Path:        "/share/{id}",
+		Summary:     "Get a share",
+		Description: "Returns a share along with its feeds parsed, or the share page when the request accepts text/html",
+		Tags:        []string{"Share"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "The share, or its HTML page",
+				Content: map[string]*huma.MediaType{
+					"application/json": {Schema: registry.Schema(reflect.TypeOf(ShareDetails{}), true, "ShareDetails")},
+					"text/html":        {Schema: &huma.Schema{Type: huma.TypeString}},
+				},
+			},
+		},
+	}, h.GetShare)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getSharePage",
+		Method:      http.MethodGet,
+		Path:        "/share/{id}/page",
+		Summary:     "Render a share page",
+		Description: "Renders a share as an HTML page with Open Graph tags, favicons and the latest items of each feed",
+		Tags:        []string{"Share"},
+	}, h.GetSharePage)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getShareOPML",
+		Method:      http.MethodGet,
+		Path:        "/share/{id}/opml",
+		Summary:     "Export a share as OPML",
+		Description: "Returns the feeds of a share as an OPML subscription list",
+		Tags:        []string{"Share"},
+	}, h.GetShareOPML)
+}
+
+// ShareLinksRequest resolves the base URL share links are built from
+type ShareLinksRequest struct {
+	baseURL string
+}
+
+// Resolve records the scheme and host the request was made to
+func (r *ShareLinksRequest) Resolve(ctx huma.Context) []error {
+	u := requestURL(ctx)
+	r.baseURL = (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+	return nil
+}
+
+// ShareLinks are the URLs a share can be viewed or subscribed to at
+type ShareLinks struct {
+	Page string `json:"page" doc:"HTML page of the share"`
+	OPML string `json:"opml" doc:"OPML export of the share"`
+	Atom string `json:"atom" doc:"Combined Atom feed of the share"`
+	RSS  string `json:"rss" doc:"Combined RSS feed of the share"`
+}
+
+// shareLinks builds the links of a share relative to the base URL
+func shareLinks(baseURL, id string) ShareLinks {
+	base := strings.TrimSuffix(baseURL, "/") + "/share/" + url.PathEscape(id)
+	return ShareLinks{
+		Page: base + "/page",
+		OPML: base + "/opml",
+		Atom: base + "/feed/atom",
+		RSS:  base + "/feed/rss",
+	}
+}
+
+// CreateShareInput defines the input for creating a share
+type CreateShareInput struct {
+	ShareLinksRequest
+	Body struct {
+		URLs        []string   `json:"urls" minItems:"1" maxItems:"100" doc:"Feed URLs to share"`
+		Title       string     `json:"title,omitempty" maxLength:"200" doc:"Title of the share"`
+		Description string     `json:"description,omitempty" maxLength:"2000" doc:"Description of the share"`
+		ExpiresIn   string     `json:"expiresIn,omitempty" doc:"Expire the share after this duration, such as 72h"`
+		ExpiresAt   *time.Time `json:"expiresAt,omitempty" doc:"Expire the share at this time"`
+	}
+}
+
+// ShareResponse describes a share
+type ShareResponse struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	URLs        []string   `json:"urls"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	Links       ShareLinks `json:"links"`
+}
+
+// CreateShareOutput defines the output for creating a share
+type CreateShareOutput struct {
+	Body ShareResponse
+}
+
+// GetShareInput defines the input for getting a share
+type GetShareInput struct {
+	ShareLinksRequest
+	ID     string `path:"id" doc:"Share ID"`
+	Accept string `header:"Accept" doc:"Send text/html to get the share page"`
+}
+
+// ShareDetails is a share along with its parsed feeds
+type ShareDetails struct {
+	ShareResponse
+	Feeds []responses.FeedV1Response `json:"feeds"`
+}
+
+// GetShareOutput defines the output for getting a share: ShareDetails, or
+// the rendered share page
+type GetShareOutput struct {
+	ContentType  string `header:"Content-Type"`
+	CacheControl string `header:"Cache-Control"`
+	Vary         string `header:"Vary"`
+	Body         any
+}
+
+// ShareDocumentOutput is a rendered share page or export
+type ShareDocumentOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	CacheControl       string `header:"Cache-Control"`
+	Body               []byte
+}
+
+// CreateShare handles the POST /share endpoint
+func (h *ShareHandler) CreateShare(ctx context.Context, input *CreateShareInput) (*CreateShareOutput, error) {
+	opts := domain.ShareOptions{
+		Title:       input.Body.Title,
+		Description: input.Body.Description,
+		ExpiresAt:   input.Body.ExpiresAt,
+	}
+
+	if input.Body.ExpiresIn != "" {
+		if input.Body.ExpiresAt != nil {
+			return nil, huma.Error400BadRequest("Use either expiresIn or expiresAt, not both")
+		}
+		d, err := time.ParseDuration(input.Body.ExpiresIn)
+		if err != nil || d <= 0 {
+			return nil, huma.Error400BadRequest("Invalid expiresIn value: use a duration such as 72h")
+		}
+		expiresAt := time.Now().Add(d)
+		opts.ExpiresAt = &expiresAt
+	}
+	if opts.ExpiresAt != nil && time.Until(*opts.ExpiresAt) > maxShareExpiry {
+		return nil, huma.Error400BadRequest("Shares cannot expire more than a year from now")
+	}
+
+	created, err := h.shareService.CreateShareWithOptions(ctx, input.Body.URLs, opts)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return &CreateShareOutput{Body: toShareResponse(created, input.baseURL)}, nil
+}
+
+// GetShare handles the GET /share/{id} endpoint
+func (h *ShareHandler) GetShare(ctx context.Context, input *GetShareInput) (*GetShareOutput, error) {
+	found, err := lookupShare(ctx, h.shareService, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := h.feedService.ParseFeeds(ctx, found.URLs)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	if acceptsHTML(input.Accept) {
+		page, err := h.sharePage(ctx, found, feeds, input.baseURL)
+		if err != nil {
+			return nil, err
+		}
+		return &GetShareOutput{
+			ContentType:  page.ContentType,
+			CacheControl: page.CacheControl,
+			Vary:         "Accept",
+			Body:         page.Body,
+		}, nil
+	}
+
+	return &GetShareOutput{
+		Vary: "Accept",
+		Body: ShareDetails{
+			ShareResponse: toShareResponse(found, input.baseURL),
+			Feeds:         responses.ConvertDomainFeedsToV1Response(feeds).Feeds,
+		},
+	}, nil
+}
+
+// GetSharePage handles the GET /share/{id}/page endpoint
+func (h *ShareHandler) GetSharePage(ctx context.Context, input *GetShareInput) (*ShareDocumentOutput, error) {
+	found, err := lookupShare(ctx, h.shareService, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := h.feedService.ParseFeeds(ctx, found.URLs)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	return h.sharePage(ctx, found, feeds, input.baseURL)
+}
+
+// sharePage renders the HTML page of a share
+func (h *ShareHandler) sharePage(ctx context.Context, found *domain.Share, feeds []*domain.Feed, baseURL string) (*ShareDocumentOutput, error) {
+	body, err := h.renderSharePage(ctx, found, feeds, shareLinks(baseURL, found.ID))
+	if err != nil {
+		return nil, huma.Error500InternalServerError(err.Error())
+	}
+
+	return &ShareDocumentOutput{
+		ContentType:  "text/html; charset=utf-8",
+		CacheControl: sharePageCacheControl,
+		Body:         body,
+	}, nil
+}
+
+// acceptsHTML reports whether an Accept header asks for HTML, as browsers
+// and link unfurlers do; API clients asking for JSON first get JSON
+func acceptsHTML(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+		switch strings.ToLower(mediaType) {
+		case "text/html", "application/xhtml+xml":
+			return true
+		case "application/json":
+			return false
+		}
+	}
+	return false
+}
+
+// GetShareOPML handles the GET /share/{id}/opml endpoint
+func (h *ShareHandler) GetShareOPML(ctx context.Context, input *GetShareInput) (*ShareDocumentOutput, error) {
+	found, err := lookupShare(ctx, h.shareService, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := h.feedService.ParseFeeds(ctx, found.URLs)
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+
+	title := found.Title
+	if title == "" {
+		title = "Shared feeds"
+	}
+	publishURL := strings.TrimSuffix(input.baseURL, "/") + "/publish/rss"
+	body, err := publish.RenderOPML(title, found.CreatedAt, found.URLs, feeds, publishURL)
+	if err != nil {
+		return nil, huma.Error500InternalServerError(err.Error())
+	}
+
+	return &ShareDocumentOutput{
+		ContentType:        publish.OPMLContentType,
+		ContentDisposition: fmt.Sprintf(`attachment; filename="share-%s.opml"`, found.ID),
+		CacheControl:       sharePageCacheControl,
+		Body:               body,
+	}, nil
+}
+
+// lookupShare retrieves a share, mapping missing and expired shares to HTTP
+// errors; storage failures are not mistaken for missing shares
+func lookupShare(ctx context.Context, shareService interfaces.ShareService, id string) (*domain.Share, error) {
+	found, err := shareService.GetShare(ctx, id)
+	if errors.Is(err, share.ErrShareExpired) {
+		return nil, huma.Error410Gone("Share has expired")
+	}
+	if err != nil {
+		return nil, toHumaError(err)
+	}
+	if found == nil {
+		return nil, huma.Error404NotFound("Share not found")
+	}
+
+	return found, nil
+}
+
+// toShareResponse converts a share into its API representation
+func toShareResponse(s *domain.Share, baseURL string) ShareResponse {
+	return ShareResponse{
+		ID:          s.ID,
+		Title:       s.Title,
+		Description: s.Description,
+		URLs:        s.URLs,
+		CreatedAt:   s.CreatedAt,
+		ExpiresAt:   s.ExpiresAt,
+		Links:       shareLinks(baseURL, s.ID),
+	}
+}
@@ -0,0 +1,198 @@
+// ABOUTME: Server-rendered HTML page for share links with Open Graph tags
+// ABOUTME: Lists the shared feeds with favicons, latest items and OPML/feed alternates
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+// sharePageItems is how many of the latest items are listed per feed
+const sharePageItems = 3
+
+// sharePageTemplate renders a share; html/template escapes every value
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="Digests">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.Links.Page}}">
+{{- if .Image}}
+<meta property="og:image" content="{{.Image}}">
+{{- end}}
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<link rel="canonical" href="{{.Links.Page}}">
+<link rel="alternate" type="application/atom+xml" title="{{.Title}}" href="{{.Links.Atom}}">
+<link rel="alternate" type="application/rss+xml" title="{{.Title}}" href="{{.Links.RSS}}">
+<link rel="alternate" type="text/x-opml" title="{{.Title}} (OPML)" href="{{.Links.OPML}}">
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;max-width:720px;margin:2rem auto;padding:0 1rem;color:#1f2328;line-height:1.5}
+header p{color:#59636e}
+.actions a{display:inline-block;margin:0 .5rem .5rem 0;padding:.4rem .8rem;border:1px solid #d1d9e0;border-radius:6px;text-decoration:none;color:inherit}
+.feed{border-top:1px solid #d1d9e0;padding:1rem 0}
+.feed h2{font-size:1.1rem;margin:0;display:flex;align-items:center;gap:.5rem}
+.feed h2 img{width:20px;height:20px;border-radius:4px}
+.feed ul{padding-left:1.2rem;margin:.5rem 0 0}
+.feed time,.feed .url{color:#59636e;font-size:.85rem}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<p class="actions"><a href="{{.Links.Atom}}">Subscribe (Atom)</a><a href="{{.Links.OPML}}" download>Download OPML</a></p>
+</header>
+<main>
+{{- range .Feeds}}
+<section class="feed">
+<h2>{{if .Favicon}}<img src="{{.Favicon}}" alt="" loading="lazy">{{end}}{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+<div class="url">{{.URL}}</div>
+{{- if .Items}}
+<ul>
+{{- range .Items}}
+<li>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if not .Published.IsZero}} <time datetime="{{.Published.Format "2006-01-02T15:04:05Z07:00"}}">{{.Published.Format "Jan 2, 2006"}}</time>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+</main>
+</body>
+</html>
+`))
+
+// sharePage is the data rendered by sharePageTemplate
+type sharePage struct {
+	Title       string
+	Description string
+	Image       string
+	Links       ShareLinks
+	Feeds       []sharePageFeed
+}
+
+// sharePageFeed is a shared feed on the page
+type sharePageFeed struct {
+	Title   string
+	URL     string
+	Link    string
+	Favicon string
+	Items   []sharePageItem
+}
+
+// sharePageItem is one of the latest items of a shared feed
+type sharePageItem struct {
+	Title     string
+	Link      string
+	Published time.Time
+}
+
+// renderSharePage builds the HTML page of a share from its parsed feeds.
+// Feeds without a favicon get one from page metadata when the enrichment
+// service is available, falling back to /favicon.ico of the site.
+func (h *ShareHandler) renderSharePage(ctx context.Context, s *domain.Share, feeds []*domain.Feed, links ShareLinks) ([]byte, error) {
+	parsed := make(map[string]*domain.Feed, len(feeds))
+	var siteLinks []string
+	for _, feed := range feeds {
+		if feed == nil {
+			continue
+		}
+		parsed[feed.URL] = feed
+		if feed.Favicon == "" && feed.Link != "" {
+			siteLinks = append(siteLinks, feed.Link)
+		}
+	}
+
+	favicons := make(map[string]string)
+	if h.enrichmentService != nil && len(siteLinks) > 0 {
+		for link, meta := range h.enrichmentService.ExtractMetadataBatch(ctx, siteLinks) {
+			if meta != nil && meta.Favicon != "" {
+				favicons[link] = meta.Favicon
+			}
+		}
+	}
+
+	page := sharePage{
+		Title:       s.Title,
+		Description: s.Description,
+		Links:       links,
+	}
+
+	var titles []string
+	for _, u := range s.URLs {
+		entry := sharePageFeed{Title: u, URL: u}
+
+		if feed, ok := parsed[u]; ok {
+			if feed.Title != "" {
+				entry.Title = feed.Title
+			}
+			entry.Link = feed.Link
+			entry.Favicon = feed.Favicon
+			if entry.Favicon == "" {
+				entry.Favicon = favicons[feed.Link]
+			}
+			if page.Image == "" {
+				page.Image = feed.Image
+			}
+			for i, item := range feed.Items {
+				if i >= sharePageItems {
+					break
+				}
+				entry.Items = append(entry.Items, sharePageItem{Title: item.Title, Link: item.Link, Published: item.Published})
+			}
+		}
+
+		if entry.Favicon == "" {
+			entry.Favicon = defaultFavicon(entry.Link, u)
+		}
+
+		titles = append(titles, entry.Title)
+		page.Feeds = append(page.Feeds, entry)
+	}
+
+	if page.Title == "" {
+		page.Title = fmt.Sprintf("%d shared feeds", len(s.URLs))
+		if len(s.URLs) == 1 {
+			page.Title = titles[0]
+		}
+	}
+	if page.Description == "" {
+		page.Description = "Feeds: " + strings.Join(titles, ", ")
+	}
+
+	var buf bytes.Buffer
+	if err := sharePageTemplate.Execute(&buf, page); err != nil {
+		return nil, fmt.Errorf("failed to render share page: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// defaultFavicon guesses the favicon location of a site, preferring its website link
+func defaultFavicon(candidates ...string) string {
+	for _, candidate := range candidates {
+		u, err := url.Parse(candidate)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		return u.Scheme + "://" + u.Host + "/favicon.ico"
+	}
+	return ""
+}
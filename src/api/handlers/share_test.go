@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/share"
+	"digests-app-api/infrastructure/storage/memory"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestShareHandler_CreateAndGet(t *testing.T) {
+	var requested []string
+	storage := memory.NewShareStorage()
+	handler := NewShareHandler(share.NewShareService(storage), publishFeedService(&requested), nil)
+
+	_, api := humatest.New(t)
+	handler.RegisterRoutes(api)
+
+	resp := api.Post("/share", map[string]interface{}{
+		"urls":      []string{"https://a.example.com/feed"},
+		"title":     "Reading list",
+		"expiresIn": "72h",
+	})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+
+	var created ShareResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if created.ID == "" || created.Title != "Reading list" || created.ExpiresAt == nil {
+		t.Fatalf("unexpected share: %+v", created)
+	}
+	if !strings.HasSuffix(created.Links.Page, "/share/"+created.ID+"/page") || !strings.HasPrefix(created.Links.OPML, "http") {
+		t.Errorf("unexpected share links: %+v", created.Links)
+	}
+
+	resp = api.Get("/share/" + created.ID)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	var got struct {
+		Title string `json:"title"`
+		Feeds []struct {
+			FeedURL string `json:"feedUrl"`
+		} `json:"feeds"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if got.Title != "Reading list" || len(got.Feeds) != 1 || len(requested) != 1 {
+		t.Errorf("expected the share's feeds to be parsed: %s", resp.Body.String())
+	}
+
+	if resp := api.Get("/share/550e8400-e29b-41d4-a716-446655440000"); resp.Code != http.StatusNotFound {
+		t.Errorf("missing share status = %d, want 404", resp.Code)
+	}
+}
+
+func TestShareHandler_ExpiredShare(t *testing.T) {
+	storage := memory.NewShareStorage()
+	past := time.Now().Add(-time.Hour)
+	expired := &domain.Share{ID: "550e8400-e29b-41d4-a716-446655440000", URLs: []string{"https://a.example.com/feed"}, ExpiresAt: &past}
+	_ = storage.Save(context.Background(), expired)
+
+	var requested []string
+	_, api := humatest.New(t)
+	NewShareHandler(share.NewShareService(storage), publishFeedService(&requested), nil).RegisterRoutes(api)
+
+	if resp := api.Get("/share/" + expired.ID); resp.Code != http.StatusGone {
+		t.Errorf("expired share status = %d, want 410", resp.Code)
+	}
+
+	resp := api.Post("/share", map[string]interface{}{
+		"urls":      []string{"https://a.example.com/feed"},
+		"expiresIn": "soon",
+	})
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid expiresIn status = %d, want 400", resp.Code)
+	}
+}
+
+// failingShareStorage fails every operation, as an unreachable database would
+type failingShareStorage struct{}
+
+func (f failingShareStorage) Save(ctx context.Context, s *domain.Share) error {
+	return errors.New("database is locked")
+}
+
+func (f failingShareStorage) Get(ctx context.Context, id string) (*domain.Share, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestShareHandler_ErrorMapping(t *testing.T) {
+	var requested []string
+	_, api := humatest.New(t)
+	NewShareHandler(share.NewShareService(failingShareStorage{}), publishFeedService(&requested), nil).RegisterRoutes(api)
+
+	// Storage failures are server errors, not missing shares or bad requests
+	if resp := api.Get("/share/550e8400-e29b-41d4-a716-446655440000"); resp.Code != http.StatusInternalServerError {
+		t.Errorf("storage failure on get status = %d, want 500", resp.Code)
+	}
+	if resp := api.Post("/share", map[string]interface{}{"urls": []string{"https://a.example.com/feed"}}); resp.Code != http.StatusInternalServerError {
+		t.Errorf("storage failure on create status = %d, want 500", resp.Code)
+	}
+
+	// IDs that cannot exist are missing, invalid shares are bad requests
+	if resp := api.Get("/share/not-a-share"); resp.Code != http.StatusNotFound {
+		t.Errorf("invalid ID status = %d, want 404", resp.Code)
+	}
+	if resp := api.Post("/share", map[string]interface{}{"urls": []string{"not a url"}}); resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid URL status = %d, want 400", resp.Code)
+	}
+}
+
+func TestShareHandler_PageAndOPML(t *testing.T) {
+	var requested []string
+	storage := memory.NewShareStorage()
+	service := share.NewShareService(storage)
+	created, err := service.CreateShareWithOptions(context.Background(), []string{"https://a.example.com/feed", "https://b.example.com/feed"}, domain.ShareOptions{
+		Title: "Go & friends",
+	})
+	if err != nil {
+		t.Fatalf("create share: %v", err)
+	}
+
+	_, api := humatest.New(t)
+	NewShareHandler(service, publishFeedService(&requested), &mockEnrichmentService{}).RegisterRoutes(api)
+
+	resp := api.Get("/share/" + created.ID + "/page")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	page := resp.Body.String()
+	for _, want := range []string{
+		`<meta property="og:title" content="Go &amp; friends">`,
+		`type="application/atom+xml"`,
+		`/share/` + created.ID + `/feed/atom"`,
+		`type="text/x-opml"`,
+		`https://a.example.com/favicon.ico`,
+		`Feed https://b.example.com/feed`,
+		`Weekly notes`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	resp = api.Get("/share/" + created.ID + "/opml")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", resp.Code, resp.Body.String())
+	}
+	if cd := resp.Header().Get("Content-Disposition"); !strings.Contains(cd, "share-"+created.ID+".opml") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !strings.Contains(resp.Body.String(), `xmlUrl="https://b.example.com/feed"`) {
+		t.Errorf("OPML missing feed: %s", resp.Body.String())
+	}
+
+	if resp := api.Get("/share/550e8400-e29b-41d4-a716-446655440000/page"); resp.Code != http.StatusNotFound {
+		t.Errorf("missing share page status = %d, want 404", resp.Code)
+	}
+
+	// Browsers and link unfurlers opening the share link get the page
+	resp = api.Get("/share/"+created.ID, "Accept: text/html,application/xhtml+xml,*/*;q=0.8")
+	if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected the share page, got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(resp.Body.String(), `<meta property="og:title" content="Go &amp; friends">`) || resp.Header().Get("Vary") != "Accept" {
+		t.Errorf("unexpected share page: %s", resp.Body.String())
+	}
+
+	resp = api.Get("/share/"+created.ID, "Accept: application/json")
+	if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("expected JSON for API clients, got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+}
@@ -15,14 +15,27 @@ import (
 
 	"digests-app-api/api"
 	"digests-app-api/api/handlers"
+	"digests-app-api/core/autotag"
+	"digests-app-api/core/cluster"
+	"digests-app-api/core/domain"
+	"digests-app-api/core/epub"
 	"digests-app-api/core/feed"
 	"digests-app-api/core/interfaces"
+	"digests-app-api/core/language"
 	"digests-app-api/core/reader"
 	"digests-app-api/core/search"
 	"digests-app-api/core/services"
+	"digests-app-api/core/share"
+	"digests-app-api/core/siterules"
+	"digests-app-api/core/sources"
+	"digests-app-api/core/summarize"
+	"digests-app-api/core/workers"
 	"digests-app-api/infrastructure/cache/memory"
 	"digests-app-api/infrastructure/cache/redis"
 	"digests-app-api/infrastructure/cache/sqlite"
+	memstorage "digests-app-api/infrastructure/storage/memory"
+	redisstorage "digests-app-api/infrastructure/storage/redis"
+	sqlitestorage "digests-app-api/infrastructure/storage/sqlite"
 	stdhttp "digests-app-api/infrastructure/http/standard"
 	stdlogger "digests-app-api/infrastructure/logger/standard"
 	"digests-app-api/pkg/config"
@@ -82,6 +95,93 @@ func main() {
 		logger.Info("Using memory cache", nil)
 	}
 
+	// Create persistent storage for user-defined entities
+	var sqliteStore *sqlitestorage.Store
+	openSQLiteStore := func() *sqlitestorage.Store {
+		if sqliteStore == nil {
+			store, err := sqlitestorage.NewStore(cfg.Storage.SQLite.FilePath)
+			if err != nil {
+				log.Fatalf("Failed to open SQLite storage: %v", err)
+			}
+			sqliteStore = store
+		}
+		return sqliteStore
+	}
+
+	var scraperStorage interfaces.ScraperStorage
+	var mappingStorage interfaces.JSONMappingStorage
+	switch cfg.Storage.Type {
+	case "sqlite":
+		scraperStorage = sqlitestorage.NewScraperStorage(openSQLiteStore())
+		mappingStorage = sqlitestorage.NewJSONMappingStorage(openSQLiteStore())
+		logger.Info("Using SQLite storage", map[string]interface{}{
+			"file_path": cfg.Storage.SQLite.FilePath,
+		})
+	default:
+		scraperStorage = memstorage.NewScraperStorage()
+		mappingStorage = memstorage.NewJSONMappingStorage()
+		logger.Info("Using memory storage", nil)
+	}
+
+	// Shares use their own backend so they can live in Redis next to the cache
+	shareStorageType := cfg.Storage.ShareType
+	if shareStorageType == "" {
+		shareStorageType = cfg.Storage.Type
+	}
+	var shareStorage interfaces.ShareStorage
+	switch shareStorageType {
+	case "redis":
+		redisShares, err := redisstorage.NewShareStorage(cfg.Cache.Redis)
+		if err != nil {
+			logger.Error("Failed to create Redis share storage, falling back to memory", map[string]interface{}{
+				"error": err.Error(),
+			})
+			shareStorage = memstorage.NewShareStorage()
+		} else {
+			defer redisShares.Close()
+			shareStorage = redisShares
+			logger.Info("Using Redis share storage", map[string]interface{}{
+				"address": cfg.Cache.Redis.Address,
+			})
+		}
+	case "sqlite":
+		shareStorage = sqlitestorage.NewShareStorage(openSQLiteStore())
+	default:
+		shareStorage = memstorage.NewShareStorage()
+	}
+
+	// Background enrichment jobs survive restarts in SQLite or Redis; Redis
+	// lets several instances share the queue
+	queueType := cfg.Queue.Type
+	if queueType == "" {
+		queueType = cfg.Storage.Type
+	}
+	var queueStorage interfaces.JobQueueStorage
+	switch queueType {
+	case "redis":
+		redisQueue, err := redisstorage.NewJobQueueStorage(cfg.Cache.Redis)
+		if err != nil {
+			logger.Error("Failed to create Redis job queue, falling back to memory", map[string]interface{}{
+				"error": err.Error(),
+			})
+			queueStorage = memstorage.NewJobQueueStorage()
+		} else {
+			defer redisQueue.Close()
+			queueStorage = redisQueue
+			logger.Info("Using Redis job queue", map[string]interface{}{
+				"address": cfg.Cache.Redis.Address,
+			})
+		}
+	case "sqlite":
+		queueStorage = sqlitestorage.NewJobQueueStorage(openSQLiteStore())
+	default:
+		queueStorage = memstorage.NewJobQueueStorage()
+	}
+
+	if sqliteStore != nil {
+		defer sqliteStore.Close()
+	}
+
 	// Create HTTP client
 	httpClient := stdhttp.NewStandardHTTPClient(30 * time.Second)
 
@@ -92,17 +192,98 @@ func main() {
 		Logger:     logger,
 	}
 
-	// Create services
-	feedService := feed.NewFeedService(deps)
-	searchService := search.NewSearchService(deps)
-	readerService := reader.NewService(cache, logger)
-	
+	// Services that fetch URLs supplied by clients only reach public addresses
+	clientDeps := deps
+	clientDeps.HTTPClient = stdhttp.NewGuardedHTTPClient(30 * time.Second)
+
+	// Per-site extraction rules, reloaded on SIGHUP and when the files change
+	siteRules := siterules.NewDirRegistry(cfg.SiteRules.Dir, logger)
+	if cfg.SiteRules.Dir != "" {
+		if err := siteRules.Reload(); err != nil {
+			logger.Error("Failed to load site rules", map[string]interface{}{
+				"dir":   cfg.SiteRules.Dir,
+				"error": err.Error(),
+			})
+		} else {
+			logger.Info("Loaded site rules", map[string]interface{}{
+				"dir":   cfg.SiteRules.Dir,
+				"rules": siteRules.Len(),
+			})
+		}
+	}
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	go siteRules.Watch(watchCtx, time.Duration(cfg.SiteRules.ReloadSeconds)*time.Second)
+
 	// Create unified enrichment service with configurable cache TTL
 	colorCacheTTL := time.Duration(cfg.Cache.ColorCacheDays) * 24 * time.Hour
 	enrichmentService := services.NewContentEnrichmentService(deps, colorCacheTTL)
-	
-	// Note: Share service would need a storage implementation
-	_ = searchService // Will be used when we add search handlers
+	enrichmentService.SetSiteRules(siteRules)
+
+	// Page and image URLs come from clients and feeds, so they are only
+	// fetched from public addresses
+	enrichmentService.SetPageTransport(stdhttp.NewGuardedTransport())
+	enrichmentService.SetImageHTTPClient(stdhttp.NewGuardedHTTPClient(10 * time.Second))
+	imageProxy := services.NewImageProxyService(deps)
+	imageProxy.SetHTTPClient(stdhttp.NewGuardedHTTPClient(30 * time.Second))
+	if cfg.Cache.ImageCacheDays > 0 {
+		imageProxy.SetCacheTTL(time.Duration(cfg.Cache.ImageCacheDays) * 24 * time.Hour)
+	}
+
+	// Create services
+	feedService := feed.NewFeedService(deps)
+	searchService := search.NewSearchService(deps, buildSearchProviders(cfg.Search, httpClient, logger)...)
+	readerService := reader.NewService(clientDeps)
+	readerService.SetMaxPages(cfg.Reader.MaxPages)
+	readerService.SetSiteRules(siteRules)
+	readerService.SetFeedService(feedService)
+	shareService := share.NewShareService(shareStorage)
+	epubService := epub.NewService(clientDeps, readerService)
+	summarizer := summarize.NewService(deps)
+	summarizer.SetDefaultSentences(cfg.Summary.Sentences)
+	tagger := autotag.NewService(deps)
+	tagger.SetDefaultTags(cfg.AutoTag.MaxTags)
+	tagger.SetCorpusSize(cfg.AutoTag.CorpusSize)
+	clusterer := cluster.NewService(deps)
+	clusterer.SetMaxDistance(cfg.Cluster.MaxDistance)
+
+	// Thumbnail colors, and metadata and reader views that failed to fetch,
+	// are computed in the background from the job queue
+	jobWorker := workers.NewEnrichmentWorker(queueStorage, logger, workers.WorkerConfig{
+		Concurrency: map[string]int{
+			domain.JobTypeColor:    cfg.Queue.ColorWorkers,
+			domain.JobTypeMetadata: cfg.Queue.MetadataWorkers,
+			domain.JobTypeReader:   cfg.Queue.ReaderWorkers,
+		},
+		MaxAttempts: cfg.Queue.MaxAttempts,
+	})
+	jobWorker.Handle(domain.JobTypeColor, enrichmentService.RefreshThumbnail)
+	jobWorker.Handle(domain.JobTypeMetadata, enrichmentService.RefreshMetadata)
+	jobWorker.Handle(domain.JobTypeReader, readerService.RefreshReaderView)
+	enrichmentService.SetJobQueue(jobWorker)
+	readerService.SetJobQueue(jobWorker)
+	enrichmentJobs := services.NewEnrichmentJobService(deps, enrichmentService, jobWorker)
+	if err := jobWorker.Start(); err != nil {
+		log.Fatalf("Failed to start enrichment worker: %v", err)
+	}
+
+	// Every parsed feed is indexed for local full-text search
+	searchIndex := search.NewIndex(search.DefaultMaxIndexedItems)
+	feedService.SetIndexer(searchIndex)
+
+	// Items and feeds without a declared language get a detected one
+	feedService.SetLanguageDetector(language.NewService(deps))
+
+	// Register non-RSS feed sources (e.g. "sitemap+https://example.com/sitemap.xml")
+	feedService.RegisterSource(sources.NewSitemapSource(deps, enrichmentService))
+
+	// User-defined scrapers are parsed as "scraper://<id>"
+	scraperSource := sources.NewScraperSource(clientDeps, scraperStorage)
+	feedService.RegisterSource(scraperSource)
+
+	// User-defined JSON API mappings are parsed as "jsonapi://<id>"
+	jsonAPISource := sources.NewJSONAPISource(clientDeps, mappingStorage)
+	feedService.RegisterSource(jsonAPISource)
 
 	// Create API with middleware
 	apiConfig := api.APIConfig{
@@ -114,19 +295,61 @@ func main() {
 
 	// Create and register handlers
 	feedHandler := handlers.NewFeedHandler(feedService, enrichmentService)
+	feedHandler.SetSummarizer(summarizer)
+	feedHandler.SetTagger(tagger)
+	feedHandler.SetClusterer(clusterer)
+	feedHandler.SetEnrichmentJobs(enrichmentJobs)
+	feedHandler.SetLogger(logger)
 	feedHandler.RegisterRoutes(humaAPI)
 	
-	discoverHandler := handlers.NewDiscoverHandler(httpClient)
+	discoverHandler := handlers.NewDiscoverHandler(httpClient, feedService)
 	discoverHandler.RegisterRoutes(humaAPI)
 	
-	metadataHandler := handlers.NewMetadataHandler()
+	metadataHandler := handlers.NewMetadataHandler(enrichmentService)
 	metadataHandler.RegisterRoutes(humaAPI)
+
+	colorsHandler := handlers.NewColorsHandler(enrichmentService)
+	colorsHandler.RegisterRoutes(humaAPI)
+
+	imageHandler := handlers.NewImageHandler(imageProxy)
+	imageHandler.RegisterRoutes(humaAPI)
+
+	enrichmentHandler := handlers.NewEnrichmentHandler(enrichmentJobs)
+	enrichmentHandler.RegisterRoutes(humaAPI)
+
+	// Queue stats list the URLs users enriched, so they are opt-in and
+	// need the admin token
+	if cfg.Queue.AdminToken != "" {
+		queueHandler := handlers.NewQueueHandler(jobWorker, cfg.Queue.AdminToken)
+		queueHandler.RegisterRoutes(humaAPI)
+	}
 	
 	validateHandler := handlers.NewValidateHandler(httpClient)
 	validateHandler.RegisterRoutes(humaAPI)
 	
 	readerHandler := handlers.NewReaderHandler(readerService)
+	readerHandler.SetSummarizer(summarizer)
 	readerHandler.RegisterRoutes(humaAPI)
+	
+	scraperHandler := handlers.NewScraperHandler(scraperSource)
+	scraperHandler.RegisterRoutes(humaAPI)
+	
+	jsonMappingHandler := handlers.NewJSONMappingHandler(jsonAPISource)
+	jsonMappingHandler.RegisterRoutes(humaAPI)
+	
+	shareHandler := handlers.NewShareHandler(shareService, feedService, enrichmentService)
+	shareHandler.RegisterRoutes(humaAPI)
+	
+	exportHandler := handlers.NewExportHandler(epubService, shareService, feedService)
+	exportHandler.RegisterRoutes(humaAPI)
+	
+	publishHandler := handlers.NewPublishHandler(feedService, shareService)
+	publishHandler.SetTagger(tagger)
+	publishHandler.SetClusterer(clusterer)
+	publishHandler.RegisterRoutes(humaAPI)
+	
+	searchHandler := handlers.NewSearchHandler(searchIndex, searchService)
+	searchHandler.RegisterRoutes(humaAPI)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -150,6 +373,23 @@ func main() {
 		}
 	}()
 
+	// SIGHUP reloads the site rules without a restart
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := siteRules.Reload(); err != nil {
+				logger.Error("Failed to reload site rules", map[string]interface{}{
+					"error": err.Error(),
+				})
+				continue
+			}
+			logger.Info("Reloaded site rules", map[string]interface{}{
+				"rules": siteRules.Len(),
+			})
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -168,9 +408,51 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Running jobs finish; queued ones are picked up after a restart
+	_ = jobWorker.Stop()
+
 	logger.Info("Server stopped", nil)
 }
 
+// buildSearchProviders creates the feed directory providers enabled in config.
+// Podcast Index needs an API key unless a custom (stand-in) base URL is set,
+// and the curated directory needs a file.
+func buildSearchProviders(cfg config.SearchConfig, httpClient interfaces.HTTPClient, logger interfaces.Logger) []interfaces.SearchProvider {
+	var providers []interfaces.SearchProvider
+
+	for _, name := range cfg.Providers {
+		switch name {
+		case "itunes":
+			providers = append(providers, search.NewITunesProvider(httpClient, cfg.ITunesBaseURL))
+		case "podcastindex":
+			if cfg.PodcastIndex.APIKey == "" && cfg.PodcastIndex.BaseURL == search.DefaultPodcastIndexBaseURL {
+				logger.Info("Podcast Index search disabled: PODCASTINDEX_API_KEY not set", nil)
+				continue
+			}
+			providers = append(providers, search.NewPodcastIndexProvider(httpClient, cfg.PodcastIndex.BaseURL, cfg.PodcastIndex.APIKey, cfg.PodcastIndex.APISecret))
+		case "directory":
+			if cfg.DirectoryPath == "" {
+				continue
+			}
+			directory, err := search.NewDirectoryProvider(cfg.DirectoryPath)
+			if err != nil {
+				logger.Error("Failed to load search directory", map[string]interface{}{
+					"path":  cfg.DirectoryPath,
+					"error": err.Error(),
+				})
+				continue
+			}
+			logger.Info("Loaded search directory", map[string]interface{}{
+				"path":  cfg.DirectoryPath,
+				"feeds": directory.Len(),
+			})
+			providers = append(providers, directory)
+		}
+	}
+
+	return providers
+}
+
 func init() {
 	// Print banner
 	fmt.Println(`
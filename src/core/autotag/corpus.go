@@ -0,0 +1,89 @@
+// ABOUTME: Rolling corpus of recently tagged items for inverse document frequencies
+// ABOUTME: Forgets the oldest items once full, so tags follow what feeds write about now
+
+package autotag
+
+import "sync"
+
+// DefaultCorpusSize is how many items the corpus remembers by default
+const DefaultCorpusSize = 5000
+
+// Corpus counts how many recent items use each term. It is safe for
+// concurrent use.
+type Corpus struct {
+	mu      sync.Mutex
+	maxDocs int
+	docs    map[string][]string
+	order   []string
+	df      map[string]int
+}
+
+// NewCorpus creates a corpus remembering up to maxDocs items
+func NewCorpus(maxDocs int) *Corpus {
+	if maxDocs <= 0 {
+		maxDocs = DefaultCorpusSize
+	}
+	return &Corpus{
+		maxDocs: maxDocs,
+		docs:    make(map[string][]string),
+		df:      make(map[string]int),
+	}
+}
+
+// Add records the distinct terms of an item. Adding an item again replaces
+// its terms, so re-parsing a feed does not skew the counts.
+func (c *Corpus) Add(key string, terms []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.docs[key]; ok {
+		c.removeTermsLocked(old)
+	} else {
+		c.order = append(c.order, key)
+	}
+	c.docs[key] = terms
+	for _, term := range terms {
+		c.df[term]++
+	}
+
+	for len(c.docs) > c.maxDocs {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.removeTermsLocked(c.docs[oldest])
+		delete(c.docs, oldest)
+	}
+
+	// Reslicing never frees the front of the queue, so copy it now and then
+	if cap(c.order) > 2*c.maxDocs {
+		c.order = append([]string(nil), c.order...)
+	}
+}
+
+// Len returns the number of items in the corpus
+func (c *Corpus) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.docs)
+}
+
+// frequencies returns how many items use each term, and the corpus size
+func (c *Corpus) frequencies(terms []string) (map[string]int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	df := make(map[string]int, len(terms))
+	for _, term := range terms {
+		df[term] = c.df[term]
+	}
+	return df, len(c.docs)
+}
+
+func (c *Corpus) removeTermsLocked(terms []string) {
+	for _, term := range terms {
+		if c.df[term] <= 1 {
+			delete(c.df, term)
+		} else {
+			c.df[term]--
+		}
+	}
+}
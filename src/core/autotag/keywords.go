@@ -0,0 +1,424 @@
+// ABOUTME: Keyword and key phrase extraction for auto-tagging feed items
+// ABOUTME: Scores words and capitalized name-like phrases by TF-IDF against the corpus
+
+package autotag
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"digests-app-api/core/summarize"
+)
+
+const (
+	// DefaultTags is how many tags an item gets by default
+	DefaultTags = 5
+
+	// MaxTags is the most tags an item can get
+	MaxTags = 10
+
+	// titleWeight is how many body mentions a mention in the title counts as
+	titleWeight = 2
+
+	// minMentions is how often a candidate must be mentioned to be a tag,
+	// with at least one mention outside the title
+	minMentions = 2
+
+	// phraseBoost favours names and multi-word phrases over single words
+	phraseBoost = 1.5
+
+	// commonCorpusSize is the corpus size from which terms used by more than
+	// half of all items are too common to be tags
+	commonCorpusSize = 20
+
+	// maxPhraseWords is the longest key phrase
+	maxPhraseWords = 4
+)
+
+// Document holds the tag candidates of a single item
+type Document struct {
+	candidates map[string]*candidate
+}
+
+// candidate is a keyword or key phrase found in a document
+type candidate struct {
+	// weight counts mentions, with title mentions counting titleWeight times
+	weight int
+
+	// mentions and titled count all mentions and those in the title
+	mentions int
+	titled   int
+
+	// words is the number of words in the phrase
+	words int
+
+	// named is set for names: capitalized phrases and acronyms
+	named bool
+
+	// forms counts the capitalized spellings, to show the most common one
+	forms map[string]int
+
+	// lowercase counts mentions written in lower case
+	lowercase int
+}
+
+// word is a word of a sentence with its original spelling
+type word struct {
+	text  string
+	lower string
+}
+
+// Extract finds the keywords and capitalized, name-like key phrases of an
+// item. text may be HTML.
+func Extract(title, text string) *Document {
+	doc := &Document{candidates: make(map[string]*candidate)}
+
+	doc.addSentence(strings.Join(strings.Fields(title), " "), titleWeight)
+	for _, sentence := range summarize.Sentences(summarize.PlainText(text)) {
+		doc.addSentence(sentence, 1)
+	}
+
+	return doc
+}
+
+// Terms returns the distinct terms of the document, for the corpus
+func (d *Document) Terms() []string {
+	terms := make([]string, 0, len(d.candidates))
+	for key := range d.candidates {
+		terms = append(terms, key)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// Tags returns up to max tags ranked by TF-IDF against the corpus, leaving
+// out the given existing categories. The document should already be in
+// the corpus.
+func (d *Document) Tags(corpus *Corpus, max int, exclude []string) []string {
+	if max <= 0 {
+		max = DefaultTags
+	}
+	if max > MaxTags {
+		max = MaxTags
+	}
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, category := range exclude {
+		excluded[strings.ToLower(strings.TrimSpace(category))] = true
+	}
+
+	df, n := corpus.frequencies(d.Terms())
+
+	type scored struct {
+		key   string
+		score float64
+	}
+	var ranked []scored
+	for key, c := range d.candidates {
+		if c.mentions < minMentions || c.mentions == c.titled || excluded[key] {
+			continue
+		}
+		if n >= commonCorpusSize && df[key]*2 > n {
+			continue
+		}
+
+		idf := math.Log(float64(n+1)/float64(df[key]+1)) + 1
+		score := (1 + math.Log(float64(c.weight))) * idf
+		if c.named || c.words > 1 {
+			score *= phraseBoost
+		}
+		ranked = append(ranked, scored{key: key, score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].key < ranked[j].key
+	})
+
+	// A word is left out when a better ranked phrase already contains it,
+	// and a phrase when it contains a better ranked one
+	var tags, chosen []string
+	for _, r := range ranked {
+		if len(tags) == max {
+			break
+		}
+		overlaps := false
+		for _, key := range chosen {
+			if containsWords(key, r.key) || containsWords(r.key, key) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		chosen = append(chosen, r.key)
+		tags = append(tags, d.candidates[r.key].display(r.key))
+	}
+
+	return tags
+}
+
+// addSentence adds the keywords and key phrases of a sentence
+func (d *Document) addSentence(sentence string, weight int) {
+	words := splitWords(sentence)
+	if len(words) == 0 {
+		return
+	}
+
+	// In headline-style capitalization every word looks like a name
+	capitalized := 0
+	for _, w := range words {
+		if isCapitalized(w.text) {
+			capitalized++
+		}
+	}
+	headline := len(words) > 3 && capitalized*5 > len(words)*3
+
+	for _, w := range words {
+		if !isKeyword(w.lower) && !isAcronym(w.text) {
+			continue
+		}
+		c := d.add(w.lower, 1, weight)
+		switch {
+		case !isCapitalized(w.text):
+			c.lowercase++
+		case !headline:
+			c.forms[w.text]++
+		}
+	}
+	if headline {
+		return
+	}
+
+	for _, phrase := range namedPhrases(words) {
+		if len(phrase) == 1 {
+			// Single names were counted as keywords above
+			d.candidates[phrase[0].lower].named = true
+			continue
+		}
+		forms := make([]string, len(phrase))
+		keys := make([]string, len(phrase))
+		for i, w := range phrase {
+			forms[i] = w.text
+			keys[i] = w.lower
+		}
+		c := d.add(strings.Join(keys, " "), len(phrase), weight)
+		c.named = true
+		c.forms[strings.Join(forms, " ")]++
+	}
+}
+
+func (d *Document) add(key string, words, weight int) *candidate {
+	c, ok := d.candidates[key]
+	if !ok {
+		c = &candidate{words: words, forms: make(map[string]int)}
+		d.candidates[key] = c
+	}
+	c.weight += weight
+	c.mentions++
+	if weight == titleWeight {
+		c.titled++
+	}
+	return c
+}
+
+// display returns the most common capitalized spelling of names and of
+// words never written in lower case, such as product names opening a
+// sentence, and the lower-cased keyword otherwise
+func (c *candidate) display(key string) string {
+	if !c.named && (c.lowercase > 0 || len(c.forms) == 0) {
+		return key
+	}
+	best, bestCount := key, 0
+	for form, count := range c.forms {
+		if count > bestCount || (count == bestCount && form < best) {
+			best, bestCount = form, count
+		}
+	}
+	return best
+}
+
+// namedPhrases returns the runs of capitalized words in a sentence, such as
+// "European Central Bank" or "Bank of England". A single capitalized word
+// opening the sentence is not taken as a name, as every sentence starts
+// that way; acronyms are. For the same reason a connector right after the
+// opening word ends the run, so "Shares of Apple" yields "Apple".
+func namedPhrases(words []word) [][]word {
+	var phrases [][]word
+
+	flush := func(run []word, start int) {
+		// Drop leading articles ("The Guardian") and trailing connectors
+		for len(run) > 0 && stopWords[run[0].lower] && !isAcronym(run[0].text) {
+			run = run[1:]
+			start++
+		}
+		for len(run) > 0 && phraseConnectors[run[len(run)-1].lower] {
+			run = run[:len(run)-1]
+		}
+		if len(run) == 0 || len(run) > maxPhraseWords {
+			return
+		}
+		if len(run) == 1 && !isAcronym(run[0].text) && (start == 0 || !isKeyword(run[0].lower)) {
+			return
+		}
+		phrases = append(phrases, run)
+	}
+
+	start := -1
+	for i, w := range words {
+		switch {
+		case isCapitalized(w.text):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0 && !(start == 0 && i == 1) && phraseConnectors[w.lower] && i+1 < len(words) && isCapitalized(words[i+1].text):
+			// "of" in "Bank of England" continues the phrase
+		default:
+			if start >= 0 {
+				flush(words[start:i], start)
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		flush(words[start:], start)
+	}
+
+	return phrases
+}
+
+// containsWords reports whether phrase contains all the words of part in order
+func containsWords(phrase, part string) bool {
+	return strings.Contains(" "+phrase+" ", " "+part+" ")
+}
+
+// splitWords splits a sentence into words. Apostrophes and hyphens inside
+// words are kept ("open-source", "COVID-19"); possessive endings are not.
+// Words in scripts without spaces between words are skipped.
+func splitWords(sentence string) []word {
+	var words []word
+
+	add := func(text string) {
+		text = strings.Trim(text, "'’-")
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "'s"), "’s")
+		if text == "" || hasCJK(text) {
+			return
+		}
+		words = append(words, word{text: text, lower: strings.ToLower(text)})
+	}
+
+	start := -1
+	for i, r := range sentence {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == '-'
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			add(sentence[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		add(sentence[start:])
+	}
+
+	return words
+}
+
+// isKeyword reports whether a lower-cased word can be a tag on its own
+func isKeyword(lower string) bool {
+	if utf8.RuneCountInString(lower) < 3 || stopWords[lower] {
+		return false
+	}
+	for _, r := range lower {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCapitalized reports whether a word starts with an upper-case letter
+func isCapitalized(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsUpper(r)
+}
+
+// isAcronym reports whether a word is an acronym such as "NASA" or "GPT-4"
+func isAcronym(text string) bool {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+func hasCJK(text string) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			return true
+		}
+	}
+	return false
+}
+
+// phraseConnectors may join the capitalized words of a name
+var phraseConnectors = map[string]bool{
+	"of": true, "for": true, "de": true, "van": true, "von": true, "&": true,
+}
+
+// stopWords are common English words that say nothing about an item's
+// topic, including words too generic to tag anything with
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "again": true, "against": true, "all": true,
+	"almost": true, "also": true, "although": true, "always": true, "am": true, "among": true,
+	"an": true, "and": true, "another": true, "any": true, "anyone": true, "anything": true,
+	"are": true, "around": true, "as": true, "at": true, "back": true, "be": true,
+	"became": true, "because": true, "become": true, "been": true, "before": true,
+	"being": true, "best": true, "better": true, "between": true, "big": true, "both": true,
+	"but": true, "by": true, "came": true, "can": true, "can't": true, "come": true,
+	"could": true, "day": true, "days": true, "did": true, "didn't": true, "do": true,
+	"does": true, "doesn't": true, "doing": true, "don't": true, "done": true, "down": true,
+	"during": true, "each": true, "early": true, "either": true, "else": true, "enough": true,
+	"even": true, "ever": true, "every": true, "first": true, "few": true, "find": true,
+	"for": true, "found": true, "from": true, "further": true, "get": true, "gets": true,
+	"getting": true, "give": true, "go": true, "goes": true, "going": true, "good": true,
+	"got": true, "great": true, "had": true, "has": true, "have": true, "having": true,
+	"he": true, "her": true, "here": true, "hers": true, "him": true, "his": true,
+	"how": true, "however": true, "i": true, "i'm": true, "if": true, "in": true,
+	"into": true, "is": true, "isn't": true, "it": true, "it's": true, "its": true,
+	"just": true, "know": true, "last": true, "later": true, "least": true, "less": true,
+	"let": true, "like": true, "little": true, "long": true, "look": true, "lot": true,
+	"made": true, "make": true, "makes": true, "making": true, "many": true, "may": true,
+	"me": true, "might": true, "more": true, "most": true, "much": true, "must": true,
+	"my": true, "need": true, "never": true, "new": true, "next": true, "no": true,
+	"nor": true, "not": true, "nothing": true, "now": true, "of": true, "off": true,
+	"often": true, "old": true, "on": true, "once": true, "one": true, "only": true,
+	"or": true, "other": true, "others": true, "our": true, "out": true, "over": true,
+	"own": true, "part": true, "people": true, "per": true, "put": true, "rather": true,
+	"read": true, "really": true, "right": true, "said": true, "same": true, "say": true,
+	"says": true, "see": true, "seen": true, "set": true, "several": true, "she": true,
+	"should": true, "show": true, "since": true, "so": true, "some": true, "something": true,
+	"still": true, "such": true, "take": true, "than": true, "that": true, "that's": true,
+	"the": true, "their": true, "them": true, "then": true, "there": true, "there's": true,
+	"these": true, "they": true, "thing": true, "things": true, "think": true, "this": true,
+	"those": true, "though": true, "three": true, "through": true, "time": true,
+	"times": true, "to": true, "today": true, "too": true, "two": true, "under": true,
+	"until": true, "up": true, "upon": true, "us": true, "use": true, "used": true,
+	"using": true, "very": true, "want": true, "was": true, "way": true, "ways": true,
+	"we": true, "week": true, "well": true, "went": true, "were": true, "what": true,
+	"when": true, "where": true, "whether": true, "which": true, "while": true, "who": true,
+	"whom": true, "why": true, "will": true, "with": true, "within": true, "without": true,
+	"won't": true, "work": true, "would": true, "year": true, "years": true, "yet": true,
+	"you": true, "your": true,
+}
@@ -0,0 +1,116 @@
+package autotag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const rateArticle = `<p>The European Central Bank raised interest rates again on Thursday, its tenth increase in a row.</p>
+<p>Christine Lagarde said inflation in the euro area was still too high. Analysts had expected the European Central Bank to hold rates steady.</p>
+<p>Higher interest rates make mortgages more expensive for households. Banks in Germany and Italy reported falling demand for mortgages.</p>
+<p>The ECB will publish new inflation forecasts in December.</p>`
+
+func TestTags_NamesAndKeywords(t *testing.T) {
+	corpus := NewCorpus(10)
+	doc := Extract("ECB raises interest rates to record high", rateArticle)
+	corpus.Add("rates", doc.Terms())
+
+	tags := doc.Tags(corpus, 6, nil)
+	for _, want := range []string{"ECB", "European Central Bank", "rates", "inflation"} {
+		found := false
+		for _, tag := range tags {
+			found = found || tag == want
+		}
+		if !found {
+			t.Errorf("expected tag %q in %q", want, tags)
+		}
+	}
+
+	// Words of a chosen phrase are not tags of their own
+	for _, tag := range tags {
+		if tag == "european" || tag == "bank" || tag == "central" {
+			t.Errorf("unexpected tag %q, already part of a phrase: %q", tag, tags)
+		}
+	}
+}
+
+func TestTags_CorpusLowersCommonTerms(t *testing.T) {
+	corpus := NewCorpus(100)
+	// Every item in this corpus talks about inflation
+	for i := 0; i < 30; i++ {
+		other := Extract("", strings.Repeat("Inflation keeps rising while wages lag behind inflation. ", 2))
+		corpus.Add(string(rune('a'+i)), other.Terms())
+	}
+
+	doc := Extract("ECB raises interest rates to record high", rateArticle)
+	corpus.Add("rates", doc.Terms())
+
+	for _, tag := range doc.Tags(corpus, 10, nil) {
+		if tag == "inflation" {
+			t.Errorf("a term used by most of the corpus should not be a tag")
+		}
+	}
+}
+
+func TestTags_SkipsCategoriesAndHeadlines(t *testing.T) {
+	corpus := NewCorpus(10)
+	doc := Extract("How We Rebuilt Our Search Engine In Rust",
+		"We rewrote the search engine in Rust. The Rust version answers queries twice as fast as the old search engine.")
+	corpus.Add("search", doc.Terms())
+
+	tags := doc.Tags(corpus, 10, []string{"Rust"})
+	for _, tag := range tags {
+		if strings.EqualFold(tag, "rust") {
+			t.Errorf("existing category repeated as a tag: %q", tags)
+		}
+		if tag == "Search Engine" || tag == "Rebuilt" {
+			t.Errorf("headline capitalization taken for a name: %q", tags)
+		}
+	}
+	if !reflect.DeepEqual(tags[:2], []string{"engine", "search"}) {
+		t.Errorf("expected the repeated keywords first, got %q", tags)
+	}
+}
+
+func TestNamedPhrases(t *testing.T) {
+	tests := []struct {
+		sentence string
+		want     []string
+	}{
+		{"The Bank of England kept rates unchanged", []string{"Bank of England"}},
+		{"Shares of Apple fell after the report", []string{"Apple"}},
+		{"Researchers at NASA and the ESA agreed", []string{"NASA", "ESA"}},
+		{"Yesterday it rained", nil},
+		{"Officials in the US said", []string{"US"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, phrase := range namedPhrases(splitWords(tt.sentence)) {
+			var words []string
+			for _, w := range phrase {
+				words = append(words, w.text)
+			}
+			got = append(got, strings.Join(words, " "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("namedPhrases(%q) = %q, want %q", tt.sentence, got, tt.want)
+		}
+	}
+}
+
+func TestCorpus_ReplacesAndEvicts(t *testing.T) {
+	corpus := NewCorpus(2)
+	corpus.Add("a", []string{"go", "rust"})
+	corpus.Add("a", []string{"go"})
+	if df, n := corpus.frequencies([]string{"go", "rust"}); n != 1 || df["go"] != 1 || df["rust"] != 0 {
+		t.Errorf("re-adding an item should replace its terms, got %v of %d", df, n)
+	}
+
+	corpus.Add("b", []string{"go"})
+	corpus.Add("c", []string{"zig"})
+	if df, n := corpus.frequencies([]string{"go", "zig"}); n != 2 || df["go"] != 1 || df["zig"] != 1 {
+		t.Errorf("the oldest item should be forgotten, got %v of %d", df, n)
+	}
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Service layer that auto-tags feed items with keywords and key phrases
+// ABOUTME: Learns term frequencies from every tagged item, without leaving the process
+
+package autotag
+
+import (
+	"context"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// Service tags feed items against a corpus of recently tagged items
+type Service struct {
+	deps   interfaces.Dependencies
+	corpus *Corpus
+	tags   int
+}
+
+// NewService creates an auto-tagging service
+func NewService(deps interfaces.Dependencies) *Service {
+	return &Service{
+		deps:   deps,
+		corpus: NewCorpus(DefaultCorpusSize),
+		tags:   DefaultTags,
+	}
+}
+
+// SetCorpusSize sets how many recent items term frequencies are taken
+// from. It starts a new, empty corpus.
+func (s *Service) SetCorpusSize(size int) {
+	s.corpus = NewCorpus(size)
+}
+
+// SetDefaultTags sets how many tags items get when a request asks for none
+func (s *Service) SetDefaultTags(tags int) {
+	if tags < 1 || tags > MaxTags {
+		tags = DefaultTags
+	}
+	s.tags = tags
+}
+
+// TagItems sets the auto-tags of feed items; 0 tags uses the default count.
+// The items are added to the corpus first, so words common to the whole
+// batch rank lower too. Tags repeating an item's categories are left out.
+func (s *Service) TagItems(ctx context.Context, items []*domain.FeedItem, tags int) {
+	if tags <= 0 {
+		tags = s.tags
+	}
+
+	docs := make([]*Document, len(items))
+	for i, item := range items {
+		text := item.ContentEncoded
+		if text == "" {
+			text = item.Content
+		}
+		if text == "" {
+			text = item.Description
+		}
+		docs[i] = Extract(item.Title, text)
+		s.corpus.Add(itemKey(item), docs[i].Terms())
+	}
+
+	for i, item := range items {
+		item.AutoTags = docs[i].Tags(s.corpus, tags, item.Categories)
+	}
+
+	s.deps.Logger.Debug("Auto-tagged items", map[string]interface{}{
+		"items":  len(items),
+		"corpus": s.corpus.Len(),
+	})
+}
+
+// itemKey identifies an item in the corpus, so re-parsed items replace
+// their earlier terms
+func itemKey(item *domain.FeedItem) string {
+	switch {
+	case item.Link != "":
+		return item.Link
+	case item.ID != "":
+		return item.ID
+	default:
+		return item.Title
+	}
+}
@@ -0,0 +1,37 @@
+package autotag
+
+import (
+	"context"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+func TestService_TagItems(t *testing.T) {
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+	service.SetDefaultTags(3)
+
+	items := []*domain.FeedItem{
+		{Link: "https://example.com/rates", Title: "ECB raises interest rates to record high", ContentEncoded: rateArticle},
+		{Link: "https://example.com/empty", Title: "Untitled"},
+		{ID: "3", Description: "Kubernetes operators manage stateful services. Kubernetes schedules the operators.", Categories: []string{"kubernetes"}},
+	}
+	service.TagItems(context.Background(), items, 0)
+
+	if len(items[0].AutoTags) != 3 {
+		t.Errorf("expected the default 3 tags, got %q", items[0].AutoTags)
+	}
+	if len(items[1].AutoTags) != 0 {
+		t.Errorf("expected no tags for an item without text, got %q", items[1].AutoTags)
+	}
+	if len(items[2].AutoTags) == 0 || items[2].AutoTags[0] != "operators" {
+		t.Errorf("expected the description to be tagged without the category, got %q", items[2].AutoTags)
+	}
+
+	// Tagging the same items again does not grow the corpus
+	service.TagItems(context.Background(), items, 1)
+	if service.corpus.Len() != 3 || len(items[0].AutoTags) != 1 {
+		t.Errorf("corpus has %d items, tags %q", service.corpus.Len(), items[0].AutoTags)
+	}
+}
@@ -0,0 +1,207 @@
+// ABOUTME: Service layer that clusters near-duplicate items of feeds into stories
+// ABOUTME: Finds candidate pairs by SimHash bands instead of comparing every pair
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+const (
+	// DefaultMaxDistance is how many bits the fingerprints of items in the
+	// same story may differ by. Unrelated items differ by about 32 bits.
+	DefaultMaxDistance = 6
+
+	// MaxDistance is the largest allowed distance; beyond it unrelated
+	// items start to be grouped
+	MaxDistance = 12
+)
+
+// Service clusters feed items by fingerprint
+type Service struct {
+	deps        interfaces.Dependencies
+	maxDistance int
+}
+
+// NewService creates a story clustering service
+func NewService(deps interfaces.Dependencies) *Service {
+	return &Service{
+		deps:        deps,
+		maxDistance: DefaultMaxDistance,
+	}
+}
+
+// SetMaxDistance sets how many bits fingerprints in a story may differ by
+func (s *Service) SetMaxDistance(distance int) {
+	if distance < 1 || distance > MaxDistance {
+		distance = DefaultMaxDistance
+	}
+	s.maxDistance = distance
+}
+
+// member is a fingerprinted item
+type member struct {
+	feedURL     string
+	item        *domain.FeedItem
+	fingerprint uint64
+	length      int
+}
+
+// ClusterFeeds groups the near-identical items of the feeds into stories.
+// Items in a story with at least one other item get its ClusterID; the
+// stories are returned newest first.
+func (s *Service) ClusterFeeds(ctx context.Context, feeds []*domain.Feed) []domain.StoryCluster {
+	var members []member
+	for _, feed := range feeds {
+		if feed == nil {
+			continue
+		}
+		for i := range feed.Items {
+			item := &feed.Items[i]
+			item.ClusterID = ""
+			text := item.ContentEncoded
+			if text == "" {
+				text = item.Content
+			}
+			if text == "" {
+				text = item.Description
+			}
+			if fingerprint, ok := Fingerprint(item.Title, text); ok {
+				members = append(members, member{feedURL: feed.URL, item: item, fingerprint: fingerprint, length: len(text)})
+			}
+		}
+	}
+
+	var clusters []domain.StoryCluster
+	for _, group := range group(members, s.maxDistance) {
+		if len(group) < 2 {
+			continue
+		}
+		clusters = append(clusters, newCluster(members, group))
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return latest(clusters[i]).After(latest(clusters[j]))
+	})
+
+	s.deps.Logger.Debug("Clustered items into stories", map[string]interface{}{
+		"items":    len(members),
+		"clusters": len(clusters),
+	})
+
+	return clusters
+}
+
+// group returns the indexes of members in the same story. Fingerprints
+// are split into maxDistance+1 bands: two fingerprints that differ in at
+// most maxDistance bits share at least one band, so only members sharing
+// a band are compared.
+func group(members []member, maxDistance int) [][]int {
+	parent := make([]int, len(members))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bands := maxDistance + 1
+	for band := 0; band < bands; band++ {
+		from, to := band*64/bands, (band+1)*64/bands
+		mask := (uint64(1)<<uint(to-from) - 1) << uint(from)
+
+		buckets := make(map[uint64][]int)
+		for i, m := range members {
+			key := m.fingerprint & mask
+			for _, j := range buckets[key] {
+				if find(i) != find(j) && Distance(m.fingerprint, members[j].fingerprint) <= maxDistance {
+					parent[find(i)] = find(j)
+				}
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range members {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	result := make([][]int, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, groups[root])
+	}
+	return result
+}
+
+// newCluster builds a story from a group of members. The representative is
+// the earliest dated item, as it is most likely the original report; among
+// items published at the same time the one with the most text is preferred.
+func newCluster(members []member, group []int) domain.StoryCluster {
+	sort.SliceStable(group, func(a, b int) bool {
+		x, y := members[group[a]], members[group[b]]
+		if x.item.Published.IsZero() != y.item.Published.IsZero() {
+			return y.item.Published.IsZero()
+		}
+		if !x.item.Published.Equal(y.item.Published) {
+			return x.item.Published.Before(y.item.Published)
+		}
+		return x.length > y.length
+	})
+
+	representative := members[group[0]]
+	sum := sha256.Sum256([]byte(representative.feedURL + "|" + itemKey(representative.item)))
+	cluster := domain.StoryCluster{ID: "story-" + hex.EncodeToString(sum[:8])}
+
+	for _, index := range group {
+		m := members[index]
+		m.item.ClusterID = cluster.ID
+		cluster.Members = append(cluster.Members, domain.StoryMember{
+			FeedURL:   m.feedURL,
+			ItemID:    m.item.ID,
+			Title:     m.item.Title,
+			Link:      m.item.Link,
+			Published: m.item.Published,
+		})
+	}
+	cluster.Representative = cluster.Members[0]
+
+	return cluster
+}
+
+// latest returns when the newest item of a story was published
+func latest(cluster domain.StoryCluster) time.Time {
+	newest := cluster.Members[0].Published
+	for _, m := range cluster.Members[1:] {
+		if m.Published.After(newest) {
+			newest = m.Published
+		}
+	}
+	return newest
+}
+
+func itemKey(item *domain.FeedItem) string {
+	if item.ID != "" {
+		return item.ID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	return item.Title
+}
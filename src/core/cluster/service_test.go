@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+func TestService_ClusterFeeds(t *testing.T) {
+	monday := time.Date(2024, 9, 9, 17, 0, 0, 0, time.UTC)
+	feeds := []*domain.Feed{
+		{URL: "https://a.example.com/feed", Items: []domain.FeedItem{
+			{ID: "a1", Title: "Apple unveils iPhone 16 - Site A", Content: launchStory, Published: monday.Add(time.Hour)},
+			{ID: "a2", Title: "Weekly notes", Description: "Short post."},
+		}},
+		{URL: "https://b.example.com/feed", Items: []domain.FeedItem{
+			{ID: "b1", Title: "Google releases Android 15", Description: "Google on Tuesday released Android 15 to Pixel phones, bringing private spaces, satellite messaging and better battery life to supported devices.", Published: monday.Add(24 * time.Hour)},
+		}},
+		{URL: "https://c.example.com/feed", Items: []domain.FeedItem{
+			{ID: "c1", Title: "Apple unveils iPhone 16", ContentEncoded: "<p>" + launchStory + "</p>", Published: monday},
+		}},
+		nil,
+	}
+
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+	clusters := service.ClusterFeeds(context.Background(), feeds)
+
+	if len(clusters) != 1 {
+		t.Fatalf("Expected 1 story, got %+v", clusters)
+	}
+	story := clusters[0]
+	if len(story.Members) != 2 {
+		t.Fatalf("Expected 2 items in the story, got %+v", story.Members)
+	}
+
+	// The earliest report represents the story
+	if story.Representative.ItemID != "c1" || story.Members[0].ItemID != "c1" || story.Members[1].FeedURL != "https://a.example.com/feed" {
+		t.Errorf("unexpected story: %+v", story)
+	}
+	if feeds[0].Items[0].ClusterID != story.ID || feeds[2].Items[0].ClusterID != story.ID {
+		t.Errorf("Expected both items to carry the cluster ID %q", story.ID)
+	}
+	if feeds[0].Items[1].ClusterID != "" || feeds[1].Items[0].ClusterID != "" {
+		t.Error("Expected items without duplicates to have no cluster")
+	}
+
+	// The ID stays the same while the representative does
+	again := service.ClusterFeeds(context.Background(), feeds)
+	if len(again) != 1 || again[0].ID != story.ID {
+		t.Errorf("Expected a stable cluster ID, got %+v", again)
+	}
+}
+
+func TestGroup_UsesBands(t *testing.T) {
+	members := []member{
+		{fingerprint: 0},
+		{fingerprint: 0b111111},   // 6 bits from the first
+		{fingerprint: 0b1111111},  // 7 bits from the first, 1 from the second
+		{fingerprint: ^uint64(0)}, // far from all
+	}
+
+	groups := group(members, 6)
+	if len(groups) != 2 || len(groups[0]) != 3 || len(groups[1]) != 1 {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+}
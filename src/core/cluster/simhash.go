@@ -0,0 +1,90 @@
+// ABOUTME: SimHash fingerprints of feed items for near-duplicate detection
+// ABOUTME: Items telling the same story get fingerprints a few bits apart
+
+package cluster
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"digests-app-api/core/summarize"
+)
+
+const (
+	// maxTokens caps how much of an item is fingerprinted; the opening of a
+	// story says what it is about
+	maxTokens = 300
+
+	// minWords is the least distinct words an item needs to be fingerprinted,
+	// as fingerprints of a few words collide by chance
+	minWords = 8
+)
+
+// Fingerprint returns the 64-bit SimHash of an item's title and text, which
+// may be HTML. Features are the distinct words, so repeated words and
+// reordered sentences do not move the fingerprint. Title words count no
+// more than others, as sites often add their name to the title. ok is false
+// for items with too little text to compare.
+func Fingerprint(title, text string) (fingerprint uint64, ok bool) {
+	words := make(map[string]bool)
+	for _, word := range tokenize(title) {
+		words[word] = true
+	}
+	body := tokenize(summarize.PlainText(text))
+	if len(body) > maxTokens {
+		body = body[:maxTokens]
+	}
+	for _, word := range body {
+		words[word] = true
+	}
+	if len(words) < minWords {
+		return 0, false
+	}
+
+	var weights [64]int
+	for word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		feature := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if feature&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint, true
+}
+
+// Distance returns the number of bits in which two fingerprints differ
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// tokenize splits text into lower-cased words, leaving out short words
+// such as "a" and "of" that every story shares
+func tokenize(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) >= 3 || hasDigit(word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func hasDigit(word string) bool {
+	return strings.IndexFunc(word, unicode.IsDigit) >= 0
+}
@@ -0,0 +1,35 @@
+package cluster
+
+import "testing"
+
+const launchStory = "Apple on Monday unveiled the iPhone 16, adding a dedicated camera button and a faster A18 chip. The phone goes on sale next week starting at $799, the company said at its event in Cupertino. Preorders open on Friday."
+
+func TestFingerprint_NearDuplicates(t *testing.T) {
+	original, ok := Fingerprint("Apple unveils the iPhone 16 with a new camera button", launchStory)
+	if !ok {
+		t.Fatal("expected a fingerprint")
+	}
+	syndicated, _ := Fingerprint("Apple unveils iPhone 16 with new camera button - TechSite",
+		"<p>Apple on Monday unveiled the iPhone 16, adding a dedicated camera button and a faster A18 chip.</p><p>The phone goes on sale next week, starting at $799, the company said at its Cupertino event. Preorders open Friday.</p>")
+	unrelated, _ := Fingerprint("Google releases Android 15",
+		"Google on Tuesday released Android 15 to Pixel phones, bringing private spaces, satellite messaging and better battery life to supported devices.")
+
+	if d := Distance(original, syndicated); d > DefaultMaxDistance {
+		t.Errorf("near-identical stories differ by %d bits", d)
+	}
+	if d := Distance(original, unrelated); d <= 2*DefaultMaxDistance {
+		t.Errorf("unrelated stories differ by only %d bits", d)
+	}
+}
+
+func TestFingerprint_TooShort(t *testing.T) {
+	if _, ok := Fingerprint("Weekly notes", "Short post."); ok {
+		t.Error("expected no fingerprint for a few words")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	if d := Distance(0b1011, 0b0110); d != 3 {
+		t.Errorf("Distance() = %d, want 3", d)
+	}
+}
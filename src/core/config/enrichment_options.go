@@ -10,9 +10,24 @@ type EnrichmentConfig struct {
 	
 	// ExtractColors controls whether to extract colors from images
 	ExtractColors bool
+	
+	// Summarize controls whether to add extractive summaries of item content
+	Summarize bool
+	
+	// SummarySentences is the summary length; 0 uses the summarizer's default
+	SummarySentences int
+	
+	// AutoTag controls whether to add computed keywords and key phrases to items
+	AutoTag bool
+	
+	// MaxTags is the number of auto-tags per item; 0 uses the tagger's default
+	MaxTags int
+	
+	// Cluster controls whether to group near-identical items into story clusters
+	Cluster bool
 }
 
-// DefaultEnrichmentConfig returns the default configuration with all features enabled
+// DefaultEnrichmentConfig returns the default configuration with metadata and colors enabled
 func DefaultEnrichmentConfig() EnrichmentConfig {
 	return EnrichmentConfig{
 		ExtractMetadata: true,
@@ -0,0 +1,28 @@
+// ABOUTME: Domain types for grouping near-duplicate items into stories
+// ABOUTME: A story cluster lists the items of several feeds that report the same thing
+
+package domain
+
+import "time"
+
+// StoryCluster is a group of near-identical items, typically the same
+// story covered or syndicated by several feeds
+type StoryCluster struct {
+	// ID identifies the cluster; it stays the same while its representative does
+	ID string
+
+	// Representative is the item shown for the whole story
+	Representative StoryMember
+
+	// Members are all items of the story, the representative first
+	Members []StoryMember
+}
+
+// StoryMember is an item in a story cluster
+type StoryMember struct {
+	FeedURL   string
+	ItemID    string
+	Title     string
+	Link      string
+	Published time.Time
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Enrichment job domain model for results computed in the background
+// ABOUTME: Tracks the status and outcome of each URL so clients can poll for them
+
+package domain
+
+import "time"
+
+// Statuses of an enrichment job and of each of its URLs
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// EnrichmentJob is a batch of thumbnails enriched in the background
+type EnrichmentJob struct {
+	ID        string                       `json:"id"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	URLs      []string                     `json:"urls"`
+	Results   map[string]*EnrichmentResult `json:"results"`
+}
+
+// EnrichmentResult is the outcome for one URL of a job. Palette and
+// Placeholder are set once done; Error says why a URL failed.
+type EnrichmentResult struct {
+	Status      string              `json:"status"`
+	Palette     *ColorPalette       `json:"palette,omitempty"`
+	Placeholder *ImagePlaceholder   `json:"placeholder,omitempty"`
+	Rejection   *ThumbnailRejection `json:"rejection,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// NewEnrichmentJob creates a job with every URL pending
+func NewEnrichmentJob(id string, urls []string) *EnrichmentJob {
+	job := &EnrichmentJob{
+		ID:        id,
+		CreatedAt: time.Now(),
+		URLs:      urls,
+		Results:   make(map[string]*EnrichmentResult, len(urls)),
+	}
+	for _, url := range urls {
+		job.Results[url] = &EnrichmentResult{Status: EnrichmentPending}
+	}
+	return job
+}
+
+// Status is pending while any URL is, and done once all are done or failed
+func (j *EnrichmentJob) Status() string {
+	for _, result := range j.Results {
+		if result.Status == EnrichmentPending {
+			return EnrichmentPending
+		}
+	}
+	return EnrichmentDone
+}
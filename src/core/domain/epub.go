@@ -0,0 +1,16 @@
+// ABOUTME: Domain types for exporting reader views as EPUB e-books
+// ABOUTME: Options describe the digest book built from a batch of articles
+
+package domain
+
+// EPUBOptions describes the e-book a batch of articles is exported as
+type EPUBOptions struct {
+	// Title is the book title; defaults to a dated "Digest" title
+	Title string
+
+	// Author is the book's creator; article bylines are listed as contributors
+	Author string
+
+	// Language is the book language; defaults to the first article's language
+	Language string
+}
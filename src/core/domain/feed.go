@@ -26,6 +26,10 @@ type Feed struct {
 	// Link is the website URL associated with the feed
 	Link string
 
+	// SelfURL is the URL the feed declares for itself (Atom rel="self",
+	// JSON Feed feed_url), where redirects and alternate paths end up
+	SelfURL string
+
 	// Items contains the feed entries
 	Items []FeedItem
 
@@ -33,7 +37,7 @@ type Feed struct {
 	LastUpdated time.Time
 
 	// Additional metadata fields
-	Language    string     // Feed language (e.g., "en-US")
+	Language    string     // Feed language (e.g., "en-US"), declared or detected
 	Favicon     string     // URL to the feed's favicon
 	Author      *Author    // Feed author information
 	Categories  string     // Feed categories as a string
@@ -41,6 +45,9 @@ type Feed struct {
 	Image       string     // Feed image URL
 	Subtitle    string     // Feed subtitle (for podcasts)
 	Published   *time.Time // Feed publication date
+
+	// LanguageConfidence is 1 for a declared language, or how sure detection is
+	LanguageConfidence float64
 }
 
 // Author represents author information
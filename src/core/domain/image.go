@@ -0,0 +1,33 @@
+// ABOUTME: Domain models for proxied images
+// ABOUTME: Describes how an image is resized and re-encoded, and the result
+
+package domain
+
+// Fit modes of a resized image
+const (
+	ImageFitContain = "contain" // fit inside the requested box, keeping the whole image
+	ImageFitCover   = "cover"   // fill the requested box, cropping the overflow from the center
+)
+
+// Output formats of a proxied image
+const (
+	ImageFormatAuto = ""     // PNG for images with transparency, JPEG otherwise
+	ImageFormatJPEG = "jpeg" // JPEG
+	ImageFormatPNG  = "png"  // PNG
+)
+
+// ImageOptions describes how a proxied image is processed. Images are
+// never enlarged; a zero width or height leaves that side to the aspect.
+type ImageOptions struct {
+	Width   int
+	Height  int
+	Fit     string
+	Format  string
+	Quality int // JPEG quality, 1 to 100
+}
+
+// ProcessedImage is a resized and re-encoded image
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+}
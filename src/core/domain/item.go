@@ -36,6 +36,11 @@ type FeedItem struct {
 	Thumbnail  string       // Thumbnail image URL
 	Duration   string       // Media duration (e.g., "00:28:19")
 	
+	// ThumbnailCandidates are the images the thumbnail is picked from, best
+	// first; ThumbnailRejections records why candidates before it were skipped
+	ThumbnailCandidates []string
+	ThumbnailRejections []ThumbnailRejection
+	
 	// Podcast-specific fields
 	Episode     int    // Episode number
 	Season      int    // Season number
@@ -43,6 +48,20 @@ type FeedItem struct {
 	Subtitle    string // Episode subtitle
 	Summary     string // Episode summary
 	Image       string // Episode image
+
+	// KeySentences is an extractive summary of the content, when requested
+	KeySentences []string
+
+	// AutoTags are keywords and key phrases computed from the text, when requested
+	AutoTags []string
+
+	// Language is the detected language of the item, or the feed's when it
+	// cannot be told; LanguageConfidence is between 0 and 1
+	Language           string
+	LanguageConfidence float64
+
+	// ClusterID is the story cluster of near-identical items this item is in, when requested
+	ClusterID string
 }
 
 // Enclosure represents media attachment information
@@ -0,0 +1,98 @@
+// ABOUTME: JSON mapping domain model describes how to build a feed from a JSON API
+// ABOUTME: Holds the document URL and JSONPath-style expressions used to extract items
+
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JSONMapping describes how to map a JSON document into feed items.
+// ItemsPath is evaluated against the document; the other paths are evaluated
+// against each item, e.g. ItemsPath "$.data.posts[*]" and TitlePath "$.title".
+type JSONMapping struct {
+	// ID is the unique identifier (UUID) for the mapping
+	ID string
+
+	// Name is a human-readable name, used as the feed title
+	Name string
+
+	// URL is the JSON document to fetch
+	URL string
+
+	// ItemsPath selects the list of items in the document
+	ItemsPath string
+
+	// IDPath selects the item ID (defaults to the link)
+	IDPath string
+
+	// TitlePath selects the item title
+	TitlePath string
+
+	// LinkPath selects the item link
+	LinkPath string
+
+	// DatePath selects the publication date (RFC 3339-like strings or Unix timestamps)
+	DatePath string
+
+	// ContentPath selects the item content
+	ContentPath string
+
+	// SummaryPath selects the item summary (defaults to the content)
+	SummaryPath string
+
+	// AuthorPath selects the item author
+	AuthorPath string
+
+	// ImagePath selects the item image
+	ImagePath string
+
+	// CreatedAt is when the mapping was created
+	CreatedAt time.Time
+}
+
+// NewJSONMapping creates a new JSONMapping with a generated ID and validates it
+func NewJSONMapping(mapping JSONMapping) (*JSONMapping, error) {
+	mapping.ID = uuid.New().String()
+	mapping.CreatedAt = time.Now()
+	mapping.Name = strings.TrimSpace(mapping.Name)
+	mapping.URL = strings.TrimSpace(mapping.URL)
+
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &mapping, nil
+}
+
+// Validate checks that the mapping can be used to build a feed
+func (m *JSONMapping) Validate() error {
+	if m.URL == "" {
+		return errors.New("mapping URL cannot be empty")
+	}
+
+	parsedURL, err := url.Parse(m.URL)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return errors.New("mapping URL must be a valid HTTP(S) URL")
+	}
+
+	if strings.TrimSpace(m.ItemsPath) == "" {
+		return errors.New("items path cannot be empty")
+	}
+
+	if strings.TrimSpace(m.TitlePath) == "" && strings.TrimSpace(m.LinkPath) == "" {
+		return errors.New("a title path or link path is required")
+	}
+
+	return nil
+}
+
+// FeedURL returns the source URL used to parse this mapping as a feed
+func (m *JSONMapping) FeedURL() string {
+	return "jsonapi://" + m.ID
+}
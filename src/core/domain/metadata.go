@@ -0,0 +1,65 @@
+// ABOUTME: Page metadata domain model for what a web page declares about itself
+// ABOUTME: Combines Open Graph, Twitter Card, JSON-LD, microdata and plain meta tags
+
+package domain
+
+// PageMetadata is the metadata of a web page. When the page cannot be read,
+// Error says why and only URL is set.
+type PageMetadata struct {
+	// URL is the page that was requested; CanonicalURL is the one it declares
+	URL          string `json:"url"`
+	CanonicalURL string `json:"canonicalUrl,omitempty"`
+	Domain       string `json:"domain,omitempty"`
+
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Type is the Open Graph type (e.g. "article"), or else the schema.org
+	// type of the page's main entity (e.g. "NewsArticle")
+	Type       string `json:"type,omitempty"`
+	SiteName   string `json:"siteName,omitempty"`
+	Locale     string `json:"locale,omitempty"`
+	Determiner string `json:"determiner,omitempty"`
+
+	Author    string `json:"author,omitempty"`
+	Published string `json:"published,omitempty"`
+	Modified  string `json:"modified,omitempty"`
+
+	// Thumbnail is the lead image; Images lists every image found, best first
+	Thumbnail string        `json:"thumbnail,omitempty"`
+	Images    []MediaObject `json:"images,omitempty"`
+	Videos    []MediaObject `json:"videos,omitempty"`
+
+	// Duration is the length of the page's video in seconds
+	Duration int `json:"duration,omitempty"`
+
+	ThemeColor string `json:"themeColor,omitempty"`
+	Favicon    string `json:"favicon,omitempty"`
+
+	// StructuredData holds the page's JSON-LD documents as declared
+	StructuredData []interface{} `json:"structuredData,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// MediaObject is an image or video a page declares
+type MediaObject struct {
+	URL         string   `json:"url"`
+	SecureURL   string   `json:"secureUrl,omitempty"`
+	Alt         string   `json:"alt,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Width       int      `json:"width,omitempty"`
+	Height      int      `json:"height,omitempty"`
+	Duration    int      `json:"duration,omitempty"`
+	ReleaseDate string   `json:"releaseDate,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// ImageURLs returns the URLs of the page's images, in order
+func (m *PageMetadata) ImageURLs() []string {
+	urls := make([]string, 0, len(m.Images))
+	for _, image := range m.Images {
+		urls = append(urls, image.URL)
+	}
+	return urls
+}
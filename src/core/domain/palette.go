@@ -0,0 +1,31 @@
+// ABOUTME: Domain model for the color palette of an image
+// ABOUTME: Holds named swatches and a readable text color for cards drawn over the image
+
+package domain
+
+// ColorPalette is the set of colors picked from an image. Swatches the
+// image has no suitable color for are nil.
+type ColorPalette struct {
+	// Dominant is the most prominent color of the image
+	Dominant RGBColor `json:"dominant"`
+
+	// Vibrant is a saturated color of medium lightness
+	Vibrant *RGBColor `json:"vibrant,omitempty"`
+
+	// Muted is a desaturated color of medium lightness
+	Muted *RGBColor `json:"muted,omitempty"`
+
+	// DarkVibrant is a saturated dark color
+	DarkVibrant *RGBColor `json:"darkVibrant,omitempty"`
+
+	// LightMuted is a desaturated light color
+	LightMuted *RGBColor `json:"lightMuted,omitempty"`
+
+	// TextColor is a color for text drawn over the image, chosen to contrast
+	// with the dominant color
+	TextColor RGBColor `json:"textColor"`
+
+	// TextContrast is the WCAG contrast ratio of TextColor on Dominant,
+	// from 1 to 21; 4.5 meets WCAG AA for body text
+	TextContrast float64 `json:"textContrast"`
+}
@@ -0,0 +1,18 @@
+// ABOUTME: Domain model for the placeholders shown while an image loads
+// ABOUTME: Holds a BlurHash and a tiny inline preview with the image's dimensions
+
+package domain
+
+// ImagePlaceholder is a stand-in for an image that has not loaded yet
+type ImagePlaceholder struct {
+	// BlurHash is a compact string that decodes to a blurred image
+	BlurHash string `json:"blurHash"`
+
+	// LQIP is a low-quality image placeholder: a tiny JPEG as a data URI
+	LQIP string `json:"lqip"`
+
+	// Width and Height are the dimensions of the original image, so the
+	// placeholder can be laid out with the right aspect ratio
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Queued job domain model for background enrichment work
+// ABOUTME: Jobs are keyed by type and URL so the same work is only queued once
+
+package domain
+
+import "time"
+
+// Job types processed by the background queue
+const (
+	JobTypeMetadata = "metadata"
+	JobTypeColor    = "color"
+	JobTypeReader   = "reader"
+)
+
+// JobTypes lists every job type, in the order they are reported
+var JobTypes = []string{JobTypeColor, JobTypeMetadata, JobTypeReader}
+
+// Job statuses; a job is removed from the queue once it succeeds
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDead    = "dead"
+)
+
+// QueuedJob is a unit of background work for one URL
+type QueuedJob struct {
+	// ID is the job type and URL, so a URL is queued once per type
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+
+	Status string `json:"status"`
+
+	// Attempts counts how many times the job was claimed
+	Attempts int `json:"attempts"`
+
+	// LastError is why the last attempt failed
+	LastError string `json:"lastError,omitempty"`
+
+	// RunAt is when a pending job is due, or when a running job's lease
+	// expires and it may be claimed again
+	RunAt time.Time `json:"runAt"`
+
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// NewQueuedJob creates a pending job due now
+func NewQueuedJob(jobType, url string, now time.Time) *QueuedJob {
+	return &QueuedJob{
+		ID:         QueuedJobID(jobType, url),
+		Type:       jobType,
+		URL:        url,
+		Status:     JobPending,
+		RunAt:      now,
+		EnqueuedAt: now,
+		UpdatedAt:  now,
+	}
+}
+
+// QueuedJobID returns the ID of the job of a type for a URL
+func QueuedJobID(jobType, url string) string {
+	return jobType + ":" + url
+}
+
+// IsDue reports whether the job can be claimed at the given time
+func (j *QueuedJob) IsDue(now time.Time) bool {
+	return j.Status != JobDead && !j.RunAt.After(now)
+}
+
+// Claim marks the job as running until its lease expires
+func (j *QueuedJob) Claim(now time.Time, lease time.Duration) {
+	j.Status = JobRunning
+	j.Attempts++
+	j.RunAt = now.Add(lease)
+	j.UpdatedAt = now
+}
+
+// QueueStats describes the queue for monitoring
+type QueueStats struct {
+	// Depth counts jobs by type and status
+	Depth map[string]map[string]int
+
+	// Failures are dead jobs, most recent first
+	Failures []*QueuedJob
+}
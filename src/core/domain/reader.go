@@ -3,16 +3,46 @@
 
 package domain
 
+import "time"
+
 // ReaderView represents extracted article content from a webpage
 type ReaderView struct {
-	URL         string `json:"url"`
-	Title       string `json:"title"`
-	Content     string `json:"content"`         // HTML content
-	Markdown    string `json:"markdown"`        // Markdown content
-	TextContent string `json:"textContent"`     // Plain text content
-	SiteName    string `json:"siteName"`
-	Image       string `json:"image"`
-	Favicon     string `json:"favicon"`
-	Status      string `json:"status"`
-	Error       string `json:"error,omitempty"`
-}
\ No newline at end of file
+	URL                string     `json:"url"`
+	Title              string     `json:"title"`
+	Content            string     `json:"content"`     // HTML content
+	Markdown           string     `json:"markdown"`    // Markdown content
+	TextContent        string     `json:"textContent"` // Plain text content
+	Excerpt            string     `json:"excerpt,omitempty"`
+	Byline             string     `json:"byline,omitempty"` // Author as shown on the page
+	SiteName           string     `json:"siteName"`
+	Image              string     `json:"image"`
+	Favicon            string     `json:"favicon"`
+	Language           string     `json:"language,omitempty"`
+	LanguageConfidence float64    `json:"languageConfidence,omitempty"` // 1 when declared by the page, else detected
+	PublishedTime      *time.Time `json:"publishedTime,omitempty"`
+	ModifiedTime       *time.Time `json:"modifiedTime,omitempty"`
+	WordCount          int        `json:"wordCount"`
+	ReadingTimeMinutes int        `json:"readingTimeMinutes"`     // Estimated at ReadingWordsPerMinute
+	Pages              int        `json:"pages"`                  // Pages of a paginated article stitched together
+	Source             string     `json:"source,omitempty"`       // ReaderSourcePage or ReaderSourceFeed
+	KeySentences       []string   `json:"keySentences,omitempty"` // Extractive summary, when requested
+	Status             string     `json:"status"`
+	Error              string     `json:"error,omitempty"`
+}
+
+// Sources a reader view can be built from
+const (
+	ReaderSourcePage = "page" // the fetched web page
+	ReaderSourceFeed = "feed" // full-text content of the feed item
+)
+
+// ReaderTarget is a page to extract a reader view for. FeedURL and ItemID
+// optionally name the feed item the page was linked from.
+type ReaderTarget struct {
+	URL     string
+	FeedURL string
+	ItemID  string
+}
+
+// ReadingWordsPerMinute is the reading speed reading time estimates are based on
+const ReadingWordsPerMinute = 230
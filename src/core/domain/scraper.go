@@ -0,0 +1,88 @@
+// ABOUTME: Scraper domain model describes how to build a feed from an ordinary web page
+// ABOUTME: Holds the page URL and CSS selectors used to extract items, with validation
+
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScraperDefinition describes how to extract feed items from a web page
+// using CSS selectors. Item field selectors are relative to the item container.
+type ScraperDefinition struct {
+	// ID is the unique identifier (UUID) for the definition
+	ID string
+
+	// Name is a human-readable name, used as the feed title
+	Name string
+
+	// URL is the page to scrape
+	URL string
+
+	// ItemSelector matches one container element per item
+	ItemSelector string
+
+	// TitleSelector matches the item title (defaults to the link text)
+	TitleSelector string
+
+	// LinkSelector matches the element holding the item link (defaults to the first a[href])
+	LinkSelector string
+
+	// LinkAttribute is the attribute holding the link (defaults to "href")
+	LinkAttribute string
+
+	// DateSelector matches the item date; a datetime attribute is preferred over text
+	DateSelector string
+
+	// SummarySelector matches the item summary
+	SummarySelector string
+
+	// ImageSelector matches the item image
+	ImageSelector string
+
+	// CreatedAt is when the definition was created
+	CreatedAt time.Time
+}
+
+// NewScraperDefinition creates a new ScraperDefinition with a generated ID
+// and validates it
+func NewScraperDefinition(def ScraperDefinition) (*ScraperDefinition, error) {
+	def.ID = uuid.New().String()
+	def.CreatedAt = time.Now()
+	def.Name = strings.TrimSpace(def.Name)
+	def.URL = strings.TrimSpace(def.URL)
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &def, nil
+}
+
+// Validate checks that the definition can be used to scrape a page
+func (d *ScraperDefinition) Validate() error {
+	if d.URL == "" {
+		return errors.New("scraper URL cannot be empty")
+	}
+
+	parsedURL, err := url.Parse(d.URL)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return errors.New("scraper URL must be a valid HTTP(S) URL")
+	}
+
+	if strings.TrimSpace(d.ItemSelector) == "" {
+		return errors.New("item selector cannot be empty")
+	}
+
+	return nil
+}
+
+// FeedURL returns the source URL used to parse this scraper as a feed
+func (d *ScraperDefinition) FeedURL() string {
+	return "scraper://" + d.ID
+}
@@ -1,8 +1,10 @@
 // ABOUTME: Search domain models for feed discovery results
-// ABOUTME: Defines structures for search results from external feed discovery APIs
+// ABOUTME: Defines structures for feed discovery results and local full-text search
 
 package domain
 
+import "time"
+
 // SearchResult represents a discovered RSS feed from search
 type SearchResult struct {
 	// Title is the feed's title
@@ -20,6 +22,80 @@ type SearchResult struct {
 	// Language is the feed's language (e.g., "en", "es")
 	Language string
 
+	// Author is the feed's author or publisher
+	Author string
+
+	// Image is the feed's artwork URL
+	Image string
+
 	// Score is the relevance score (0-100)
 	Score int
-}
\ No newline at end of file
+
+	// Providers lists the directories that returned this feed
+	Providers []string
+}
+
+// ItemSearchQuery describes a full-text search over indexed feeds and items
+type ItemSearchQuery struct {
+	// Text is the query: terms are combined with AND, "quoted phrases" must
+	// appear in order and -terms exclude items
+	Text string
+
+	// FeedURLs restricts results to items from these feeds
+	FeedURLs []string
+
+	// From and To restrict results to items published in this range
+	From time.Time
+	To   time.Time
+
+	// Limit and Offset page through the ranked results
+	Limit  int
+	Offset int
+}
+
+// ItemSearchResults holds ranked item hits with facets over all matches
+type ItemSearchResults struct {
+	// Total is the number of matching items before paging
+	Total int
+
+	// Items are the ranked item hits for the requested page
+	Items []ItemSearchHit
+
+	// Feeds are indexed feeds whose title or description match the query
+	Feeds []FeedSearchHit
+
+	// FeedFacets counts matching items per feed
+	FeedFacets []SearchFacet
+
+	// DateFacets counts matching items per publication month ("2006-01")
+	DateFacets []SearchFacet
+}
+
+// ItemSearchHit is a single ranked item
+type ItemSearchHit struct {
+	Item      FeedItem
+	FeedURL   string
+	FeedTitle string
+	Score     float64
+
+	// TitleHighlight and Snippet are HTML-escaped with matches wrapped in <mark>
+	TitleHighlight string
+	Snippet        string
+}
+
+// FeedSearchHit is an indexed feed matching a query
+type FeedSearchHit struct {
+	Title       string
+	Description string
+	URL         string
+	Link        string
+	ItemCount   int
+	Score       float64
+}
+
+// SearchFacet is a facet value with the number of matching items
+type SearchFacet struct {
+	Value string
+	Label string
+	Count int
+}
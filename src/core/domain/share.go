@@ -19,6 +19,12 @@ type Share struct {
 	// URLs contains the list of shared URLs
 	URLs []string
 
+	// Title is an optional name for the share
+	Title string
+
+	// Description is an optional summary of the share
+	Description string
+
 	// CreatedAt is when the share was created
 	CreatedAt time.Time
 
@@ -26,8 +32,33 @@ type Share struct {
 	ExpiresAt *time.Time
 }
 
+const (
+	// maxShareTitleLength limits share titles
+	maxShareTitleLength = 200
+
+	// maxShareDescriptionLength limits share descriptions
+	maxShareDescriptionLength = 2000
+)
+
+// ShareOptions holds optional share attributes
+type ShareOptions struct {
+	// Title is an optional name for the share
+	Title string
+
+	// Description is an optional summary of the share
+	Description string
+
+	// ExpiresAt is when the share expires (nil means no expiration)
+	ExpiresAt *time.Time
+}
+
 // NewShare creates a new Share instance with validation
 func NewShare(urls []string) (*Share, error) {
+	return NewShareWithOptions(urls, ShareOptions{})
+}
+
+// NewShareWithOptions creates a new Share with a title, description and expiry
+func NewShareWithOptions(urls []string, opts ShareOptions) (*Share, error) {
 	if len(urls) == 0 {
 		return nil, errors.New("urls cannot be empty")
 	}
@@ -40,11 +71,26 @@ func NewShare(urls []string) (*Share, error) {
 		}
 	}
 
+	if len(opts.Title) > maxShareTitleLength {
+		return nil, errors.New("title cannot exceed 200 characters")
+	}
+
+	if len(opts.Description) > maxShareDescriptionLength {
+		return nil, errors.New("description cannot exceed 2000 characters")
+	}
+
+	now := time.Now()
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(now) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
 	share := &Share{
-		ID:        uuid.New().String(),
-		URLs:      urls,
-		CreatedAt: time.Now(),
-		ExpiresAt: nil,
+		ID:          uuid.New().String(),
+		URLs:        urls,
+		Title:       opts.Title,
+		Description: opts.Description,
+		CreatedAt:   now,
+		ExpiresAt:   opts.ExpiresAt,
 	}
 
 	return share, nil
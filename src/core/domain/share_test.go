@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -93,4 +94,28 @@ func TestShare_IsExpired(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNewShareWithOptions(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	share, err := NewShareWithOptions([]string{"https://example.com/feed"}, ShareOptions{
+		Title:       "Reading list",
+		Description: "Feeds worth following",
+		ExpiresAt:   &future,
+	})
+	if err != nil {
+		t.Fatalf("NewShareWithOptions() error = %v", err)
+	}
+	if share.Title != "Reading list" || share.Description != "Feeds worth following" || share.ExpiresAt == nil {
+		t.Errorf("options not applied: %+v", share)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if _, err := NewShareWithOptions([]string{"https://example.com/feed"}, ShareOptions{ExpiresAt: &past}); err == nil {
+		t.Error("expected error for an expiry in the past")
+	}
+
+	if _, err := NewShareWithOptions([]string{"https://example.com/feed"}, ShareOptions{Title: strings.Repeat("a", 201)}); err == nil {
+		t.Error("expected error for a title that is too long")
+	}
+}
@@ -0,0 +1,102 @@
+// ABOUTME: Domain model for per-site extraction rules
+// ABOUTME: Rules tell the reader and metadata extraction where a site keeps its content
+
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// SiteRule holds CSS selectors that override generic extraction on matching hosts
+type SiteRule struct {
+	// Name identifies the rule in logs; defaults to the file it was loaded from
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Hosts are host globs such as "example.com" or "*.example.com"
+	Hosts []string `json:"hosts" yaml:"hosts"`
+
+	// Content selects the article body; matches are joined in document order
+	Content []string `json:"content,omitempty" yaml:"content,omitempty"`
+
+	// Remove selects elements to strip before extraction (cookie banners, newsletter boxes)
+	Remove []string `json:"remove,omitempty" yaml:"remove,omitempty"`
+
+	// Title, Author and Date select the element holding each value
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	Author string `json:"author,omitempty" yaml:"author,omitempty"`
+	Date   string `json:"date,omitempty" yaml:"date,omitempty"`
+
+	// HeroImage selects the article's lead image
+	HeroImage string `json:"heroImage,omitempty" yaml:"heroImage,omitempty"`
+}
+
+// Validate checks that the rule has hosts and that every selector is valid CSS
+func (r *SiteRule) Validate() error {
+	if len(r.Hosts) == 0 {
+		return errors.New("site rule needs at least one host")
+	}
+	for _, host := range r.Hosts {
+		if _, err := path.Match(normalizeRuleHost(host), ""); err != nil || strings.TrimSpace(host) == "" {
+			return fmt.Errorf("invalid host pattern %q", host)
+		}
+	}
+
+	selectors := append(append([]string{}, r.Content...), r.Remove...)
+	selectors = append(selectors, r.Title, r.Author, r.Date, r.HeroImage)
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %v", selector, err)
+		}
+	}
+
+	return nil
+}
+
+// MatchHost reports how specifically the rule matches a host: 0 for no
+// match, higher for more specific patterns. "*.example.com" matches
+// "example.com" and any host below it, "*" matches every host but ranks
+// below any other pattern, and a leading "www." is ignored on both sides.
+func (r *SiteRule) MatchHost(host string) int {
+	host = normalizeRuleHost(host)
+	best := 0
+	for _, pattern := range r.Hosts {
+		pattern = normalizeRuleHost(pattern)
+
+		score := 0
+		switch {
+		case pattern == host:
+			score = 1000 + len(pattern)
+		case pattern == "*":
+			score = 1
+		case strings.HasPrefix(pattern, "*.") && !strings.ContainsAny(pattern[2:], "*?["):
+			if host == pattern[2:] || strings.HasSuffix(host, pattern[1:]) {
+				score = 1 + len(pattern)
+			}
+		default:
+			if ok, _ := path.Match(pattern, host); ok {
+				score = 1 + len(strings.ReplaceAll(pattern, "*", ""))
+			}
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// normalizeRuleHost lowercases a host and strips its port and leading "www."
+func normalizeRuleHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
@@ -0,0 +1,35 @@
+// ABOUTME: Thumbnail validation results for feed item image candidates
+// ABOUTME: Records why a candidate image was not used as an item's thumbnail
+
+package domain
+
+// Reasons a thumbnail candidate is rejected
+const (
+	// ThumbnailInvalidURL is a candidate that is not an absolute http(s) URL
+	ThumbnailInvalidURL = "invalid_url"
+
+	// ThumbnailUnreachable is an image that could not be downloaded
+	ThumbnailUnreachable = "unreachable"
+
+	// ThumbnailBadStatus is an image whose server answered with an error
+	ThumbnailBadStatus = "bad_status"
+
+	// ThumbnailUndecodable is a file that is not a supported raster image
+	ThumbnailUndecodable = "undecodable"
+
+	// ThumbnailTooSmall is an image too small to show, such as a spacer or icon
+	ThumbnailTooSmall = "too_small"
+
+	// ThumbnailUniform is an image of nearly a single color, such as a blank placeholder
+	ThumbnailUniform = "uniform"
+
+	// ThumbnailRepeated is an image shared by every item of a feed, such as a site logo
+	ThumbnailRepeated = "repeated"
+)
+
+// ThumbnailRejection records why a thumbnail candidate was not used
+type ThumbnailRejection struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
@@ -0,0 +1,347 @@
+// ABOUTME: Writes EPUB 3 containers: package document, navigation, cover and chapters
+// ABOUTME: Includes an NCX table of contents so EPUB 2 readers can navigate the book
+
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+)
+
+// MediaType is the MIME type of EPUB files
+const MediaType = "application/epub+zip"
+
+// Book is an e-book ready to be written
+type Book struct {
+	Identifier   string // unique ID, e.g. "urn:uuid:..."
+	Title        string
+	Author       string
+	Contributors []string
+	Language     string
+	Description  string
+	Modified     time.Time
+
+	// Cover is the cover page's XHTML body; CoverImage is the Href of the
+	// image in Images used as the cover, if any
+	Cover      string
+	CoverImage string
+
+	Chapters []Chapter
+	Images   []Image
+}
+
+// Chapter is a section of the book with its body as XHTML
+type Chapter struct {
+	Title    string
+	Language string
+	Body     string
+}
+
+// Image is a resource referenced from the book's XHTML
+type Image struct {
+	Href      string // path relative to the package document, e.g. "images/image-1.jpg"
+	MediaType string
+	Data      []byte
+}
+
+const (
+	containerPath = "META-INF/container.xml"
+	packageDir    = "EPUB/"
+	packagePath   = packageDir + "package.opf"
+)
+
+// stylesheet keeps articles readable with the reading system's own fonts
+const stylesheet = `body { margin: 0 1em; line-height: 1.5; }
+h1 { font-size: 1.6em; line-height: 1.25; margin: 1em 0 0.4em; }
+.meta { color: #555; font-size: 0.9em; margin-bottom: 1.5em; }
+.source { font-size: 0.85em; word-break: break-all; }
+img { max-width: 100%; height: auto; }
+figure { margin: 1em 0; }
+figcaption { font-size: 0.85em; color: #555; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+blockquote { margin: 1em 0; padding-left: 1em; border-left: 3px solid #ccc; }
+.cover { text-align: center; margin-top: 2em; }
+.cover img { max-height: 50%; }
+.cover ol { text-align: left; }
+`
+
+// Write writes the book as an EPUB container
+func Write(w io.Writer, book *Book) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype comes first, uncompressed and without extra fields,
+	// so the file type can be sniffed from a fixed offset
+	if err := writeStored(zw, "mimetype", []byte(MediaType)); err != nil {
+		return err
+	}
+
+	ncx, err := ncxDocument(book)
+	if err != nil {
+		return err
+	}
+	opf, err := packageDocument(book)
+	if err != nil {
+		return err
+	}
+
+	files := []file{
+		{containerPath, []byte(containerXML)},
+		{packagePath, opf},
+		{packageDir + "toc.ncx", ncx},
+		{packageDir + "nav.xhtml", []byte(navDocument(book))},
+		{packageDir + "style.css", []byte(stylesheet)},
+		{packageDir + "cover.xhtml", []byte(xhtmlDocument(book.Title, book.Language, "style.css", book.Cover))},
+	}
+	for i, chapter := range book.Chapters {
+		language := chapter.Language
+		if language == "" {
+			language = book.Language
+		}
+		body := xhtmlDocument(chapter.Title, language, "../style.css", chapter.Body)
+		files = append(files, file{packageDir + chapterHref(i), []byte(body)})
+	}
+
+	for _, f := range files {
+		if err := writeDeflated(zw, f.name, f.data); err != nil {
+			return err
+		}
+	}
+	for _, image := range book.Images {
+		if err := writeStored(zw, packageDir+image.Href, image.Data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// file is an entry of the EPUB container
+type file struct {
+	name string
+	data []byte
+}
+
+func writeStored(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(data)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeDeflated(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func chapterHref(index int) string {
+	return fmt.Sprintf("articles/article-%03d.xhtml", index+1)
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="` + packagePath + `" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// xhtmlDocument wraps an XHTML body in a complete document
+func xhtmlDocument(title, language, stylesheetHref, body string) string {
+	var doc strings.Builder
+	doc.WriteString(xml.Header)
+	doc.WriteString("<!DOCTYPE html>\n")
+	fmt.Fprintf(&doc, `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">`, escape(language), escape(language))
+	doc.WriteString("\n<head>\n")
+	fmt.Fprintf(&doc, "<meta charset=\"utf-8\"/>\n<title>%s</title>\n", escape(title))
+	fmt.Fprintf(&doc, "<link rel=\"stylesheet\" type=\"text/css\" href=\"%s\"/>\n", stylesheetHref)
+	doc.WriteString("</head>\n<body>\n")
+	doc.WriteString(body)
+	doc.WriteString("\n</body>\n</html>\n")
+	return doc.String()
+}
+
+// navDocument renders the EPUB 3 navigation document
+func navDocument(book *Book) string {
+	var nav strings.Builder
+	nav.WriteString(`<nav epub:type="toc" id="toc">` + "\n")
+	fmt.Fprintf(&nav, "<h1>%s</h1>\n<ol>\n", escape(book.Title))
+	for i, chapter := range book.Chapters {
+		fmt.Fprintf(&nav, "<li><a href=\"%s\">%s</a></li>\n", chapterHref(i), escape(chapter.Title))
+	}
+	nav.WriteString("</ol>\n</nav>\n")
+	nav.WriteString(`<nav epub:type="landmarks" id="landmarks" hidden="hidden">` + "\n<ol>\n")
+	nav.WriteString(`<li><a epub:type="cover" href="cover.xhtml">Cover</a></li>` + "\n")
+	nav.WriteString(`<li><a epub:type="toc" href="nav.xhtml">Contents</a></li>` + "\n")
+	if len(book.Chapters) > 0 {
+		fmt.Fprintf(&nav, "<li><a epub:type=\"bodymatter\" href=\"%s\">Articles</a></li>\n", chapterHref(0))
+	}
+	nav.WriteString("</ol>\n</nav>")
+
+	return xhtmlDocument(book.Title, book.Language, "style.css", nav.String())
+}
+
+// OPF package document
+
+type opfPackage struct {
+	XMLName          xml.Name    `xml:"http://www.idpf.org/2007/opf package"`
+	Version          string      `xml:"version,attr"`
+	UniqueIdentifier string      `xml:"unique-identifier,attr"`
+	Lang             string      `xml:"xml:lang,attr"`
+	Metadata         opfMetadata `xml:"metadata"`
+	Manifest         []opfItem   `xml:"manifest>item"`
+	Spine            opfSpine    `xml:"spine"`
+}
+
+type opfMetadata struct {
+	DC           string        `xml:"xmlns:dc,attr"`
+	Identifier   opfIdentifier `xml:"dc:identifier"`
+	Title        string        `xml:"dc:title"`
+	Creator      string        `xml:"dc:creator,omitempty"`
+	Contributors []string      `xml:"dc:contributor"`
+	Language     string        `xml:"dc:language"`
+	Description  string        `xml:"dc:description,omitempty"`
+	Date         string        `xml:"dc:date"`
+	Meta         []opfMeta     `xml:"meta"`
+}
+
+type opfIdentifier struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opfMeta struct {
+	Property string `xml:"property,attr,omitempty"`
+	Name     string `xml:"name,attr,omitempty"`
+	Content  string `xml:"content,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr,omitempty"`
+}
+
+type opfSpine struct {
+	Toc      string       `xml:"toc,attr"`
+	ItemRefs []opfItemRef `xml:"itemref"`
+}
+
+type opfItemRef struct {
+	IDRef  string `xml:"idref,attr"`
+	Linear string `xml:"linear,attr,omitempty"`
+}
+
+func packageDocument(book *Book) ([]byte, error) {
+	modified := book.Modified.UTC().Format("2006-01-02T15:04:05Z")
+
+	pkg := opfPackage{
+		Version:          "3.0",
+		UniqueIdentifier: "book-id",
+		Lang:             book.Language,
+		Metadata: opfMetadata{
+			DC:           "http://purl.org/dc/elements/1.1/",
+			Identifier:   opfIdentifier{ID: "book-id", Value: book.Identifier},
+			Title:        book.Title,
+			Creator:      book.Author,
+			Contributors: book.Contributors,
+			Language:     book.Language,
+			Description:  book.Description,
+			Date:         modified,
+			Meta:         []opfMeta{{Property: "dcterms:modified", Value: modified}},
+		},
+		Spine: opfSpine{Toc: "ncx"},
+	}
+
+	pkg.Manifest = []opfItem{
+		{ID: "nav", Href: "nav.xhtml", MediaType: "application/xhtml+xml", Properties: "nav"},
+		{ID: "ncx", Href: "toc.ncx", MediaType: "application/x-dtbncx+xml"},
+		{ID: "style", Href: "style.css", MediaType: "text/css"},
+		{ID: "cover", Href: "cover.xhtml", MediaType: "application/xhtml+xml"},
+	}
+
+	pkg.Spine.ItemRefs = []opfItemRef{{IDRef: "cover"}, {IDRef: "nav"}}
+	for i := range book.Chapters {
+		id := fmt.Sprintf("article-%03d", i+1)
+		pkg.Manifest = append(pkg.Manifest, opfItem{ID: id, Href: chapterHref(i), MediaType: "application/xhtml+xml"})
+		pkg.Spine.ItemRefs = append(pkg.Spine.ItemRefs, opfItemRef{IDRef: id})
+	}
+	for i, image := range book.Images {
+		item := opfItem{ID: fmt.Sprintf("image-%03d", i+1), Href: image.Href, MediaType: image.MediaType}
+		if image.Href == book.CoverImage {
+			item.Properties = "cover-image"
+			// EPUB 2 reading systems find the cover through this meta
+			pkg.Metadata.Meta = append(pkg.Metadata.Meta, opfMeta{Name: "cover", Content: item.ID})
+		}
+		pkg.Manifest = append(pkg.Manifest, item)
+	}
+
+	return marshalXML(pkg)
+}
+
+// NCX table of contents for EPUB 2 reading systems
+
+type ncxRoot struct {
+	XMLName xml.Name   `xml:"http://www.daisy.org/z3986/2005/ncx/ ncx"`
+	Version string     `xml:"version,attr"`
+	Meta    []opfMeta  `xml:"head>meta"`
+	Title   string     `xml:"docTitle>text"`
+	Points  []ncxPoint `xml:"navMap>navPoint"`
+}
+
+type ncxPoint struct {
+	ID        string `xml:"id,attr"`
+	PlayOrder int    `xml:"playOrder,attr"`
+	Label     string `xml:"navLabel>text"`
+	Content   struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+}
+
+func ncxDocument(book *Book) ([]byte, error) {
+	doc := ncxRoot{
+		Version: "2005-1",
+		Meta:    []opfMeta{{Name: "dtb:uid", Content: book.Identifier}},
+		Title:   book.Title,
+	}
+	for i, chapter := range book.Chapters {
+		point := ncxPoint{ID: fmt.Sprintf("nav-%03d", i+1), PlayOrder: i + 1, Label: chapter.Title}
+		point.Content.Src = chapterHref(i)
+		doc.Points = append(doc.Points, point)
+	}
+	return marshalXML(doc)
+}
+
+// marshalXML encodes an XML document with a declaration header
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode EPUB document: %w", err)
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
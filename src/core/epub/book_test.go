@@ -0,0 +1,273 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// epubPackage is the part of the package document the validator checks
+type epubPackage struct {
+	Version          string `xml:"version,attr"`
+	UniqueIdentifier string `xml:"unique-identifier,attr"`
+	Metadata         struct {
+		Identifiers []struct {
+			ID    string `xml:"id,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"identifier"`
+		Titles    []string `xml:"title"`
+		Creators  []string `xml:"creator"`
+		Languages []string `xml:"language"`
+		Meta      []struct {
+			Property string `xml:"property,attr"`
+			Name     string `xml:"name,attr"`
+			Content  string `xml:"content,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+var modifiedPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)
+
+// validateEPUB checks the structure of an EPUB 3 container: the OCF
+// mimetype and container, the package metadata, that the manifest and
+// spine match the files in the archive, that every document is well-formed
+// XML and that local links and images resolve. It returns the files.
+func validateEPUB(t *testing.T, data []byte) (map[string][]byte, *epubPackage) {
+	t.Helper()
+
+	// The mimetype must be the first entry, stored, with no extra field
+	if len(data) < 58 || string(data[30:38]) != "mimetype" || string(data[38:58]) != MediaType {
+		t.Fatalf("mimetype is not the first, uncompressed entry")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	if zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Fatalf("first entry is %q (method %d), want stored mimetype", zr.File[0].Name, zr.File[0].Method)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		if _, dup := files[f.Name]; dup {
+			t.Errorf("duplicate entry %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		files[f.Name] = content
+	}
+
+	// Every XML document must be well-formed
+	for name, content := range files {
+		switch path.Ext(name) {
+		case ".xml", ".opf", ".ncx", ".xhtml":
+			decoder := xml.NewDecoder(bytes.NewReader(content))
+			for {
+				if _, err := decoder.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("%s is not well-formed XML: %v", name, err)
+				}
+			}
+		}
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(files["META-INF/container.xml"], &container); err != nil || len(container.Rootfiles) == 0 {
+		t.Fatalf("invalid META-INF/container.xml: %v", err)
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	if container.Rootfiles[0].MediaType != "application/oebps-package+xml" {
+		t.Errorf("rootfile media type is %q", container.Rootfiles[0].MediaType)
+	}
+
+	var pkg epubPackage
+	if err := xml.Unmarshal(files[opfPath], &pkg); err != nil {
+		t.Fatalf("invalid package document %s: %v", opfPath, err)
+	}
+	if pkg.Version != "3.0" {
+		t.Errorf("package version is %q, want 3.0", pkg.Version)
+	}
+	identified := false
+	for _, id := range pkg.Metadata.Identifiers {
+		identified = identified || (id.ID == pkg.UniqueIdentifier && strings.TrimSpace(id.Value) != "")
+	}
+	if !identified {
+		t.Errorf("no dc:identifier matches unique-identifier %q", pkg.UniqueIdentifier)
+	}
+	if len(pkg.Metadata.Titles) == 0 || pkg.Metadata.Titles[0] == "" {
+		t.Error("missing dc:title")
+	}
+	if len(pkg.Metadata.Languages) == 0 || pkg.Metadata.Languages[0] == "" {
+		t.Error("missing dc:language")
+	}
+	modified := ""
+	for _, meta := range pkg.Metadata.Meta {
+		if meta.Property == "dcterms:modified" {
+			modified = meta.Value
+		}
+	}
+	if !modifiedPattern.MatchString(modified) {
+		t.Errorf("dcterms:modified is %q", modified)
+	}
+
+	// The manifest lists every resource in the archive, each exactly once
+	baseDir := path.Dir(opfPath)
+	manifest := make(map[string]string) // id -> full path
+	listed := make(map[string]bool)
+	navs := 0
+	for _, item := range pkg.Manifest {
+		full := path.Join(baseDir, item.Href)
+		if _, ok := files[full]; !ok {
+			t.Errorf("manifest item %s points at missing file %s", item.ID, full)
+		}
+		if _, dup := manifest[item.ID]; dup || listed[full] {
+			t.Errorf("manifest item %s (%s) is listed twice", item.ID, item.Href)
+		}
+		manifest[item.ID] = full
+		listed[full] = true
+		if strings.Contains(item.Properties, "nav") {
+			navs++
+		}
+	}
+	if navs != 1 {
+		t.Errorf("found %d navigation documents, want 1", navs)
+	}
+	for name := range files {
+		if name != "mimetype" && name != opfPath && !strings.HasPrefix(name, "META-INF/") && !listed[name] {
+			t.Errorf("%s is not listed in the manifest", name)
+		}
+	}
+
+	if _, ok := manifest[pkg.Spine.Toc]; pkg.Spine.Toc != "" && !ok {
+		t.Errorf("spine toc %q is not in the manifest", pkg.Spine.Toc)
+	}
+	if len(pkg.Spine.ItemRefs) == 0 {
+		t.Error("spine is empty")
+	}
+	for _, ref := range pkg.Spine.ItemRefs {
+		if _, ok := manifest[ref.IDRef]; !ok {
+			t.Errorf("spine item %q is not in the manifest", ref.IDRef)
+		}
+	}
+
+	// Local links and images in documents resolve to resources
+	reference := regexp.MustCompile(`(?:src|href)="([^"]+)"`)
+	for name, content := range files {
+		if path.Ext(name) != ".xhtml" {
+			continue
+		}
+		for _, match := range reference.FindAllStringSubmatch(string(content), -1) {
+			ref := strings.ReplaceAll(match[1], "&amp;", "&")
+			if u, err := url.Parse(ref); err != nil || u.Scheme != "" {
+				continue
+			}
+			target := path.Join(path.Dir(name), strings.SplitN(ref, "#", 2)[0])
+			if !listed[target] {
+				t.Errorf("%s references %s, which is not in the manifest", name, ref)
+			}
+		}
+	}
+
+	return files, &pkg
+}
+
+func TestWrite_ProducesValidEPUB(t *testing.T) {
+	book := &Book{
+		Identifier:   "urn:uuid:5f0d1c2e-0000-4000-8000-000000000000",
+		Title:        "Weekend <Reading> & More",
+		Author:       "Digests",
+		Contributors: []string{"Jane Doe"},
+		Language:     "en",
+		Modified:     time.Date(2024, 3, 9, 10, 30, 0, 0, time.UTC),
+		Cover:        `<section class="cover"><img src="images/image-001.png" alt=""/><h1>Weekend</h1></section>`,
+		CoverImage:   "images/image-001.png",
+		Chapters: []Chapter{
+			{Title: "First & foremost", Body: `<h1>First</h1><p><img src="../images/image-001.png" alt=""/></p>`},
+			{Title: "Second", Language: "de", Body: `<h1>Zweiter</h1><p>Text</p>`},
+		},
+		Images: []Image{{Href: "images/image-001.png", MediaType: "image/png", Data: []byte("\x89PNG\r\n\x1a\n")}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, book); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	files, pkg := validateEPUB(t, buf.Bytes())
+
+	if pkg.Metadata.Titles[0] != "Weekend <Reading> & More" {
+		t.Errorf("title = %q", pkg.Metadata.Titles[0])
+	}
+	if len(pkg.Metadata.Creators) != 1 || pkg.Metadata.Creators[0] != "Digests" {
+		t.Errorf("creators = %q", pkg.Metadata.Creators)
+	}
+
+	coverMeta := false
+	for _, meta := range pkg.Metadata.Meta {
+		coverMeta = coverMeta || (meta.Name == "cover" && meta.Content == "image-001")
+	}
+	if !coverMeta {
+		t.Error("expected an EPUB 2 cover meta pointing at the cover image")
+	}
+	for _, item := range pkg.Manifest {
+		if item.Href == "images/image-001.png" && item.Properties != "cover-image" {
+			t.Errorf("cover image properties = %q", item.Properties)
+		}
+	}
+
+	// Spine: cover, table of contents, then the chapters in order
+	var spine []string
+	for _, ref := range pkg.Spine.ItemRefs {
+		spine = append(spine, ref.IDRef)
+	}
+	if strings.Join(spine, ",") != "cover,nav,article-001,article-002" {
+		t.Errorf("spine = %v", spine)
+	}
+
+	nav := string(files["EPUB/nav.xhtml"])
+	for _, want := range []string{`epub:type="toc"`, `href="articles/article-001.xhtml">First &amp; foremost</a>`, `href="articles/article-002.xhtml">Second</a>`} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav.xhtml missing %s", want)
+		}
+	}
+	if !strings.Contains(string(files["EPUB/toc.ncx"]), `src="articles/article-002.xhtml"`) {
+		t.Error("toc.ncx is missing the second chapter")
+	}
+	if !strings.Contains(string(files["EPUB/articles/article-002.xhtml"]), `xml:lang="de"`) {
+		t.Error("chapter language was not set")
+	}
+}
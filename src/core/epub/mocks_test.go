@@ -0,0 +1,83 @@
+package epub
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// mockReaderService returns canned reader views by URL
+type mockReaderService struct {
+	views   map[string]domain.ReaderView
+	targets []domain.ReaderTarget
+}
+
+func (m *mockReaderService) ExtractReaderViews(ctx context.Context, urls []string) []domain.ReaderView {
+	targets := make([]domain.ReaderTarget, len(urls))
+	for i, url := range urls {
+		targets[i] = domain.ReaderTarget{URL: url}
+	}
+	return m.ExtractReaderViewsFor(ctx, targets)
+}
+
+func (m *mockReaderService) ExtractReaderViewsFor(ctx context.Context, targets []domain.ReaderTarget) []domain.ReaderView {
+	m.targets = targets
+	views := make([]domain.ReaderView, len(targets))
+	for i, target := range targets {
+		view, ok := m.views[target.URL]
+		if !ok {
+			view = domain.ReaderView{URL: target.URL, Status: "error", Error: "not found"}
+		}
+		views[i] = view
+	}
+	return views
+}
+
+// mockHTTPClient serves canned responses by URL
+type mockHTTPClient struct {
+	responses map[string]*mockResponse
+}
+
+func (m *mockHTTPClient) Get(ctx context.Context, url string) (interfaces.Response, error) {
+	if resp, ok := m.responses[url]; ok {
+		return resp, nil
+	}
+	return &mockResponse{statusCode: 404}, nil
+}
+
+func (m *mockHTTPClient) Post(ctx context.Context, url string, body io.Reader) (interfaces.Response, error) {
+	return nil, nil
+}
+
+// mockResponse is a mock implementation of the Response interface
+type mockResponse struct {
+	statusCode int
+	body       string
+	headers    map[string]string
+}
+
+func (m *mockResponse) StatusCode() int {
+	return m.statusCode
+}
+
+func (m *mockResponse) Body() io.ReadCloser {
+	return io.NopCloser(strings.NewReader(m.body))
+}
+
+func (m *mockResponse) Header(key string) string {
+	if m.headers != nil {
+		return m.headers[key]
+	}
+	return ""
+}
+
+// mockLogger is a mock implementation of the Logger interface
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, fields map[string]interface{}) {}
+func (m *mockLogger) Info(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Warn(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Error(msg string, fields map[string]interface{}) {}
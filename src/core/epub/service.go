@@ -0,0 +1,376 @@
+// ABOUTME: Service layer that exports reader views of articles as an EPUB digest
+// ABOUTME: Fetches article images through the HTTP client and embeds them in the book
+
+package epub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxArticles is the most articles a single book can hold
+	MaxArticles = 20
+
+	// maxImages caps the images embedded in a book; the rest are left out
+	maxImages = 60
+
+	// maxImageSize is the largest image embedded in a book
+	maxImageSize = 5 * 1024 * 1024
+
+	// maxBookImageBytes caps the images embedded in a book altogether; images
+	// past the budget are left out
+	maxBookImageBytes = 20 * 1024 * 1024
+
+	// extractTimeout and imageTimeout bound the two fetch phases of a build so
+	// the book is written well within the server's write timeout; articles and
+	// images still pending when time runs out are left out
+	extractTimeout = 6 * time.Second
+	imageTimeout   = 4 * time.Second
+
+	// imageWorkers is how many images are fetched at once
+	imageWorkers = 6
+
+	// defaultAuthor credits the book when no author is given
+	defaultAuthor = "Digests"
+)
+
+// ErrNoArticles is returned when none of the requested articles could be extracted
+var ErrNoArticles = errors.New("none of the articles could be extracted")
+
+// imageExtensions are the image types embedded in books, by sniffed MIME type
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Service builds EPUB books from reader views
+type Service struct {
+	deps        interfaces.Dependencies
+	reader      interfaces.ReaderService
+	now         func() time.Time
+	imageBudget int
+}
+
+// NewService creates an EPUB service that extracts articles with the reader
+// service and fetches their images through deps.HTTPClient
+func NewService(deps interfaces.Dependencies, reader interfaces.ReaderService) *Service {
+	return &Service{
+		deps:        deps,
+		reader:      reader,
+		now:         time.Now,
+		imageBudget: maxBookImageBytes,
+	}
+}
+
+// BuildEPUB extracts the target articles and writes them as an EPUB book,
+// one chapter per article. Articles that fail to extract are left out.
+func (s *Service) BuildEPUB(ctx context.Context, targets []domain.ReaderTarget, opts domain.EPUBOptions) ([]byte, error) {
+	if len(targets) > MaxArticles {
+		targets = targets[:MaxArticles]
+	}
+
+	extractCtx, cancelExtract := context.WithTimeout(ctx, extractTimeout)
+	extracted := s.reader.ExtractReaderViewsFor(extractCtx, targets)
+	cancelExtract()
+
+	var views []domain.ReaderView
+	for _, view := range extracted {
+		if view.Status != "ok" || strings.TrimSpace(view.Content) == "" {
+			s.deps.Logger.Warn("Leaving article out of EPUB", map[string]interface{}{
+				"url":   view.URL,
+				"error": view.Error,
+			})
+			continue
+		}
+		views = append(views, view)
+	}
+	if len(views) == 0 {
+		return nil, ErrNoArticles
+	}
+
+	book := s.newBook(views, opts)
+	images := s.fetchImages(ctx, views)
+
+	bases := make([]*url.URL, len(views))
+	for i, view := range views {
+		bases[i], _ = url.Parse(view.URL)
+	}
+
+	// Images are added in the order the book first uses them
+	added := make(map[string]bool)
+	useImage := func(src string) (string, bool) {
+		image, ok := images[src]
+		if !ok {
+			return "", false
+		}
+		if !added[image.Href] {
+			added[image.Href] = true
+			book.Images = append(book.Images, image)
+		}
+		return image.Href, true
+	}
+
+	// The first article image that could be fetched is the cover
+	for i, view := range views {
+		if href, ok := useImage(resolve(bases[i], view.Image)); ok {
+			book.CoverImage = href
+			break
+		}
+	}
+	book.Cover = coverBody(book, views)
+
+	for i, view := range views {
+		body := toXHTML(view.Content, bases[i], func(src string) (string, bool) {
+			href, ok := useImage(src)
+			// Chapters live one directory below the images
+			return "../" + href, ok
+		})
+		book.Chapters = append(book.Chapters, Chapter{
+			Title:    chapterTitle(view),
+			Language: view.Language,
+			Body:     chapterHeader(view) + body + chapterFooter(view),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, book); err != nil {
+		return nil, fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newBook fills in the book metadata from the options and the articles
+func (s *Service) newBook(views []domain.ReaderView, opts domain.EPUBOptions) *Book {
+	modified := s.now().UTC().Truncate(time.Second)
+
+	book := &Book{
+		Title:    opts.Title,
+		Author:   opts.Author,
+		Language: opts.Language,
+		Modified: modified,
+	}
+	if book.Title == "" {
+		book.Title = "Digest, " + modified.Format("January 2, 2006")
+	}
+	if book.Author == "" {
+		book.Author = defaultAuthor
+	}
+	if book.Language == "" {
+		for _, view := range views {
+			if view.Language != "" {
+				book.Language = view.Language
+				break
+			}
+		}
+	}
+	if book.Language == "" {
+		book.Language = "en"
+	}
+
+	// The same articles exported on the same day make the same book
+	urls := make([]string, len(views))
+	seenBylines := make(map[string]bool)
+	var sites []string
+	seenSites := make(map[string]bool)
+	for i, view := range views {
+		urls[i] = view.URL
+		if view.Byline != "" && !seenBylines[view.Byline] {
+			seenBylines[view.Byline] = true
+			book.Contributors = append(book.Contributors, view.Byline)
+		}
+		if site := siteName(view); !seenSites[site] {
+			seenSites[site] = true
+			sites = append(sites, site)
+		}
+	}
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(modified.Format("2006-01-02")+"\n"+strings.Join(urls, "\n")))
+	book.Identifier = "urn:uuid:" + id.String()
+	book.Description = fmt.Sprintf("%s from %s", pluralArticles(len(views)), strings.Join(sites, ", "))
+
+	return book
+}
+
+// fetchImages downloads the images of every article, keyed by absolute URL.
+// Images that fail to download, are not JPEG, PNG, GIF or WebP, or would take
+// the book past its image budget are left out.
+func (s *Service) fetchImages(ctx context.Context, views []domain.ReaderView) map[string]Image {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, view := range views {
+		base, _ := url.Parse(view.URL)
+		candidates := append([]string{resolve(base, view.Image)}, imageURLs(view.Content, base)...)
+		for _, src := range candidates {
+			if src != "" && !seen[src] && len(sources) < maxImages {
+				seen[src] = true
+				sources = append(sources, src)
+			}
+		}
+	}
+
+	images := make(map[string]Image)
+	if s.deps.HTTPClient == nil {
+		return images
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, imageTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	remaining := s.imageBudget
+	jobs := make(chan int)
+	for w := 0; w < imageWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				mu.Lock()
+				exhausted := remaining <= 0
+				mu.Unlock()
+				if exhausted {
+					continue
+				}
+
+				data, mediaType, err := s.fetchImage(ctx, sources[index])
+				if err != nil {
+					s.deps.Logger.Debug("Leaving image out of EPUB", map[string]interface{}{
+						"url":   sources[index],
+						"error": err.Error(),
+					})
+					continue
+				}
+
+				mu.Lock()
+				if len(data) > remaining {
+					mu.Unlock()
+					s.deps.Logger.Debug("Leaving image out of EPUB", map[string]interface{}{
+						"url":   sources[index],
+						"error": "book image budget exceeded",
+					})
+					continue
+				}
+				remaining -= len(data)
+				images[sources[index]] = Image{
+					Href:      fmt.Sprintf("images/image-%03d%s", index+1, imageExtensions[mediaType]),
+					MediaType: mediaType,
+					Data:      data,
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for index := range sources {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return images
+}
+
+// fetchImage downloads an image and sniffs its type from its content, as
+// servers often send generic or wrong Content-Type headers
+func (s *Service) fetchImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	resp, err := s.deps.HTTPClient.Get(ctx, imageURL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, "", fmt.Errorf("image returned status code %d", resp.StatusCode())
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body(), maxImageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageSize {
+		return nil, "", errors.New("image is too large")
+	}
+
+	mediaType := http.DetectContentType(data)
+	if _, ok := imageExtensions[mediaType]; !ok {
+		return nil, "", fmt.Errorf("unsupported image type %s", mediaType)
+	}
+	return data, mediaType, nil
+}
+
+// coverBody renders the cover page: cover image, title and list of articles
+func coverBody(book *Book, views []domain.ReaderView) string {
+	var cover strings.Builder
+	cover.WriteString(`<section class="cover" epub:type="cover">` + "\n")
+	if book.CoverImage != "" {
+		fmt.Fprintf(&cover, "<img src=\"%s\" alt=\"\"/>\n", escape(book.CoverImage))
+	}
+	fmt.Fprintf(&cover, "<h1>%s</h1>\n", escape(book.Title))
+	fmt.Fprintf(&cover, "<p class=\"meta\">%s · %s</p>\n", pluralArticles(len(views)), book.Modified.Format("January 2, 2006"))
+	cover.WriteString("<ol>\n")
+	for _, view := range views {
+		fmt.Fprintf(&cover, "<li>%s <span class=\"meta\">%s</span></li>\n", escape(chapterTitle(view)), escape(siteName(view)))
+	}
+	cover.WriteString("</ol>\n</section>")
+	return cover.String()
+}
+
+// chapterHeader renders an article's title and byline
+func chapterHeader(view domain.ReaderView) string {
+	var meta []string
+	if view.Byline != "" {
+		meta = append(meta, view.Byline)
+	}
+	meta = append(meta, siteName(view))
+	if view.PublishedTime != nil {
+		meta = append(meta, view.PublishedTime.Format("January 2, 2006"))
+	}
+	if view.ReadingTimeMinutes > 0 {
+		meta = append(meta, fmt.Sprintf("%d min read", view.ReadingTimeMinutes))
+	}
+
+	return fmt.Sprintf("<h1>%s</h1>\n<p class=\"meta\">%s</p>\n", escape(chapterTitle(view)), escape(strings.Join(meta, " · ")))
+}
+
+// chapterFooter links back to the original article
+func chapterFooter(view domain.ReaderView) string {
+	return fmt.Sprintf("\n<p class=\"source\"><a href=\"%s\">%s</a></p>", escape(view.URL), escape(view.URL))
+}
+
+func chapterTitle(view domain.ReaderView) string {
+	if title := strings.TrimSpace(view.Title); title != "" {
+		return title
+	}
+	return view.URL
+}
+
+// siteName returns the article's site name, falling back to its host
+func siteName(view domain.ReaderView) string {
+	if view.SiteName != "" {
+		return view.SiteName
+	}
+	if u, err := url.Parse(view.URL); err == nil && u.Host != "" {
+		return strings.TrimPrefix(u.Host, "www.")
+	}
+	return view.URL
+}
+
+func pluralArticles(n int) string {
+	if n == 1 {
+		return "1 article"
+	}
+	return fmt.Sprintf("%d articles", n)
+}
@@ -0,0 +1,193 @@
+package epub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// pngData is a PNG header, enough for content sniffing
+const pngData = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func testService(views map[string]domain.ReaderView) (*Service, *mockReaderService) {
+	reader := &mockReaderService{views: views}
+	client := &mockHTTPClient{responses: map[string]*mockResponse{
+		"https://example.com/hero.png":   {statusCode: 200, body: pngData},
+		"https://example.com/inline.png": {statusCode: 200, body: pngData},
+		"https://example.com/page.html":  {statusCode: 200, body: "<html><body>not an image</body></html>"},
+	}}
+	service := NewService(interfaces.Dependencies{HTTPClient: client, Logger: &mockLogger{}}, reader)
+	service.now = func() time.Time { return time.Date(2024, 3, 9, 10, 30, 0, 0, time.UTC) }
+	return service, reader
+}
+
+func TestService_BuildEPUB(t *testing.T) {
+	published := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	service, reader := testService(map[string]domain.ReaderView{
+		"https://example.com/one": {
+			URL:                "https://example.com/one",
+			Title:              "Rust & Go",
+			Byline:             "Jane Doe",
+			SiteName:           "Example Blog",
+			Image:              "https://example.com/hero.png",
+			Language:           "en",
+			PublishedTime:      &published,
+			ReadingTimeMinutes: 4,
+			Content:            `<div><p>Intro</p><img src="/inline.png"><img src="/missing.png"><img src="/page.html"><p>Body</p></div>`,
+			Status:             "ok",
+		},
+		"https://news.example.org/two": {
+			URL:     "https://news.example.org/two",
+			Title:   "Second story",
+			Byline:  "John Roe",
+			Content: `<p>Second <img src="https://example.com/hero.png"></p>`,
+			Status:  "ok",
+		},
+	})
+
+	data, err := service.BuildEPUB(context.Background(), []domain.ReaderTarget{
+		{URL: "https://example.com/one"},
+		{URL: "https://example.com/broken"},
+		{URL: "https://news.example.org/two"},
+	}, domain.EPUBOptions{Title: "Weekend reading"})
+	if err != nil {
+		t.Fatalf("BuildEPUB() error = %v", err)
+	}
+	if len(reader.targets) != 3 {
+		t.Errorf("Expected every target to go through the reader service, got %d", len(reader.targets))
+	}
+
+	files, pkg := validateEPUB(t, data)
+
+	// Metadata comes from the options and the reader views
+	if pkg.Metadata.Titles[0] != "Weekend reading" {
+		t.Errorf("title = %q", pkg.Metadata.Titles[0])
+	}
+	if pkg.Metadata.Languages[0] != "en" {
+		t.Errorf("language = %q", pkg.Metadata.Languages[0])
+	}
+	opf := string(files["EPUB/package.opf"])
+	for _, want := range []string{"<dc:creator>Digests</dc:creator>", "<dc:contributor>Jane Doe</dc:contributor>", "<dc:contributor>John Roe</dc:contributor>", "2 articles from Example Blog, news.example.org"} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("package.opf missing %s", want)
+		}
+	}
+
+	// The broken article is left out; the others are chapters in order
+	chapters := 0
+	for _, item := range pkg.Manifest {
+		if strings.HasPrefix(item.Href, "articles/") {
+			chapters++
+		}
+	}
+	if chapters != 2 {
+		t.Errorf("Expected 2 chapters, got %d", chapters)
+	}
+	first := string(files["EPUB/articles/article-001.xhtml"])
+	for _, want := range []string{"<h1>Rust &amp; Go</h1>", "Jane Doe · Example Blog · March 1, 2024 · 4 min read", `src="../images/image-002.png"`, `href="https://example.com/one"`} {
+		if !strings.Contains(first, want) {
+			t.Errorf("first chapter missing %s:\n%s", want, first)
+		}
+	}
+	if strings.Contains(first, "missing.png") || strings.Contains(first, "page.html") {
+		t.Errorf("Expected images that failed to download to be dropped:\n%s", first)
+	}
+
+	// The hero image is the cover and is stored once, though two articles use it
+	images := 0
+	for _, item := range pkg.Manifest {
+		if strings.HasPrefix(item.Href, "images/") {
+			images++
+			if item.Href == "images/image-001.png" && item.Properties != "cover-image" {
+				t.Errorf("Expected the hero image to be the cover, got properties %q", item.Properties)
+			}
+		}
+	}
+	if images != 2 {
+		t.Errorf("Expected 2 embedded images, got %d", images)
+	}
+	if !strings.Contains(string(files["EPUB/articles/article-002.xhtml"]), `src="../images/image-001.png"`) {
+		t.Error("Expected the second article to reuse the embedded hero image")
+	}
+
+	cover := string(files["EPUB/cover.xhtml"])
+	for _, want := range []string{`src="images/image-001.png"`, "<h1>Weekend reading</h1>", "2 articles · March 9, 2024", "Second story"} {
+		if !strings.Contains(cover, want) {
+			t.Errorf("cover missing %s:\n%s", want, cover)
+		}
+	}
+}
+
+func TestService_BuildEPUB_Defaults(t *testing.T) {
+	service, _ := testService(map[string]domain.ReaderView{
+		"https://example.de/artikel": {URL: "https://example.de/artikel", Language: "de", Content: "<p>Hallo</p>", Status: "ok"},
+	})
+
+	data, err := service.BuildEPUB(context.Background(), []domain.ReaderTarget{{URL: "https://example.de/artikel"}}, domain.EPUBOptions{})
+	if err != nil {
+		t.Fatalf("BuildEPUB() error = %v", err)
+	}
+
+	files, pkg := validateEPUB(t, data)
+	if pkg.Metadata.Titles[0] != "Digest, March 9, 2024" {
+		t.Errorf("title = %q", pkg.Metadata.Titles[0])
+	}
+	if pkg.Metadata.Languages[0] != "de" {
+		t.Errorf("language = %q", pkg.Metadata.Languages[0])
+	}
+	// Untitled articles are named after their URL
+	if !strings.Contains(string(files["EPUB/nav.xhtml"]), ">https://example.de/artikel</a>") {
+		t.Error("Expected the untitled article to be listed by URL")
+	}
+
+	// The same articles on the same day make the same book
+	again, _ := service.BuildEPUB(context.Background(), []domain.ReaderTarget{{URL: "https://example.de/artikel"}}, domain.EPUBOptions{})
+	_, pkgAgain := validateEPUB(t, again)
+	if pkg.Metadata.Identifiers[0].Value != pkgAgain.Metadata.Identifiers[0].Value {
+		t.Error("Expected a stable book identifier")
+	}
+}
+
+func TestService_BuildEPUB_NoArticles(t *testing.T) {
+	service, _ := testService(nil)
+
+	_, err := service.BuildEPUB(context.Background(), []domain.ReaderTarget{{URL: "https://example.com/broken"}}, domain.EPUBOptions{})
+	if !errors.Is(err, ErrNoArticles) {
+		t.Errorf("Expected ErrNoArticles, got %v", err)
+	}
+}
+
+func TestService_BuildEPUB_ImageBudget(t *testing.T) {
+	service, _ := testService(map[string]domain.ReaderView{
+		"https://example.com/one": {
+			URL:     "https://example.com/one",
+			Title:   "Pictures",
+			Image:   "https://example.com/hero.png",
+			Content: `<p>Intro <img src="/inline.png"></p>`,
+			Status:  "ok",
+		},
+	})
+	// Room for one image only
+	service.imageBudget = len(pngData)
+
+	data, err := service.BuildEPUB(context.Background(), []domain.ReaderTarget{{URL: "https://example.com/one"}}, domain.EPUBOptions{})
+	if err != nil {
+		t.Fatalf("BuildEPUB() error = %v", err)
+	}
+
+	_, pkg := validateEPUB(t, data)
+	images := 0
+	for _, item := range pkg.Manifest {
+		if strings.HasPrefix(item.Href, "images/") {
+			images++
+		}
+	}
+	if images != 1 {
+		t.Errorf("Expected 1 embedded image within the budget, got %d", images)
+	}
+}
@@ -0,0 +1,217 @@
+// ABOUTME: Converts article HTML into well-formed XHTML for EPUB chapters
+// ABOUTME: Keeps text-level markup, drops scripts and embeds, and rewrites image sources
+
+package epub
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// droppedElements are removed along with everything inside them
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Frame: true, atom.Frameset: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+	atom.Video: true, atom.Audio: true, atom.Source: true, atom.Track: true, atom.Canvas: true,
+	atom.Svg: true, atom.Math: true, atom.Link: true, atom.Meta: true, atom.Title: true,
+	atom.Head: true, atom.Dialog: true,
+}
+
+// keptElements are written out; other elements are replaced by their content
+var keptElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Span: true, atom.A: true, atom.Br: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Em: true, atom.Strong: true, atom.B: true, atom.I: true, atom.U: true, atom.S: true,
+	atom.Sub: true, atom.Sup: true, atom.Small: true, atom.Mark: true, atom.Del: true, atom.Ins: true,
+	atom.Abbr: true, atom.Cite: true, atom.Q: true, atom.Time: true, atom.Code: true, atom.Kbd: true,
+	atom.Samp: true, atom.Var: true, atom.Pre: true, atom.Blockquote: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Img: true,
+	atom.Table: true, atom.Caption: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true,
+	atom.Tr: true, atom.Th: true, atom.Td: true,
+	atom.Section: true, atom.Article: true, atom.Aside: true, atom.Header: true, atom.Footer: true,
+}
+
+// voidElements have no content and are self-closed
+var voidElements = map[atom.Atom]bool{
+	atom.Br: true, atom.Hr: true, atom.Img: true,
+}
+
+// keptAttributes are copied for any kept element; href and src are handled separately
+var keptAttributes = map[string]bool{
+	"alt": true, "title": true, "datetime": true, "colspan": true, "rowspan": true, "lang": true,
+}
+
+// imageSource maps an image URL found in content to its path in the book,
+// or returns false to drop the image
+type imageSource func(src string) (string, bool)
+
+// toXHTML converts an HTML fragment into XHTML. Relative links are resolved
+// against base and images are rewritten with images.
+func toXHTML(content string, base *url.URL, images imageSource) string {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return "<p>" + escape(content) + "</p>"
+	}
+
+	var out strings.Builder
+	for _, node := range nodes {
+		writeXHTML(&out, node, base, images)
+	}
+	return out.String()
+}
+
+// imageURLs lists the absolute image URLs of an HTML fragment in document order
+func imageURLs(content string, base *url.URL) []string {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil
+	}
+
+	var urls []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && droppedElements[n.DataAtom] {
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			if src := resolve(base, attr(n, "src")); src != "" {
+				urls = append(urls, src)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return urls
+}
+
+func writeXHTML(out *strings.Builder, n *html.Node, base *url.URL, images imageSource) {
+	switch n.Type {
+	case html.TextNode:
+		out.WriteString(escape(n.Data))
+		return
+	case html.DocumentNode:
+		writeChildren(out, n, base, images)
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes are dropped
+		return
+	}
+
+	if droppedElements[n.DataAtom] {
+		return
+	}
+	if !keptElements[n.DataAtom] {
+		writeChildren(out, n, base, images)
+		return
+	}
+
+	var attrs strings.Builder
+	for _, a := range n.Attr {
+		if a.Namespace == "" && keptAttributes[a.Key] {
+			attrs.WriteString(" " + a.Key + `="` + escape(a.Val) + `"`)
+		}
+	}
+
+	switch n.DataAtom {
+	case atom.Img:
+		src := resolve(base, attr(n, "src"))
+		if src == "" {
+			return
+		}
+		href, ok := images(src)
+		if !ok {
+			return
+		}
+		if !hasAttr(n, "alt") {
+			attrs.WriteString(` alt=""`)
+		}
+		attrs.WriteString(` src="` + escape(href) + `"`)
+	case atom.A:
+		// Only web and mail links survive, pointing at the original page
+		if href := resolve(base, attr(n, "href")); strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "mailto:") {
+			attrs.WriteString(` href="` + escape(href) + `"`)
+		}
+	}
+
+	out.WriteString("<" + n.Data + attrs.String())
+	if voidElements[n.DataAtom] {
+		out.WriteString("/>")
+		return
+	}
+	out.WriteString(">")
+	writeChildren(out, n, base, images)
+	out.WriteString("</" + n.Data + ">")
+}
+
+func writeChildren(out *strings.Builder, n *html.Node, base *url.URL, images imageSource) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeXHTML(out, c, base, images)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve makes a link absolute, returning "" for links that cannot be parsed
+func resolve(base *url.URL, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// escape escapes text for XML and drops characters XML does not allow
+func escape(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '<':
+			out.WriteString("&lt;")
+		case r == '>':
+			out.WriteString("&gt;")
+		case r == '&':
+			out.WriteString("&amp;")
+		case r == '"':
+			out.WriteString("&quot;")
+		case r == '\t' || r == '\n' || r == '\r':
+			out.WriteRune(r)
+		case r < 0x20 || r == 0xFFFE || r == 0xFFFF || (r >= 0xD800 && r <= 0xDFFF):
+			continue
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
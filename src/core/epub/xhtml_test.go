@@ -0,0 +1,52 @@
+package epub
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestToXHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/story")
+	content := `<div class="article"><h2 id="x">Heading</h2>
+<p style="color:red" onclick="evil()">Text &amp; <b>bold</b><br>line<script>alert(1)</script></p>
+<picture><source srcset="a.webp"><img src="/img/a.jpg" width="10"></picture>
+<img src="https://tracker.example.com/pixel.gif">
+<p><a href="/other">relative</a> <a href="javascript:alert(1)">script</a> <a href="#top">fragment</a></p>
+<iframe src="https://video.example.com"></iframe><custom-widget>kept text</custom-widget>
+<!-- comment --><p>bad&#1;char</p></div>`
+
+	var requested []string
+	got := toXHTML(content, base, func(src string) (string, bool) {
+		requested = append(requested, src)
+		if strings.Contains(src, "tracker") {
+			return "", false
+		}
+		return "../images/image-001.jpg", true
+	})
+
+	for _, want := range []string{
+		`<div><h2>Heading</h2>`,
+		`<p>Text &amp; <b>bold</b><br/>line</p>`,
+		`<img alt="" src="../images/image-001.jpg"/>`,
+		`<a href="https://example.com/other">relative</a> <a>script</a> <a href="https://example.com/posts/story#top">fragment</a>`,
+		`kept text`,
+		`<p>badchar</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("toXHTML() missing %s in:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"script>", "alert", "iframe", "style=", "onclick", "source", "tracker", "comment", "custom-widget", "width="} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("toXHTML() kept %q in:\n%s", unwanted, got)
+		}
+	}
+
+	if len(requested) != 2 || requested[0] != "https://example.com/img/a.jpg" {
+		t.Errorf("requested images = %q", requested)
+	}
+	if urls := imageURLs(content, base); strings.Join(urls, ",") != strings.Join(requested, ",") {
+		t.Errorf("imageURLs() = %q, want %q", urls, requested)
+	}
+}
@@ -27,15 +27,98 @@ import (
 // FeedService handles feed parsing and management
 type FeedService struct {
 	deps interfaces.Dependencies
+
+	// sources holds adapters for non-RSS source URLs, keyed by scheme
+	sources   map[string]interfaces.FeedSource
+	sourcesMu sync.RWMutex
+
+	// indexer receives every successfully parsed feed for local search
+	indexer interfaces.FeedIndexer
+
+	// indexed holds the URLs of feeds indexed since the service started, so
+	// cache hits only index feeds the index has not seen yet
+	indexed   map[string]bool
+	indexedMu sync.Mutex
+
+	// languageDetector sets the languages of parsed feeds and their items
+	languageDetector interfaces.LanguageDetector
 }
 
 // NewFeedService creates a new feed service instance
 func NewFeedService(deps interfaces.Dependencies) *FeedService {
 	return &FeedService{
-		deps: deps,
+		deps:    deps,
+		sources: make(map[string]interfaces.FeedSource),
+		indexed: make(map[string]bool),
+	}
+}
+
+// RegisterSource registers an adapter for source URLs using its scheme.
+// Feeds produced by a source are cached the same way as RSS/Atom feeds.
+func (s *FeedService) RegisterSource(source interfaces.FeedSource) {
+	s.sourcesMu.Lock()
+	defer s.sourcesMu.Unlock()
+	
+	if s.sources == nil {
+		s.sources = make(map[string]interfaces.FeedSource)
+	}
+	s.sources[strings.ToLower(source.Scheme())] = source
+}
+
+// SetIndexer registers an indexer that receives every parsed feed.
+// It must be called before the service starts handling requests.
+func (s *FeedService) SetIndexer(indexer interfaces.FeedIndexer) {
+	s.indexer = indexer
+}
+
+// SetLanguageDetector registers a detector that sets the languages of every
+// parsed feed before it is cached.
+// It must be called before the service starts handling requests.
+func (s *FeedService) SetLanguageDetector(detector interfaces.LanguageDetector) {
+	s.languageDetector = detector
+}
+
+// indexFeed hands a parsed feed to the indexer, if one is configured.
+// Unless fresh, feeds already indexed under feedURL are skipped.
+func (s *FeedService) indexFeed(ctx context.Context, feedURL string, feed *domain.Feed, fresh bool) {
+	if s.indexer == nil || feed == nil {
+		return
+	}
+
+	s.indexedMu.Lock()
+	if s.indexed == nil {
+		s.indexed = make(map[string]bool)
+	}
+	seen := s.indexed[feedURL]
+	s.indexed[feedURL] = true
+	s.indexedMu.Unlock()
+	if seen && !fresh {
+		return
+	}
+	if err := s.indexer.IndexFeed(ctx, feed); err != nil && s.deps.Logger != nil {
+		s.deps.Logger.Warn("Failed to index feed", map[string]interface{}{
+			"url":   feed.URL,
+			"error": err.Error(),
+		})
 	}
 }
 
+// sourceFor returns the registered source for a URL, if any.
+// Both "scheme://..." and "scheme+https://..." forms are recognised.
+func (s *FeedService) sourceFor(u *url.URL) interfaces.FeedSource {
+	scheme := strings.ToLower(u.Scheme)
+	if idx := strings.Index(scheme, "+"); idx > 0 {
+		scheme = scheme[:idx]
+	}
+	if scheme == "" || scheme == "http" || scheme == "https" {
+		return nil
+	}
+	
+	s.sourcesMu.RLock()
+	defer s.sourcesMu.RUnlock()
+	return s.sources[scheme]
+}
+
 // ParseFeeds parses one or more feeds concurrently
 func (s *FeedService) ParseFeeds(ctx context.Context, urls []string) ([]*domain.Feed, error) {
 	if len(urls) == 0 {
@@ -63,25 +146,48 @@ func (s *FeedService) parseSingleFeed(ctx context.Context, feedURL string) (*dom
 	}
 
 	parsedURL, err := url.Parse(feedURL)
-	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL format: %s", feedURL)
+	}
+	
+	source := s.sourceFor(parsedURL)
+	if source == nil && (parsedURL.Scheme == "" || parsedURL.Host == "") {
 		return nil, fmt.Errorf("invalid URL format: %s", feedURL)
 	}
 
 	// Check cache first
 	cachedFeed, err := s.getCachedFeed(ctx, feedURL)
 	if err == nil && cachedFeed != nil {
+		// Cached feeds may predate the index (e.g. a shared cache after a
+		// restart), so each one is indexed the first time it is served
+		s.indexFeed(ctx, feedURL, cachedFeed, false)
 		return cachedFeed, nil
 	}
 
-	// Fetch and parse the feed
-	feed, err := s.fetchAndParseFeed(ctx, feedURL)
+	// Fetch and parse the feed, delegating to a source adapter if one matches
+	var feed *domain.Feed
+	if source != nil {
+		feed, err = source.FetchFeed(ctx, feedURL)
+		if err == nil && feed == nil {
+			err = fmt.Errorf("source %s returned no feed", source.Scheme())
+		}
+	} else {
+		feed, err = s.fetchAndParseFeed(ctx, feedURL)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// Detect languages before caching, so cached feeds carry them
+	if s.languageDetector != nil {
+		s.languageDetector.DetectFeed(ctx, feed)
+	}
+
 	// Cache the feed (ignore cache errors)
 	_ = s.cacheFeed(ctx, feedURL, feed)
 
+	s.indexFeed(ctx, feedURL, feed, true)
+
 	return feed, nil
 }
 
@@ -154,6 +260,7 @@ func (s *FeedService) parseFeedContent(content []byte, feedURL string) (*domain.
 		Description: parsedFeed.Description,
 		URL:         feedURL,         // Keep the actual RSS URL
 		Link:        parsedFeed.Link, // Website link
+		SelfURL:     parsedFeed.FeedLink,
 		Items:       make([]domain.FeedItem, 0, len(parsedFeed.Items)),
 		Language:    parsedFeed.Language,
 		FeedType:    detectFeedType(parsedFeed),
@@ -278,7 +385,10 @@ func (s *FeedService) convertItemToDomain(item *gofeed.Item, feed *gofeed.Feed)
 	}
 
 	// Thumbnail discovery logic (following old implementation)
-	feedItem.Thumbnail = s.findThumbnail(item, feed)
+	feedItem.ThumbnailCandidates = s.thumbnailCandidates(item, feed)
+	if len(feedItem.ThumbnailCandidates) > 0 {
+		feedItem.Thumbnail = feedItem.ThumbnailCandidates[0]
+	}
 
 	// iTunes extensions
 	if item.ITunesExt != nil {
@@ -299,37 +409,51 @@ func (s *FeedService) convertItemToDomain(item *gofeed.Item, feed *gofeed.Feed)
 	return feedItem
 }
 
-// findThumbnail finds thumbnail from various sources
-func (s *FeedService) findThumbnail(item *gofeed.Item, feed *gofeed.Feed) string {
+// thumbnailCandidates lists every image an item's thumbnail can come from,
+// best first, so a broken or generic image can be skipped for the next
+func (s *FeedService) thumbnailCandidates(item *gofeed.Item, feed *gofeed.Feed) []string {
+	var candidates []string
+	add := func(url string) {
+		if url == "" {
+			return
+		}
+		for _, existing := range candidates {
+			if existing == url {
+				return
+			}
+		}
+		candidates = append(candidates, url)
+	}
+
 	// Priority order matching old implementation:
 	// 1. iTunes extension image
-	if item.ITunesExt != nil && item.ITunesExt.Image != "" {
-		return item.ITunesExt.Image
+	if item.ITunesExt != nil {
+		add(item.ITunesExt.Image)
 	}
 	
 	// 2. Image enclosures
 	for _, enc := range item.Enclosures {
-		if enc.URL != "" && strings.HasPrefix(enc.Type, "image/") {
-			return enc.URL
+		if strings.HasPrefix(enc.Type, "image/") {
+			add(enc.URL)
 		}
 	}
 	
 	// 3. Item image
-	if item.Image != nil && item.Image.URL != "" {
-		return item.Image.URL
+	if item.Image != nil {
+		add(item.Image.URL)
 	}
 	
 	// 4. Feed level iTunes image
-	if feed != nil && feed.ITunesExt != nil && feed.ITunesExt.Image != "" {
-		return feed.ITunesExt.Image
+	if feed != nil && feed.ITunesExt != nil {
+		add(feed.ITunesExt.Image)
 	}
 	
 	// 5. Feed image
-	if feed != nil && feed.Image != nil && feed.Image.URL != "" {
-		return feed.Image.URL
+	if feed != nil && feed.Image != nil {
+		add(feed.Image.URL)
 	}
 	
-	return ""
+	return candidates
 }
 
 // detectFeedType determines if this is an article feed or podcast
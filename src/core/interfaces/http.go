@@ -18,6 +18,16 @@ type HTTPClient interface {
 	Post(ctx context.Context, url string, body io.Reader) (Response, error)
 }
 
+// HeaderHTTPClient is implemented by HTTP clients that can send extra request
+// headers, such as API credentials. Callers should type-assert for it and fall
+// back to HTTPClient.Get when it is not available.
+type HeaderHTTPClient interface {
+	HTTPClient
+
+	// GetWithHeaders performs an HTTP GET request with additional headers.
+	GetWithHeaders(ctx context.Context, url string, headers map[string]string) (Response, error)
+}
+
 // Response defines the interface for HTTP responses.
 // This abstraction allows different HTTP client implementations to provide
 // their own response types while maintaining a consistent interface.
@@ -20,28 +20,100 @@ type FeedService interface {
 	ParseFeedsWithConfig(ctx context.Context, urls []string, config interface{}) ([]*domain.Feed, error)
 }
 
+// FeedSource produces feeds from non-RSS sources addressed by a custom URL scheme.
+// A source registered for "sitemap" handles URLs such as "sitemap+https://example.com/sitemap.xml".
+type FeedSource interface {
+	// Scheme returns the URL scheme prefix handled by this source
+	Scheme() string
+	
+	// FetchFeed builds a feed for the given source URL
+	FetchFeed(ctx context.Context, sourceURL string) (*domain.Feed, error)
+}
+
 // SearchService defines the interface for RSS feed discovery operations
 type SearchService interface {
 	// SearchRSSFeeds searches for RSS feeds using an external API
 	SearchRSSFeeds(ctx context.Context, query string) ([]domain.SearchResult, error)
 }
 
+// SearchProvider is a feed directory that can be searched by keyword
+type SearchProvider interface {
+	// Name identifies the provider in results and logs
+	Name() string
+
+	// Search returns up to limit feeds matching the query, best matches first
+	Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error)
+}
+
+// FeedIndexer receives feeds as they are parsed so they can be searched
+type FeedIndexer interface {
+	// IndexFeed adds or updates a feed and its items in the index
+	IndexFeed(ctx context.Context, feed *domain.Feed) error
+}
+
+// ItemSearchService defines full-text search over indexed feeds and items
+type ItemSearchService interface {
+	// SearchItems runs a ranked full-text query with facets and highlighting
+	SearchItems(ctx context.Context, query domain.ItemSearchQuery) (*domain.ItemSearchResults, error)
+}
+
 // ShareService defines the interface for URL sharing operations
 type ShareService interface {
 	// CreateShare creates a new share with the given URLs
 	CreateShare(ctx context.Context, urls []string) (*domain.Share, error)
+
+	// CreateShareWithOptions creates a new share with a title, description and expiry
+	CreateShareWithOptions(ctx context.Context, urls []string, opts domain.ShareOptions) (*domain.Share, error)
 	
 	// GetShare retrieves a share by ID
 	GetShare(ctx context.Context, id string) (*domain.Share, error)
 }
 
+// ScraperService defines operations for user-defined page scrapers
+type ScraperService interface {
+	// CreateScraper validates and stores a new scraper definition
+	CreateScraper(ctx context.Context, def domain.ScraperDefinition) (*domain.ScraperDefinition, error)
+	
+	// GetScraper retrieves a scraper definition by ID
+	GetScraper(ctx context.Context, id string) (*domain.ScraperDefinition, error)
+	
+	// ListScrapers returns all stored scraper definitions
+	ListScrapers(ctx context.Context) ([]*domain.ScraperDefinition, error)
+	
+	// DeleteScraper removes a scraper definition
+	DeleteScraper(ctx context.Context, id string) error
+	
+	// PreviewScraper extracts a feed from the live page without storing anything
+	PreviewScraper(ctx context.Context, def domain.ScraperDefinition) (*domain.Feed, error)
+}
+
+// JSONMappingService defines operations for user-defined JSON API mappings
+type JSONMappingService interface {
+	// CreateMapping validates and stores a new mapping
+	CreateMapping(ctx context.Context, mapping domain.JSONMapping) (*domain.JSONMapping, error)
+	
+	// GetMapping retrieves a mapping by ID
+	GetMapping(ctx context.Context, id string) (*domain.JSONMapping, error)
+	
+	// ListMappings returns all stored mappings
+	ListMappings(ctx context.Context) ([]*domain.JSONMapping, error)
+	
+	// DeleteMapping removes a mapping
+	DeleteMapping(ctx context.Context, id string) error
+	
+	// PreviewMapping maps the live document without storing anything
+	PreviewMapping(ctx context.Context, mapping domain.JSONMapping) (*domain.Feed, error)
+}
+
 // ContentEnrichmentService defines the interface for content enrichment operations
 type ContentEnrichmentService interface {
-	// ExtractMetadata extracts metadata from a URL
-	ExtractMetadata(ctx context.Context, url string) (*MetadataResult, error)
+	// ExtractMetadata extracts metadata from a URL; when the page cannot be
+	// read, the returned metadata carries the error as well
+	ExtractMetadata(ctx context.Context, url string) (*domain.PageMetadata, error)
 	
-	// ExtractMetadataBatch extracts metadata for multiple URLs
-	ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*MetadataResult
+	// ExtractMetadataBatch extracts metadata for multiple URLs, with an entry
+	// for every URL; pages that could not be read have Error set
+	ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*domain.PageMetadata
 	
 	// ExtractColor extracts the prominent color from an image URL
 	ExtractColor(ctx context.Context, imageURL string) (*domain.RGBColor, error)
@@ -51,22 +123,100 @@ type ContentEnrichmentService interface {
 	
 	// GetCachedColor retrieves a cached color without computing
 	GetCachedColor(ctx context.Context, imageURL string) (*domain.RGBColor, error)
+	
+	// ExtractPalette extracts the color palette of an image URL
+	ExtractPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error)
+	
+	// ExtractPaletteBatch extracts palettes for multiple URLs
+	ExtractPaletteBatch(ctx context.Context, imageURLs []string) map[string]*domain.ColorPalette
+	
+	// GetCachedPalette retrieves a cached palette without computing
+	GetCachedPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error)
+	
+	// GetCachedPlaceholder retrieves a cached image placeholder without computing;
+	// placeholders are computed along with palettes
+	GetCachedPlaceholder(ctx context.Context, imageURL string) (*domain.ImagePlaceholder, error)
+	
+	// SelectThumbnails sets each item's thumbnail to its first candidate not
+	// found broken, tiny, blank or shared by the whole feed, recording why
+	// candidates were skipped. Images are checked when their palettes are
+	// extracted; unchecked candidates are used as they are.
+	SelectThumbnails(ctx context.Context, feeds []*domain.Feed)
+}
+
+// EnrichmentJobService runs enrichment in the background as jobs clients can poll
+type EnrichmentJobService interface {
+	// StartThumbnailJob starts computing the palettes and placeholders of
+	// images, and returns the job with every image pending
+	StartThumbnailJob(ctx context.Context, imageURLs []string) (*domain.EnrichmentJob, error)
+
+	// GetJob returns a job with the results computed so far
+	GetJob(ctx context.Context, id string) (*domain.EnrichmentJob, error)
+}
+
+// JobQueue runs enrichment work for URLs in the background, retrying
+// failures, and survives restarts when its storage does
+type JobQueue interface {
+	// Enqueue queues a job for a URL unless one is already queued
+	Enqueue(ctx context.Context, jobType, url string) error
+
+	// Job returns the queued job of a type for a URL, or nil when none is
+	// queued, as jobs are removed once they succeed
+	Job(ctx context.Context, jobType, url string) (*domain.QueuedJob, error)
+
+	// Stats returns the queue depth and failed jobs
+	Stats(ctx context.Context) (*domain.QueueStats, error)
+}
+
+// SiteRuleMatcher finds the per-site extraction rule for a host
+type SiteRuleMatcher interface {
+	Match(host string) *domain.SiteRule
 }
 
 // ReaderService defines operations for reader view extraction
 type ReaderService interface {
 	ExtractReaderViews(ctx context.Context, urls []string) []domain.ReaderView
+
+	// ExtractReaderViewsFor extracts views for pages with optional feed item context
+	ExtractReaderViewsFor(ctx context.Context, targets []domain.ReaderTarget) []domain.ReaderView
 }
 
-// MetadataResult represents the result of metadata extraction
-type MetadataResult struct {
-	Title       string
-	Description string
-	Thumbnail   string
-	Author      string
-	Published   string
-	Images      []string
-	ThemeColor  string
-	Favicon     string
-	Domain      string
-}
\ No newline at end of file
+// EPUBService exports articles as e-books
+type EPUBService interface {
+	// BuildEPUB extracts the target articles and returns them as an EPUB 3 book
+	BuildEPUB(ctx context.Context, targets []domain.ReaderTarget, opts domain.EPUBOptions) ([]byte, error)
+}
+
+// Summarizer picks the key sentences of articles and feed items
+type Summarizer interface {
+	// Summarize returns up to the given number of key sentences; 0 uses the default
+	Summarize(ctx context.Context, text string, sentences int) []string
+
+	// SummarizeItems sets KeySentences on each item from its content
+	SummarizeItems(ctx context.Context, items []*domain.FeedItem, sentences int)
+}
+
+// Tagger computes keywords and key phrases of feed items
+type Tagger interface {
+	// TagItems sets AutoTags on each item; 0 tags uses the default count
+	TagItems(ctx context.Context, items []*domain.FeedItem, tags int)
+}
+
+// StoryClusterer groups near-identical items of feeds into stories
+type StoryClusterer interface {
+	// ClusterFeeds sets ClusterID on items in a story with other items and
+	// returns the stories, newest first
+	ClusterFeeds(ctx context.Context, feeds []*domain.Feed) []domain.StoryCluster
+}
+
+// ImageProxyService fetches images and serves them resized and re-encoded
+type ImageProxyService interface {
+	// ProcessImage returns an image resized and re-encoded as the options ask
+	ProcessImage(ctx context.Context, imageURL string, opts domain.ImageOptions) (*domain.ProcessedImage, error)
+}
+
+// LanguageDetector identifies the languages of feeds and their items
+type LanguageDetector interface {
+	// DetectFeed sets Language on each item, and on the feed when it declares none
+	DetectFeed(ctx context.Context, feed *domain.Feed)
+}
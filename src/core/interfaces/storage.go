@@ -5,6 +5,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 	
 	"digests-app-api/core/domain"
 )
@@ -16,4 +17,65 @@ type ShareStorage interface {
 
 	// Get retrieves a share by ID
 	Get(ctx context.Context, id string) (*domain.Share, error)
-}
\ No newline at end of file
+}
+
+// ScraperStorage defines the interface for scraper definition persistence
+type ScraperStorage interface {
+	// Save persists a scraper definition, replacing any with the same ID
+	Save(ctx context.Context, def *domain.ScraperDefinition) error
+
+	// Get retrieves a scraper definition by ID, returning nil if it does not exist
+	Get(ctx context.Context, id string) (*domain.ScraperDefinition, error)
+
+	// List returns all scraper definitions
+	List(ctx context.Context) ([]*domain.ScraperDefinition, error)
+
+	// Delete removes a scraper definition by ID
+	Delete(ctx context.Context, id string) error
+}
+
+// JSONMappingStorage defines the interface for JSON mapping persistence
+type JSONMappingStorage interface {
+	// Save persists a mapping, replacing any with the same ID
+	Save(ctx context.Context, mapping *domain.JSONMapping) error
+
+	// Get retrieves a mapping by ID, returning nil if it does not exist
+	Get(ctx context.Context, id string) (*domain.JSONMapping, error)
+
+	// List returns all mappings
+	List(ctx context.Context) ([]*domain.JSONMapping, error)
+
+	// Delete removes a mapping by ID
+	Delete(ctx context.Context, id string) error
+}
+
+// JobQueueStorage defines the interface for background job persistence
+type JobQueueStorage interface {
+	// Enqueue stores a job unless one with the same ID exists, reporting
+	// whether it was stored
+	Enqueue(ctx context.Context, job *domain.QueuedJob) (bool, error)
+
+	// Claim marks the due job of a type that has waited longest as running
+	// and returns it, or nil when no job is due. Running jobs whose lease
+	// expired are due again.
+	Claim(ctx context.Context, jobType string, now time.Time, lease time.Duration) (*domain.QueuedJob, error)
+
+	// Save updates a job
+	Save(ctx context.Context, job *domain.QueuedJob) error
+
+	// Get retrieves a job by ID, returning nil if it does not exist
+	Get(ctx context.Context, id string) (*domain.QueuedJob, error)
+
+	// Delete removes a job by ID
+	Delete(ctx context.Context, id string) error
+
+	// List returns the jobs with a status, or all jobs when status is empty
+	List(ctx context.Context, status string) ([]*domain.QueuedJob, error)
+
+	// Count returns the number of jobs by type and status
+	Count(ctx context.Context) (map[string]map[string]int, error)
+
+	// PurgeDead removes dead jobs that failed before the given time and
+	// returns how many were removed
+	PurgeDead(ctx context.Context, before time.Time) (int, error)
+}
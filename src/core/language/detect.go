@@ -0,0 +1,262 @@
+// ABOUTME: Offline language identification from character trigrams and scripts
+// ABOUTME: Returns an ISO 639-1 code with a confidence score between 0 and 1
+
+package language
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// MinConfidence is the confidence below which a guess is not reported
+	MinConfidence = 0.2
+
+	// minLetters is the least text identified; shorter text is too ambiguous
+	minLetters = 12
+
+	// maxRunes caps how much text is looked at
+	maxRunes = 2000
+
+	// fullLetters is how many letters give full confidence; shorter text is
+	// scaled down, as a headline can read well in several languages
+	fullLetters = 60
+
+	// maxTrigrams caps how many trigrams count towards the confidence
+	maxTrigrams = 100
+
+	// temperature tempers the naive Bayes posterior
+	temperature = 2
+
+	// smoothing and vocabulary give trigrams missing from a profile a small
+	// probability (additive smoothing over a vocabulary of that many trigrams)
+	smoothing  = 0.5
+	vocabulary = 5000
+)
+
+// profile holds the trigram log-probabilities of a language
+type profile struct {
+	language string
+	script   *unicode.RangeTable
+	logProbs map[string]float64
+	unseen   float64
+}
+
+var (
+	profilesOnce sync.Once
+	profiles     []profile
+)
+
+// scriptLanguages are the languages identified by their script alone
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Tamil, "ta"},
+	{unicode.Armenian, "hy"},
+	{unicode.Georgian, "ka"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+}
+
+// Detect identifies the language of a text. It returns "" when the text is
+// too short or no language is a clear match.
+func Detect(text string) (string, float64) {
+	letters := 0
+	counts := make(map[*unicode.RangeTable]int)
+	kana := 0
+	runes := 0
+	var sample strings.Builder
+	for _, r := range text {
+		if runes++; runes > maxRunes {
+			break
+		}
+		sample.WriteRune(r)
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Latin, r):
+			counts[unicode.Latin]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts[unicode.Cyrillic]++
+		default:
+			for _, sl := range scriptLanguages {
+				if unicode.Is(sl.script, r) {
+					counts[sl.script]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return "", 0
+	}
+
+	// Japanese mixes kana with Han characters; a few kana decide it
+	if kana*10 >= letters {
+		return "ja", share(kana+counts[unicode.Han], letters)
+	}
+
+	dominant, most := (*unicode.RangeTable)(nil), 0
+	for script, count := range counts {
+		if count > most {
+			dominant, most = script, count
+		}
+	}
+	if dominant == nil || most*2 < letters {
+		return "", 0
+	}
+
+	if dominant != unicode.Latin && dominant != unicode.Cyrillic {
+		for _, sl := range scriptLanguages {
+			if sl.script == dominant {
+				if sl.language == "ar" && isPersian(text) {
+					return "fa", share(most, letters)
+				}
+				return sl.language, share(most, letters)
+			}
+		}
+	}
+
+	if most < minLetters {
+		return "", 0
+	}
+
+	language, confidence := matchTrigrams(sample.String(), dominant)
+	confidence *= math.Min(1, float64(most)/fullLetters)
+	if confidence < MinConfidence {
+		return "", 0
+	}
+	return language, round(confidence)
+}
+
+// matchTrigrams scores the trigrams of a text against the profiles of the
+// languages written in its script with naive Bayes. Trigrams overlap and
+// are far from independent, so the posterior is tempered: each trigram
+// counts for 1/temperature, and no more than maxTrigrams are counted.
+func matchTrigrams(text string, script *unicode.RangeTable) (string, float64) {
+	profilesOnce.Do(buildProfiles)
+
+	counts := trigrams(text)
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return "", 0
+	}
+	weight := math.Min(float64(total), maxTrigrams) / float64(total) / temperature
+
+	var candidates []profile
+	var scores []float64
+	best := -1
+	for _, p := range profiles {
+		if p.script != script {
+			continue
+		}
+		var score float64
+		for gram, count := range counts {
+			logProb, ok := p.logProbs[gram]
+			if !ok {
+				logProb = p.unseen
+			}
+			score += float64(count) * logProb * weight
+		}
+		candidates = append(candidates, p)
+		scores = append(scores, score)
+		if best < 0 || score > scores[best] {
+			best = len(scores) - 1
+		}
+	}
+	if best < 0 {
+		return "", 0
+	}
+
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp(score - scores[best])
+	}
+	return candidates[best].language, 1 / sum
+}
+
+// buildProfiles turns the sample texts into trigram log-probabilities,
+// smoothed so that trigrams missing from a sample are merely unlikely
+func buildProfiles() {
+	for language, text := range samples {
+		counts := trigrams(text)
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		denominator := float64(total) + smoothing*vocabulary
+
+		p := profile{
+			language: language,
+			script:   unicode.Latin,
+			logProbs: make(map[string]float64, len(counts)),
+			unseen:   math.Log(smoothing / denominator),
+		}
+		for gram, count := range counts {
+			p.logProbs[gram] = math.Log((float64(count) + smoothing) / denominator)
+		}
+		for _, r := range text {
+			if unicode.Is(unicode.Cyrillic, r) {
+				p.script = unicode.Cyrillic
+				break
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].language < profiles[j].language })
+}
+
+// trigrams counts the character trigrams of the words of a text, with a
+// space marking the start and end of each word
+func trigrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+	return counts
+}
+
+// isPersian reports whether Arabic-script text uses letters found in
+// Persian but not in Arabic
+func isPersian(text string) bool {
+	persian := 0
+	for _, r := range text {
+		switch r {
+		case 'پ', 'چ', 'ژ', 'گ', 'ک', 'ی':
+			persian++
+		}
+	}
+	return persian >= 2
+}
+
+func share(part, total int) float64 {
+	return round(float64(part) / float64(total))
+}
+
+// round keeps two decimals, which is all the precision a confidence has
+func round(confidence float64) float64 {
+	return math.Round(confidence*100) / 100
+}
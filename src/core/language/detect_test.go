@@ -0,0 +1,80 @@
+package language
+
+import "testing"
+
+func TestDetect_TrigramLanguages(t *testing.T) {
+	// None of these sentences are in the samples
+	tests := map[string]string{
+		"en": "Apple announced a new laptop on Monday with a faster chip and a battery that lasts all day.",
+		"de": "Die Regierung will die Steuern für Rentner senken, doch die Opposition hält den Plan für zu teuer.",
+		"fr": "Le gouvernement veut baisser les impôts des retraités, mais l'opposition juge le projet trop coûteux.",
+		"es": "El gobierno quiere bajar los impuestos a los jubilados, pero la oposición cree que el plan es demasiado caro.",
+		"it": "Il governo vuole abbassare le tasse ai pensionati, ma l'opposizione ritiene il piano troppo costoso.",
+		"pt": "O governo quer baixar os impostos dos aposentados, mas a oposição acha o plano caro demais.",
+		"nl": "De regering wil de belastingen voor gepensioneerden verlagen, maar de oppositie vindt het plan te duur.",
+		"sv": "Regeringen vill sänka skatten för pensionärer, men oppositionen tycker att planen är för dyr.",
+		"da": "Regeringen vil sænke skatten for pensionister, men oppositionen mener, at planen er for dyr.",
+		"nb": "Regjeringen vil senke skatten for pensjonister, men opposisjonen mener planen er for dyr.",
+		"fi": "Hallitus aikoo laskea eläkeläisten veroja, mutta oppositio pitää suunnitelmaa liian kalliina.",
+		"pl": "Rząd chce obniżyć podatki dla emerytów, ale opozycja uważa, że plan jest zbyt drogi.",
+		"ru": "Правительство хочет снизить налоги для пенсионеров, но оппозиция считает план слишком дорогим.",
+		"uk": "Уряд хоче знизити податки для пенсіонерів, але опозиція вважає план занадто дорогим.",
+	}
+
+	for want, text := range tests {
+		got, confidence := Detect(text)
+		if got != want {
+			t.Errorf("Detect(%q) = %q, want %q", text, got, want)
+			continue
+		}
+		if confidence < MinConfidence || confidence > 1 {
+			t.Errorf("Detect(%q) confidence = %v", text, confidence)
+		}
+	}
+}
+
+func TestDetect_ScriptLanguages(t *testing.T) {
+	tests := map[string]string{
+		"zh": "政府计划降低退休人员的税收，但反对派认为这个计划太贵了。",
+		"ja": "政府は年金生活者の税金を下げる予定ですが、野党は計画が高すぎると考えています。",
+		"ko": "정부는 연금 수급자의 세금을 낮출 계획이지만 야당은 계획이 너무 비싸다고 생각한다.",
+		"el": "Η κυβέρνηση θέλει να μειώσει τους φόρους για τους συνταξιούχους.",
+		"ar": "تريد الحكومة خفض الضرائب على المتقاعدين لكن المعارضة ترى أن الخطة مكلفة للغاية.",
+		"fa": "دولت می‌خواهد مالیات بازنشستگان را کاهش دهد اما مخالفان این برنامه را گران می‌دانند.",
+	}
+
+	for want, text := range tests {
+		if got, confidence := Detect(text); got != want || confidence < 0.9 {
+			t.Errorf("Detect(%q) = %q (%v), want %q", text, got, confidence, want)
+		}
+	}
+}
+
+func TestDetect_TooLittleText(t *testing.T) {
+	for _, text := range []string{"", "12345 !!!", "OK", "Hello world", "Der Zug fährt wieder"} {
+		if got, confidence := Detect(text); got != "" || confidence != 0 {
+			t.Errorf("Detect(%q) = %q (%v), want no language", text, got, confidence)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	if !Matches("en-US", []string{"de", "EN"}) || !Matches("pt_BR", []string{"pt-PT"}) {
+		t.Error("Expected tags to match by primary subtag")
+	}
+	if Matches("", []string{"en"}) || Matches("en", []string{"de"}) {
+		t.Error("Expected unknown and other languages not to match")
+	}
+}
+
+func TestKeep(t *testing.T) {
+	if !Keep("en-US", []string{"en"}, true) || Keep("de", []string{"en"}, false) {
+		t.Error("Expected detected languages to be filtered by the wanted ones")
+	}
+	if !Keep("", []string{"en"}, false) || Keep("", []string{"en"}, true) {
+		t.Error("Expected undetected languages to be kept unless they are dropped")
+	}
+	if !Keep("", nil, true) {
+		t.Error("Expected an empty filter to keep every item")
+	}
+}
@@ -0,0 +1,9 @@
+package language
+
+// mockLogger is a mock implementation of the Logger interface
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, fields map[string]interface{}) {}
+func (m *mockLogger) Info(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Warn(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Error(msg string, fields map[string]interface{}) {}
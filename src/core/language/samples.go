@@ -0,0 +1,182 @@
+// ABOUTME: Sample text the language profiles are built from
+// ABOUTME: Everyday news-style prose per language, so profiles follow how feeds are written
+
+package language
+
+// samples holds prose for each language identified by character trigrams.
+// Languages with a script of their own are recognised by script instead.
+var samples = map[string]string{
+	"en": `The city council approved a new budget on Tuesday after months of debate about housing and public transport.
+Most residents say they want more buses and safer streets for children walking to school.
+The company announced that its latest phone will be available next week in stores across the country.
+Researchers found that people who sleep less than six hours a night are more likely to get sick.
+We have been working on this release for a long time, and we think you will love the new features.
+It is not clear whether the government will change the law before the elections later this year.
+Here is what you need to know about the weather this weekend, which should be warm and sunny.`,
+
+	"de": `Der Stadtrat hat am Dienstag nach monatelanger Debatte über Wohnungen und Nahverkehr einen neuen Haushalt beschlossen.
+Die meisten Einwohner wünschen sich mehr Busse und sicherere Straßen für Kinder auf dem Schulweg.
+Das Unternehmen kündigte an, dass sein neuestes Telefon ab nächster Woche in den Geschäften im ganzen Land erhältlich sein wird.
+Forscher haben herausgefunden, dass Menschen, die weniger als sechs Stunden pro Nacht schlafen, häufiger krank werden.
+Wir arbeiten schon lange an dieser Version und glauben, dass Ihnen die neuen Funktionen gefallen werden.
+Es ist noch nicht klar, ob die Regierung das Gesetz vor den Wahlen im Herbst ändern wird.
+Hier erfahren Sie alles über das Wetter am Wochenende, das warm und sonnig werden soll.`,
+
+	"fr": `Le conseil municipal a approuvé mardi un nouveau budget après des mois de débat sur le logement et les transports publics.
+La plupart des habitants disent vouloir plus de bus et des rues plus sûres pour les enfants qui vont à l'école.
+L'entreprise a annoncé que son dernier téléphone sera disponible la semaine prochaine dans les magasins de tout le pays.
+Des chercheurs ont découvert que les personnes qui dorment moins de six heures par nuit tombent plus souvent malades.
+Nous travaillons depuis longtemps sur cette version et nous pensons que vous allez adorer les nouvelles fonctionnalités.
+On ne sait pas encore si le gouvernement modifiera la loi avant les élections prévues plus tard cette année.
+Voici ce qu'il faut savoir sur la météo de ce week-end, qui devrait être chaude et ensoleillée.`,
+
+	"es": `El ayuntamiento aprobó el martes un nuevo presupuesto después de meses de debate sobre la vivienda y el transporte público.
+La mayoría de los vecinos dicen que quieren más autobuses y calles más seguras para los niños que van al colegio.
+La empresa anunció que su último teléfono estará disponible la próxima semana en las tiendas de todo el país.
+Los investigadores descubrieron que las personas que duermen menos de seis horas por noche se enferman con más frecuencia.
+Llevamos mucho tiempo trabajando en esta versión y creemos que te van a encantar las nuevas funciones.
+Todavía no está claro si el gobierno cambiará la ley antes de las elecciones de este año.
+Esto es lo que necesitas saber sobre el tiempo de este fin de semana, que será cálido y soleado.`,
+
+	"it": `Il consiglio comunale ha approvato martedì un nuovo bilancio dopo mesi di dibattito sulla casa e sui trasporti pubblici.
+La maggior parte dei cittadini dice di volere più autobus e strade più sicure per i bambini che vanno a scuola.
+L'azienda ha annunciato che il suo ultimo telefono sarà disponibile la prossima settimana nei negozi di tutto il paese.
+I ricercatori hanno scoperto che le persone che dormono meno di sei ore per notte si ammalano più spesso.
+Lavoriamo da molto tempo a questa versione e pensiamo che le nuove funzioni vi piaceranno molto.
+Non è ancora chiaro se il governo cambierà la legge prima delle elezioni previste per quest'anno.
+Ecco cosa c'è da sapere sul tempo di questo fine settimana, che dovrebbe essere caldo e soleggiato.`,
+
+	"pt": `A câmara municipal aprovou na terça-feira um novo orçamento depois de meses de debate sobre habitação e transportes públicos.
+A maioria dos moradores diz que quer mais ônibus e ruas mais seguras para as crianças que vão para a escola.
+A empresa anunciou que o seu novo telefone estará disponível na próxima semana nas lojas de todo o país.
+Os pesquisadores descobriram que as pessoas que dormem menos de seis horas por noite ficam doentes com mais frequência.
+Estamos trabalhando nesta versão há muito tempo e achamos que você vai adorar as novas funcionalidades.
+Ainda não está claro se o governo vai mudar a lei antes das eleições deste ano.
+Veja o que você precisa saber sobre o tempo neste fim de semana, que deve ser quente e ensolarado.`,
+
+	"nl": `De gemeenteraad heeft dinsdag na maanden van discussie over woningen en openbaar vervoer een nieuwe begroting goedgekeurd.
+De meeste inwoners zeggen dat ze meer bussen en veiligere straten willen voor kinderen die naar school lopen.
+Het bedrijf maakte bekend dat zijn nieuwste telefoon volgende week in winkels in het hele land verkrijgbaar is.
+Onderzoekers ontdekten dat mensen die minder dan zes uur per nacht slapen vaker ziek worden.
+We werken al lang aan deze versie en we denken dat je de nieuwe functies geweldig zult vinden.
+Het is nog niet duidelijk of de regering de wet voor de verkiezingen van dit jaar zal veranderen.
+Dit is wat je moet weten over het weer dit weekend, dat warm en zonnig zou moeten worden.`,
+
+	"sv": `Kommunfullmäktige godkände på tisdagen en ny budget efter flera månaders debatt om bostäder och kollektivtrafik.
+De flesta invånare säger att de vill ha fler bussar och säkrare gator för barn som går till skolan.
+Företaget meddelade att dess senaste telefon kommer att finnas i butiker över hela landet nästa vecka.
+Forskare har upptäckt att människor som sover mindre än sex timmar per natt oftare blir sjuka.
+Vi har arbetat med den här versionen länge och tror att du kommer att älska de nya funktionerna.
+Det är ännu inte klart om regeringen kommer att ändra lagen före valet senare i år.
+Här är vad du behöver veta om vädret i helgen, som ska bli varmt och soligt.`,
+
+	"da": `Byrådet vedtog tirsdag et nyt budget efter flere måneders debat om boliger og offentlig transport.
+De fleste borgere siger, at de ønsker flere busser og sikrere gader for børn, der går i skole.
+Virksomheden meddelte, at dens nyeste telefon kan købes i butikker over hele landet fra næste uge.
+Forskere har fundet ud af, at mennesker, der sover mindre end seks timer om natten, oftere bliver syge.
+Vi har arbejdet på denne version i lang tid, og vi tror, at du vil elske de nye funktioner.
+Det er endnu ikke klart, om regeringen vil ændre loven før valget senere i år.
+Her er, hvad du skal vide om vejret i weekenden, som bliver varmt og solrigt.
+Politiet efterforsker stadig ulykken, som skete i nat på motorvejen syd for byen.
+Nogle eksperter mener, at situationen på boligmarkedet er blevet meget værre af flere grunde.
+Organisationen har fået en ny direktør, og hun siger, at hun glæder sig til at komme i gang.`,
+
+	"nb": `Bystyret vedtok tirsdag et nytt budsjett etter flere måneders debatt om boliger og kollektivtransport.
+De fleste innbyggerne sier at de ønsker flere busser og tryggere gater for barn som går til skolen.
+Selskapet kunngjorde at den nyeste telefonen blir å få kjøpt i butikker over hele landet fra neste uke.
+Forskere har funnet ut at mennesker som sover mindre enn seks timer i natten, oftere blir syke.
+Vi har jobbet med denne versjonen lenge, og vi tror du kommer til å like de nye funksjonene.
+Det er ennå ikke klart om regjeringen vil endre loven før valget senere i år.
+Her er det du trenger å vite om været i helgen, som skal bli varmt og solfylt.
+Politiet etterforsker fortsatt ulykken, som skjedde i natt på motorveien sør for byen.
+Noen eksperter mener at situasjonen på boligmarkedet har blitt mye verre av flere grunner.
+Organisasjonen har fått en ny direktør, og hun sier at hun gleder seg til å komme i gang.`,
+
+	"fi": `Kaupunginvaltuusto hyväksyi tiistaina uuden talousarvion kuukausia kestäneen asumista ja joukkoliikennettä koskevan keskustelun jälkeen.
+Useimmat asukkaat sanovat haluavansa lisää busseja ja turvallisempia katuja koulua käyville lapsille.
+Yhtiö ilmoitti, että sen uusin puhelin on saatavilla kaupoissa ympäri maata ensi viikolla.
+Tutkijat havaitsivat, että ihmiset, jotka nukkuvat alle kuusi tuntia yössä, sairastuvat useammin.
+Olemme työskennelleet tämän version parissa pitkään ja uskomme, että pidät uusista ominaisuuksista.
+Vielä ei ole selvää, muuttaako hallitus lakia ennen tämän vuoden vaaleja.
+Tässä on kaikki, mitä sinun tarvitsee tietää viikonlopun säästä, jonka pitäisi olla lämmin ja aurinkoinen.`,
+
+	"pl": `Rada miasta zatwierdziła we wtorek nowy budżet po wielu miesiącach dyskusji o mieszkaniach i transporcie publicznym.
+Większość mieszkańców mówi, że chce więcej autobusów i bezpieczniejszych ulic dla dzieci idących do szkoły.
+Firma ogłosiła, że jej najnowszy telefon będzie dostępny w przyszłym tygodniu w sklepach w całym kraju.
+Naukowcy odkryli, że osoby, które śpią mniej niż sześć godzin na dobę, częściej chorują.
+Pracujemy nad tą wersją od dawna i myślimy, że nowe funkcje bardzo ci się spodobają.
+Nie wiadomo jeszcze, czy rząd zmieni ustawę przed tegorocznymi wyborami.
+Oto co musisz wiedzieć o pogodzie w ten weekend, który ma być ciepły i słoneczny.`,
+
+	"cs": `Městské zastupitelstvo v úterý schválilo nový rozpočet po měsících debat o bydlení a veřejné dopravě.
+Většina obyvatel říká, že chce více autobusů a bezpečnější ulice pro děti, které chodí do školy.
+Společnost oznámila, že její nejnovější telefon bude příští týden k dostání v obchodech po celé zemi.
+Vědci zjistili, že lidé, kteří spí méně než šest hodin za noc, jsou častěji nemocní.
+Na této verzi pracujeme už dlouho a myslíme si, že se vám nové funkce budou líbit.
+Zatím není jasné, zda vláda změní zákon ještě před letošními volbami.
+Tady je to, co potřebujete vědět o počasí o víkendu, které má být teplé a slunečné.`,
+
+	"tr": `Belediye meclisi, konut ve toplu taşıma üzerine aylar süren tartışmaların ardından salı günü yeni bütçeyi onayladı.
+Sakinlerin çoğu daha fazla otobüs ve okula yürüyen çocuklar için daha güvenli sokaklar istediklerini söylüyor.
+Şirket, en yeni telefonunun gelecek hafta ülke genelindeki mağazalarda satışa çıkacağını duyurdu.
+Araştırmacılar, gecede altı saatten az uyuyan kişilerin daha sık hastalandığını buldu.
+Bu sürüm üzerinde uzun süredir çalışıyoruz ve yeni özellikleri çok seveceğinizi düşünüyoruz.
+Hükümetin bu yılki seçimlerden önce yasayı değiştirip değiştirmeyeceği henüz belli değil.
+Sıcak ve güneşli geçmesi beklenen hafta sonu hava durumu hakkında bilmeniz gerekenler burada.`,
+
+	"ro": `Consiliul local a aprobat marți un nou buget după luni de dezbateri despre locuințe și transportul public.
+Majoritatea locuitorilor spun că își doresc mai multe autobuze și străzi mai sigure pentru copiii care merg la școală.
+Compania a anunțat că cel mai nou telefon al său va fi disponibil săptămâna viitoare în magazinele din toată țara.
+Cercetătorii au descoperit că oamenii care dorm mai puțin de șase ore pe noapte se îmbolnăvesc mai des.
+Lucrăm de mult timp la această versiune și credem că noile funcții vă vor plăcea foarte mult.
+Nu este încă clar dacă guvernul va schimba legea înainte de alegerile de anul acesta.
+Iată ce trebuie să știți despre vremea din acest weekend, care ar trebui să fie caldă și însorită.`,
+
+	"hu": `A városi közgyűlés kedden elfogadta az új költségvetést, miután hónapokig vitatkoztak a lakhatásról és a tömegközlekedésről.
+A legtöbb lakos azt mondja, hogy több buszt és biztonságosabb utcákat szeretne az iskolába gyalogló gyerekeknek.
+A vállalat bejelentette, hogy legújabb telefonja jövő héttől az ország minden üzletében kapható lesz.
+A kutatók megállapították, hogy akik éjszakánként hat óránál kevesebbet alszanak, gyakrabban betegszenek meg.
+Régóta dolgozunk ezen a verzión, és úgy gondoljuk, hogy imádni fogja az új funkciókat.
+Még nem világos, hogy a kormány módosítja-e a törvényt az idei választások előtt.
+Íme, amit a hétvégi időjárásról tudni kell, amely várhatóan meleg és napos lesz.`,
+
+	"id": `Dewan kota menyetujui anggaran baru pada hari Selasa setelah berbulan-bulan berdebat tentang perumahan dan transportasi umum.
+Sebagian besar warga mengatakan mereka menginginkan lebih banyak bus dan jalan yang lebih aman bagi anak-anak yang berjalan ke sekolah.
+Perusahaan itu mengumumkan bahwa ponsel terbarunya akan tersedia minggu depan di toko-toko di seluruh negeri.
+Para peneliti menemukan bahwa orang yang tidur kurang dari enam jam setiap malam lebih sering jatuh sakit.
+Kami sudah lama mengerjakan versi ini dan kami yakin Anda akan menyukai fitur-fitur barunya.
+Belum jelas apakah pemerintah akan mengubah undang-undang tersebut sebelum pemilihan umum tahun ini.
+Inilah yang perlu Anda ketahui tentang cuaca akhir pekan ini, yang diperkirakan hangat dan cerah.`,
+
+	"vi": `Hội đồng thành phố đã thông qua ngân sách mới vào thứ Ba sau nhiều tháng tranh luận về nhà ở và giao thông công cộng.
+Hầu hết người dân nói rằng họ muốn có thêm xe buýt và đường phố an toàn hơn cho trẻ em đi bộ đến trường.
+Công ty thông báo rằng điện thoại mới nhất của họ sẽ được bán tại các cửa hàng trên toàn quốc vào tuần tới.
+Các nhà nghiên cứu phát hiện ra rằng những người ngủ ít hơn sáu tiếng mỗi đêm thường dễ bị ốm hơn.
+Chúng tôi đã làm việc với phiên bản này trong một thời gian dài và tin rằng bạn sẽ thích các tính năng mới.
+Vẫn chưa rõ liệu chính phủ có thay đổi luật trước cuộc bầu cử vào cuối năm nay hay không.
+Đây là những điều bạn cần biết về thời tiết cuối tuần này, dự kiến sẽ ấm áp và nhiều nắng.`,
+
+	"ru": `Городской совет во вторник утвердил новый бюджет после нескольких месяцев споров о жилье и общественном транспорте.
+Большинство жителей говорят, что хотят больше автобусов и более безопасных улиц для детей, которые ходят в школу.
+Компания объявила, что её новый телефон появится в магазинах по всей стране на следующей неделе.
+Исследователи обнаружили, что люди, которые спят меньше шести часов в сутки, чаще болеют.
+Мы долго работали над этой версией и думаем, что вам понравятся новые функции.
+Пока неясно, изменит ли правительство закон до выборов, которые пройдут в этом году.
+Вот что нужно знать о погоде на выходных, которая обещает быть тёплой и солнечной.`,
+
+	"uk": `Міська рада у вівторок затвердила новий бюджет після кількох місяців суперечок про житло та громадський транспорт.
+Більшість мешканців кажуть, що хочуть більше автобусів і безпечніших вулиць для дітей, які ходять до школи.
+Компанія оголосила, що її новий телефон з'явиться в магазинах по всій країні наступного тижня.
+Дослідники виявили, що люди, які сплять менше шести годин на добу, частіше хворіють.
+Ми довго працювали над цією версією і думаємо, що вам сподобаються нові функції.
+Поки що незрозуміло, чи змінить уряд закон до виборів, які відбудуться цього року.
+Ось що потрібно знати про погоду на вихідних, яка обіцяє бути теплою та сонячною.`,
+
+	"bg": `Общинският съвет одобри във вторник нов бюджет след месеци на спорове за жилищата и обществения транспорт.
+Повечето жители казват, че искат повече автобуси и по-безопасни улици за децата, които ходят на училище.
+Компанията обяви, че новият ѝ телефон ще се продава в магазините в цялата страна от следващата седмица.
+Изследователите установиха, че хората, които спят по-малко от шест часа на нощ, боледуват по-често.
+Работим по тази версия от дълго време и смятаме, че новите функции ще ви харесат.
+Все още не е ясно дали правителството ще промени закона преди изборите тази година.
+Ето какво трябва да знаете за времето през уикенда, което се очаква да бъде топло и слънчево.`,
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Service layer that sets the languages of feeds and their items
+// ABOUTME: Detects each item, and feeds without a declared language from their items
+
+package language
+
+import (
+	"context"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"digests-app-api/core/summarize"
+)
+
+// Service detects the languages of feeds
+type Service struct {
+	deps interfaces.Dependencies
+}
+
+// NewService creates a language detection service
+func NewService(deps interfaces.Dependencies) *Service {
+	return &Service{deps: deps}
+}
+
+// DetectFeed sets the language of each item from its title and text, and
+// the language of the feed when the feed does not declare one. Declared
+// languages are kept with a confidence of 1; a detected feed language is
+// the one most items are in, weighted by confidence. Items whose language
+// cannot be told take the language of the feed.
+func (s *Service) DetectFeed(ctx context.Context, feed *domain.Feed) {
+	if feed == nil {
+		return
+	}
+
+	weights := make(map[string]float64)
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		text := item.ContentEncoded
+		if text == "" {
+			text = item.Content
+		}
+		if text == "" {
+			text = item.Description
+		}
+		item.Language, item.LanguageConfidence = Detect(item.Title + "\n" + summarize.PlainText(text))
+		if item.Language != "" {
+			weights[item.Language] += item.LanguageConfidence
+		}
+	}
+
+	switch {
+	case feed.Language != "":
+		feed.LanguageConfidence = 1
+	case len(weights) > 0:
+		best := ""
+		for language, weight := range weights {
+			if best == "" || weight > weights[best] || (weight == weights[best] && language < best) {
+				best = language
+			}
+		}
+		feed.Language = best
+		feed.LanguageConfidence = round(weights[best] / float64(len(feed.Items)))
+	default:
+		feed.Language, feed.LanguageConfidence = Detect(feed.Title + "\n" + summarize.PlainText(feed.Description))
+	}
+
+	for i := range feed.Items {
+		if feed.Items[i].Language == "" {
+			feed.Items[i].Language = feed.Language
+			feed.Items[i].LanguageConfidence = feed.LanguageConfidence
+		}
+	}
+
+	s.deps.Logger.Debug("Detected feed language", map[string]interface{}{
+		"url":        feed.URL,
+		"language":   feed.Language,
+		"confidence": feed.LanguageConfidence,
+	})
+}
+
+// Matches reports whether a language tag is one of the wanted languages.
+// Tags are compared by their primary subtag, so "en-US" matches "en".
+func Matches(tag string, wanted []string) bool {
+	primary := Primary(tag)
+	if primary == "" {
+		return false
+	}
+	for _, want := range wanted {
+		if Primary(want) == primary {
+			return true
+		}
+	}
+	return false
+}
+
+// Keep reports whether an item in a language passes a filter of wanted
+// languages. Short or mixed-language text is often left undetected, so such
+// items are kept unless dropUndetected is set; an empty filter keeps all.
+func Keep(tag string, wanted []string, dropUndetected bool) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	if Primary(tag) == "" {
+		return !dropUndetected
+	}
+	return Matches(tag, wanted)
+}
+
+// Primary returns the lower-cased primary subtag of a language tag
+func Primary(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
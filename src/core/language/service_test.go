@@ -0,0 +1,48 @@
+package language
+
+import (
+	"context"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+func TestService_DetectFeed(t *testing.T) {
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+
+	// Without a declared language, the feed takes the language of most items
+	feed := &domain.Feed{
+		URL: "https://example.com/feed",
+		Items: []domain.FeedItem{
+			{Title: "Neue Steuern", ContentEncoded: "<p>Die Regierung will die Steuern für Rentner senken, doch die Opposition hält den Plan für zu teuer.</p>"},
+			{Title: "Der Zug fährt wieder", Description: "Nach dem Sturm fahren die Züge zwischen Hamburg und Berlin seit heute Morgen wieder nach Fahrplan."},
+			{Title: "New taxes", Content: "The government wants to cut taxes for pensioners, but the opposition thinks the plan is too expensive."},
+			{Title: "OK"},
+		},
+	}
+	service.DetectFeed(context.Background(), feed)
+
+	if feed.Language != "de" || feed.LanguageConfidence <= 0 || feed.LanguageConfidence > 1 {
+		t.Errorf("Expected a detected German feed, got %q (%v)", feed.Language, feed.LanguageConfidence)
+	}
+	if feed.Items[0].Language != "de" || feed.Items[2].Language != "en" {
+		t.Errorf("Expected items in their own language, got %q and %q", feed.Items[0].Language, feed.Items[2].Language)
+	}
+	if feed.Items[3].Language != "de" || feed.Items[3].LanguageConfidence != feed.LanguageConfidence {
+		t.Errorf("Expected an item without text to take the feed language, got %q (%v)", feed.Items[3].Language, feed.Items[3].LanguageConfidence)
+	}
+
+	// A declared language is kept
+	declared := &domain.Feed{
+		Language: "en-GB",
+		Items:    []domain.FeedItem{{Title: "Bonjour"}},
+	}
+	service.DetectFeed(context.Background(), declared)
+
+	if declared.Language != "en-GB" || declared.LanguageConfidence != 1 || declared.Items[0].Language != "en-GB" {
+		t.Errorf("Expected the declared language to be kept, got %+v", declared)
+	}
+
+	service.DetectFeed(context.Background(), nil)
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+// mockCache is an in-memory implementation of the Cache interface
+type mockCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMockCache() *mockCache {
+	return &mockCache{data: make(map[string][]byte)}
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.data[key]; ok {
+		return value, nil
+	}
+	return nil, errors.New("cache miss")
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *mockCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// mockLogger is a mock implementation of the Logger interface
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, fields map[string]interface{}) {}
+func (m *mockLogger) Info(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Warn(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Error(msg string, fields map[string]interface{}) {}
+
+// mockSiteRules returns the same rule for every host
+type mockSiteRules struct {
+	rule *domain.SiteRule
+}
+
+func (m *mockSiteRules) Match(host string) *domain.SiteRule {
+	return m.rule
+}
+
+// mockJobQueue records the jobs enqueued
+type mockJobQueue struct {
+	mu   sync.Mutex
+	jobs []string
+}
+
+func (m *mockJobQueue) Enqueue(ctx context.Context, jobType, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs = append(m.jobs, jobType+":"+url)
+	return nil
+}
+
+func (m *mockJobQueue) Job(ctx context.Context, jobType, url string) (*domain.QueuedJob, error) {
+	return nil, nil
+}
+
+func (m *mockJobQueue) Stats(ctx context.Context) (*domain.QueueStats, error) {
+	return nil, nil
+}
@@ -0,0 +1,648 @@
+// ABOUTME: Reads page metadata from Open Graph, Twitter Card, JSON-LD and microdata markup
+// ABOUTME: Sources are read from most to least authoritative; later ones only fill what is missing
+
+package metadata
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// metaTag is a <meta> tag keyed by its lowercased property or name
+type metaTag struct {
+	key     string
+	content string
+}
+
+// pageParser collects the metadata of a parsed page
+type pageParser struct {
+	doc    *goquery.Document
+	base   *url.URL
+	result *domain.PageMetadata
+	metas  []metaTag
+}
+
+// parsePage reads the metadata a page declares. pageURL is the URL that
+// was requested; base is the URL the page was served from after redirects.
+func parsePage(doc *goquery.Document, pageURL string, base *url.URL, rule *domain.SiteRule) *domain.PageMetadata {
+	p := &pageParser{
+		doc:  doc,
+		base: base,
+		result: &domain.PageMetadata{
+			URL:    pageURL,
+			Domain: base.Host,
+		},
+	}
+
+	// Site rules go first so their values win over the page's own markup
+	if rule != nil {
+		p.applySiteRule(rule)
+	}
+
+	p.readMetaTags()
+	p.openGraph()
+	p.twitterCard()
+	p.jsonLD()
+	p.microdata()
+	p.htmlTags()
+	if len(p.result.Images) == 0 {
+		p.contentImages()
+	}
+
+	if p.result.Thumbnail == "" && len(p.result.Images) > 0 {
+		p.result.Thumbnail = p.result.Images[0].URL
+	}
+	if p.result.Duration == 0 && len(p.result.Videos) > 0 {
+		p.result.Duration = p.result.Videos[0].Duration
+	}
+
+	return p.result
+}
+
+// readMetaTags collects the page's <meta> tags in document order
+func (p *pageParser) readMetaTags() {
+	p.doc.Find("meta[content]").Each(func(_ int, sel *goquery.Selection) {
+		key := sel.AttrOr("property", "")
+		if key == "" {
+			key = sel.AttrOr("name", "")
+		}
+		content := strings.TrimSpace(sel.AttrOr("content", ""))
+		if key == "" || content == "" {
+			return
+		}
+		p.metas = append(p.metas, metaTag{key: strings.ToLower(strings.TrimSpace(key)), content: content})
+	})
+}
+
+// openGraph reads og:* and article:* tags. Structured properties such as
+// og:image:width describe the image declared before them.
+func (p *pageParser) openGraph() {
+	image, video := -1, -1
+	for _, tag := range p.metas {
+		switch tag.key {
+		case "og:title":
+			setIfEmpty(&p.result.Title, tag.content)
+		case "og:description":
+			setIfEmpty(&p.result.Description, tag.content)
+		case "og:site_name":
+			setIfEmpty(&p.result.SiteName, tag.content)
+		case "og:url":
+			setIfEmpty(&p.result.CanonicalURL, p.absolute(tag.content))
+		case "og:type":
+			setIfEmpty(&p.result.Type, tag.content)
+		case "og:locale":
+			setIfEmpty(&p.result.Locale, tag.content)
+		case "og:determiner":
+			setIfEmpty(&p.result.Determiner, tag.content)
+		case "article:published_time":
+			setIfEmpty(&p.result.Published, tag.content)
+		case "article:modified_time", "og:updated_time":
+			setIfEmpty(&p.result.Modified, tag.content)
+
+		case "og:image", "og:image:url":
+			image = p.addImage(domain.MediaObject{URL: tag.content})
+		case "og:image:secure_url", "og:image:width", "og:image:height", "og:image:alt", "og:image:type":
+			if image >= 0 {
+				setMediaProperty(&p.result.Images[image], strings.TrimPrefix(tag.key, "og:image:"), tag.content)
+			}
+
+		case "og:video", "og:video:url":
+			video = p.addVideo(domain.MediaObject{URL: tag.content})
+		case "og:video:secure_url", "og:video:width", "og:video:height", "og:video:type",
+			"og:video:duration", "og:video:release_date", "og:video:tag":
+			if video >= 0 {
+				setMediaProperty(&p.result.Videos[video], strings.TrimPrefix(tag.key, "og:video:"), tag.content)
+			}
+		}
+	}
+}
+
+// twitterCard reads twitter:* tags
+func (p *pageParser) twitterCard() {
+	image, video := -1, -1
+	for _, tag := range p.metas {
+		switch tag.key {
+		case "twitter:title":
+			setIfEmpty(&p.result.Title, tag.content)
+		case "twitter:description":
+			setIfEmpty(&p.result.Description, tag.content)
+		case "twitter:image", "twitter:image:src":
+			image = p.addImage(domain.MediaObject{URL: tag.content})
+		case "twitter:image:alt":
+			if image >= 0 {
+				setIfEmpty(&p.result.Images[image].Alt, tag.content)
+			}
+		case "twitter:player":
+			video = p.addVideo(domain.MediaObject{URL: tag.content})
+		case "twitter:player:width", "twitter:player:height":
+			if video >= 0 {
+				setMediaProperty(&p.result.Videos[video], strings.TrimPrefix(tag.key, "twitter:player:"), tag.content)
+			}
+		}
+	}
+}
+
+// setMediaProperty sets an Open Graph structured property on an image or video
+func setMediaProperty(media *domain.MediaObject, property, value string) {
+	switch property {
+	case "secure_url":
+		setIfEmpty(&media.SecureURL, value)
+	case "width":
+		if media.Width == 0 {
+			media.Width, _ = strconv.Atoi(value)
+		}
+	case "height":
+		if media.Height == 0 {
+			media.Height, _ = strconv.Atoi(value)
+		}
+	case "alt":
+		setIfEmpty(&media.Alt, value)
+	case "type":
+		setIfEmpty(&media.Type, value)
+	case "duration":
+		if media.Duration == 0 {
+			media.Duration = parseDuration(value)
+		}
+	case "release_date":
+		setIfEmpty(&media.ReleaseDate, value)
+	case "tag":
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				media.Tags = append(media.Tags, tag)
+			}
+		}
+	}
+}
+
+// jsonLD reads the page's JSON-LD documents. Every document is kept as
+// structured data; articles and videos found anywhere in them, including
+// @graph containers and nested properties, fill in the metadata.
+func (p *pageParser) jsonLD() {
+	p.doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, sel *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(sel.Text())), &data); err != nil {
+			return
+		}
+		p.result.StructuredData = append(p.result.StructuredData, data)
+		walkJSONLD(data, p.jsonLDNode)
+	})
+}
+
+// walkJSONLD calls visit for every object in a JSON-LD document, parents
+// before the objects nested in them and properties in name order
+func walkJSONLD(data interface{}, visit func(map[string]interface{})) {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, child := range v {
+			walkJSONLD(child, visit)
+		}
+	case map[string]interface{}:
+		visit(v)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkJSONLD(v[key], visit)
+		}
+	}
+}
+
+// jsonLDNode reads an article or video object
+func (p *pageParser) jsonLDNode(node map[string]interface{}) {
+	var itemType string
+	for _, t := range jsonLDTypes(node["@type"]) {
+		if isMainEntityType(t) {
+			itemType = t
+			break
+		}
+	}
+	if itemType == "" {
+		if isSiteType(node["@type"]) {
+			setIfEmpty(&p.result.SiteName, jsonLDText(node["name"]))
+		}
+		return
+	}
+
+	setIfEmpty(&p.result.Type, itemType)
+	setIfEmpty(&p.result.Title, firstNonEmpty(jsonLDText(node["headline"]), jsonLDText(node["name"])))
+	setIfEmpty(&p.result.Description, jsonLDText(node["description"]))
+	setIfEmpty(&p.result.Author, jsonLDNames(node["author"]))
+	setIfEmpty(&p.result.Published, firstNonEmpty(jsonLDText(node["datePublished"]), jsonLDText(node["uploadDate"])))
+	setIfEmpty(&p.result.Modified, jsonLDText(node["dateModified"]))
+	setIfEmpty(&p.result.SiteName, jsonLDNames(node["publisher"]))
+
+	for _, key := range []string{"image", "thumbnailUrl"} {
+		for _, image := range jsonLDImages(node[key]) {
+			p.addImage(image)
+		}
+	}
+
+	if itemType == "VideoObject" {
+		p.addVideo(domain.MediaObject{
+			URL:         firstNonEmpty(jsonLDText(node["contentUrl"]), jsonLDText(node["embedUrl"])),
+			Type:        jsonLDText(node["encodingFormat"]),
+			Width:       jsonLDInt(node["width"]),
+			Height:      jsonLDInt(node["height"]),
+			Duration:    parseDuration(jsonLDText(node["duration"])),
+			ReleaseDate: jsonLDText(node["uploadDate"]),
+		})
+	}
+}
+
+// jsonLDTypes returns the types of a JSON-LD object, which may be a single
+// type or a list
+func jsonLDTypes(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		types := make([]string, 0, len(v))
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// isSiteType reports whether a JSON-LD type names the website itself
+func isSiteType(value interface{}) bool {
+	for _, t := range jsonLDTypes(value) {
+		if t == "WebSite" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDText returns a JSON-LD value as text
+func jsonLDText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		return jsonLDText(v["@value"])
+	}
+	return ""
+}
+
+// jsonLDNames returns the names of people or organizations, which may be
+// given as strings, objects or a list of either
+func jsonLDNames(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		return jsonLDText(v["name"])
+	case []interface{}:
+		names := make([]string, 0, len(v))
+		for _, child := range v {
+			if name := jsonLDNames(child); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// jsonLDImages returns the images of an image property, which may be a
+// URL, an ImageObject or a list of either
+func jsonLDImages(value interface{}) []domain.MediaObject {
+	switch v := value.(type) {
+	case string:
+		return []domain.MediaObject{{URL: v}}
+	case map[string]interface{}:
+		return []domain.MediaObject{{
+			URL:    firstNonEmpty(jsonLDText(v["url"]), jsonLDText(v["contentUrl"])),
+			Alt:    firstNonEmpty(jsonLDText(v["caption"]), jsonLDText(v["description"])),
+			Width:  jsonLDInt(v["width"]),
+			Height: jsonLDInt(v["height"]),
+		}}
+	case []interface{}:
+		var images []domain.MediaObject
+		for _, child := range v {
+			images = append(images, jsonLDImages(child)...)
+		}
+		return images
+	}
+	return nil
+}
+
+// jsonLDInt returns a number given as a number, a string or a
+// QuantitativeValue
+func jsonLDInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px"))
+		return n
+	case map[string]interface{}:
+		return jsonLDInt(v["value"])
+	}
+	return 0
+}
+
+// microdata reads schema.org articles and videos marked up with itemscope
+func (p *pageParser) microdata() {
+	p.doc.Find("[itemscope][itemtype]").Each(func(_ int, scope *goquery.Selection) {
+		itemType := schemaType(scope.AttrOr("itemtype", ""))
+		if !isMainEntityType(itemType) {
+			return
+		}
+		props := microdataProps(scope)
+		value := func(names ...string) string {
+			for _, name := range names {
+				if sel, ok := props[name]; ok {
+					if text := microdataText(sel); text != "" {
+						return text
+					}
+				}
+			}
+			return ""
+		}
+
+		setIfEmpty(&p.result.Type, itemType)
+		setIfEmpty(&p.result.Title, value("headline", "name"))
+		setIfEmpty(&p.result.Description, value("description"))
+		setIfEmpty(&p.result.Author, value("author", "creator"))
+		setIfEmpty(&p.result.Published, value("datePublished", "uploadDate"))
+		setIfEmpty(&p.result.Modified, value("dateModified"))
+		setIfEmpty(&p.result.SiteName, value("publisher"))
+
+		for _, name := range []string{"image", "thumbnailUrl"} {
+			sel, ok := props[name]
+			if !ok {
+				continue
+			}
+			if _, nested := sel.Attr("itemscope"); nested {
+				nestedProps := microdataProps(sel)
+				for _, key := range []string{"url", "contentUrl"} {
+					if urlSel, ok := nestedProps[key]; ok {
+						p.addImage(domain.MediaObject{URL: microdataValue(urlSel)})
+						break
+					}
+				}
+				continue
+			}
+			p.addImage(domain.MediaObject{URL: microdataValue(sel), Alt: sel.AttrOr("alt", "")})
+		}
+
+		if itemType == "VideoObject" {
+			p.addVideo(domain.MediaObject{
+				URL:         value("contentUrl", "embedUrl"),
+				Type:        value("encodingFormat"),
+				Width:       jsonLDInt(value("width")),
+				Height:      jsonLDInt(value("height")),
+				Duration:    parseDuration(value("duration")),
+				ReleaseDate: value("uploadDate"),
+			})
+		}
+	})
+}
+
+// schemaType returns the type name of an itemtype URL such as
+// https://schema.org/NewsArticle
+func schemaType(itemType string) string {
+	fields := strings.Fields(itemType)
+	if len(fields) == 0 {
+		return ""
+	}
+	t := strings.TrimSuffix(fields[0], "/")
+	return t[strings.LastIndex(t, "/")+1:]
+}
+
+// microdataProps returns the first element of each property of an item,
+// leaving out the properties of items nested in it
+func microdataProps(scope *goquery.Selection) map[string]*goquery.Selection {
+	props := make(map[string]*goquery.Selection)
+	scope.Find("[itemprop]").Each(func(_ int, sel *goquery.Selection) {
+		owner := sel.Parent().Closest("[itemscope]")
+		if owner.Length() == 0 || owner.Get(0) != scope.Get(0) {
+			return
+		}
+		for _, name := range strings.Fields(sel.AttrOr("itemprop", "")) {
+			if _, ok := props[name]; !ok {
+				props[name] = sel
+			}
+		}
+	})
+	return props
+}
+
+// microdataText returns a property's value, or the name of the item it holds
+func microdataText(sel *goquery.Selection) string {
+	if _, nested := sel.Attr("itemscope"); nested {
+		if name, ok := microdataProps(sel)["name"]; ok {
+			return microdataValue(name)
+		}
+		return ""
+	}
+	return microdataValue(sel)
+}
+
+// microdataValue returns a property's value as the HTML microdata spec reads it
+func microdataValue(sel *goquery.Selection) string {
+	var value string
+	switch goquery.NodeName(sel) {
+	case "meta":
+		value = sel.AttrOr("content", "")
+	case "img", "audio", "video", "source", "embed", "iframe", "track":
+		value = sel.AttrOr("src", "")
+	case "a", "link", "area":
+		value = sel.AttrOr("href", "")
+	case "object":
+		value = sel.AttrOr("data", "")
+	case "data", "meter":
+		value = sel.AttrOr("value", "")
+	case "time":
+		value = sel.AttrOr("datetime", sel.Text())
+	default:
+		value = sel.Text()
+	}
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// isMainEntityType reports whether a schema.org type describes the page's
+// main content
+func isMainEntityType(itemType string) bool {
+	return strings.HasSuffix(itemType, "Article") || itemType == "BlogPosting" || itemType == "VideoObject"
+}
+
+// htmlTags reads plain meta tags, the <title> and <link> elements
+func (p *pageParser) htmlTags() {
+	setIfEmpty(&p.result.Title, strings.Join(strings.Fields(p.doc.Find("title").First().Text()), " "))
+
+	var articleAuthor string
+	for _, tag := range p.metas {
+		switch tag.key {
+		case "description":
+			setIfEmpty(&p.result.Description, tag.content)
+		case "author":
+			setIfEmpty(&p.result.Author, tag.content)
+		case "theme-color":
+			setIfEmpty(&p.result.ThemeColor, tag.content)
+		case "article:author":
+			// Often a profile URL rather than a name
+			if !strings.HasPrefix(tag.content, "http") {
+				setIfEmpty(&articleAuthor, tag.content)
+			}
+		}
+	}
+	setIfEmpty(&p.result.Author, articleAuthor)
+
+	p.doc.Find("link[rel][href]").Each(func(_ int, sel *goquery.Selection) {
+		href := p.absolute(sel.AttrOr("href", ""))
+		for _, rel := range strings.Fields(strings.ToLower(sel.AttrOr("rel", ""))) {
+			switch rel {
+			case "canonical":
+				setIfEmpty(&p.result.CanonicalURL, href)
+			case "icon", "shortcut", "apple-touch-icon":
+				setIfEmpty(&p.result.Favicon, href)
+			}
+		}
+	})
+}
+
+// contentImages falls back to the significant images in the page body
+func (p *pageParser) contentImages() {
+	p.doc.Find("img").Each(func(_ int, sel *goquery.Selection) {
+		if isSignificantImage(sel) {
+			p.addImage(domain.MediaObject{URL: sel.AttrOr("src", ""), Alt: sel.AttrOr("alt", "")})
+		}
+	})
+}
+
+// isSignificantImage checks if an image is likely to be content (not logo/icon)
+func isSignificantImage(sel *goquery.Selection) bool {
+	width := sel.AttrOr("width", "")
+	height := sel.AttrOr("height", "")
+
+	// Check dimensions if available
+	if width != "" && height != "" {
+		w, _ := strconv.Atoi(width)
+		h, _ := strconv.Atoi(height)
+		if w < 200 || h < 200 {
+			return false
+		}
+	}
+
+	// Skip logos, icons, avatars
+	class := strings.ToLower(sel.AttrOr("class", ""))
+	id := strings.ToLower(sel.AttrOr("id", ""))
+	alt := strings.ToLower(sel.AttrOr("alt", ""))
+	for _, pattern := range []string{"logo", "icon", "avatar", "profile", "user", "author"} {
+		if strings.Contains(class, pattern) || strings.Contains(id, pattern) || strings.Contains(alt, pattern) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addImage adds an image unless it is already listed, and returns its index
+// in Images, or -1 when the URL is unusable
+func (p *pageParser) addImage(image domain.MediaObject) int {
+	var index int
+	p.result.Images, index = p.addMedia(p.result.Images, image)
+	return index
+}
+
+// addVideo adds a video unless it is already listed, and returns its index
+// in Videos, or -1 when the URL is unusable
+func (p *pageParser) addVideo(video domain.MediaObject) int {
+	var index int
+	p.result.Videos, index = p.addMedia(p.result.Videos, video)
+	return index
+}
+
+// addMedia adds media with an absolute URL to a list. Media already listed
+// gets the details it was missing instead.
+func (p *pageParser) addMedia(list []domain.MediaObject, media domain.MediaObject) ([]domain.MediaObject, int) {
+	media.URL = p.absolute(media.URL)
+	if media.URL == "" {
+		return list, -1
+	}
+
+	for i := range list {
+		if list[i].URL == media.URL {
+			setIfEmpty(&list[i].Alt, media.Alt)
+			setIfEmpty(&list[i].Type, media.Type)
+			if list[i].Width == 0 && list[i].Height == 0 {
+				list[i].Width, list[i].Height = media.Width, media.Height
+			}
+			if list[i].Duration == 0 {
+				list[i].Duration = media.Duration
+			}
+			return list, i
+		}
+	}
+	return append(list, media), len(list)
+}
+
+// absolute resolves a URL against the page, returning "" for empty and
+// inline data URLs
+func (p *pageParser) absolute(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	resolved, err := p.base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// isoDuration matches ISO 8601 durations such as PT1H2M3S
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseDuration returns a duration in seconds, given in seconds or as an
+// ISO 8601 duration
+func parseDuration(value string) int {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return seconds
+	}
+
+	match := isoDuration.FindStringSubmatch(strings.ToUpper(value))
+	if match == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(match[1])
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	seconds, _ := strconv.ParseFloat(match[4], 64)
+	return days*86400 + hours*3600 + minutes*60 + int(seconds)
+}
+
+// setIfEmpty sets a field unless it already has a value
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = strings.TrimSpace(value)
+	}
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// parseHTML parses a page as if it was served from https://example.com/post
+func parseHTML(t *testing.T, page string, rule *domain.SiteRule) *domain.PageMetadata {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	base, _ := url.Parse("https://example.com/post")
+	return parsePage(doc, "https://example.com/post", base, rule)
+}
+
+func TestParsePage_OpenGraphAndTwitter(t *testing.T) {
+	result := parseHTML(t, `<html><head>
+<title>Fallback title</title>
+<meta name="twitter:title" content="Twitter title">
+<meta property="og:title" content="OG title">
+<meta property="og:type" content="video.other">
+<meta property="og:site_name" content="Example">
+<meta property="og:locale" content="en_GB">
+<meta property="og:url" content="/posts/1">
+<meta property="og:image" content="/lead.jpg">
+<meta property="og:image:width" content="1200">
+<meta property="og:image:height" content="630">
+<meta property="og:image:alt" content="Lead image">
+<meta property="og:image" content="https://cdn.example.com/second.jpg">
+<meta property="og:video" content="https://example.com/clip.mp4">
+<meta property="og:video:type" content="video/mp4">
+<meta property="og:video:duration" content="95">
+<meta property="og:video:tag" content="go, interfaces">
+<meta name="twitter:image" content="https://example.com/lead.jpg">
+<meta name="twitter:image" content="https://example.com/card.jpg">
+<meta name="twitter:image:alt" content="Card image">
+<meta property="article:published_time" content="2024-03-01T10:30:00Z">
+<meta property="article:author" content="https://example.com/authors/jane">
+<meta name="theme-color" content="#336699">
+<link rel="icon" href="/favicon.ico">
+</head><body></body></html>`, nil)
+
+	if result.Title != "OG title" || result.Type != "video.other" || result.SiteName != "Example" || result.Locale != "en_GB" {
+		t.Errorf("unexpected Open Graph fields: %+v", result)
+	}
+	if result.CanonicalURL != "https://example.com/posts/1" || result.URL != "https://example.com/post" || result.Domain != "example.com" {
+		t.Errorf("unexpected URLs: %q, %q, %q", result.URL, result.CanonicalURL, result.Domain)
+	}
+	if len(result.Images) != 3 {
+		t.Fatalf("expected 3 images, got %+v", result.Images)
+	}
+	lead := result.Images[0]
+	if lead.URL != "https://example.com/lead.jpg" || lead.Width != 1200 || lead.Height != 630 || lead.Alt != "Lead image" {
+		t.Errorf("unexpected lead image: %+v", lead)
+	}
+	if result.Images[2].URL != "https://example.com/card.jpg" || result.Images[2].Alt != "Card image" {
+		t.Errorf("unexpected Twitter image: %+v", result.Images[2])
+	}
+	if result.Thumbnail != "https://example.com/lead.jpg" {
+		t.Errorf("Thumbnail = %q", result.Thumbnail)
+	}
+	if len(result.Videos) != 1 || result.Videos[0].Duration != 95 || len(result.Videos[0].Tags) != 2 || result.Duration != 95 {
+		t.Errorf("unexpected videos: %+v, duration %d", result.Videos, result.Duration)
+	}
+	if result.Published != "2024-03-01T10:30:00Z" || result.Author != "" {
+		t.Errorf("unexpected article fields: published %q, author %q", result.Published, result.Author)
+	}
+	if result.ThemeColor != "#336699" || result.Favicon != "https://example.com/favicon.ico" {
+		t.Errorf("unexpected theme color or favicon: %q, %q", result.ThemeColor, result.Favicon)
+	}
+}
+
+func TestParsePage_JSONLDGraph(t *testing.T) {
+	result := parseHTML(t, `<html><head>
+<title>Page title</title>
+<meta name="author" content="Meta Author">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+  {"@type":"WebSite","name":"Example News"},
+  {"@type":"WebPage","name":"Page","mainEntity":{
+    "@type":"NewsArticle",
+    "headline":"Graph headline",
+    "description":"From JSON-LD",
+    "author":[{"@type":"Person","name":"Jane Doe"},{"@type":"Person","name":"John Roe"}],
+    "datePublished":"2024-03-01T10:30:00Z",
+    "dateModified":"2024-03-02T08:00:00Z",
+    "image":[{"@type":"ImageObject","url":"https://example.com/a.jpg","width":800,"height":"600"},"https://example.com/b.jpg"],
+    "video":{"@type":"VideoObject","name":"Clip","contentUrl":"https://example.com/clip.mp4","duration":"PT1M30S","uploadDate":"2024-03-01"}
+  }}
+]}</script>
+<script type="application/ld+json">{"@type":"BreadcrumbList"}</script>
+<script type="application/ld+json">not json</script>
+</head><body></body></html>`, nil)
+
+	if result.Title != "Graph headline" || result.Description != "From JSON-LD" || result.Type != "NewsArticle" {
+		t.Errorf("unexpected article fields: %+v", result)
+	}
+	if result.Author != "Jane Doe, John Roe" {
+		t.Errorf("Author = %q", result.Author)
+	}
+	if result.Published != "2024-03-01T10:30:00Z" || result.Modified != "2024-03-02T08:00:00Z" {
+		t.Errorf("unexpected dates: %q, %q", result.Published, result.Modified)
+	}
+	if result.SiteName != "Example News" {
+		t.Errorf("SiteName = %q", result.SiteName)
+	}
+	if len(result.Images) != 2 || result.Images[0].Width != 800 || result.Images[0].Height != 600 || result.Thumbnail != "https://example.com/a.jpg" {
+		t.Errorf("unexpected images: %+v", result.Images)
+	}
+	if len(result.Videos) != 1 || result.Videos[0].Duration != 90 || result.Duration != 90 {
+		t.Errorf("unexpected videos: %+v", result.Videos)
+	}
+	if len(result.StructuredData) != 2 {
+		t.Errorf("expected the 2 valid JSON-LD documents, got %d", len(result.StructuredData))
+	}
+}
+
+func TestParsePage_Microdata(t *testing.T) {
+	result := parseHTML(t, `<html><head><title>Site | Post</title></head><body>
+<article itemscope itemtype="https://schema.org/BlogPosting">
+  <h1 itemprop="headline">Microdata headline</h1>
+  <span itemprop="author" itemscope itemtype="https://schema.org/Person"><span itemprop="name">Jane Doe</span></span>
+  <time itemprop="datePublished" datetime="2024-03-01T10:30:00Z">March 1</time>
+  <img itemprop="image" src="/hero.jpg" alt="Hero">
+  <div itemprop="publisher" itemscope itemtype="https://schema.org/Organization"><meta itemprop="name" content="Example Blog"></div>
+</article>
+</body></html>`, nil)
+
+	if result.Title != "Microdata headline" || result.Type != "BlogPosting" {
+		t.Errorf("unexpected title or type: %q, %q", result.Title, result.Type)
+	}
+	if result.Author != "Jane Doe" || result.Published != "2024-03-01T10:30:00Z" || result.SiteName != "Example Blog" {
+		t.Errorf("unexpected microdata fields: %+v", result)
+	}
+	if result.Thumbnail != "https://example.com/hero.jpg" || result.Images[0].Alt != "Hero" {
+		t.Errorf("unexpected images: %+v", result.Images)
+	}
+}
+
+func TestParsePage_HTMLFallbacks(t *testing.T) {
+	result := parseHTML(t, `<html><head>
+<title> Plain   title </title>
+<meta name="description" content="Plain description">
+<meta name="author" content="Jane Doe">
+<link rel="canonical" href="https://example.com/canonical">
+<link rel="shortcut icon" href="https://static.example.com/icon.png">
+</head><body>
+<img src="/logo.png" class="site-logo">
+<img src="/small.png" width="50" height="50">
+<img src="/photo.jpg" width="800" height="600">
+</body></html>`, nil)
+
+	if result.Title != "Plain title" || result.Description != "Plain description" || result.Author != "Jane Doe" {
+		t.Errorf("unexpected fallbacks: %+v", result)
+	}
+	if result.CanonicalURL != "https://example.com/canonical" || result.Favicon != "https://static.example.com/icon.png" {
+		t.Errorf("unexpected links: %q, %q", result.CanonicalURL, result.Favicon)
+	}
+	if len(result.Images) != 1 || result.Thumbnail != "https://example.com/photo.jpg" {
+		t.Errorf("expected only the significant content image, got %+v", result.Images)
+	}
+}
+
+func TestParsePage_SiteRule(t *testing.T) {
+	result := parseHTML(t, `<html><head>
+<meta property="og:title" content="Site name: the post">
+<meta property="og:image" content="https://example.com/og.jpg">
+</head><body>
+<h1 class="headline">The post</h1>
+<span class="byline">By Jane Doe</span>
+<figure class="hero"><img src="/hero.jpg"></figure>
+</body></html>`, &domain.SiteRule{
+		Hosts:     []string{"example.com"},
+		Title:     "h1.headline",
+		Author:    ".byline",
+		HeroImage: "figure.hero",
+	})
+
+	if result.Title != "The post" || result.Author != "By Jane Doe" {
+		t.Errorf("expected site rule values, got title %q, author %q", result.Title, result.Author)
+	}
+	if result.Thumbnail != "https://example.com/hero.jpg" || len(result.Images) != 2 || result.Images[0].URL != "https://example.com/hero.jpg" {
+		t.Errorf("expected the hero image first, got %+v", result.Images)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]int{
+		"95":      95,
+		"PT1M30S": 90,
+		"PT1H":    3600,
+		"P1DT2H":  93600,
+		"PT12.5S": 12,
+		"pt2m":    120,
+		"":        0,
+		"forever": 0,
+	}
+	for value, want := range tests {
+		if got := parseDuration(value); got != want {
+			t.Errorf("parseDuration(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// ABOUTME: Applies per-site extraction rules to page metadata
+// ABOUTME: Rule selectors take precedence over the metadata a page declares
+
+package metadata
+
+import (
+	"strings"
+
+	"digests-app-api/core/domain"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// applySiteRule fills the metadata from a site rule's title, author, date
+// and hero image selectors, after removing the elements the rule strips
+func (p *pageParser) applySiteRule(rule *domain.SiteRule) {
+	for _, selector := range rule.Remove {
+		p.doc.Find(selector).Remove()
+	}
+
+	if rule.Title != "" {
+		setIfEmpty(&p.result.Title, ruleText(p.doc.Find(rule.Title).First()))
+	}
+	if rule.Author != "" {
+		setIfEmpty(&p.result.Author, ruleText(p.doc.Find(rule.Author).First()))
+	}
+	if rule.Date != "" {
+		if sel := p.doc.Find(rule.Date).First(); sel.Length() > 0 {
+			setIfEmpty(&p.result.Published, sel.AttrOr("datetime", ruleText(sel)))
+		}
+	}
+	if rule.HeroImage != "" {
+		sel := p.doc.Find(rule.HeroImage).First()
+		if !sel.Is("img, meta") {
+			sel = sel.Find("img").First()
+		}
+		src := sel.AttrOr("content", sel.AttrOr("src", sel.AttrOr("data-src", "")))
+		if index := p.addImage(domain.MediaObject{URL: src, Alt: sel.AttrOr("alt", "")}); index >= 0 {
+			p.result.Thumbnail = p.result.Images[index].URL
+		}
+	}
+}
+
+// ruleText returns the text of a selected element, or the content of a <meta>
+func ruleText(sel *goquery.Selection) string {
+	if sel.Is("meta") {
+		return strings.TrimSpace(sel.AttrOr("content", ""))
+	}
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
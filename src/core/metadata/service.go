@@ -0,0 +1,280 @@
+// ABOUTME: Metadata service fetching web pages and reading the metadata they declare
+// ABOUTME: Serves the /metadata endpoint and feed enrichment from one cached extraction
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+)
+
+const (
+	// userAgent is sent when fetching pages; many sites only serve their
+	// full Open Graph markup to link preview crawlers
+	userAgent = "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"
+
+	// maxPageSize caps how much of a page is read
+	maxPageSize = 5 * 1024 * 1024
+
+	requestTimeout   = 10 * time.Second
+	cacheTTL         = 24 * time.Hour
+	batchConcurrency = 10
+)
+
+// Service extracts the metadata of web pages
+type Service struct {
+	deps      interfaces.Dependencies
+	siteRules interfaces.SiteRuleMatcher
+	jobQueue  interfaces.JobQueue
+	transport http.RoundTripper
+}
+
+// NewService creates a metadata service that caches extracted metadata in deps.Cache
+func NewService(deps interfaces.Dependencies) *Service {
+	return &Service{
+		deps: deps,
+	}
+}
+
+// SetSiteRules sets the per-site rules applied before generic extraction
+func (s *Service) SetSiteRules(rules interfaces.SiteRuleMatcher) {
+	s.siteRules = rules
+}
+
+// SetTransport sets the transport pages are fetched with; the default is
+// http.DefaultTransport
+func (s *Service) SetTransport(transport http.RoundTripper) {
+	s.transport = transport
+}
+
+// SetJobQueue sets the queue pages that could not be fetched are retried
+// through, so a later request finds their metadata cached
+func (s *Service) SetJobQueue(queue interfaces.JobQueue) {
+	s.jobQueue = queue
+}
+
+// Extract returns the metadata of a page. When the page cannot be read, the
+// error is returned along with metadata that only carries the URL and Error.
+func (s *Service) Extract(ctx context.Context, pageURL string) (*domain.PageMetadata, error) {
+	if cached := s.cached(ctx, pageURL); cached != nil {
+		return cached, nil
+	}
+
+	result, err := s.fetch(ctx, pageURL)
+	if err != nil {
+		if s.jobQueue != nil && isTransient(err) {
+			if err := s.jobQueue.Enqueue(ctx, domain.JobTypeMetadata, pageURL); err != nil {
+				s.deps.Logger.Warn("Failed to queue metadata retry", map[string]interface{}{
+					"url":   pageURL,
+					"error": err.Error(),
+				})
+			}
+		}
+		return &domain.PageMetadata{URL: pageURL, Error: err.Error()}, err
+	}
+
+	s.cache(ctx, result)
+	return result, nil
+}
+
+// ExtractBatch extracts the metadata of several pages concurrently. Every
+// URL gets an entry; pages that could not be read have Error set.
+func (s *Service) ExtractBatch(ctx context.Context, urls []string) map[string]*domain.PageMetadata {
+	results := make(map[string]*domain.PageMetadata, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	semaphore := make(chan struct{}, batchConcurrency)
+	for _, pageURL := range urls {
+		wg.Add(1)
+		go func(pageURL string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			result, _ := s.Extract(ctx, pageURL)
+			mu.Lock()
+			results[pageURL] = result
+			mu.Unlock()
+		}(pageURL)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// Refresh extracts and caches the metadata of a page, returning why it
+// failed instead
+func (s *Service) Refresh(ctx context.Context, pageURL string) error {
+	result, err := s.fetch(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+
+	s.cache(ctx, result)
+	return nil
+}
+
+// cached returns the cached metadata of a page, if any
+func (s *Service) cached(ctx context.Context, pageURL string) *domain.PageMetadata {
+	if s.deps.Cache == nil {
+		return nil
+	}
+	data, err := s.deps.Cache.Get(ctx, cacheKey(pageURL))
+	if err != nil || data == nil {
+		return nil
+	}
+	var result domain.PageMetadata
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// cache stores the metadata extracted from a page
+func (s *Service) cache(ctx context.Context, result *domain.PageMetadata) {
+	if s.deps.Cache == nil {
+		return
+	}
+	if data, err := json.Marshal(result); err == nil {
+		_ = s.deps.Cache.Set(ctx, cacheKey(result.URL), data, cacheTTL)
+	}
+}
+
+// cacheKey returns the cache key of a page's metadata
+func cacheKey(pageURL string) string {
+	return "pageMetadata:" + pageURL
+}
+
+// errInvalidURL is returned for URLs that cannot be fetched at all
+var errInvalidURL = errors.New("URL must be an absolute http or https URL")
+
+// fetchError is a failure to fetch a page. Transient failures, such as an
+// unreachable host or a 5xx or 429 status, may pass when the page is
+// fetched again; missing pages and pages that are not HTML will not.
+type fetchError struct {
+	err       error
+	transient bool
+}
+
+func (e *fetchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fetchError) Unwrap() error {
+	return e.err
+}
+
+// isTransient reports whether a failed extraction is worth retrying
+func isTransient(err error) bool {
+	var fetchErr *fetchError
+	return errors.As(err, &fetchErr) && fetchErr.transient
+}
+
+// fetch downloads a page once and parses its metadata
+func (s *Service) fetch(ctx context.Context, pageURL string) (*domain.PageMetadata, error) {
+	parsed, err := url.Parse(pageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errInvalidURL
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c := colly.NewCollector(
+		colly.UserAgent(userAgent),
+		colly.MaxBodySize(maxPageSize),
+		colly.AllowURLRevisit(),
+	)
+	c.SetRequestTimeout(requestTimeout)
+
+	// Colly does not take a context, so requests are bound to ctx by the
+	// transport, and redirects are not followed once ctx is done
+	transport := s.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c.WithTransport(&contextTransport{ctx: ctx, base: transport})
+
+	var result *domain.PageMetadata
+	var fetchErr error
+	c.OnRequest(func(r *colly.Request) {
+		if err := ctx.Err(); err != nil {
+			fetchErr = err
+			r.Abort()
+		}
+	})
+	c.OnResponse(func(r *colly.Response) {
+		if contentType := r.Headers.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+			fetchErr = &fetchError{err: fmt.Errorf("not an HTML page: %s", contentType)}
+			return
+		}
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+		if err != nil {
+			fetchErr = fmt.Errorf("failed to parse page: %w", err)
+			return
+		}
+		result = parsePage(doc, pageURL, r.Request.URL, s.siteRule(r.Request.URL.Host))
+	})
+	c.OnError(func(r *colly.Response, err error) {
+		if r.StatusCode >= 400 {
+			transient := r.StatusCode >= http.StatusInternalServerError || r.StatusCode == http.StatusTooManyRequests
+			fetchErr = &fetchError{err: fmt.Errorf("status %d", r.StatusCode), transient: transient}
+		}
+	})
+
+	if err := c.Visit(pageURL); err != nil && fetchErr == nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// The caller gave up; that says nothing about the page
+			fetchErr = ctxErr
+		} else {
+			// The request itself failed, e.g. the host was unreachable
+			fetchErr = &fetchError{err: err, transient: true}
+		}
+	}
+	if fetchErr != nil {
+		s.deps.Logger.Debug("Failed to extract page metadata", map[string]interface{}{
+			"url":   pageURL,
+			"error": fetchErr.Error(),
+		})
+		return nil, fetchErr
+	}
+	if result == nil {
+		return nil, errors.New("no response")
+	}
+
+	return result, nil
+}
+
+// contextTransport makes every request of a collector with ctx, so
+// cancelling ctx cancels requests in flight
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request with the transport's context
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// siteRule returns the site rule for a host, if any
+func (s *Service) siteRule(host string) *domain.SiteRule {
+	if s.siteRules == nil {
+		return nil
+	}
+	return s.siteRules.Match(host)
+}
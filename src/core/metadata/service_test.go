@@ -0,0 +1,192 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head>
+<title>Understanding Go interfaces</title>
+<meta property="og:title" content="Understanding Go interfaces">
+<meta property="og:image" content="/images/lead.jpg">
+<meta name="author" content="Jane Doe">
+<meta property="article:published_time" content="2024-03-01T10:30:00Z">
+</head><body><p>Interfaces are satisfied implicitly.</p></body></html>`
+
+// newTestServer serves the article page, a 404, a 503 and a non-HTML
+// response, counting requests
+func newTestServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/post":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(articlePage))
+		case "/feed.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		case "/busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestService_Extract(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, &requests)
+	service := NewService(interfaces.Dependencies{Cache: newMockCache(), Logger: &mockLogger{}})
+	ctx := context.Background()
+
+	result, err := service.Extract(ctx, server.URL+"/post")
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if result.Title != "Understanding Go interfaces" || result.Author != "Jane Doe" || result.Published != "2024-03-01T10:30:00Z" {
+		t.Errorf("unexpected metadata: %+v", result)
+	}
+	if result.Thumbnail != server.URL+"/images/lead.jpg" {
+		t.Errorf("Thumbnail = %q", result.Thumbnail)
+	}
+	if requests != 1 {
+		t.Errorf("expected the page to be fetched once, got %d requests", requests)
+	}
+
+	// Served from cache the second time
+	if cached, err := service.Extract(ctx, server.URL+"/post"); err != nil || cached.Title != result.Title {
+		t.Errorf("unexpected cached result: %+v, %v", cached, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected cached metadata, got %d requests", requests)
+	}
+}
+
+func TestService_ExtractFailureIsQueued(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, &requests)
+	queue := &mockJobQueue{}
+	service := NewService(interfaces.Dependencies{Cache: newMockCache(), Logger: &mockLogger{}})
+	service.SetJobQueue(queue)
+	ctx := context.Background()
+
+	result, err := service.Extract(ctx, server.URL+"/busy")
+	if err == nil || err.Error() != "status 503" {
+		t.Fatalf("expected status 503 error, got %v", err)
+	}
+	if result == nil || result.URL != server.URL+"/busy" || result.Error != "status 503" {
+		t.Errorf("unexpected failed result: %+v", result)
+	}
+	if len(queue.jobs) != 1 || queue.jobs[0] != domain.JobTypeMetadata+":"+server.URL+"/busy" {
+		t.Errorf("expected a metadata retry job, got %v", queue.jobs)
+	}
+
+	// Failures are not cached
+	_, _ = service.Extract(ctx, server.URL+"/busy")
+	if requests != 2 {
+		t.Errorf("expected the failed page to be fetched again, got %d requests", requests)
+	}
+	if len(queue.jobs) != 2 {
+		t.Errorf("expected the failure to be queued again, got %v", queue.jobs)
+	}
+
+	// Missing pages, non-HTML responses and invalid URLs are not worth retrying
+	if _, err := service.Extract(ctx, server.URL+"/missing"); err == nil || err.Error() != "status 404" {
+		t.Errorf("expected status 404 error, got %v", err)
+	}
+	if _, err := service.Extract(ctx, server.URL+"/feed.json"); err == nil {
+		t.Error("expected an error for a non-HTML page")
+	}
+	if _, err := service.Extract(ctx, "about:blank"); err == nil {
+		t.Error("expected an error for a non-HTTP URL")
+	}
+	if len(queue.jobs) != 2 {
+		t.Errorf("expected only transient failures to be queued, got %v", queue.jobs)
+	}
+}
+
+func TestService_FetchStopsWhenContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	queue := &mockJobQueue{}
+	service := NewService(interfaces.Dependencies{Cache: newMockCache(), Logger: &mockLogger{}})
+	service.SetJobQueue(queue)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := service.Extract(ctx, server.URL+"/slow")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the fetch, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("fetch took %v after the deadline", elapsed)
+	}
+	if len(queue.jobs) != 0 {
+		t.Errorf("expected a cancelled fetch not to be queued, got %v", queue.jobs)
+	}
+}
+
+func TestService_ExtractBatch(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, &requests)
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+
+	urls := []string{server.URL + "/post", server.URL + "/missing", server.URL + "/feed.json"}
+	results := service.ExtractBatch(context.Background(), urls)
+	if len(results) != 3 {
+		t.Fatalf("expected a result for every URL, got %d", len(results))
+	}
+	if results[urls[0]].Error != "" || results[urls[0]].Title == "" {
+		t.Errorf("unexpected result for the article: %+v", results[urls[0]])
+	}
+	if results[urls[1]].Error != "status 404" {
+		t.Errorf("unexpected result for the missing page: %+v", results[urls[1]])
+	}
+	if results[urls[2]].Error == "" {
+		t.Errorf("expected non-HTML responses to fail, got %+v", results[urls[2]])
+	}
+}
+
+func TestService_RefreshAppliesSiteRules(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, &requests)
+	cache := newMockCache()
+	service := NewService(interfaces.Dependencies{Cache: cache, Logger: &mockLogger{}})
+	service.SetSiteRules(&mockSiteRules{rule: &domain.SiteRule{Hosts: []string{"*"}, Title: "p"}})
+	ctx := context.Background()
+
+	if err := service.Refresh(ctx, server.URL+"/missing"); err == nil {
+		t.Error("expected Refresh to return the fetch error")
+	}
+	if err := service.Refresh(ctx, server.URL+"/post"); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if _, ok := cache.data[cacheKey(server.URL+"/post")]; !ok {
+		t.Fatal("expected refreshed metadata to be cached")
+	}
+
+	result, _ := service.Extract(ctx, server.URL+"/post")
+	if result.Title != "Interfaces are satisfied implicitly." {
+		t.Errorf("expected the site rule title, got %q", result.Title)
+	}
+	if requests != 2 {
+		t.Errorf("expected Extract to use the refreshed cache, got %d requests", requests)
+	}
+}
@@ -0,0 +1,372 @@
+// ABOUTME: Publish channel aggregates parsed feeds into a single publishable feed
+// ABOUTME: Merges, filters, sorts and assigns stable GUIDs before rendering
+
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/language"
+)
+
+// Format is an output feed format
+type Format string
+
+const (
+	// FormatRSS renders RSS 2.0
+	FormatRSS Format = "rss"
+
+	// FormatAtom renders Atom 1.0
+	FormatAtom Format = "atom"
+
+	// FormatJSON renders JSON Feed 1.1
+	FormatJSON Format = "json"
+
+	// defaultItemLimit is how many items a channel holds unless configured
+	defaultItemLimit = 50
+
+	// maxItemLimit caps the configurable item limit
+	maxItemLimit = 500
+)
+
+// ParseFormat converts a format name (or file extension) into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "rss", "rss2", "xml":
+		return FormatRSS, nil
+	case "atom":
+		return FormatAtom, nil
+	case "json", "jsonfeed":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported feed format: %s", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/rss+xml; charset=utf-8"
+	}
+}
+
+// Filter selects which items of the source feeds are published
+type Filter struct {
+	// Query keeps items whose title, description or content contain every term
+	Query string
+
+	// Categories keeps items tagged with at least one of these categories,
+	// either by the publisher or as an auto-tag
+	Categories []string
+
+	// Languages keeps items in one of these languages, compared by primary
+	// subtag; items whose language could not be detected are kept
+	Languages []string
+
+	// Since keeps items published at or after this time
+	Since time.Time
+}
+
+// Options configures how feeds are aggregated into a channel
+type Options struct {
+	// Title overrides the channel title
+	Title string
+
+	// Description overrides the channel description
+	Description string
+
+	// Link overrides the channel's website link
+	Link string
+
+	// SelfURL is the URL the rendered feed is served from
+	SelfURL string
+
+	// Filter selects the published items
+	Filter Filter
+
+	// Limit is the maximum number of items (defaults to 50)
+	Limit int
+
+	// Stories collapses the items of each story into one entry: the
+	// highest ranked member that passes the filter, usually the representative
+	Stories []domain.StoryCluster
+}
+
+// Entry is a published item along with the feed it came from
+type Entry struct {
+	domain.FeedItem
+
+	// GUID is the stable identifier of the item in the published feed
+	GUID string
+
+	// FeedTitle is the title of the source feed
+	FeedTitle string
+
+	// FeedURL is the URL of the source feed
+	FeedURL string
+
+	// Story is the story the entry stands for when stories are collapsed
+	Story *domain.StoryCluster
+
+	// storyRank orders the members of a story, the representative first
+	storyRank int
+}
+
+// Channel is an aggregated feed ready to be rendered
+type Channel struct {
+	Title       string
+	Description string
+	Link        string
+	SelfURL     string
+	Language    string
+	Image       string
+	Author      string
+	Updated     time.Time
+
+	// Podcast is set when every source feed is a podcast, enabling iTunes tags
+	Podcast bool
+
+	Items []Entry
+}
+
+// BuildChannel merges feeds into a channel, newest items first.
+// A single source feed keeps its own title, link and artwork.
+func BuildChannel(feeds []*domain.Feed, opts Options) (*Channel, error) {
+	sourceFeeds := make([]*domain.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if feed != nil {
+			sourceFeeds = append(sourceFeeds, feed)
+		}
+	}
+	if len(sourceFeeds) == 0 {
+		return nil, errors.New("no feeds to publish")
+	}
+
+	ch := &Channel{
+		SelfURL: opts.SelfURL,
+		Podcast: true,
+	}
+
+	if len(sourceFeeds) == 1 {
+		feed := sourceFeeds[0]
+		ch.Title = feed.Title
+		ch.Description = feed.Description
+		ch.Link = feed.Link
+		ch.Language = feed.Language
+		ch.Image = feed.Image
+		if feed.Author != nil {
+			ch.Author = feed.Author.Name
+		}
+	} else {
+		titles := make([]string, 0, len(sourceFeeds))
+		for _, feed := range sourceFeeds {
+			titles = append(titles, feed.Title)
+		}
+		ch.Title = "Digest"
+		ch.Description = "Aggregated from " + strings.Join(titles, ", ")
+	}
+
+	if opts.Title != "" {
+		ch.Title = opts.Title
+	}
+	if opts.Description != "" {
+		ch.Description = opts.Description
+	}
+	if opts.Link != "" {
+		ch.Link = opts.Link
+	}
+	if ch.Link == "" {
+		ch.Link = opts.SelfURL
+	}
+
+	stories := indexStories(opts.Stories)
+	storyEntries := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, feed := range sourceFeeds {
+		if feed.FeedType != "podcast" {
+			ch.Podcast = false
+		}
+
+		for _, item := range feed.Items {
+			if !opts.Filter.matches(item) {
+				continue
+			}
+
+			entry := Entry{
+				FeedItem:  item,
+				GUID:      ItemGUID(feed.URL, item),
+				FeedTitle: feed.Title,
+				FeedURL:   feed.URL,
+			}
+			if seen[entry.GUID] {
+				continue
+			}
+			seen[entry.GUID] = true
+
+			if story, ok := stories[item.ClusterID]; ok {
+				entry.Story = story.cluster
+				rank, ok := story.ranks[storyMemberKey(feed.URL, item.ID, item.Link)]
+				if !ok {
+					rank = len(story.cluster.Members)
+				}
+				entry.storyRank = rank
+				if index, collapsed := storyEntries[item.ClusterID]; collapsed {
+					if entry.storyRank < ch.Items[index].storyRank {
+						ch.Items[index] = entry
+					}
+					continue
+				}
+				storyEntries[item.ClusterID] = len(ch.Items)
+			}
+			ch.Items = append(ch.Items, entry)
+		}
+	}
+
+	sort.SliceStable(ch.Items, func(i, j int) bool {
+		return ch.Items[i].Published.After(ch.Items[j].Published)
+	})
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultItemLimit
+	}
+	if limit > maxItemLimit {
+		limit = maxItemLimit
+	}
+	if len(ch.Items) > limit {
+		ch.Items = ch.Items[:limit]
+	}
+
+	for _, entry := range ch.Items {
+		if entry.Published.After(ch.Updated) {
+			ch.Updated = entry.Published
+		}
+	}
+	if ch.Updated.IsZero() {
+		for _, feed := range sourceFeeds {
+			if feed.LastUpdated.After(ch.Updated) {
+				ch.Updated = feed.LastUpdated
+			}
+		}
+	}
+
+	return ch, nil
+}
+
+// related returns the other items of the entry's story
+func (e Entry) related() []domain.StoryMember {
+	if e.Story == nil {
+		return nil
+	}
+	related := make([]domain.StoryMember, 0, len(e.Story.Members))
+	for rank, member := range e.Story.Members {
+		if rank != e.storyRank {
+			related = append(related, member)
+		}
+	}
+	return related
+}
+
+// story is a cluster with the rank of each of its members
+type story struct {
+	cluster *domain.StoryCluster
+	ranks   map[string]int
+}
+
+// indexStories maps cluster IDs to their stories
+func indexStories(clusters []domain.StoryCluster) map[string]story {
+	stories := make(map[string]story, len(clusters))
+	for i := range clusters {
+		ranks := make(map[string]int, len(clusters[i].Members))
+		for rank, member := range clusters[i].Members {
+			key := storyMemberKey(member.FeedURL, member.ItemID, member.Link)
+			if _, ok := ranks[key]; !ok {
+				ranks[key] = rank
+			}
+		}
+		stories[clusters[i].ID] = story{cluster: &clusters[i], ranks: ranks}
+	}
+	return stories
+}
+
+// storyMemberKey identifies an item among the members of a story by its
+// ID, or by its link when it has none
+func storyMemberKey(feedURL, itemID, link string) string {
+	if itemID != "" {
+		return feedURL + "\x00" + itemID
+	}
+	return feedURL + "\x00\x00" + link
+}
+
+// ItemGUID returns a stable identifier for an item. Absolute URL identifiers
+// are kept as-is; anything else is hashed with the feed URL so that
+// identifiers such as "1" or "post-42" cannot collide across aggregated feeds.
+func ItemGUID(feedURL string, item domain.FeedItem) string {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		id = strings.TrimSpace(item.Link)
+	}
+
+	if u, err := url.Parse(id); err == nil && u.IsAbs() && u.Host != "" {
+		return id
+	}
+
+	if id == "" {
+		id = item.Title + "|" + item.Published.UTC().Format(time.RFC3339)
+	}
+
+	sum := sha256.Sum256([]byte(feedURL + "|" + id))
+	return "urn:sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// matches reports whether an item passes the filter
+func (f Filter) matches(item domain.FeedItem) bool {
+	if !f.Since.IsZero() && item.Published.Before(f.Since) {
+		return false
+	}
+
+	if !language.Keep(item.Language, f.Languages, false) {
+		return false
+	}
+
+	if len(f.Categories) > 0 {
+		tags := make([]string, 0, len(item.Categories)+len(item.AutoTags))
+		tags = append(append(tags, item.Categories...), item.AutoTags...)
+
+		found := false
+		for _, want := range f.Categories {
+			for _, category := range tags {
+				if strings.EqualFold(strings.TrimSpace(category), strings.TrimSpace(want)) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if query := strings.TrimSpace(f.Query); query != "" {
+		text := strings.ToLower(item.Title + " " + item.Description + " " + item.Content)
+		for _, term := range strings.Fields(strings.ToLower(query)) {
+			if !strings.Contains(text, term) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+// ABOUTME: Renders a list of feeds as an OPML 2.0 subscription list
+// ABOUTME: Lets feed collections such as shares be imported into any feed reader
+
+package publish
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+// OPMLContentType is the MIME type of OPML documents
+const OPMLContentType = "text/x-opml; charset=utf-8"
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Head    opmlHead      `xml:"head"`
+	Outline []opmlOutline `xml:"body>outline"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlOutline struct {
+	Type        string `xml:"type,attr"`
+	Text        string `xml:"text,attr"`
+	Title       string `xml:"title,attr,omitempty"`
+	XMLURL      string `xml:"xmlUrl,attr"`
+	HTMLURL     string `xml:"htmlUrl,attr,omitempty"`
+	Description string `xml:"description,attr,omitempty"`
+	Language    string `xml:"language,attr,omitempty"`
+}
+
+// RenderOPML lists the feed URLs as OPML outlines. Parsed feeds, matched by
+// URL, supply titles and links; URLs that could not be parsed are still listed.
+// Feed readers cannot fetch internal source URLs such as scraper:// or
+// sitemap+https://, so those are listed through publishURL, the RSS publish
+// endpoint, or left out when it is empty.
+func RenderOPML(title string, created time.Time, urls []string, feeds []*domain.Feed, publishURL string) ([]byte, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no feeds to export")
+	}
+
+	parsed := make(map[string]*domain.Feed, len(feeds))
+	for _, feed := range feeds {
+		if feed != nil {
+			parsed[feed.URL] = feed
+		}
+	}
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Head:    opmlHead{Title: title},
+	}
+	if !created.IsZero() {
+		doc.Head.DateCreated = created.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, u := range urls {
+		xmlURL, ok := subscribableURL(u, publishURL)
+		if !ok {
+			continue
+		}
+
+		outline := opmlOutline{Type: "rss", Text: u, XMLURL: xmlURL}
+		if feed, ok := parsed[u]; ok {
+			if feed.Title != "" {
+				outline.Text = feed.Title
+				outline.Title = feed.Title
+			}
+			outline.HTMLURL = feed.Link
+			outline.Description = feed.Description
+			outline.Language = feed.Language
+		}
+		doc.Outline = append(doc.Outline, outline)
+	}
+	if len(doc.Outline) == 0 {
+		return nil, errors.New("no feeds to export")
+	}
+
+	return marshalXML(doc)
+}
+
+// subscribableURL returns the URL a feed reader can subscribe to a feed at
+func subscribableURL(feedURL, publishURL string) (string, bool) {
+	if parsed, err := url.Parse(feedURL); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		return feedURL, true
+	}
+	if publishURL == "" {
+		return "", false
+	}
+	return publishURL + "?url=" + url.QueryEscape(feedURL), true
+}
@@ -0,0 +1,389 @@
+package publish
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func testFeeds() []*domain.Feed {
+	published := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return []*domain.Feed{
+		{
+			Title: "Blog A",
+			URL:   "https://a.example.com/feed.xml",
+			Link:  "https://a.example.com",
+			Items: []domain.FeedItem{
+				{ID: "1", Title: "Go generics", Link: "https://a.example.com/generics", Description: "About generics", Published: published, Categories: []string{"go"}},
+				{ID: "https://a.example.com/old", Title: "Old post", Link: "https://a.example.com/old", Published: published.Add(-48 * time.Hour)},
+			},
+		},
+		{
+			Title: "Blog B",
+			URL:   "https://b.example.com/rss",
+			Link:  "https://b.example.com",
+			Items: []domain.FeedItem{
+				{ID: "1", Title: "Rust traits", Link: "https://b.example.com/traits", ContentEncoded: "<p>Traits</p>", Published: published.Add(time.Hour), Categories: []string{"rust"}},
+			},
+		},
+	}
+}
+
+func podcastFeed() *domain.Feed {
+	return &domain.Feed{
+		Title:    "The Show",
+		URL:      "https://pod.example.com/feed",
+		Link:     "https://pod.example.com",
+		Image:    "https://pod.example.com/art.jpg",
+		FeedType: "podcast",
+		Author:   &domain.Author{Name: "Host"},
+		Items: []domain.FeedItem{
+			{
+				ID:          "ep-1",
+				Title:       "Episode 1",
+				Link:        "https://pod.example.com/1",
+				Published:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Enclosures:  []domain.Enclosure{{URL: "https://pod.example.com/1.mp3", Length: "1234", Type: "audio/mpeg"}},
+				Duration:    "00:28:19",
+				Episode:     1,
+				EpisodeType: "full",
+			},
+		},
+	}
+}
+
+func TestBuildChannel_MergesSortsAndFilters(t *testing.T) {
+	ch, err := BuildChannel(testFeeds(), Options{SelfURL: "https://api.example.com/publish/rss"})
+	if err != nil {
+		t.Fatalf("BuildChannel returned error: %v", err)
+	}
+
+	if len(ch.Items) != 3 || ch.Items[0].Title != "Rust traits" {
+		t.Fatalf("expected three items newest first, got %+v", ch.Items)
+	}
+	if ch.Items[0].GUID == ch.Items[1].GUID {
+		t.Error("items with the same ID in different feeds must get different GUIDs")
+	}
+	if ch.Items[2].GUID != "https://a.example.com/old" {
+		t.Errorf("URL identifiers should be kept, got %q", ch.Items[2].GUID)
+	}
+	if ch.Link != "https://api.example.com/publish/rss" || ch.Podcast {
+		t.Errorf("unexpected channel: %+v", ch)
+	}
+
+	filtered, _ := BuildChannel(testFeeds(), Options{Filter: Filter{Query: "GENERICS"}})
+	if len(filtered.Items) != 1 || filtered.Items[0].Title != "Go generics" {
+		t.Errorf("query filter failed: %+v", filtered.Items)
+	}
+
+	filtered, _ = BuildChannel(testFeeds(), Options{Filter: Filter{Categories: []string{"Rust"}}, Limit: 5})
+	if len(filtered.Items) != 1 || filtered.Items[0].Title != "Rust traits" {
+		t.Errorf("category filter failed: %+v", filtered.Items)
+	}
+
+	tagged := testFeeds()
+	tagged[0].Items[0].AutoTags = []string{"Type Parameters"}
+	filtered, _ = BuildChannel(tagged, Options{Filter: Filter{Categories: []string{"type parameters"}}})
+	if len(filtered.Items) != 1 || filtered.Items[0].Title != "Go generics" {
+		t.Errorf("category filter should match auto-tags: %+v", filtered.Items)
+	}
+
+	languages := testFeeds()
+	languages[0].Items[0].Language = "en-US"
+	languages[1].Items[0].Language = "de"
+	filtered, _ = BuildChannel(languages, Options{Filter: Filter{Languages: []string{"EN"}}})
+	if len(filtered.Items) != 2 || filtered.Items[0].Title != "Go generics" || filtered.Items[1].Title != "Old post" {
+		t.Errorf("language filter should drop other languages and keep undetected ones: %+v", filtered.Items)
+	}
+
+	limited, _ := BuildChannel(testFeeds(), Options{Limit: 1})
+	if len(limited.Items) != 1 {
+		t.Errorf("limit not applied, got %d items", len(limited.Items))
+	}
+
+	if _, err := BuildChannel(nil, Options{}); err == nil {
+		t.Error("expected error without feeds")
+	}
+}
+
+func TestBuildChannel_CollapsesStories(t *testing.T) {
+	launch := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	feeds := []*domain.Feed{
+		{Title: "Wire", URL: "https://wire.example.com/feed", Items: []domain.FeedItem{
+			{ID: "w1", Title: "Launch announced", Link: "https://wire.example.com/launch", Published: launch, ClusterID: "story-1"},
+		}},
+		{Title: "Blog", URL: "https://blog.example.com/feed", Items: []domain.FeedItem{
+			{ID: "b1", Title: "Launch announced (copy)", Link: "https://blog.example.com/launch", Published: launch.Add(time.Hour), ClusterID: "story-1"},
+			{ID: "b2", Title: "Unrelated", Link: "https://blog.example.com/other", Published: launch.Add(2 * time.Hour)},
+		}},
+	}
+	stories := []domain.StoryCluster{{
+		ID: "story-1",
+		Members: []domain.StoryMember{
+			{FeedURL: "https://wire.example.com/feed", ItemID: "w1", Title: "Launch announced", Link: "https://wire.example.com/launch", Published: launch},
+			{FeedURL: "https://blog.example.com/feed", ItemID: "b1", Title: "Launch announced (copy)", Link: "https://blog.example.com/launch", Published: launch.Add(time.Hour)},
+		},
+	}}
+
+	ch, err := BuildChannel(feeds, Options{Stories: stories})
+	if err != nil {
+		t.Fatalf("BuildChannel returned error: %v", err)
+	}
+	if len(ch.Items) != 2 || ch.Items[1].Title != "Launch announced" || ch.Items[1].Story == nil {
+		t.Fatalf("expected the story to collapse into its representative, got %+v", ch.Items)
+	}
+
+	// The next member stands in when the filter drops the representative
+	filtered, _ := BuildChannel(feeds, Options{Stories: stories, Filter: Filter{Query: "copy"}})
+	if len(filtered.Items) != 1 || filtered.Items[0].Title != "Launch announced (copy)" {
+		t.Fatalf("expected the remaining member to represent the story, got %+v", filtered.Items)
+	}
+
+	body, err := Render(ch, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	var feed struct {
+		Items []struct {
+			Title string `json:"title"`
+			Story *struct {
+				ID      string `json:"id"`
+				Related []struct {
+					URL string `json:"url"`
+				} `json:"related"`
+			} `json:"_digests_story"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("rendered JSON Feed is not valid: %v", err)
+	}
+	story := feed.Items[1].Story
+	if feed.Items[0].Story != nil || story == nil || story.ID != "story-1" || len(story.Related) != 1 || story.Related[0].URL != "https://blog.example.com/launch" {
+		t.Errorf("unexpected story extension: %s", body)
+	}
+
+	atom, _ := Render(ch, FormatAtom)
+	if !strings.Contains(string(atom), `href="https://blog.example.com/launch" rel="related"`) {
+		t.Errorf("expected related links in Atom entries:\n%s", atom)
+	}
+}
+
+func TestItemGUID_Stable(t *testing.T) {
+	item := domain.FeedItem{ID: "42", Title: "Post"}
+	first := ItemGUID("https://example.com/feed", item)
+	if first != ItemGUID("https://example.com/feed", item) {
+		t.Error("GUID must be stable")
+	}
+	if !strings.HasPrefix(first, "urn:sha256:") {
+		t.Errorf("GUID = %q, want hashed URN", first)
+	}
+}
+
+func TestRender_RSS(t *testing.T) {
+	ch, _ := BuildChannel(testFeeds(), Options{Title: "My Digest", SelfURL: "https://api.example.com/publish/rss"})
+
+	body, err := Render(ch, FormatRSS)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				GUID struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+				Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("rendered RSS is not valid XML: %v\n%s", err, body)
+	}
+	if doc.Channel.Title != "My Digest" || len(doc.Channel.Items) != 3 {
+		t.Fatalf("unexpected channel: %+v", doc.Channel)
+	}
+	if doc.Channel.Items[0].Content != "<p>Traits</p>" {
+		t.Errorf("content:encoded = %q", doc.Channel.Items[0].Content)
+	}
+	if doc.Channel.Items[2].GUID.IsPermaLink != "true" {
+		t.Error("expected URL GUID equal to link to be a permalink")
+	}
+	if _, err := time.Parse(time.RFC1123Z, doc.Channel.Items[0].PubDate); err != nil {
+		t.Errorf("pubDate not RFC 1123: %v", err)
+	}
+	if strings.Contains(string(body), "itunes") {
+		t.Error("non-podcast feeds must not include iTunes tags")
+	}
+}
+
+func TestRender_RSSPodcast(t *testing.T) {
+	ch, _ := BuildChannel([]*domain.Feed{podcastFeed()}, Options{})
+	body, err := Render(ch, FormatRSS)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	out := string(body)
+	for _, want := range []string{
+		`xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`,
+		`<itunes:author>Host</itunes:author>`,
+		`<itunes:image href="https://pod.example.com/art.jpg">`,
+		`<itunes:duration>00:28:19</itunes:duration>`,
+		`<itunes:episode>1</itunes:episode>`,
+		`<enclosure url="https://pod.example.com/1.mp3" length="1234" type="audio/mpeg">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("podcast RSS missing %s\n%s", want, out)
+		}
+	}
+}
+
+func TestRender_Atom(t *testing.T) {
+	ch, _ := BuildChannel([]*domain.Feed{podcastFeed()}, Options{SelfURL: "https://api.example.com/publish/atom"})
+	body, err := Render(ch, FormatAtom)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	var feed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Entries []struct {
+			ID    string `xml:"id"`
+			Links []struct {
+				Rel    string `xml:"rel,attr"`
+				Href   string `xml:"href,attr"`
+				Length string `xml:"length,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("rendered Atom is not valid: %v\n%s", err, body)
+	}
+	if feed.ID != "https://api.example.com/publish/atom" || len(feed.Entries) != 1 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	foundEnclosure := false
+	for _, link := range feed.Entries[0].Links {
+		if link.Rel == "enclosure" && link.Href == "https://pod.example.com/1.mp3" && link.Length == "1234" {
+			foundEnclosure = true
+		}
+	}
+	if !foundEnclosure {
+		t.Errorf("expected enclosure link, got %+v", feed.Entries[0].Links)
+	}
+}
+
+func TestRender_JSONFeed(t *testing.T) {
+	ch, _ := BuildChannel([]*domain.Feed{podcastFeed()}, Options{})
+	body, err := Render(ch, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	var feed struct {
+		Version string `json:"version"`
+		Items   []struct {
+			ID          string `json:"id"`
+			ContentText string `json:"content_text"`
+			Attachments []struct {
+				MimeType          string `json:"mime_type"`
+				SizeInBytes       int64  `json:"size_in_bytes"`
+				DurationInSeconds int    `json:"duration_in_seconds"`
+			} `json:"attachments"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("rendered JSON Feed is not valid: %v", err)
+	}
+	if feed.Version != "https://jsonfeed.org/version/1.1" || len(feed.Items) != 1 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+	attachment := feed.Items[0].Attachments[0]
+	if attachment.MimeType != "audio/mpeg" || attachment.SizeInBytes != 1234 || attachment.DurationInSeconds != 1699 {
+		t.Errorf("unexpected attachment: %+v", attachment)
+	}
+	if feed.Items[0].ContentText == "" {
+		t.Error("items without HTML content need content_text")
+	}
+}
+
+func TestETag(t *testing.T) {
+	etag := ETag([]byte("body"))
+	if etag != ETag([]byte("body")) || etag == ETag([]byte("other")) {
+		t.Error("ETag must be deterministic and content-based")
+	}
+
+	if !MatchesETag(etag, etag) || !MatchesETag(`"x", W/`+etag, etag) || !MatchesETag("*", etag) {
+		t.Error("expected If-None-Match to match")
+	}
+	if MatchesETag("", etag) || MatchesETag(`"other"`, etag) {
+		t.Error("expected If-None-Match not to match")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"rss": FormatRSS, "XML": FormatRSS, "atom": FormatAtom, "json": FormatJSON} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestRenderOPML(t *testing.T) {
+	urls := []string{"https://a.example.com/feed.xml", "https://unparsed.example.com/rss", "scraper://news"}
+	body, err := RenderOPML("Shared feeds", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), urls, testFeeds(), "https://api.example.com/publish/rss")
+	if err != nil {
+		t.Fatalf("RenderOPML returned error: %v", err)
+	}
+
+	var doc struct {
+		Title    string `xml:"head>title"`
+		Outlines []struct {
+			Text    string `xml:"text,attr"`
+			XMLURL  string `xml:"xmlUrl,attr"`
+			HTMLURL string `xml:"htmlUrl,attr"`
+		} `xml:"body>outline"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("rendered OPML is not valid: %v\n%s", err, body)
+	}
+	if doc.Title != "Shared feeds" || len(doc.Outlines) != 3 {
+		t.Fatalf("unexpected OPML: %+v", doc)
+	}
+	if doc.Outlines[0].Text != "Blog A" || doc.Outlines[0].HTMLURL != "https://a.example.com" {
+		t.Errorf("parsed feeds should supply titles and links: %+v", doc.Outlines[0])
+	}
+	if doc.Outlines[1].Text != urls[1] || doc.Outlines[1].XMLURL != urls[1] {
+		t.Errorf("unparsed feeds should still be listed: %+v", doc.Outlines[1])
+	}
+
+	if doc.Outlines[2].XMLURL != "https://api.example.com/publish/rss?url=scraper%3A%2F%2Fnews" {
+		t.Errorf("internal feed URLs should be listed through the publish endpoint: %+v", doc.Outlines[2])
+	}
+
+	// Without a publish endpoint, internal feed URLs are left out
+	body, _ = RenderOPML("Shared feeds", time.Time{}, urls, nil, "")
+	if strings.Contains(string(body), "scraper://") {
+		t.Errorf("internal feed URLs should be skipped:\n%s", body)
+	}
+	if _, err := RenderOPML("Internal", time.Time{}, []string{"sitemap+https://example.com/sitemap.xml"}, nil, ""); err == nil {
+		t.Error("expected error when no feed can be subscribed to")
+	}
+
+	if _, err := RenderOPML("Empty", time.Time{}, nil, nil, ""); err == nil {
+		t.Error("expected error without feeds")
+	}
+}
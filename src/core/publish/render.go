@@ -0,0 +1,538 @@
+// ABOUTME: Renders publish channels as RSS 2.0, Atom 1.0 or JSON Feed 1.1
+// ABOUTME: Also computes ETags so clients can revalidate rendered feeds
+
+package publish
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Render serializes a channel in the given format
+func Render(ch *Channel, format Format) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return renderRSS(ch)
+	case FormatAtom:
+		return renderAtom(ch)
+	case FormatJSON:
+		return renderJSONFeed(ch)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: %s", format)
+	}
+}
+
+// ETag returns a strong entity tag for a rendered feed
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// MatchesETag reports whether an If-None-Match header value matches the ETag
+func MatchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalXML encodes an XML document with a declaration header
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// RSS 2.0 with the iTunes, content and Atom namespaces
+
+type rssDocument struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	XMLNSAtom    string     `xml:"xmlns:atom,attr"`
+	XMLNSContent string     `xml:"xmlns:content,attr"`
+	XMLNSITunes  string     `xml:"xmlns:itunes,attr,omitempty"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string       `xml:"title"`
+	Link           string       `xml:"link"`
+	Description    string       `xml:"description"`
+	Language       string       `xml:"language,omitempty"`
+	LastBuildDate  string       `xml:"lastBuildDate,omitempty"`
+	Generator      string       `xml:"generator"`
+	AtomLink       *atomLink    `xml:"atom:link,omitempty"`
+	Image          *rssImage    `xml:"image,omitempty"`
+	ITunesAuthor   string       `xml:"itunes:author,omitempty"`
+	ITunesImage    *itunesImage `xml:"itunes:image,omitempty"`
+	ITunesExplicit string       `xml:"itunes:explicit,omitempty"`
+	Items          []rssItem    `xml:"item"`
+}
+
+type rssImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Value string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title             string        `xml:"title"`
+	Link              string        `xml:"link,omitempty"`
+	Description       string        `xml:"description,omitempty"`
+	ContentEncoded    *cdata        `xml:"content:encoded,omitempty"`
+	Author            string        `xml:"author,omitempty"`
+	Categories        []string      `xml:"category"`
+	GUID              rssGUID       `xml:"guid"`
+	PubDate           string        `xml:"pubDate,omitempty"`
+	Enclosure         *rssEnclosure `xml:"enclosure,omitempty"`
+	Source            *rssSource    `xml:"source,omitempty"`
+	ITunesDuration    string        `xml:"itunes:duration,omitempty"`
+	ITunesEpisode     int           `xml:"itunes:episode,omitempty"`
+	ITunesSeason      int           `xml:"itunes:season,omitempty"`
+	ITunesEpisodeType string        `xml:"itunes:episodeType,omitempty"`
+	ITunesSubtitle    string        `xml:"itunes:subtitle,omitempty"`
+	ITunesSummary     string        `xml:"itunes:summary,omitempty"`
+	ITunesImage       *itunesImage  `xml:"itunes:image,omitempty"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+func renderRSS(ch *Channel) ([]byte, error) {
+	doc := rssDocument{
+		Version:      "2.0",
+		XMLNSAtom:    "http://www.w3.org/2005/Atom",
+		XMLNSContent: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:       ch.Title,
+			Link:        ch.Link,
+			Description: ch.Description,
+			Language:    ch.Language,
+			Generator:   "Digests API",
+			Items:       make([]rssItem, 0, len(ch.Items)),
+		},
+	}
+
+	if !ch.Updated.IsZero() {
+		doc.Channel.LastBuildDate = ch.Updated.UTC().Format(time.RFC1123Z)
+	}
+	if ch.SelfURL != "" {
+		doc.Channel.AtomLink = &atomLink{Href: ch.SelfURL, Rel: "self", Type: FormatRSS.mediaType()}
+	}
+	if ch.Image != "" {
+		doc.Channel.Image = &rssImage{URL: ch.Image, Title: ch.Title, Link: ch.Link}
+	}
+	if ch.Podcast {
+		doc.XMLNSITunes = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+		doc.Channel.ITunesAuthor = ch.Author
+		doc.Channel.ITunesExplicit = "false"
+		if ch.Image != "" {
+			doc.Channel.ITunesImage = &itunesImage{Href: ch.Image}
+		}
+	}
+
+	for _, entry := range ch.Items {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Description,
+			Author:      entry.Author,
+			Categories:  entry.Categories,
+			GUID:        rssGUID{IsPermaLink: "false", Value: entry.GUID},
+		}
+		if entry.GUID == entry.Link {
+			item.GUID.IsPermaLink = "true"
+		}
+		if html := entryHTML(entry); html != "" && html != entry.Description {
+			item.ContentEncoded = &cdata{Value: html}
+		}
+		if !entry.Published.IsZero() {
+			item.PubDate = entry.Published.UTC().Format(time.RFC1123Z)
+		}
+		// RSS allows a single enclosure per item
+		if len(entry.Enclosures) > 0 && entry.Enclosures[0].URL != "" {
+			enclosure := entry.Enclosures[0]
+			item.Enclosure = &rssEnclosure{URL: enclosure.URL, Length: enclosureLength(enclosure.Length), Type: enclosure.Type}
+		}
+		if entry.FeedURL != "" && entry.FeedURL != ch.SelfURL {
+			item.Source = &rssSource{URL: entry.FeedURL, Value: entry.FeedTitle}
+		}
+		if ch.Podcast {
+			item.ITunesDuration = entry.Duration
+			item.ITunesEpisode = entry.Episode
+			item.ITunesSeason = entry.Season
+			item.ITunesEpisodeType = entry.EpisodeType
+			item.ITunesSubtitle = entry.Subtitle
+			item.ITunesSummary = entry.Summary
+			if entry.Image != "" {
+				item.ITunesImage = &itunesImage{Href: entry.Image}
+			}
+		}
+
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return marshalXML(doc)
+}
+
+// Atom 1.0
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Icon     string      `xml:"icon,omitempty"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSource struct {
+	ID    string     `xml:"id"`
+	Title string     `xml:"title"`
+	Links []atomLink `xml:"link"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Source     *atomSource    `xml:"source,omitempty"`
+}
+
+func renderAtom(ch *Channel) ([]byte, error) {
+	updated := atomTime(ch.Updated)
+
+	feed := atomFeed{
+		ID:       ch.SelfURL,
+		Title:    ch.Title,
+		Subtitle: ch.Description,
+		Updated:  updated,
+		Icon:     ch.Image,
+		Entries:  make([]atomEntry, 0, len(ch.Items)),
+	}
+	if feed.ID == "" {
+		feed.ID = ch.Link
+	}
+	if ch.Link != "" {
+		feed.Links = append(feed.Links, atomLink{Href: ch.Link, Rel: "alternate", Type: "text/html"})
+	}
+	if ch.SelfURL != "" {
+		feed.Links = append(feed.Links, atomLink{Href: ch.SelfURL, Rel: "self", Type: FormatAtom.mediaType()})
+	}
+	if ch.Author != "" {
+		feed.Author = &atomPerson{Name: ch.Author}
+	}
+
+	for _, entry := range ch.Items {
+		ae := atomEntry{
+			ID:      entry.GUID,
+			Title:   entry.Title,
+			Updated: updated,
+		}
+		if !entry.Published.IsZero() {
+			ae.Published = atomTime(entry.Published)
+			ae.Updated = ae.Published
+		}
+		if entry.Link != "" {
+			ae.Links = append(ae.Links, atomLink{Href: entry.Link, Rel: "alternate", Type: "text/html"})
+		}
+		for _, enclosure := range entry.Enclosures {
+			if enclosure.URL == "" {
+				continue
+			}
+			ae.Links = append(ae.Links, atomLink{
+				Href:   enclosure.URL,
+				Rel:    "enclosure",
+				Type:   enclosure.Type,
+				Length: enclosureLength(enclosure.Length),
+			})
+		}
+		for _, member := range entry.related() {
+			if member.Link != "" {
+				ae.Links = append(ae.Links, atomLink{Href: member.Link, Rel: "related", Type: "text/html", Title: member.Title})
+			}
+		}
+		// Atom requires an author on every entry when the feed has none
+		author := entry.Author
+		if author == "" && ch.Author == "" {
+			author = entry.FeedTitle
+		}
+		if author != "" {
+			ae.Author = &atomPerson{Name: author}
+		}
+		if entry.Description != "" {
+			ae.Summary = &atomText{Type: "html", Value: entry.Description}
+		}
+		if html := entryHTML(entry); html != "" && html != entry.Description {
+			ae.Content = &atomText{Type: "html", Value: html}
+		}
+		for _, category := range entry.Categories {
+			ae.Categories = append(ae.Categories, atomCategory{Term: category})
+		}
+		if entry.FeedURL != "" && entry.FeedURL != ch.SelfURL {
+			ae.Source = &atomSource{
+				ID:    entry.FeedURL,
+				Title: entry.FeedTitle,
+				Links: []atomLink{{Href: entry.FeedURL, Rel: "self"}},
+			}
+		}
+
+		feed.Entries = append(feed.Entries, ae)
+	}
+
+	return marshalXML(feed)
+}
+
+// JSON Feed 1.1
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL               string `json:"url"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes,omitempty"`
+	DurationInSeconds int    `json:"duration_in_seconds,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+	Story         *jsonFeedStory       `json:"_digests_story,omitempty"`
+}
+
+// jsonFeedStory is an extension listing the other items of a collapsed story
+type jsonFeedStory struct {
+	ID      string                `json:"id"`
+	Related []jsonFeedStoryMember `json:"related"`
+}
+
+type jsonFeedStoryMember struct {
+	URL           string `json:"url,omitempty"`
+	Title         string `json:"title,omitempty"`
+	FeedURL       string `json:"feed_url"`
+	DatePublished string `json:"date_published,omitempty"`
+}
+
+func renderJSONFeed(ch *Channel) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       ch.Title,
+		HomePageURL: ch.Link,
+		FeedURL:     ch.SelfURL,
+		Description: ch.Description,
+		Icon:        ch.Image,
+		Language:    ch.Language,
+		Items:       make([]jsonFeedItem, 0, len(ch.Items)),
+	}
+	if ch.Author != "" {
+		feed.Authors = []jsonFeedAuthor{{Name: ch.Author}}
+	}
+
+	for _, entry := range ch.Items {
+		item := jsonFeedItem{
+			ID:      entry.GUID,
+			URL:     entry.Link,
+			Title:   entry.Title,
+			Summary: entry.Description,
+			Image:   entry.Thumbnail,
+			Tags:    entry.Categories,
+		}
+		if html := entryHTML(entry); html != "" {
+			item.ContentHTML = html
+		} else {
+			// JSON Feed requires content_html or content_text
+			item.ContentText = entry.Title
+		}
+		if !entry.Published.IsZero() {
+			item.DatePublished = entry.Published.Format(time.RFC3339)
+		}
+		if entry.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: entry.Author}}
+		}
+		for _, enclosure := range entry.Enclosures {
+			if enclosure.URL == "" {
+				continue
+			}
+			size, _ := strconv.ParseInt(enclosure.Length, 10, 64)
+			item.Attachments = append(item.Attachments, jsonFeedAttachment{
+				URL:               enclosure.URL,
+				MimeType:          enclosureType(enclosure.Type),
+				SizeInBytes:       size,
+				DurationInSeconds: durationSeconds(entry.Duration),
+			})
+		}
+
+		if entry.Story != nil {
+			item.Story = &jsonFeedStory{ID: entry.Story.ID, Related: []jsonFeedStoryMember{}}
+			for _, member := range entry.related() {
+				related := jsonFeedStoryMember{URL: member.Link, Title: member.Title, FeedURL: member.FeedURL}
+				if !member.Published.IsZero() {
+					related.DatePublished = member.Published.Format(time.RFC3339)
+				}
+				item.Story.Related = append(item.Story.Related, related)
+			}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	body, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+
+	return append(body, '\n'), nil
+}
+
+// mediaType returns the content type without parameters
+func (f Format) mediaType() string {
+	return strings.SplitN(f.ContentType(), ";", 2)[0]
+}
+
+// entryHTML returns the richest HTML content of an item
+func entryHTML(entry Entry) string {
+	switch {
+	case entry.ContentEncoded != "":
+		return entry.ContentEncoded
+	case entry.Content != "":
+		return entry.Content
+	default:
+		return entry.Description
+	}
+}
+
+// atomTime formats a time for Atom, which requires a timestamp on every feed
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// enclosureLength returns a valid length attribute; RSS requires one, "0" means unknown
+func enclosureLength(length string) string {
+	if _, err := strconv.ParseInt(length, 10, 64); err != nil {
+		return "0"
+	}
+	return length
+}
+
+// enclosureType returns the enclosure MIME type, falling back to a generic one
+func enclosureType(mimeType string) string {
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
+
+// durationSeconds converts "HH:MM:SS", "MM:SS" or plain seconds to seconds
+func durationSeconds(duration string) int {
+	if duration == "" {
+		return 0
+	}
+
+	total := 0
+	for _, part := range strings.Split(duration, ":") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
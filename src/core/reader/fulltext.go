@@ -0,0 +1,195 @@
+// ABOUTME: Builds reader views from full-text feed content without fetching the page
+// ABOUTME: Tells full articles in content:encoded apart from truncated teasers
+
+package reader
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// minFullTextWords is the shortest content:encoded treated as a full article
+	minFullTextWords = 150
+
+	// structuredFullTextWords is the length above which a single block of
+	// text counts as a full article; shorter content needs several paragraphs
+	structuredFullTextWords = 400
+
+	// minFullTextParagraphs is how many paragraphs shorter articles need
+	minFullTextParagraphs = 3
+)
+
+// truncationPattern matches the endings feeds add to truncated content
+var truncationPattern = regexp.MustCompile(`(?i)(…|\.\.\.|\[…\]|\[\.\.\.\]|\bread (the )?(full|more|rest)\b.{0,40}|\b(continue|keep) reading\b.{0,40})\s*[»→›]?\s*$`)
+
+// lineBreakParagraphs matches the double line breaks some feeds separate paragraphs with
+var lineBreakParagraphs = regexp.MustCompile(`(?i)<br\s*/?>\s*<br\s*/?>`)
+
+// feedFooterPattern matches footers full-text feeds append, which are not truncation
+var feedFooterPattern = regexp.MustCompile(`(?i)\s*the post .{1,300} appeared first on .{1,200}$`)
+
+// feedItemView builds a reader view from the feed item a page came from,
+// when the feed carries the full article. It returns false when the feed
+// cannot be read or only has a teaser, so the page is fetched instead.
+func (s *Service) feedItemView(ctx context.Context, target domain.ReaderTarget) (domain.ReaderView, bool) {
+	if s.feeds == nil || target.FeedURL == "" {
+		return domain.ReaderView{}, false
+	}
+
+	feed, err := s.feeds.ParseSingleFeed(ctx, target.FeedURL)
+	if err != nil || feed == nil {
+		s.deps.Logger.Debug("Feed context unavailable for reader view", map[string]interface{}{
+			"url":      target.URL,
+			"feed_url": target.FeedURL,
+		})
+		return domain.ReaderView{}, false
+	}
+
+	item := findFeedItem(feed, target)
+	if item == nil || !isFullText(item.ContentEncoded) {
+		return domain.ReaderView{}, false
+	}
+
+	content, text := cleanFeedContent(item.ContentEncoded, itemBaseURL(target.URL, item.Link))
+
+	view := domain.ReaderView{
+		URL:                target.URL,
+		Title:              item.Title,
+		Content:            content,
+		TextContent:        text,
+		Excerpt:            feedExcerpt(item),
+		Byline:             item.Author,
+		SiteName:           feed.Title,
+		Image:              firstNonEmpty(item.Image, item.Thumbnail),
+		Favicon:            feed.Favicon,
+		Language:           feed.Language,
+		LanguageConfidence: feed.LanguageConfidence,
+		Pages:              1,
+		Source:             domain.ReaderSourceFeed,
+		Status:             "ok",
+	}
+	if item.Language != "" {
+		view.Language, view.LanguageConfidence = item.Language, item.LanguageConfidence
+	}
+	if !item.Published.IsZero() {
+		published := item.Published
+		view.PublishedTime = &published
+	}
+
+	return view, true
+}
+
+// findFeedItem finds the target's item by ID, falling back to its link. The
+// item must link to the target page, so a feed cannot stand in for pages it
+// does not link to.
+func findFeedItem(feed *domain.Feed, target domain.ReaderTarget) *domain.FeedItem {
+	for i := range feed.Items {
+		if target.ItemID != "" && feed.Items[i].ID == target.ItemID {
+			if feed.Items[i].Link != target.URL {
+				return nil
+			}
+			return &feed.Items[i]
+		}
+	}
+	for i := range feed.Items {
+		if feed.Items[i].Link != "" && feed.Items[i].Link == target.URL {
+			return &feed.Items[i]
+		}
+	}
+	return nil
+}
+
+// isFullText decides whether feed content is the whole article rather than
+// a teaser: long enough, structured into paragraphs unless it is very long,
+// and not ending in a truncation marker
+func isFullText(contentHTML string) bool {
+	if strings.TrimSpace(contentHTML) == "" {
+		return false
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	if err != nil {
+		return false
+	}
+	text := normalizeSpace(doc.Text())
+	text = feedFooterPattern.ReplaceAllString(text, "")
+
+	words := countWords(text)
+	if words < minFullTextWords {
+		return false
+	}
+	if truncationPattern.MatchString(text) {
+		return false
+	}
+
+	paragraphs := doc.Find("p, li, blockquote, pre, h2, h3").Length()
+	if paragraphs == 0 {
+		paragraphs = len(lineBreakParagraphs.FindAllString(contentHTML, -1)) + 1
+	}
+	return paragraphs >= minFullTextParagraphs || words >= structuredFullTextWords
+}
+
+// cleanFeedContent sanitizes feed HTML, which comes from whoever publishes
+// the feed, and resolves relative links
+func cleanFeedContent(contentHTML string, base *url.URL) (string, string) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	if err != nil {
+		return "", ""
+	}
+
+	body := doc.Find("body")
+	sanitizeHTML(body, base)
+
+	content, err := body.Html()
+	if err != nil {
+		return "", ""
+	}
+	text := strings.TrimSpace(feedFooterPattern.ReplaceAllString(strings.TrimSpace(body.Text()), ""))
+	return "<div>" + strings.TrimSpace(content) + "</div>", text
+}
+
+// feedExcerpt returns the item's summary as plain text
+func feedExcerpt(item *domain.FeedItem) string {
+	return normalizeSpace(descriptionText(firstNonEmpty(item.Summary, item.Description)))
+}
+
+// descriptionText strips the HTML feeds often put in descriptions
+func descriptionText(description string) string {
+	if !strings.Contains(description, "<") {
+		return description
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(description))
+	if err != nil {
+		return description
+	}
+	return doc.Text()
+}
+
+// itemBaseURL returns the URL relative links in an item are resolved against
+func itemBaseURL(candidates ...string) *url.URL {
+	for _, candidate := range candidates {
+		if u, err := url.Parse(candidate); err == nil && u.Host != "" {
+			return u
+		}
+	}
+	return nil
+}
+
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
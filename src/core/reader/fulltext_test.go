@@ -0,0 +1,179 @@
+package reader
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// paragraphs returns n paragraphs of filler text, 30 words each
+func paragraphs(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString("<p>" + strings.TrimSpace(strings.Repeat("Feeds that publish the whole article save readers a trip to the website. ", 3)) + "</p>")
+	}
+	return b.String()
+}
+
+func TestIsFullText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"full article", paragraphs(8), true},
+		{"short teaser", "<p>Feeds that publish the whole article save readers a trip.</p>", false},
+		{"truncated with ellipsis", paragraphs(7) + "<p>And then the story continues…</p>", false},
+		{"read more link", paragraphs(7) + `<p><a href="/story">Continue reading &raquo;</a></p>`, false},
+		{"WordPress footer is not truncation", paragraphs(8) + `<p>The post <a href="/x">Feeds</a> appeared first on Example Blog.</p>`, true},
+		{"long single block", strings.Repeat("word ", 450), true},
+		{"short single block", strings.Repeat("word ", 200), false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFullText(tt.content); got != tt.want {
+				t.Errorf("isFullText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ReaderViewFromFeed(t *testing.T) {
+	published := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	feed := &domain.Feed{
+		Title:    "Example Blog",
+		URL:      "https://example.com/feed",
+		Language: "en",
+		Items: []domain.FeedItem{
+			{ID: "guid-1", Title: "Full story", Link: "https://example.com/full", Author: "Alex", Published: published,
+				ContentEncoded: `<script>alert(1)</script><img src="/img/a.png">` + paragraphs(8)},
+			{ID: "guid-2", Title: "Teaser", Link: "https://example.com/teaser", ContentEncoded: "<p>Just the start...</p>"},
+		},
+	}
+
+	var pageFetches int32
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				atomic.AddInt32(&pageFetches, 1)
+				return &mockResponse{statusCode: 200, body: articlePage}, nil
+			},
+		},
+		Logger: &mockLogger{},
+	})
+	service.SetFeedService(&mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			return feed, nil
+		},
+	})
+
+	views := service.ExtractReaderViewsFor(context.Background(), []domain.ReaderTarget{
+		{URL: "https://example.com/full", FeedURL: feed.URL, ItemID: "guid-1"},
+		{URL: "https://example.com/teaser", FeedURL: feed.URL},
+	})
+
+	full := views[0]
+	if full.Source != domain.ReaderSourceFeed || full.Status != "ok" {
+		t.Fatalf("expected the full-text item to be served from the feed, got %+v", full)
+	}
+	if full.Title != "Full story" || full.Byline != "Alex" || full.SiteName != "Example Blog" || full.PublishedTime == nil || !full.PublishedTime.Equal(published) {
+		t.Errorf("unexpected metadata: %+v", full)
+	}
+	if strings.Contains(full.Content, "<script") || !strings.Contains(full.Content, `src="https://example.com/img/a.png"`) {
+		t.Errorf("expected cleaned content with absolute links: %s", full.Content)
+	}
+	if full.WordCount < 200 || full.Markdown == "" {
+		t.Errorf("WordCount = %d, markdown = %q", full.WordCount, full.Markdown)
+	}
+
+	if views[1].Source != domain.ReaderSourcePage {
+		t.Errorf("expected the teaser to be fetched from the page, got source %q", views[1].Source)
+	}
+	if n := atomic.LoadInt32(&pageFetches); n != 1 {
+		t.Errorf("page fetches = %d, want only the teaser to be fetched", n)
+	}
+}
+
+func TestService_FeedViewsStayWithTheirFeed(t *testing.T) {
+	feed := &domain.Feed{
+		URL: "https://attacker.example/feed",
+		Items: []domain.FeedItem{
+			{ID: "guid-1", Link: "https://victim.example/article", ContentEncoded: paragraphs(8)},
+			{ID: "guid-2", Link: "https://attacker.example/other", ContentEncoded: paragraphs(8)},
+		},
+	}
+
+	var pageFetches int32
+	cache := newMockCache()
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				atomic.AddInt32(&pageFetches, 1)
+				return &mockResponse{statusCode: 200, body: articlePage}, nil
+			},
+		},
+		Cache:  cache,
+		Logger: &mockLogger{},
+	})
+	service.SetFeedService(&mockFeedService{
+		parseSingleFeedFunc: func(ctx context.Context, url string) (*domain.Feed, error) {
+			return feed, nil
+		},
+	})
+	ctx := context.Background()
+
+	views := service.ExtractReaderViewsFor(ctx, []domain.ReaderTarget{
+		{URL: "https://victim.example/article", FeedURL: feed.URL, ItemID: "guid-1"},
+		{URL: "https://victim.example/other", FeedURL: feed.URL, ItemID: "guid-2"},
+	})
+	if views[0].Source != domain.ReaderSourceFeed {
+		t.Fatalf("expected the matching item to be served from the feed, got %+v", views[0])
+	}
+	if views[1].Source != domain.ReaderSourcePage {
+		t.Errorf("expected an item linking elsewhere to be ignored, got source %q", views[1].Source)
+	}
+	if _, ok := cache.data[pageCacheKey("https://victim.example/article")]; ok {
+		t.Error("expected the feed view not to be cached under the page URL")
+	}
+
+	// Without the feed, the page itself is fetched
+	views = service.ExtractReaderViews(ctx, []string{"https://victim.example/article"})
+	if views[0].Source != domain.ReaderSourcePage {
+		t.Errorf("expected the page view, got source %q", views[0].Source)
+	}
+	if n := atomic.LoadInt32(&pageFetches); n != 2 {
+		t.Errorf("page fetches = %d, want 2", n)
+	}
+}
+
+func TestCleanFeedContent_Sanitizes(t *testing.T) {
+	base, _ := url.Parse("https://example.com/post")
+	content, _ := cleanFeedContent(`<p onclick="steal()" style="color:red">Hello <a href="javascript:alert(1)">bad</a> <a href="/ok">ok</a></p>`+
+		`<iframe src="https://evil.example"></iframe><custom-tag>kept text</custom-tag><img src="data:image/png;base64,AAAA" onerror="x()">`, base)
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse content: %v", err)
+	}
+	if doc.Find("[onclick], [onerror], [style], iframe, custom-tag").Length() > 0 {
+		t.Errorf("expected handlers, styles and unknown elements to be removed: %s", content)
+	}
+	if strings.Contains(content, "javascript:") || strings.Contains(content, "data:") {
+		t.Errorf("expected unsafe URLs to be removed: %s", content)
+	}
+	if href, _ := doc.Find("a").Last().Attr("href"); href != "https://example.com/ok" {
+		t.Errorf("expected relative links to be resolved, got %q", href)
+	}
+	if !strings.Contains(content, "kept text") {
+		t.Errorf("expected the text of unknown elements to be kept: %s", content)
+	}
+}
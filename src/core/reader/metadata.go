@@ -0,0 +1,187 @@
+// ABOUTME: Article metadata that readability does not extract itself
+// ABOUTME: Finds publish/modify dates and language in page markup and computes reading stats
+
+package reader
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"digests-app-api/core/domain"
+	utiltime "digests-app-api/pkg/utils/time"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// publishedSelectors locate the publish date, most authoritative first
+var publishedSelectors = []string{
+	`meta[property="article:published_time"]`,
+	`meta[name="article:published_time"]`,
+	`meta[itemprop="datePublished"]`,
+	`meta[name="pubdate"]`,
+	`meta[name="publish-date"]`,
+	`meta[name="date"]`,
+	`meta[name="DC.date.issued"]`,
+	`meta[name="dcterms.created"]`,
+	`time[itemprop="datePublished"]`,
+	`time[pubdate]`,
+}
+
+// modifiedSelectors locate the last modified date, most authoritative first
+var modifiedSelectors = []string{
+	`meta[property="article:modified_time"]`,
+	`meta[name="article:modified_time"]`,
+	`meta[property="og:updated_time"]`,
+	`meta[itemprop="dateModified"]`,
+	`meta[name="last-modified"]`,
+	`meta[name="dcterms.modified"]`,
+	`time[itemprop="dateModified"]`,
+}
+
+// pageMetadata is article metadata read from the page markup
+type pageMetadata struct {
+	published *time.Time
+	modified  *time.Time
+	language  string
+	next      string // next page of a paginated article
+	heroImage string // lead image selected by a site rule
+}
+
+// extractPageMetadata reads the dates, language and next page a page declares
+func extractPageMetadata(doc *goquery.Document, pageURL *url.URL) pageMetadata {
+	published, modified := articleDates(doc)
+	return pageMetadata{
+		published: published,
+		modified:  modified,
+		language:  pageLanguage(doc),
+		next:      nextPageURL(doc, pageURL),
+	}
+}
+
+// articleDates returns the publish and modified dates declared by a page,
+// preferring JSON-LD structured data over meta tags
+func articleDates(doc *goquery.Document) (published, modified *time.Time) {
+	ldPublished, ldModified := jsonLDDates(doc)
+
+	published = firstTime(ldPublished, selectorDate(doc, publishedSelectors))
+	modified = firstTime(ldModified, selectorDate(doc, modifiedSelectors))
+
+	return published, modified
+}
+
+// pageLanguage returns the language declared outside the <html lang> attribute
+func pageLanguage(doc *goquery.Document) string {
+	var lang string
+	doc.Find(`meta[http-equiv]`).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if strings.EqualFold(sel.AttrOr("http-equiv", ""), "content-language") {
+			lang = strings.TrimSpace(strings.Split(sel.AttrOr("content", ""), ",")[0])
+		}
+		return lang == ""
+	})
+	if lang != "" {
+		return lang
+	}
+	if locale, ok := doc.Find(`meta[property="og:locale"]`).First().Attr("content"); ok && strings.TrimSpace(locale) != "" {
+		return strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	}
+	return ""
+}
+
+// selectorDate returns the first date found by the selectors
+func selectorDate(doc *goquery.Document, selectors []string) string {
+	for _, selector := range selectors {
+		sel := doc.Find(selector).First()
+		if sel.Length() == 0 {
+			continue
+		}
+		for _, attr := range []string{"content", "datetime"} {
+			if value, ok := sel.Attr(attr); ok && strings.TrimSpace(value) != "" {
+				return value
+			}
+		}
+		if text := strings.TrimSpace(sel.Text()); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// jsonLDDates reads datePublished/dateModified from the page's JSON-LD
+// blocks, looking through arrays and @graph containers
+func jsonLDDates(doc *goquery.Document) (published, modified string) {
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		var data interface{}
+		if err := json.Unmarshal([]byte(sel.Text()), &data); err != nil {
+			return true
+		}
+		walkJSONLD(data, func(node map[string]interface{}) {
+			if value, ok := node["datePublished"].(string); ok && published == "" {
+				published = value
+			}
+			if value, ok := node["dateModified"].(string); ok && modified == "" {
+				modified = value
+			}
+		})
+		return published == "" || modified == ""
+	})
+	return published, modified
+}
+
+// walkJSONLD calls visit for every object in a JSON-LD document
+func walkJSONLD(data interface{}, visit func(map[string]interface{})) {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, child := range v {
+			walkJSONLD(child, visit)
+		}
+	case map[string]interface{}:
+		visit(v)
+		if graph, ok := v["@graph"]; ok {
+			walkJSONLD(graph, visit)
+		}
+	}
+}
+
+// firstTime parses the first of the values that is a valid date
+func firstTime(values ...string) *time.Time {
+	for _, value := range values {
+		if t := utiltime.ParseFlexibleTime(value); !t.IsZero() {
+			return &t
+		}
+	}
+	return nil
+}
+
+// countWords counts the words of a text. Scripts written without spaces
+// (Chinese, Japanese) count each character as a word.
+func countWords(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			count++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				count++
+				inWord = true
+			}
+		case r == '\'' || r == '’' || r == '-':
+			// Apostrophes and hyphens join the parts of a word
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
+
+// readingTime estimates how many minutes a text takes to read, rounding up
+func readingTime(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return (words + domain.ReadingWordsPerMinute - 1) / domain.ReadingWordsPerMinute
+}
@@ -0,0 +1,144 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// mockHTTPClient is a mock implementation of the HTTPClient interface
+type mockHTTPClient struct {
+	getFunc func(ctx context.Context, url string) (interfaces.Response, error)
+}
+
+func (m *mockHTTPClient) Get(ctx context.Context, url string) (interfaces.Response, error) {
+	if m.getFunc != nil {
+		return m.getFunc(ctx, url)
+	}
+	return nil, nil
+}
+
+func (m *mockHTTPClient) Post(ctx context.Context, url string, body io.Reader) (interfaces.Response, error) {
+	return nil, nil
+}
+
+// mockResponse is a mock implementation of the Response interface
+type mockResponse struct {
+	statusCode int
+	body       string
+	headers    map[string]string
+}
+
+func (m *mockResponse) StatusCode() int {
+	return m.statusCode
+}
+
+func (m *mockResponse) Body() io.ReadCloser {
+	return io.NopCloser(strings.NewReader(m.body))
+}
+
+func (m *mockResponse) Header(key string) string {
+	if m.headers != nil {
+		return m.headers[key]
+	}
+	return ""
+}
+
+// mockLogger is a mock implementation of the Logger interface
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, fields map[string]interface{}) {}
+func (m *mockLogger) Info(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Warn(msg string, fields map[string]interface{})  {}
+func (m *mockLogger) Error(msg string, fields map[string]interface{}) {}
+
+// mockSiteRules returns the same rule for every host
+type mockSiteRules struct {
+	rule *domain.SiteRule
+	host string
+}
+
+func (m *mockSiteRules) Match(host string) *domain.SiteRule {
+	m.host = host
+	return m.rule
+}
+
+// mockFeedService is a mock implementation of the FeedService interface
+type mockFeedService struct {
+	parseSingleFeedFunc func(ctx context.Context, url string) (*domain.Feed, error)
+}
+
+func (m *mockFeedService) ParseFeeds(ctx context.Context, urls []string) ([]*domain.Feed, error) {
+	return nil, nil
+}
+
+func (m *mockFeedService) ParseSingleFeed(ctx context.Context, url string) (*domain.Feed, error) {
+	if m.parseSingleFeedFunc != nil {
+		return m.parseSingleFeedFunc(ctx, url)
+	}
+	return nil, nil
+}
+
+func (m *mockFeedService) ParseFeedsWithConfig(ctx context.Context, urls []string, config interface{}) ([]*domain.Feed, error) {
+	return nil, nil
+}
+
+// mockCache is an in-memory implementation of the Cache interface
+type mockCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMockCache() *mockCache {
+	return &mockCache{data: make(map[string][]byte)}
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.data[key]; ok {
+		return value, nil
+	}
+	return nil, errors.New("cache miss")
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *mockCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// mockJobQueue records the jobs enqueued
+type mockJobQueue struct {
+	mu   sync.Mutex
+	jobs []string
+}
+
+func (m *mockJobQueue) Enqueue(ctx context.Context, jobType, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs = append(m.jobs, jobType+":"+url)
+	return nil
+}
+
+func (m *mockJobQueue) Job(ctx context.Context, jobType, url string) (*domain.QueuedJob, error) {
+	return nil, nil
+}
+
+func (m *mockJobQueue) Stats(ctx context.Context) (*domain.QueueStats, error) {
+	return nil, nil
+}
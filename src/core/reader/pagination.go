@@ -0,0 +1,149 @@
+// ABOUTME: Detects the link to the next page of an article split over several pages
+// ABOUTME: Understands rel="next", pagination widgets and ?page=N style URLs
+
+package reader
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxPages is how many pages of an article are stitched together by default
+const DefaultMaxPages = 5
+
+// pageParams are query parameters sites number article pages with.
+// "p" is left out: WordPress uses it for post IDs.
+var pageParams = []string{"page", "pg"}
+
+// pagePathPattern matches page numbers in paths such as /story/page/2
+var pagePathPattern = regexp.MustCompile(`^(.*)/page/(\d{1,3})/?$`)
+
+// paginationContainer matches the class or id of a pagination widget
+var paginationContainer = regexp.MustCompile(`(?i)pagination|pager|paging|page-?nav|page-?links|page-?numbers`)
+
+// nextLinkTexts are link texts of "next page" links in pagination widgets
+var nextLinkTexts = map[string]bool{
+	"next": true, "next page": true, "next »": true, "next ›": true, "next →": true,
+	"»": true, "›": true, "→": true, ">": true,
+}
+
+// nextPageURL returns the next page of a paginated article, or "" when the
+// page has none. Only pages on the same host are followed.
+func nextPageURL(doc *goquery.Document, pageURL *url.URL) string {
+	resolve := func(href string) *url.URL {
+		u, err := pageURL.Parse(strings.TrimSpace(href))
+		if err != nil || u.Host != pageURL.Host || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil
+		}
+		u.Fragment = ""
+		if u.String() == pageURL.String() {
+			return nil
+		}
+		return u
+	}
+
+	// An explicit rel="next" is the most reliable signal
+	var next *url.URL
+	doc.Find(`link[rel~="next"], a[rel~="next"]`).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		next = resolve(sel.AttrOr("href", ""))
+		return next == nil
+	})
+	if next != nil {
+		return next.String()
+	}
+
+	// Links to the following page number
+	current, ok := pageNumber(pageURL)
+	if !ok {
+		current = 1
+	}
+	doc.Find("a[href]").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		u := resolve(sel.AttrOr("href", ""))
+		if u == nil || !samePageSeries(pageURL, u) {
+			return true
+		}
+		if n, ok := pageNumber(u); ok && n == current+1 {
+			next = u
+		}
+		return next == nil
+	})
+	if next != nil {
+		return next.String()
+	}
+
+	// "Next" links inside a pagination widget
+	doc.Find("a[href]").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		text := strings.ToLower(strings.Join(strings.Fields(sel.Text()), " "))
+		label := strings.ToLower(sel.AttrOr("aria-label", ""))
+		class := strings.ToLower(sel.AttrOr("class", ""))
+		if !nextLinkTexts[text] && label != "next page" && !strings.Contains(class, "next") {
+			return true
+		}
+		if !inPagination(sel) {
+			return true
+		}
+		next = resolve(sel.AttrOr("href", ""))
+		return next == nil
+	})
+	if next != nil {
+		return next.String()
+	}
+
+	return ""
+}
+
+// pageNumber returns the page number a URL carries in its query or path
+func pageNumber(u *url.URL) (int, bool) {
+	query := u.Query()
+	for _, param := range pageParams {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			return n, err == nil && n > 0
+		}
+	}
+	if m := pagePathPattern.FindStringSubmatch(u.Path); m != nil {
+		n, err := strconv.Atoi(m[2])
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
+
+// samePageSeries reports whether two URLs are pages of the same article:
+// the same path with a different page parameter, or the same path with a
+// different /page/N suffix
+func samePageSeries(a, b *url.URL) bool {
+	return pageBase(a.Path) == pageBase(b.Path)
+}
+
+// pageBase strips a trailing /page/N from a path
+func pageBase(path string) string {
+	if m := pagePathPattern.FindStringSubmatch(path); m != nil {
+		path = m[1]
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+// inPagination reports whether a link sits inside a pagination widget
+func inPagination(sel *goquery.Selection) bool {
+	found := false
+	sel.ParentsFiltered("nav, div, ul, ol, p, span").EachWithBreak(func(_ int, parent *goquery.Selection) bool {
+		if paginationContainer.MatchString(parent.AttrOr("class", "") + " " + parent.AttrOr("id", "")) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// normalizePageURL identifies a page for loop detection
+func normalizePageURL(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+	normalized.Path = strings.TrimSuffix(normalized.Path, "/")
+	normalized.Host = strings.ToLower(normalized.Host)
+	return normalized.String()
+}
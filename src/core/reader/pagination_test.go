@@ -0,0 +1,75 @@
+package reader
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		pageURL string
+		html    string
+		want    string
+	}{
+		{
+			name:    "rel next link",
+			pageURL: "https://news.example.com/story",
+			html:    `<head><link rel="next" href="/story?page=2"></head>`,
+			want:    "https://news.example.com/story?page=2",
+		},
+		{
+			name:    "following page number",
+			pageURL: "https://news.example.com/story?page=2",
+			html:    `<a href="/story?page=1">1</a><a href="/story?page=3">3</a><a href="/other?page=3">other</a>`,
+			want:    "https://news.example.com/story?page=3",
+		},
+		{
+			name:    "page path",
+			pageURL: "https://news.example.com/story/",
+			html:    `<a href="/story/page/2/">2</a>`,
+			want:    "https://news.example.com/story/page/2/",
+		},
+		{
+			name:    "next link in pagination widget",
+			pageURL: "https://news.example.com/story",
+			html:    `<div class="pagination"><a href="/story-continued">Next &raquo;</a></div>`,
+			want:    "https://news.example.com/story-continued",
+		},
+		{
+			name:    "next link outside pagination is another article",
+			pageURL: "https://news.example.com/story",
+			html:    `<div class="related"><a href="/another-story">Next</a></div>`,
+			want:    "",
+		},
+		{
+			name:    "other hosts are not followed",
+			pageURL: "https://news.example.com/story",
+			html:    `<link rel="next" href="https://ads.example.net/story?page=2">`,
+			want:    "",
+		},
+		{
+			name:    "post IDs are not page numbers",
+			pageURL: "https://blog.example.com/?p=41",
+			html:    `<a href="/?p=42">Newer post</a>`,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pageURL, _ := url.Parse(tt.pageURL)
+
+			if got := nextPageURL(doc, pageURL); got != tt.want {
+				t.Errorf("nextPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// ABOUTME: Applies per-site extraction rules on top of readability
+// ABOUTME: Strips unwanted elements and reads content, byline, date and hero image by selector
+
+package reader
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"github.com/PuerkitoBio/goquery"
+	readability "github.com/go-shiori/go-readability"
+)
+
+// ruleExtraction holds the values a site rule selected from a page
+type ruleExtraction struct {
+	title     string
+	author    string
+	published *time.Time
+	image     string
+	content   string
+	text      string
+}
+
+// applySiteRuleRemovals strips the elements a rule removes from the page
+func applySiteRuleRemovals(doc *goquery.Document, rule *domain.SiteRule) {
+	for _, selector := range rule.Remove {
+		doc.Find(selector).Remove()
+	}
+}
+
+// extractWithSiteRule reads the values a rule selects from the page
+func extractWithSiteRule(doc *goquery.Document, rule *domain.SiteRule, pageURL *url.URL) ruleExtraction {
+	var result ruleExtraction
+
+	if rule.Title != "" {
+		result.title = selectedText(doc.Find(rule.Title).First())
+	}
+	if rule.Author != "" {
+		result.author = selectedText(doc.Find(rule.Author).First())
+	}
+	if rule.Date != "" {
+		if sel := doc.Find(rule.Date).First(); sel.Length() > 0 {
+			result.published = firstTime(sel.AttrOr("datetime", ""), sel.AttrOr("content", ""), selectedText(sel))
+		}
+	}
+	if rule.HeroImage != "" {
+		result.image = heroImageURL(doc.Find(rule.HeroImage).First(), pageURL)
+	}
+	if len(rule.Content) > 0 {
+		result.content, result.text = selectedContent(doc, strings.Join(rule.Content, ", "), pageURL)
+	}
+
+	return result
+}
+
+// apply overrides what readability extracted with the rule's values
+func (e ruleExtraction) apply(article *readability.Article, meta *pageMetadata) {
+	if e.content != "" {
+		article.Content = e.content
+		article.TextContent = e.text
+	}
+	if e.title != "" {
+		article.Title = e.title
+	}
+	if e.author != "" {
+		article.Byline = e.author
+	}
+	if e.published != nil {
+		meta.published = e.published
+	}
+	if e.image != "" {
+		article.Image = e.image
+		meta.heroImage = e.image
+	}
+}
+
+// withHeroImage puts a rule's hero image at the top of the content when it is
+// missing; readability often drops lead images placed outside the article body
+func withHeroImage(content, image string) string {
+	if image == "" || strings.Contains(content, image) || strings.Contains(content, html.EscapeString(image)) {
+		return content
+	}
+	return fmt.Sprintf(`<figure><img src="%s" alt=""></figure>`, html.EscapeString(image)) + content
+}
+
+// selectedContent returns the HTML and text of the outermost matches of a
+// selector, with scripts removed and links made absolute
+func selectedContent(doc *goquery.Document, selector string, pageURL *url.URL) (string, string) {
+	matches := doc.Find(selector).FilterFunction(func(_ int, sel *goquery.Selection) bool {
+		return sel.ParentsFiltered(selector).Length() == 0
+	})
+	if matches.Length() == 0 {
+		return "", ""
+	}
+
+	matches.Find("script, style, noscript, template").Remove()
+	matches.Find("[src], [href]").Each(func(_ int, sel *goquery.Selection) {
+		for _, attr := range []string{"src", "href"} {
+			if value, ok := sel.Attr(attr); ok {
+				if resolved, err := pageURL.Parse(strings.TrimSpace(value)); err == nil {
+					sel.SetAttr(attr, resolved.String())
+				}
+			}
+		}
+	})
+
+	var content, text strings.Builder
+	matches.Each(func(_ int, sel *goquery.Selection) {
+		if outer, err := goquery.OuterHtml(sel); err == nil {
+			content.WriteString(outer)
+		}
+		if t := strings.TrimSpace(sel.Text()); t != "" {
+			if text.Len() > 0 {
+				text.WriteString("\n\n")
+			}
+			text.WriteString(t)
+		}
+	})
+
+	if text.Len() == 0 {
+		return "", ""
+	}
+	return "<div>" + content.String() + "</div>", text.String()
+}
+
+// heroImageURL returns the image URL of a selected <img>, <meta> or container
+func heroImageURL(sel *goquery.Selection, pageURL *url.URL) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	if !sel.Is("img, meta, source") {
+		sel = sel.Find("img").First()
+	}
+
+	var candidate string
+	for _, attr := range []string{"content", "src", "data-src", "srcset", "data-srcset"} {
+		if value := strings.TrimSpace(sel.AttrOr(attr, "")); value != "" {
+			candidate = value
+			if strings.HasSuffix(attr, "srcset") {
+				// The first candidate of "image.jpg 1x, image@2x.jpg 2x"
+				candidate = ""
+				if fields := strings.Fields(strings.Split(value, ",")[0]); len(fields) > 0 {
+					candidate = fields[0]
+				}
+			}
+			break
+		}
+	}
+	if candidate == "" || strings.HasPrefix(candidate, "data:") {
+		return ""
+	}
+
+	resolved, err := pageURL.Parse(candidate)
+	if err != nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// selectedText returns the whitespace-normalized text of a selection
+func selectedText(sel *goquery.Selection) string {
+	if sel.Is("meta") {
+		return strings.TrimSpace(sel.AttrOr("content", ""))
+	}
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
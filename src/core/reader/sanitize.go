@@ -0,0 +1,160 @@
+// ABOUTME: Allowlist HTML sanitizer for article content the client did not fetch from the page
+// ABOUTME: Keeps text formatting, links and media; drops scripts, event handlers and unsafe URLs
+
+package reader
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// droppedElements are removed along with their content
+var droppedElements = []string{
+	"script", "style", "noscript", "template", "iframe", "frame", "frameset",
+	"object", "embed", "applet", "form", "input", "button", "textarea",
+	"select", "svg", "math", "link", "meta", "base", "head", "title",
+}
+
+// allowedAttributes lists the attributes kept on each allowed element;
+// elements not listed are replaced by their content
+var allowedAttributes = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"article":    nil,
+	"aside":      nil,
+	"audio":      {"src", "controls"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"cite":       nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"details":    nil,
+	"dfn":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"footer":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"header":     nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"picture":    nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"section":    nil,
+	"small":      nil,
+	"source":     {"src", "type"},
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan", "scope"},
+	"thead":      nil,
+	"time":       {"datetime"},
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+	"video":      {"src", "poster", "controls", "width", "height"},
+}
+
+// urlAttributes hold URLs, which are resolved and must be http(s)
+var urlAttributes = map[string]bool{"href": true, "src": true, "poster": true, "cite": true}
+
+// sanitizeHTML strips everything from the selection's content that is not
+// on the allowlist, resolving URLs against base. Elements that are not
+// allowed are unwrapped so their text is kept.
+func sanitizeHTML(root *goquery.Selection, base *url.URL) {
+	root.Find(strings.Join(droppedElements, ", ")).Remove()
+
+	root.Find("*").Each(func(_ int, sel *goquery.Selection) {
+		node := sel.Get(0)
+		allowed, ok := allowedAttributes[node.Data]
+		if !ok {
+			if sel.Contents().Length() > 0 {
+				sel.Contents().Unwrap()
+			} else {
+				sel.Remove()
+			}
+			return
+		}
+
+		attrs := node.Attr[:0]
+		for _, attr := range node.Attr {
+			if attr.Namespace != "" || !containsString(allowed, attr.Key) {
+				continue
+			}
+			if urlAttributes[attr.Key] {
+				value, ok := safeURL(attr.Val, base, attr.Key == "href")
+				if !ok {
+					continue
+				}
+				attr.Val = value
+			}
+			attrs = append(attrs, attr)
+		}
+		node.Attr = attrs
+
+		if node.Data == "a" {
+			node.Attr = append(node.Attr, html.Attribute{Key: "rel", Val: "noopener noreferrer nofollow"})
+		}
+	})
+}
+
+// safeURL resolves a URL and accepts it only when it is http(s), or a
+// mailto link for hrefs
+func safeURL(value string, base *url.URL, link bool) (string, bool) {
+	ref, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+
+	switch strings.ToLower(ref.Scheme) {
+	case "http", "https":
+		return ref.String(), true
+	case "mailto":
+		return ref.String(), link
+	case "":
+		// Fragments and relative links are only kept when there is no base
+		return ref.String(), base == nil
+	}
+	return "", false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -6,7 +6,11 @@ package reader
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync"
@@ -14,138 +18,412 @@ import (
 
 	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
+	"digests-app-api/core/language"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
 	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
 )
 
+// maxPageSize caps how much of an article page is read
+const maxPageSize = 10 * 1024 * 1024
+
 type Service struct {
-	cache  interfaces.Cache
-	logger interfaces.Logger
+	deps      interfaces.Dependencies
+	maxPages  int
+	siteRules interfaces.SiteRuleMatcher
+	feeds     interfaces.FeedService
+	jobQueue  interfaces.JobQueue
 }
 
-func NewService(cache interfaces.Cache, logger interfaces.Logger) *Service {
+// NewService creates a reader service that fetches pages through deps.HTTPClient
+// and caches extracted views in deps.Cache
+func NewService(deps interfaces.Dependencies) *Service {
 	return &Service{
-		cache:  cache,
-		logger: logger,
+		deps:     deps,
+		maxPages: DefaultMaxPages,
+	}
+}
+
+// SetMaxPages sets how many pages of a paginated article are stitched
+// together; 1 only reads the requested page
+func (s *Service) SetMaxPages(maxPages int) {
+	if maxPages < 1 {
+		maxPages = 1
 	}
+	s.maxPages = maxPages
+}
+
+// SetJobQueue sets the queue pages that failed to extract are retried
+// through, so a later request finds the view cached
+func (s *Service) SetJobQueue(queue interfaces.JobQueue) {
+	s.jobQueue = queue
 }
 
 // ExtractReaderViews extracts clean article content from multiple URLs
 func (s *Service) ExtractReaderViews(ctx context.Context, urls []string) []domain.ReaderView {
-	results := make([]domain.ReaderView, len(urls))
+	targets := make([]domain.ReaderTarget, len(urls))
+	for i, pageURL := range urls {
+		targets[i] = domain.ReaderTarget{URL: pageURL}
+	}
+	return s.ExtractReaderViewsFor(ctx, targets)
+}
+
+// ExtractReaderViewsFor extracts clean article content for pages that may
+// come with the feed item they were linked from. Full-text feed content is
+// used as is; other pages are fetched.
+func (s *Service) ExtractReaderViewsFor(ctx context.Context, targets []domain.ReaderTarget) []domain.ReaderView {
+	results := make([]domain.ReaderView, len(targets))
 	var wg sync.WaitGroup
 
-	for i, url := range urls {
+	for i, target := range targets {
 		wg.Add(1)
-		go func(index int, url string) {
+		go func(index int, target domain.ReaderTarget) {
 			defer wg.Done()
 			
 			// Check cache first
-			if s.cache != nil {
-				cacheKey := fmt.Sprintf("reader:%s", url)
-				if data, err := s.cache.Get(ctx, cacheKey); err == nil && data != nil {
-					var cachedView domain.ReaderView
-					if err := json.Unmarshal(data, &cachedView); err == nil {
-						results[index] = cachedView
-						return
-					}
+			if cachedView, ok := s.cachedView(ctx, pageCacheKey(target.URL)); ok {
+				results[index] = cachedView
+				return
+			}
+
+			// Extract reader view, from the feed when it carries the full
+			// article. The client picks the feed, so feed views are only
+			// cached for that feed item and never stand in for the page.
+			if target.FeedURL != "" {
+				if cachedView, ok := s.cachedView(ctx, feedCacheKey(target)); ok {
+					results[index] = cachedView
+					return
+				}
+				if view, ok := s.feedItemView(ctx, target); ok {
+					s.finishView(&view)
+					results[index] = view
+					s.cacheView(ctx, feedCacheKey(target), view)
+					return
 				}
 			}
 
-			// Extract reader view
-			view := s.extractSingleView(url)
+			view, err := s.extractSingleView(ctx, target.URL)
 			results[index] = view
 
-			// Cache successful results
-			if s.cache != nil && view.Status == "ok" {
-				cacheKey := fmt.Sprintf("reader:%s", url)
-				if data, err := json.Marshal(view); err == nil {
-					_ = s.cache.Set(ctx, cacheKey, data, 1*time.Hour)
+			// Cache successful results; pages that failed for a reason that
+			// may pass are retried in the background
+			if err == nil {
+				s.cacheView(ctx, pageCacheKey(view.URL), view)
+			} else if s.jobQueue != nil && isTransient(err) {
+				if err := s.jobQueue.Enqueue(ctx, domain.JobTypeReader, target.URL); err != nil {
+					s.deps.Logger.Warn("Failed to queue reader view retry", map[string]interface{}{
+						"url":   target.URL,
+						"error": err.Error(),
+					})
 				}
 			}
-		}(i, url)
+		}(i, target)
 	}
 
 	wg.Wait()
 	return results
 }
 
-func (s *Service) extractSingleView(url string) domain.ReaderView {
+// RefreshReaderView extracts and caches the reader view of a page,
+// returning why it failed instead
+func (s *Service) RefreshReaderView(ctx context.Context, pageURL string) error {
+	view, err := s.extractSingleView(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+
+	s.cacheView(ctx, pageCacheKey(view.URL), view)
+	return nil
+}
+
+// cachedView returns the reader view cached under a key, if any
+func (s *Service) cachedView(ctx context.Context, key string) (domain.ReaderView, bool) {
+	var view domain.ReaderView
+	if s.deps.Cache == nil {
+		return view, false
+	}
+	data, err := s.deps.Cache.Get(ctx, key)
+	if err != nil || data == nil {
+		return view, false
+	}
+	if err := json.Unmarshal(data, &view); err != nil {
+		return view, false
+	}
+	return view, true
+}
+
+// cacheView stores an extracted reader view under a key
+func (s *Service) cacheView(ctx context.Context, key string, view domain.ReaderView) {
+	if s.deps.Cache != nil {
+		if data, err := json.Marshal(view); err == nil {
+			_ = s.deps.Cache.Set(ctx, key, data, 1*time.Hour)
+		}
+	}
+}
+
+// pageCacheKey is the cache key of a view extracted from the page itself
+func pageCacheKey(pageURL string) string {
+	return fmt.Sprintf("reader:%s", pageURL)
+}
+
+// feedCacheKey is the cache key of a view built from a feed item, scoped to
+// the feed it came from
+func feedCacheKey(target domain.ReaderTarget) string {
+	return fmt.Sprintf("reader:feed:%s#%s", target.FeedURL, firstNonEmpty(target.ItemID, target.URL))
+}
+
+// fetchError is a failure to fetch a page. Transient failures, such as an
+// unreachable host or a 5xx or 429 status, may pass when the page is
+// fetched again; missing pages and pages that are not articles will not.
+type fetchError struct {
+	err       error
+	transient bool
+}
+
+func (e *fetchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fetchError) Unwrap() error {
+	return e.err
+}
+
+// isTransient reports whether a failed extraction is worth retrying
+func isTransient(err error) bool {
+	var fetchErr *fetchError
+	return errors.As(err, &fetchErr) && fetchErr.transient
+}
+
+// extractSingleView extracts the reader view of a page. A view that failed
+// has its Error set, and the failure is also returned.
+func (s *Service) extractSingleView(ctx context.Context, pageURL string) (domain.ReaderView, error) {
 	result := domain.ReaderView{
-		URL:    url,
+		URL:    pageURL,
+		Source: domain.ReaderSourcePage,
 		Status: "ok",
 	}
 
-	article, err := readability.FromURL(url, 30*time.Second)
+	article, meta, err := s.fetchArticle(ctx, pageURL)
 	if err != nil {
-		s.logger.Error("Failed to parse reader view", map[string]interface{}{
-			"url":   url,
+		s.deps.Logger.Error("Failed to parse reader view", map[string]interface{}{
+			"url":   pageURL,
 			"error": err.Error(),
 		})
 		result.Status = "error"
 		result.Error = err.Error()
-		return result
+		return result, err
 	}
 
+	// Follow the pagination of articles split over several pages
+	article.Content, article.TextContent, result.Pages = s.stitchPages(ctx, pageURL, article, meta.next)
+	article.Content = withHeroImage(article.Content, meta.heroImage)
+
 	result.Title = article.Title
 	result.Content = article.Content
 	result.TextContent = article.TextContent
+	result.Excerpt = article.Excerpt
+	result.Byline = article.Byline
 	result.SiteName = article.SiteName
 	result.Image = article.Image
 	result.Favicon = article.Favicon
+	result.Language = article.Language
+	if result.Language == "" {
+		result.Language = meta.language
+	}
+	result.PublishedTime = meta.published
+	result.ModifiedTime = meta.modified
+	s.finishView(&result)
+
+	return result, nil
+}
+
+// finishView adds the reading stats, language and markdown version of a
+// view's content
+func (s *Service) finishView(view *domain.ReaderView) {
+	view.WordCount = countWords(view.TextContent)
+	view.ReadingTimeMinutes = readingTime(view.WordCount)
+
+	// Many pages declare no language; detect it from the text instead
+	switch {
+	case view.LanguageConfidence > 0:
+	case view.Language != "":
+		view.LanguageConfidence = 1
+	default:
+		view.Language, view.LanguageConfidence = language.Detect(view.Title + "\n" + view.TextContent)
+	}
 
 	// Convert HTML content to Markdown
-	if result.Content != "" {
+	if view.Content != "" {
 		converter := md.NewConverter("", true, nil)
-		markdown, err := converter.ConvertString(result.Content)
+		markdown, err := converter.ConvertString(view.Content)
 		if err != nil {
-			s.logger.Debug("Failed to convert HTML to markdown", map[string]interface{}{
-				"url":   url,
+			s.deps.Logger.Debug("Failed to convert HTML to markdown", map[string]interface{}{
+				"url":   view.URL,
 				"error": err.Error(),
 			})
 			// Don't fail the entire request if markdown conversion fails
-			result.Markdown = ""
+			view.Markdown = ""
 		} else {
 			// Build markdown with metadata
-			result.Markdown = buildMarkdownWithMetadata(result.Title, article.Byline, "", result.SiteName, markdown)
+			view.Markdown = buildMarkdownWithMetadata(*view, markdown)
 		}
 	}
+}
 
-	return result
+// fetchArticle downloads a page through the injected HTTP client and runs
+// readability on it. Metadata readability does not extract is read from the
+// document first, as readability rewrites it.
+func (s *Service) fetchArticle(ctx context.Context, pageURL string) (readability.Article, pageMetadata, error) {
+	parsedURL, err := url.ParseRequestURI(pageURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return readability.Article{}, pageMetadata{}, fmt.Errorf("invalid URL: %s", pageURL)
+	}
+	if s.deps.HTTPClient == nil {
+		return readability.Article{}, pageMetadata{}, errors.New("HTTP client not configured")
+	}
+
+	resp, err := s.deps.HTTPClient.Get(ctx, pageURL)
+	if err != nil {
+		return readability.Article{}, pageMetadata{}, &fetchError{err: fmt.Errorf("failed to fetch the page: %w", err), transient: true}
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() != 200 {
+		transient := resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests
+		return readability.Article{}, pageMetadata{}, &fetchError{err: fmt.Errorf("page returned status code %d", resp.StatusCode()), transient: transient}
+	}
+	contentType := resp.Header("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return readability.Article{}, pageMetadata{}, errors.New("URL is not a HTML document")
+	}
+
+	// Decode legacy charsets declared in the header or a <meta> tag
+	body, err := charset.NewReader(io.LimitReader(resp.Body(), maxPageSize), contentType)
+	if err != nil {
+		return readability.Article{}, pageMetadata{}, fmt.Errorf("failed to decode the page: %w", err)
+	}
+	root, err := html.Parse(body)
+	if err != nil {
+		return readability.Article{}, pageMetadata{}, fmt.Errorf("failed to parse the page: %w", err)
+	}
+
+	doc := goquery.NewDocumentFromNode(root)
+
+	// Site rules strip clutter and select values before readability runs
+	var rule *domain.SiteRule
+	if s.siteRules != nil {
+		rule = s.siteRules.Match(parsedURL.Host)
+	}
+	var extracted ruleExtraction
+	if rule != nil {
+		applySiteRuleRemovals(doc, rule)
+		extracted = extractWithSiteRule(doc, rule, parsedURL)
+	}
+
+	meta := extractPageMetadata(doc, parsedURL)
+
+	article, err := readability.FromDocument(root, parsedURL)
+	if err != nil && extracted.content == "" {
+		return readability.Article{}, pageMetadata{}, err
+	}
+	extracted.apply(&article, &meta)
+
+	return article, meta, nil
+}
+
+// stitchPages appends the following pages of an article to its first page,
+// up to maxPages. Pages already seen, and pages repeating the previous
+// page's text, end the chain; so does a page that fails to load.
+func (s *Service) stitchPages(ctx context.Context, pageURL string, first readability.Article, next string) (content, text string, pages int) {
+	contents := []string{first.Content}
+	texts := []string{first.TextContent}
+
+	visited := make(map[string]bool)
+	if u, err := url.Parse(pageURL); err == nil {
+		visited[normalizePageURL(u)] = true
+	}
+
+	previousText := strings.TrimSpace(first.TextContent)
+	for next != "" && len(contents) < s.maxPages {
+		u, err := url.Parse(next)
+		if err != nil || visited[normalizePageURL(u)] {
+			break
+		}
+		visited[normalizePageURL(u)] = true
+
+		article, meta, err := s.fetchArticle(ctx, next)
+		if err != nil {
+			s.deps.Logger.Warn("Failed to fetch article page", map[string]interface{}{
+				"url":   next,
+				"error": err.Error(),
+			})
+			break
+		}
+
+		pageText := strings.TrimSpace(article.TextContent)
+		if pageText == "" || pageText == previousText {
+			break
+		}
+		previousText = pageText
+
+		contents = append(contents, article.Content)
+		texts = append(texts, article.TextContent)
+		next = meta.next
+	}
+
+	return strings.Join(contents, "\n"), strings.Join(texts, "\n\n"), len(contents)
+}
+
+// SetFeedService lets views be built from full-text feed content when a
+// request names the feed item a page came from
+func (s *Service) SetFeedService(feeds interfaces.FeedService) {
+	s.feeds = feeds
+}
+
+// SetSiteRules sets the per-site rules applied before generic extraction
+func (s *Service) SetSiteRules(rules interfaces.SiteRuleMatcher) {
+	s.siteRules = rules
 }
 
 // buildMarkdownWithMetadata creates a well-formatted markdown document with metadata
-func buildMarkdownWithMetadata(title, author, publishedTime, siteName, content string) string {
+func buildMarkdownWithMetadata(view domain.ReaderView, content string) string {
 	var markdown strings.Builder
 	
 	// Add title as H1
-	if title != "" {
+	if view.Title != "" {
 		markdown.WriteString("# ")
-		markdown.WriteString(title)
+		markdown.WriteString(view.Title)
 		markdown.WriteString("\n\n")
 	}
 	
 	// Add metadata section
 	var metadataItems []string
 	
-	if author != "" {
-		metadataItems = append(metadataItems, fmt.Sprintf("**Author:** %s", author))
+	if view.Byline != "" {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Author:** %s", view.Byline))
 	}
 	
-	if publishedTime != "" {
-		// Try to parse and format the time nicely
-		if parsedTime, err := time.Parse(time.RFC3339, publishedTime); err == nil {
-			formattedTime := parsedTime.Format("January 2, 2006 at 3:04 PM")
-			metadataItems = append(metadataItems, fmt.Sprintf("**Published:** %s", formattedTime))
-		} else {
-			// If parsing fails, use the raw string
-			metadataItems = append(metadataItems, fmt.Sprintf("**Published:** %s", publishedTime))
-		}
+	if view.PublishedTime != nil {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Published:** %s", view.PublishedTime.Format("January 2, 2006 at 3:04 PM")))
+	}
+
+	if view.ModifiedTime != nil && (view.PublishedTime == nil || view.ModifiedTime.After(*view.PublishedTime)) {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Updated:** %s", view.ModifiedTime.Format("January 2, 2006 at 3:04 PM")))
 	}
 	
-	if siteName != "" {
-		metadataItems = append(metadataItems, fmt.Sprintf("**Source:** %s", siteName))
+	if view.SiteName != "" {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Source:** %s", view.SiteName))
+	}
+
+	if view.Language != "" {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Language:** %s", view.Language))
+	}
+
+	if view.ReadingTimeMinutes > 0 {
+		metadataItems = append(metadataItems, fmt.Sprintf("**Reading time:** %d min (%d words)", view.ReadingTimeMinutes, view.WordCount))
 	}
 	
 	if len(metadataItems) > 0 {
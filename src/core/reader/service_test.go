@@ -0,0 +1,288 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	stdhttp "digests-app-api/infrastructure/http/standard"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html lang="en-GB">
+<head>
+<title>Understanding Go interfaces</title>
+<meta name="author" content="Jane Doe">
+<meta property="og:site_name" content="Example Blog">
+<meta property="article:modified_time" content="2024-03-05T08:00:00Z">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"WebSite"},{"@type":"Article","datePublished":"2024-03-01T10:30:00Z"}]}</script>
+</head>
+<body>
+<article>
+<h1>Understanding Go interfaces</h1>
+<p>Interfaces in Go are satisfied implicitly. A type implements an interface by implementing its methods, and there is no explicit declaration of intent, no "implements" keyword.</p>
+<p>Implicit interfaces decouple the definition of an interface from its implementation, which could then appear in any package without prearrangement. This is one of the reasons small interfaces are so common in Go programs.</p>
+<p>The io.Reader and io.Writer interfaces are good examples: they have a single method each, and almost everything that deals with streams of bytes accepts or returns one of them.</p>
+</article>
+</body>
+</html>`
+
+func TestService_ExtractReaderViews(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				mu.Lock()
+				fetched = append(fetched, url)
+				mu.Unlock()
+				if strings.HasSuffix(url, "/missing") {
+					return &mockResponse{statusCode: 404}, nil
+				}
+				return &mockResponse{statusCode: 200, body: articlePage, headers: map[string]string{"Content-Type": "text/html; charset=utf-8"}}, nil
+			},
+		},
+		Logger: &mockLogger{},
+	})
+
+	views := service.ExtractReaderViews(context.Background(), []string{"https://example.com/post", "https://example.com/missing"})
+	if len(fetched) != 2 {
+		t.Fatalf("expected pages to be fetched through the HTTP client, got %v", fetched)
+	}
+
+	view := views[0]
+	if view.Status != "ok" {
+		t.Fatalf("status = %q, error = %q", view.Status, view.Error)
+	}
+	if view.Byline != "Jane Doe" || view.Language != "en-GB" || view.LanguageConfidence != 1 || view.Excerpt == "" {
+		t.Errorf("unexpected metadata: byline %q, language %q, excerpt %q", view.Byline, view.Language, view.Excerpt)
+	}
+	if view.PublishedTime == nil || view.PublishedTime.Day() != 1 {
+		t.Errorf("PublishedTime = %v, want 2024-03-01 from JSON-LD", view.PublishedTime)
+	}
+	if view.ModifiedTime == nil || view.ModifiedTime.Day() != 5 {
+		t.Errorf("ModifiedTime = %v, want 2024-03-05", view.ModifiedTime)
+	}
+	if view.WordCount < 80 || view.ReadingTimeMinutes != 1 {
+		t.Errorf("WordCount = %d, ReadingTimeMinutes = %d", view.WordCount, view.ReadingTimeMinutes)
+	}
+	for _, want := range []string{"**Author:** Jane Doe", "**Published:** March 1, 2024", "**Updated:** March 5, 2024", "**Reading time:** 1 min"} {
+		if !strings.Contains(view.Markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, view.Markdown)
+		}
+	}
+
+	if views[1].Status != "error" || !strings.Contains(views[1].Error, "404") {
+		t.Errorf("expected an error view for the missing page, got %+v", views[1])
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Don't split well-known words - ok?", 5},
+		{"  three\tspaced\nwords ", 3},
+		{"日本語の文章", 6},
+	}
+
+	for _, tt := range tests {
+		if got := countWords(tt.text); got != tt.want {
+			t.Errorf("countWords(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+
+	if got := readingTime(231); got != 2 {
+		t.Errorf("readingTime(231) = %d, want 2", got)
+	}
+}
+
+func TestService_StitchesPaginatedArticles(t *testing.T) {
+	page := func(n int, next string) string {
+		link := ""
+		if next != "" {
+			link = `<link rel="next" href="` + next + `">`
+		}
+		return `<html><head><title>Long read</title>` + link + `</head><body><article>` +
+			strings.Repeat(fmt.Sprintf("<p>Paragraph of page %d with enough words to be kept as article content by readability.</p>", n), 5) +
+			`</article></body></html>`
+	}
+	pages := map[string]string{
+		"https://example.com/long":        page(1, "/long?page=2"),
+		"https://example.com/long?page=2": page(2, "/long?page=3"),
+		"https://example.com/long?page=3": page(3, "/long"), // loops back to the first page
+	}
+
+	var mu sync.Mutex
+	var fetched []string
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				mu.Lock()
+				fetched = append(fetched, url)
+				mu.Unlock()
+				body, ok := pages[url]
+				if !ok {
+					return &mockResponse{statusCode: 404}, nil
+				}
+				return &mockResponse{statusCode: 200, body: body}, nil
+			},
+		},
+		Logger: &mockLogger{},
+	})
+
+	view := service.ExtractReaderViews(context.Background(), []string{"https://example.com/long"})[0]
+	if view.Status != "ok" {
+		t.Fatalf("status = %q, error = %q", view.Status, view.Error)
+	}
+	if view.Pages != 3 || len(fetched) != 3 {
+		t.Fatalf("Pages = %d after fetching %v, want 3 pages without looping", view.Pages, fetched)
+	}
+	for n := 1; n <= 3; n++ {
+		want := fmt.Sprintf("page %d", n)
+		if !strings.Contains(view.TextContent, want) || !strings.Contains(view.Markdown, want) {
+			t.Errorf("stitched article is missing %q", want)
+		}
+	}
+
+	service.SetMaxPages(2)
+	fetched = nil
+	view, _ = service.extractSingleView(context.Background(), "https://example.com/long")
+	if view.Pages != 2 || strings.Contains(view.TextContent, "page 3") {
+		t.Errorf("Pages = %d, want the limit of 2", view.Pages)
+	}
+}
+
+func TestService_RefusesNonPublicAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("reader reached the loopback server at %s", r.URL)
+	}))
+	defer internal.Close()
+
+	// The public page is canned; everything else goes through the guarded client
+	public := `<html><head><title>Long read</title><link rel="next" href="` + internal.URL + `/page2"></head><body><article>` +
+		strings.Repeat("<p>Paragraph of the public page with enough words to be kept as article content by readability.</p>", 5) +
+		`</article></body></html>`
+	guarded := stdhttp.NewGuardedHTTPClient(5 * time.Second)
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				if url == "https://example.com/long" {
+					return &mockResponse{statusCode: 200, body: public}, nil
+				}
+				return guarded.Get(ctx, url)
+			},
+		},
+		Logger: &mockLogger{},
+	})
+
+	views := service.ExtractReaderViews(context.Background(), []string{internal.URL + "/admin", "https://example.com/long"})
+	if views[0].Status != "error" {
+		t.Errorf("expected the loopback URL to be refused, got %+v", views[0])
+	}
+	if views[1].Status != "ok" || views[1].Pages != 1 {
+		t.Errorf("expected the next link to the loopback server to be skipped, status = %q, pages = %d", views[1].Status, views[1].Pages)
+	}
+}
+
+func TestService_AppliesSiteRules(t *testing.T) {
+	body := `<html><head><title>Site | Headline</title></head><body>
+<div class="cookie-banner"><p>We use cookies to improve your experience. Accept all cookies to continue reading this site.</p></div>
+<figure class="lead"><img src="/images/hero.jpg"></figure>
+<h1 class="headline">The real headline</h1>
+<span class="byline">By Sam Writer</span>
+<time class="stamp" datetime="2024-05-06T07:08:09Z">May 6</time>
+<div class="story-body"><p>The story itself, which the rule selects directly instead of leaving it to readability's scoring.</p>
+<aside class="newsletter"><p>Sign up for our newsletter!</p></aside></div>
+</body></html>`
+
+	rules := &mockSiteRules{rule: &domain.SiteRule{
+		Hosts:     []string{"*.example.com"},
+		Content:   []string{".story-body"},
+		Remove:    []string{".cookie-banner", ".newsletter"},
+		Title:     "h1.headline",
+		Author:    ".byline",
+		Date:      "time.stamp",
+		HeroImage: "figure.lead",
+	}}
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				return &mockResponse{statusCode: 200, body: body}, nil
+			},
+		},
+		Logger: &mockLogger{},
+	})
+	service.SetSiteRules(rules)
+
+	view, _ := service.extractSingleView(context.Background(), "https://news.example.com/story")
+	if view.Status != "ok" {
+		t.Fatalf("status = %q, error = %q", view.Status, view.Error)
+	}
+	if rules.host != "news.example.com" {
+		t.Errorf("rules matched against %q", rules.host)
+	}
+	if view.Title != "The real headline" || view.Byline != "By Sam Writer" {
+		t.Errorf("Title = %q, Byline = %q", view.Title, view.Byline)
+	}
+	if view.PublishedTime == nil || view.PublishedTime.Day() != 6 {
+		t.Errorf("PublishedTime = %v", view.PublishedTime)
+	}
+	if view.Image != "https://news.example.com/images/hero.jpg" || !strings.HasPrefix(view.Content, `<figure><img src="https://news.example.com/images/hero.jpg"`) {
+		t.Errorf("expected the hero image to lead the content, Image = %q, Content = %q", view.Image, view.Content)
+	}
+	if !strings.Contains(view.TextContent, "The story itself") || strings.Contains(view.TextContent, "cookies") || strings.Contains(view.TextContent, "newsletter") {
+		t.Errorf("unexpected text content: %q", view.TextContent)
+	}
+}
+
+func TestService_OnlyTransientFailuresAreQueued(t *testing.T) {
+	service := NewService(interfaces.Dependencies{
+		HTTPClient: &mockHTTPClient{
+			getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+				switch {
+				case strings.HasSuffix(url, "/busy"):
+					return &mockResponse{statusCode: 503}, nil
+				case strings.HasSuffix(url, "/down"):
+					return nil, errors.New("connection refused")
+				case strings.HasSuffix(url, "/image.png"):
+					return &mockResponse{statusCode: 200, headers: map[string]string{"Content-Type": "image/png"}}, nil
+				}
+				return &mockResponse{statusCode: 404}, nil
+			},
+		},
+		Logger: &mockLogger{},
+	})
+	queue := &mockJobQueue{}
+	service.SetJobQueue(queue)
+
+	views := service.ExtractReaderViews(context.Background(), []string{
+		"https://example.com/busy",
+		"https://example.com/down",
+		"https://example.com/missing",
+		"https://example.com/image.png",
+		"not a url",
+	})
+	for _, view := range views {
+		if view.Status != "error" {
+			t.Errorf("expected %s to fail, got %+v", view.URL, view)
+		}
+	}
+
+	sort.Strings(queue.jobs)
+	want := []string{domain.JobTypeReader + ":https://example.com/busy", domain.JobTypeReader + ":https://example.com/down"}
+	if strings.Join(queue.jobs, ",") != strings.Join(want, ",") {
+		t.Errorf("expected only transient failures to be queued, got %v", queue.jobs)
+	}
+}
@@ -0,0 +1,196 @@
+// ABOUTME: Local curated directory provider loaded from an OPML or JSON file
+// ABOUTME: Matches every query term against feed titles, descriptions, categories and URLs
+
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"digests-app-api/core/domain"
+)
+
+// directoryEntry is a feed in the curated directory
+type directoryEntry struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	SiteURL     string   `json:"siteUrl"`
+	Language    string   `json:"language"`
+	Author      string   `json:"author"`
+	Image       string   `json:"image"`
+	Categories  []string `json:"categories"`
+
+	// searchText is the lower-cased text matched against queries
+	searchText string
+}
+
+// DirectoryProvider searches a curated list of feeds held in memory
+type DirectoryProvider struct {
+	entries []directoryEntry
+}
+
+// NewDirectoryProvider loads a curated directory from an OPML or JSON file
+func NewDirectoryProvider(path string) (*DirectoryProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory file: %w", err)
+	}
+
+	return ParseDirectory(data)
+}
+
+// ParseDirectory builds a directory from OPML or JSON content. JSON may be
+// an array of feeds or an object with a "feeds" array.
+func ParseDirectory(data []byte) (*DirectoryProvider, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("directory file is empty")
+	}
+
+	var (
+		entries []directoryEntry
+		err     error
+	)
+	if trimmed[0] == '<' {
+		entries, err = parseOPMLDirectory(trimmed)
+	} else {
+		entries, err = parseJSONDirectory(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	valid := entries[:0]
+	for _, entry := range entries {
+		if entry.URL == "" {
+			continue
+		}
+		entry.searchText = strings.ToLower(strings.Join(append([]string{
+			entry.Title, entry.Description, entry.Author, entry.URL, entry.SiteURL,
+		}, entry.Categories...), " "))
+		valid = append(valid, entry)
+	}
+
+	return &DirectoryProvider{entries: valid}, nil
+}
+
+// Name returns the provider name
+func (p *DirectoryProvider) Name() string {
+	return "directory"
+}
+
+// Len returns the number of feeds in the directory
+func (p *DirectoryProvider) Len() int {
+	return len(p.entries)
+}
+
+// Search returns directory feeds containing every query term, in file order
+func (p *DirectoryProvider) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	results := []domain.SearchResult{}
+
+	for _, entry := range p.entries {
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(entry.searchText, term) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, domain.SearchResult{
+			Title:       entry.Title,
+			Description: entry.Description,
+			URL:         entry.URL,
+			SiteURL:     entry.SiteURL,
+			Language:    entry.Language,
+			Author:      entry.Author,
+			Image:       entry.Image,
+		})
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// parseJSONDirectory reads a JSON directory
+func parseJSONDirectory(data []byte) ([]directoryEntry, error) {
+	var entries []directoryEntry
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse directory JSON: %w", err)
+		}
+		return entries, nil
+	}
+
+	var wrapped struct {
+		Feeds []directoryEntry `json:"feeds"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse directory JSON: %w", err)
+	}
+	return wrapped.Feeds, nil
+}
+
+// opmlOutline is an OPML outline element; folders nest feeds
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Title       string        `xml:"title,attr"`
+	XMLURL      string        `xml:"xmlUrl,attr"`
+	HTMLURL     string        `xml:"htmlUrl,attr"`
+	Description string        `xml:"description,attr"`
+	Language    string        `xml:"language,attr"`
+	Outlines    []opmlOutline `xml:"outline"`
+}
+
+// parseOPMLDirectory reads an OPML directory; folder names become categories
+func parseOPMLDirectory(data []byte) ([]directoryEntry, error) {
+	var doc struct {
+		Body struct {
+			Outlines []opmlOutline `xml:"outline"`
+		} `xml:"body"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse directory OPML: %w", err)
+	}
+
+	var entries []directoryEntry
+	var walk func(outlines []opmlOutline, categories []string)
+	walk = func(outlines []opmlOutline, categories []string) {
+		for _, o := range outlines {
+			title := o.Title
+			if title == "" {
+				title = o.Text
+			}
+
+			if o.XMLURL != "" {
+				entries = append(entries, directoryEntry{
+					Title:       title,
+					Description: o.Description,
+					URL:         o.XMLURL,
+					SiteURL:     o.HTMLURL,
+					Language:    o.Language,
+					Categories:  categories,
+				})
+			}
+			if len(o.Outlines) > 0 {
+				walk(o.Outlines, append(append([]string{}, categories...), title))
+			}
+		}
+	}
+	walk(doc.Body.Outlines, nil)
+
+	return entries, nil
+}
@@ -0,0 +1,663 @@
+// ABOUTME: In-process inverted index for full-text search over parsed feeds and items
+// ABOUTME: Ranks with BM25, supports phrase and exclusion queries, facets and highlighting
+
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"digests-app-api/core/domain"
+)
+
+const (
+	// DefaultMaxIndexedItems bounds memory use; the oldest indexed items are evicted first
+	DefaultMaxIndexedItems = 50000
+
+	// defaultSearchLimit is the page size when a query sets none
+	defaultSearchLimit = 20
+
+	// maxSearchLimit caps the page size
+	maxSearchLimit = 100
+
+	// maxFeedHits limits how many matching feeds are returned
+	maxFeedHits = 10
+
+	// maxIndexedBody limits how much item text is indexed and kept for snippets
+	maxIndexedBody = 20000
+
+	// titleBoost weights title matches over body matches
+	titleBoost = 2
+
+	// titleBodyGap separates title and body positions so phrases can't span both
+	titleBodyGap = 1000
+
+	// bm25K1 and bm25B are the standard BM25 tuning parameters
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// phraseBonus is added to the score for every matched phrase
+	phraseBonus = 1.0
+)
+
+// posting records where a term occurs in an item
+type posting struct {
+	titleTF   int
+	positions []int
+}
+
+// indexedItem is an item stored in the index
+type indexedItem struct {
+	key         string
+	item        domain.FeedItem
+	feedURL     string
+	title       string
+	body        string
+	terms       []string
+	length      int
+	fingerprint string
+}
+
+// indexedFeed is a feed stored in the index
+type indexedFeed struct {
+	title       string
+	description string
+	url         string
+	link        string
+	itemCount   int
+}
+
+// Index is an in-memory inverted index of feeds and items. It is safe for
+// concurrent use and updates incrementally: re-indexing a feed only touches
+// items that are new or changed.
+type Index struct {
+	mu       sync.RWMutex
+	maxItems int
+
+	nextID   int
+	items    map[int]*indexedItem
+	keys     map[string]int
+	order    []int
+	stale    int
+	postings map[string]map[int]*posting
+	totalLen int
+
+	feeds map[string]*indexedFeed
+}
+
+// NewIndex creates an empty index holding at most maxItems items
+// (DefaultMaxIndexedItems when maxItems <= 0)
+func NewIndex(maxItems int) *Index {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxIndexedItems
+	}
+
+	return &Index{
+		maxItems: maxItems,
+		items:    make(map[int]*indexedItem),
+		keys:     make(map[string]int),
+		postings: make(map[string]map[int]*posting),
+		feeds:    make(map[string]*indexedFeed),
+	}
+}
+
+// IndexFeed adds or updates a feed and its items
+func (idx *Index) IndexFeed(ctx context.Context, feed *domain.Feed) error {
+	if feed == nil || feed.URL == "" {
+		return errors.New("feed must have a URL to be indexed")
+	}
+
+	// Fingerprint every item, then only prepare the changed ones outside the
+	// lock; HTML stripping and tokenizing are the expensive parts
+	type candidate struct {
+		key         string
+		fingerprint string
+		item        domain.FeedItem
+	}
+	candidates := make([]candidate, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		key := itemKey(feed.URL, item)
+		if key == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{key: key, fingerprint: itemFingerprint(item), item: item})
+	}
+
+	idx.mu.RLock()
+	changed := candidates[:0]
+	for _, c := range candidates {
+		if id, exists := idx.keys[c.key]; exists && idx.items[id].fingerprint == c.fingerprint {
+			continue
+		}
+		changed = append(changed, c)
+	}
+	idx.mu.RUnlock()
+
+	docs := make([]*preparedItem, 0, len(changed))
+	for _, c := range changed {
+		docs = append(docs, prepareItem(feed.URL, c.key, c.fingerprint, c.item))
+	}
+	description := plainText(feed.Description)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	info, ok := idx.feeds[feed.URL]
+	if !ok {
+		info = &indexedFeed{url: feed.URL}
+		idx.feeds[feed.URL] = info
+	}
+	info.title = feed.Title
+	info.description = description
+	info.link = feed.Link
+
+	for _, doc := range docs {
+		// Another update may have indexed the same version in the meantime
+		if id, exists := idx.keys[doc.key]; exists {
+			if idx.items[id].fingerprint == doc.fingerprint {
+				continue
+			}
+			idx.removeLocked(id)
+			idx.stale++
+		}
+		idx.addLocked(doc)
+	}
+	idx.compactOrderLocked()
+
+	return nil
+}
+
+// Len returns the number of indexed items
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.items)
+}
+
+// preparedItem is an item ready to be added to the index
+type preparedItem struct {
+	*indexedItem
+	titleTokens []token
+	bodyTokens  []token
+}
+
+// prepareItem extracts and tokenizes the text of an item; it needs no lock
+func prepareItem(feedURL, key, fingerprint string, item domain.FeedItem) *preparedItem {
+	body := item.Description
+	if item.ContentEncoded != "" {
+		body += " " + item.ContentEncoded
+	} else if item.Content != "" && item.Content != item.Description {
+		body += " " + item.Content
+	}
+	body = truncateText(plainText(body), maxIndexedBody)
+
+	// Large content is only kept as indexed text
+	stored := item
+	stored.Content = ""
+	stored.ContentEncoded = ""
+
+	doc := &preparedItem{
+		indexedItem: &indexedItem{
+			key:         key,
+			item:        stored,
+			feedURL:     feedURL,
+			title:       strings.TrimSpace(item.Title),
+			body:        body,
+			fingerprint: fingerprint,
+		},
+	}
+	doc.titleTokens = tokenize(doc.title)
+	doc.bodyTokens = tokenize(doc.body)
+	doc.length = len(doc.titleTokens)*titleBoost + len(doc.bodyTokens)
+
+	return doc
+}
+
+// addLocked indexes a prepared item, evicting the oldest items when full
+func (idx *Index) addLocked(prepared *preparedItem) {
+	for len(idx.items) >= idx.maxItems && len(idx.order) > 0 {
+		oldest := idx.order[0]
+		idx.order = idx.order[1:]
+		if _, live := idx.items[oldest]; live {
+			idx.removeLocked(oldest)
+		} else {
+			idx.stale--
+		}
+	}
+
+	doc := prepared.indexedItem
+	id := idx.nextID
+	idx.nextID++
+
+	seen := make(map[string]bool)
+	addTerm := func(term string, position int, inTitle bool) {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int]*posting)
+			idx.postings[term] = docs
+		}
+		p, ok := docs[id]
+		if !ok {
+			p = &posting{}
+			docs[id] = p
+		}
+		if inTitle {
+			p.titleTF++
+		}
+		p.positions = append(p.positions, position)
+		if !seen[term] {
+			seen[term] = true
+			doc.terms = append(doc.terms, term)
+		}
+	}
+	for i, tok := range prepared.titleTokens {
+		addTerm(tok.term, i, true)
+	}
+	for i, tok := range prepared.bodyTokens {
+		addTerm(tok.term, len(prepared.titleTokens)+titleBodyGap+i, false)
+	}
+
+	idx.items[id] = doc
+	idx.keys[doc.key] = id
+	idx.order = append(idx.order, id)
+	idx.totalLen += doc.length
+	if info, ok := idx.feeds[doc.feedURL]; ok {
+		info.itemCount++
+	}
+}
+
+// compactOrderLocked drops replaced items from the eviction queue once they
+// make up half of it, so the queue stays proportional to the live items
+func (idx *Index) compactOrderLocked() {
+	if idx.stale == 0 || idx.stale*2 < len(idx.order) {
+		return
+	}
+
+	live := make([]int, 0, len(idx.items))
+	for _, id := range idx.order {
+		if _, ok := idx.items[id]; ok {
+			live = append(live, id)
+		}
+	}
+	idx.order = live
+	idx.stale = 0
+}
+
+// removeLocked removes an item and its postings
+func (idx *Index) removeLocked(id int) {
+	doc, ok := idx.items[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		if docs, ok := idx.postings[term]; ok {
+			delete(docs, id)
+			if len(docs) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+
+	idx.totalLen -= doc.length
+	delete(idx.items, id)
+	delete(idx.keys, doc.key)
+	if info, ok := idx.feeds[doc.feedURL]; ok {
+		info.itemCount--
+	}
+}
+
+// parsedQuery is a query split into its parts
+type parsedQuery struct {
+	terms    []string
+	phrases  [][]string
+	excluded []string
+}
+
+// highlightTerms returns every positive term of the query
+func (q parsedQuery) highlightTerms() map[string]bool {
+	terms := make(map[string]bool)
+	for _, term := range q.terms {
+		terms[term] = true
+	}
+	for _, phrase := range q.phrases {
+		for _, term := range phrase {
+			terms[term] = true
+		}
+	}
+	return terms
+}
+
+// requiredTerms returns every term an item must contain
+func (q parsedQuery) requiredTerms() []string {
+	required := append([]string{}, q.terms...)
+	for _, phrase := range q.phrases {
+		required = append(required, phrase...)
+	}
+	return required
+}
+
+// parseQuery splits query text into terms, "quoted phrases" and -excluded terms
+func parseQuery(text string) parsedQuery {
+	var q parsedQuery
+
+	rest := text
+	for {
+		start := strings.IndexByte(rest, '"')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start+1:], '"')
+		if end < 0 {
+			break
+		}
+		phrase := tokenize(rest[start+1 : start+1+end])
+		switch len(phrase) {
+		case 0:
+		case 1:
+			q.terms = append(q.terms, phrase[0].term)
+		default:
+			terms := make([]string, len(phrase))
+			for i, tok := range phrase {
+				terms[i] = tok.term
+			}
+			q.phrases = append(q.phrases, terms)
+		}
+		rest = rest[:start] + " " + rest[start+1+end+1:]
+	}
+
+	for _, word := range strings.Fields(rest) {
+		excluded := strings.HasPrefix(word, "-")
+		for _, tok := range tokenize(strings.TrimPrefix(word, "-")) {
+			if excluded {
+				q.excluded = append(q.excluded, tok.term)
+			} else {
+				q.terms = append(q.terms, tok.term)
+			}
+		}
+	}
+
+	return q
+}
+
+// SearchItems runs a ranked full-text query
+func (idx *Index) SearchItems(ctx context.Context, query domain.ItemSearchQuery) (*domain.ItemSearchResults, error) {
+	q := parseQuery(query.Text)
+	required := q.requiredTerms()
+	if len(required) == 0 {
+		return nil, errors.New("search query must contain at least one term")
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	feedFilter := make(map[string]bool, len(query.FeedURLs))
+	for _, u := range query.FeedURLs {
+		feedFilter[u] = true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Intersect postings, starting with the rarest term
+	sort.Slice(required, func(i, j int) bool {
+		return len(idx.postings[required[i]]) < len(idx.postings[required[j]])
+	})
+	var candidates []int
+	for id := range idx.postings[required[0]] {
+		candidates = append(candidates, id)
+	}
+
+	type scored struct {
+		id    int
+		score float64
+	}
+	var (
+		matches    []scored
+		feedCounts = make(map[string]int)
+		dateCounts = make(map[string]int)
+	)
+
+	n := float64(len(idx.items))
+	avgLen := 1.0
+	if len(idx.items) > 0 && idx.totalLen > 0 {
+		avgLen = float64(idx.totalLen) / n
+	}
+
+candidateLoop:
+	for _, id := range candidates {
+		for _, term := range required[1:] {
+			if _, ok := idx.postings[term][id]; !ok {
+				continue candidateLoop
+			}
+		}
+		for _, term := range q.excluded {
+			if _, ok := idx.postings[term][id]; ok {
+				continue candidateLoop
+			}
+		}
+		for _, phrase := range q.phrases {
+			if !idx.hasPhraseLocked(id, phrase) {
+				continue candidateLoop
+			}
+		}
+
+		doc := idx.items[id]
+
+		// Facets count every text match, before feed and date filters
+		feedCounts[doc.feedURL]++
+		if !doc.item.Published.IsZero() {
+			dateCounts[doc.item.Published.UTC().Format("2006-01")]++
+		}
+
+		if len(feedFilter) > 0 && !feedFilter[doc.feedURL] {
+			continue
+		}
+		if !query.From.IsZero() && doc.item.Published.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && doc.item.Published.After(query.To) {
+			continue
+		}
+
+		score := 0.0
+		for term := range q.highlightTerms() {
+			p := idx.postings[term][id]
+			if p == nil {
+				continue
+			}
+			df := float64(len(idx.postings[term]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			tf := float64(p.titleTF*titleBoost + len(p.positions) - p.titleTF)
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLen))
+		}
+		score += phraseBonus * float64(len(q.phrases))
+
+		matches = append(matches, scored{id: id, score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return idx.items[matches[i].id].item.Published.After(idx.items[matches[j].id].item.Published)
+	})
+
+	results := &domain.ItemSearchResults{
+		Total: len(matches),
+		Items: []domain.ItemSearchHit{},
+		Feeds: idx.searchFeedsLocked(q),
+	}
+
+	highlight := q.highlightTerms()
+	for i := offset; i < len(matches) && i < offset+limit; i++ {
+		doc := idx.items[matches[i].id]
+		hit := domain.ItemSearchHit{
+			Item:           doc.item,
+			FeedURL:        doc.feedURL,
+			Score:          math.Round(matches[i].score*1000) / 1000,
+			TitleHighlight: highlightText(doc.title, highlight),
+			Snippet:        snippet(doc.body, highlight),
+		}
+		if info, ok := idx.feeds[doc.feedURL]; ok {
+			hit.FeedTitle = info.title
+		}
+		results.Items = append(results.Items, hit)
+	}
+
+	for feedURL, count := range feedCounts {
+		facet := domain.SearchFacet{Value: feedURL, Label: feedURL, Count: count}
+		if info, ok := idx.feeds[feedURL]; ok && info.title != "" {
+			facet.Label = info.title
+		}
+		results.FeedFacets = append(results.FeedFacets, facet)
+	}
+	sortFacets(results.FeedFacets, false)
+
+	for month, count := range dateCounts {
+		results.DateFacets = append(results.DateFacets, domain.SearchFacet{Value: month, Label: month, Count: count})
+	}
+	sortFacets(results.DateFacets, true)
+
+	return results, nil
+}
+
+// hasPhraseLocked reports whether the terms occur consecutively in an item
+func (idx *Index) hasPhraseLocked(id int, phrase []string) bool {
+	first := idx.postings[phrase[0]][id]
+	if first == nil {
+		return false
+	}
+
+	for _, start := range first.positions {
+		matched := true
+		for offset, term := range phrase[1:] {
+			p := idx.postings[term][id]
+			if p == nil || !containsInt(p.positions, start+offset+1) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// searchFeedsLocked returns feeds whose title or description contain every positive term
+func (idx *Index) searchFeedsLocked(q parsedQuery) []domain.FeedSearchHit {
+	terms := q.requiredTerms()
+	hits := []domain.FeedSearchHit{}
+
+	for _, info := range idx.feeds {
+		titleTerms := termCounts(info.title)
+		descriptionTerms := termCounts(info.description)
+
+		score := 0.0
+		matched := true
+		for _, term := range terms {
+			count := titleTerms[term]*titleBoost + descriptionTerms[term]
+			if count == 0 {
+				matched = false
+				break
+			}
+			score += float64(count)
+		}
+		for _, term := range q.excluded {
+			if titleTerms[term]+descriptionTerms[term] > 0 {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		hits = append(hits, domain.FeedSearchHit{
+			Title:       info.title,
+			Description: info.description,
+			URL:         info.url,
+			Link:        info.link,
+			ItemCount:   info.itemCount,
+			Score:       score,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].URL < hits[j].URL
+	})
+	if len(hits) > maxFeedHits {
+		hits = hits[:maxFeedHits]
+	}
+
+	return hits
+}
+
+// itemKey identifies an item within a feed
+func itemKey(feedURL string, item domain.FeedItem) string {
+	id := item.ID
+	if id == "" {
+		id = item.Link
+	}
+	if id == "" {
+		id = item.Title
+	}
+	if id == "" {
+		return ""
+	}
+	return feedURL + "\x00" + id
+}
+
+// itemFingerprint changes whenever indexed item fields change
+func itemFingerprint(item domain.FeedItem) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%d",
+		item.Title, item.Link, item.Description, item.Content, item.ContentEncoded, item.Published.UnixNano())))
+	return hex.EncodeToString(sum[:8])
+}
+
+// termCounts counts the terms of a text
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, tok := range tokenize(text) {
+		counts[tok.term]++
+	}
+	return counts
+}
+
+// sortFacets orders facets by count, or by value (newest first) for dates
+func sortFacets(facets []domain.SearchFacet, byValue bool) {
+	sort.Slice(facets, func(i, j int) bool {
+		if byValue {
+			return facets[i].Value > facets[j].Value
+		}
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+}
+
+// containsInt reports whether a sorted slice contains v
+func containsInt(sorted []int, v int) bool {
+	i := sort.SearchInts(sorted, v)
+	return i < len(sorted) && sorted[i] == v
+}
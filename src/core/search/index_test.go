@@ -0,0 +1,210 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func indexTestFeeds() []*domain.Feed {
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	return []*domain.Feed{
+		{
+			Title:       "Go Blog",
+			Description: "News about the Go programming language",
+			URL:         "https://go.example.com/feed",
+			Items: []domain.FeedItem{
+				{ID: "1", Title: "Go generics in practice", Description: "<p>Type parameters make <b>generic code</b> easier.</p>", Published: march},
+				{ID: "2", Title: "Release notes", Description: "The release improves generic type inference & tooling.", Published: march.AddDate(0, -1, 0)},
+				{ID: "3", Title: "Error handling", Description: "Wrapping errors with fmt.Errorf.", Published: march.AddDate(0, -2, 0)},
+			},
+		},
+		{
+			Title: "Rust Weekly",
+			URL:   "https://rust.example.com/rss",
+			Items: []domain.FeedItem{
+				{ID: "1", Title: "Generic associated types", Description: "Rust generic code with traits.", Published: march.AddDate(0, 0, 1)},
+			},
+		},
+	}
+}
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+	idx := NewIndex(0)
+	for _, feed := range indexTestFeeds() {
+		if err := idx.IndexFeed(context.Background(), feed); err != nil {
+			t.Fatalf("IndexFeed returned error: %v", err)
+		}
+	}
+	return idx
+}
+
+func TestIndex_RanksAndHighlights(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, err := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "generics"})
+	if err != nil {
+		t.Fatalf("SearchItems returned error: %v", err)
+	}
+	if results.Total != 1 || results.Items[0].Item.ID != "1" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results.Items[0].TitleHighlight != "Go <mark>generics</mark> in practice" {
+		t.Errorf("TitleHighlight = %q", results.Items[0].TitleHighlight)
+	}
+	if results.Items[0].FeedTitle != "Go Blog" {
+		t.Errorf("FeedTitle = %q", results.Items[0].FeedTitle)
+	}
+
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "generic"})
+	if results.Total != 3 {
+		t.Fatalf("expected 3 matches for generic, got %d", results.Total)
+	}
+	if results.Items[0].Item.Title != "Generic associated types" {
+		t.Errorf("title matches should rank first, got %q", results.Items[0].Item.Title)
+	}
+	for _, hit := range results.Items {
+		if !strings.Contains(hit.Snippet+hit.TitleHighlight, "<mark>") {
+			t.Errorf("expected highlighted match in %+v", hit)
+		}
+	}
+
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "tooling"})
+	if results.Total != 1 || !strings.Contains(results.Items[0].Snippet, "inference &amp; <mark>tooling</mark>") {
+		t.Errorf("snippet should be escaped and highlighted: %+v", results.Items)
+	}
+}
+
+func TestIndex_PhrasesAndExclusions(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, _ := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: `"generic code"`})
+	if results.Total != 2 {
+		t.Errorf("expected 2 phrase matches, got %d", results.Total)
+	}
+
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: `"code generic"`})
+	if results.Total != 0 {
+		t.Errorf("phrase terms out of order should not match, got %d", results.Total)
+	}
+
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "generic -rust"})
+	if results.Total != 2 {
+		t.Errorf("expected exclusion to drop the Rust item, got %d", results.Total)
+	}
+
+	if _, err := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: " -rust "}); err == nil {
+		t.Error("expected error for a query without positive terms")
+	}
+}
+
+func TestIndex_FiltersAndFacets(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, _ := idx.SearchItems(context.Background(), domain.ItemSearchQuery{
+		Text:     "generic",
+		FeedURLs: []string{"https://go.example.com/feed"},
+		From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if results.Total != 1 || results.Items[0].Item.ID != "1" {
+		t.Fatalf("unexpected filtered results: %+v", results.Items)
+	}
+
+	// Facets describe every text match so clients can widen the filters
+	if len(results.FeedFacets) != 2 || results.FeedFacets[0].Label != "Go Blog" || results.FeedFacets[0].Count != 2 {
+		t.Errorf("unexpected feed facets: %+v", results.FeedFacets)
+	}
+	if len(results.DateFacets) != 2 || results.DateFacets[0].Value != "2024-03" || results.DateFacets[0].Count != 2 {
+		t.Errorf("unexpected date facets: %+v", results.DateFacets)
+	}
+
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "generic", Limit: 1, Offset: 1})
+	if results.Total != 3 || len(results.Items) != 1 {
+		t.Errorf("expected a single item page of 3 matches, got %d of %d", len(results.Items), results.Total)
+	}
+}
+
+func TestIndex_SearchesFeeds(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, _ := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "programming language"})
+	if len(results.Feeds) != 1 || results.Feeds[0].URL != "https://go.example.com/feed" || results.Feeds[0].ItemCount != 3 {
+		t.Errorf("unexpected feed hits: %+v", results.Feeds)
+	}
+}
+
+func TestIndex_IncrementalUpdates(t *testing.T) {
+	idx := newTestIndex(t)
+	if idx.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", idx.Len())
+	}
+
+	feed := indexTestFeeds()[1]
+	feed.Items[0].Title = "Const generics"
+	feed.Items = append(feed.Items, domain.FeedItem{ID: "2", Title: "Async closures", Published: time.Now()})
+	if err := idx.IndexFeed(context.Background(), feed); err != nil {
+		t.Fatalf("IndexFeed returned error: %v", err)
+	}
+
+	if idx.Len() != 5 {
+		t.Errorf("Len() = %d, want 5 after adding an item", idx.Len())
+	}
+	results, _ := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "associated"})
+	if results.Total != 0 {
+		t.Error("changed items should be re-indexed")
+	}
+	results, _ = idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "async"})
+	if results.Total != 1 {
+		t.Error("new items should be searchable")
+	}
+}
+
+func TestIndex_EvictsOldestItems(t *testing.T) {
+	idx := NewIndex(2)
+	feed := &domain.Feed{
+		Title: "Feed",
+		URL:   "https://example.com/feed",
+		Items: []domain.FeedItem{{ID: "1", Title: "alpha"}, {ID: "2", Title: "beta"}, {ID: "3", Title: "gamma"}},
+	}
+	_ = idx.IndexFeed(context.Background(), feed)
+
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+	if results, _ := idx.SearchItems(context.Background(), domain.ItemSearchQuery{Text: "alpha"}); results.Total != 0 {
+		t.Error("oldest item should have been evicted")
+	}
+}
+
+func TestIndex_CompactsReplacedItems(t *testing.T) {
+	idx := NewIndex(0)
+	feed := &domain.Feed{URL: "https://example.com/feed", Items: []domain.FeedItem{{ID: "1"}, {ID: "2"}}}
+	for i := 0; i < 10; i++ {
+		feed.Items[0].Title = strings.Repeat("edit ", i+1)
+		if err := idx.IndexFeed(context.Background(), feed); err != nil {
+			t.Fatalf("IndexFeed returned error: %v", err)
+		}
+	}
+
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+	if len(idx.order) > 2*idx.Len() {
+		t.Errorf("eviction queue holds %d IDs for %d items", len(idx.order), idx.Len())
+	}
+}
+
+func TestTruncateText_KeepsRunesWhole(t *testing.T) {
+	text := strings.Repeat("é", 10)
+	got := truncateText(text, 5)
+	if got != "éé" {
+		t.Errorf("truncateText() = %q, want %q", got, "éé")
+	}
+	if truncateText("short", 10) != "short" {
+		t.Error("short text should be kept as is")
+	}
+}
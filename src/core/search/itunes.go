@@ -0,0 +1,89 @@
+// ABOUTME: iTunes Search-style provider for podcast directory search
+// ABOUTME: Queries the /search endpoint of an iTunes Search API compatible service
+
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// DefaultITunesBaseURL is the public iTunes Search API
+const DefaultITunesBaseURL = "https://itunes.apple.com"
+
+// ITunesProvider searches an iTunes Search API compatible directory
+type ITunesProvider struct {
+	httpClient interfaces.HTTPClient
+	baseURL    string
+}
+
+// NewITunesProvider creates a provider for the given base URL
+// (DefaultITunesBaseURL when empty)
+func NewITunesProvider(httpClient interfaces.HTTPClient, baseURL string) *ITunesProvider {
+	if baseURL == "" {
+		baseURL = DefaultITunesBaseURL
+	}
+
+	return &ITunesProvider{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Name returns the provider name
+func (p *ITunesProvider) Name() string {
+	return "itunes"
+}
+
+// Search queries the directory for podcasts matching the query
+func (p *ITunesProvider) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	params := url.Values{}
+	params.Set("term", query)
+	params.Set("media", "podcast")
+	params.Set("entity", "podcast")
+	params.Set("limit", strconv.Itoa(limit))
+
+	var response struct {
+		Results []struct {
+			CollectionName    string `json:"collectionName"`
+			ArtistName        string `json:"artistName"`
+			FeedURL           string `json:"feedUrl"`
+			CollectionViewURL string `json:"collectionViewUrl"`
+			ArtworkURL600     string `json:"artworkUrl600"`
+			ArtworkURL100     string `json:"artworkUrl100"`
+			PrimaryGenreName  string `json:"primaryGenreName"`
+		} `json:"results"`
+	}
+	if err := fetchProviderJSON(ctx, p.httpClient, p.baseURL+"/search?"+params.Encode(), nil, &response); err != nil {
+		return nil, fmt.Errorf("itunes search failed: %w", err)
+	}
+
+	results := make([]domain.SearchResult, 0, len(response.Results))
+	for _, r := range response.Results {
+		if r.FeedURL == "" {
+			continue
+		}
+
+		image := r.ArtworkURL600
+		if image == "" {
+			image = r.ArtworkURL100
+		}
+
+		results = append(results, domain.SearchResult{
+			Title:       r.CollectionName,
+			Description: r.PrimaryGenreName,
+			URL:         r.FeedURL,
+			SiteURL:     r.CollectionViewURL,
+			Author:      r.ArtistName,
+			Image:       image,
+		})
+	}
+
+	return results, nil
+}
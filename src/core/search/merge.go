@@ -0,0 +1,178 @@
+// ABOUTME: Merges feed directory results from several providers
+// ABOUTME: Deduplicates by normalized feed URL and assigns 0-100 relevance scores
+
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"digests-app-api/core/domain"
+)
+
+const (
+	// relevanceWeight is the share of the score earned by matching the query text
+	relevanceWeight = 70.0
+
+	// rankWeight is the share of the score earned by a provider's own ranking
+	rankWeight = 20.0
+
+	// agreementBonus is added for every additional provider returning a feed
+	agreementBonus = 5
+)
+
+// mergeResults deduplicates provider result lists and orders them by score.
+// names[i] is the provider that returned lists[i].
+func mergeResults(query string, names []string, lists [][]domain.SearchResult) []domain.SearchResult {
+	terms := uniqueTerms(query)
+
+	merged := make(map[string]*domain.SearchResult)
+	var order []string
+
+	for i, list := range lists {
+		for rank, result := range list {
+			key := feedKey(result.URL)
+			if key == "" {
+				continue
+			}
+
+			result.Score = scoreResult(terms, query, result, rank, len(list))
+
+			existing, ok := merged[key]
+			if !ok {
+				result.Providers = []string{names[i]}
+				merged[key] = &result
+				order = append(order, key)
+				continue
+			}
+
+			if !containsString(existing.Providers, names[i]) {
+				existing.Providers = append(existing.Providers, names[i])
+			}
+			if result.Score > existing.Score {
+				existing.Score = result.Score
+			}
+			fillMissing(existing, result)
+		}
+	}
+
+	results := make([]domain.SearchResult, 0, len(order))
+	for _, key := range order {
+		result := merged[key]
+		result.Score += agreementBonus * (len(result.Providers) - 1)
+		if result.Score > 100 {
+			result.Score = 100
+		}
+		results = append(results, *result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
+
+// scoreResult rates how well a result matches the query on a 0-100 scale,
+// combining title and description term coverage with the provider's rank
+func scoreResult(terms []string, query string, result domain.SearchResult, rank, total int) int {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	titleTerms := termCounts(result.Title)
+	otherTerms := termCounts(result.Description + " " + result.Author)
+
+	titleMatches, otherMatches := 0, 0
+	for _, term := range terms {
+		if titleTerms[term] > 0 {
+			titleMatches++
+		} else if otherTerms[term] > 0 {
+			otherMatches++
+		}
+	}
+
+	coverage := (float64(titleMatches) + 0.5*float64(otherMatches)) / float64(len(terms))
+	relevance := 0.8 * coverage
+
+	normalizedTitle := strings.Join(tokenTerms(result.Title), " ")
+	normalizedQuery := strings.Join(tokenTerms(query), " ")
+	switch {
+	case normalizedTitle == normalizedQuery:
+		relevance += 0.2
+	case strings.HasPrefix(normalizedTitle, normalizedQuery):
+		relevance += 0.1
+	}
+
+	rankScore := 1.0
+	if total > 1 {
+		rankScore = 1 - float64(rank)/float64(total)
+	}
+
+	return int(math.Round(relevanceWeight*relevance + rankWeight*rankScore))
+}
+
+// feedKey normalizes a feed URL for deduplication
+func feedKey(feedURL string) string {
+	key := strings.ToLower(strings.TrimSpace(feedURL))
+	for _, prefix := range []string{"https://", "http://", "feed://", "www."} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	return strings.TrimRight(key, "/")
+}
+
+// fillMissing copies fields the existing result lacks from another result
+func fillMissing(existing *domain.SearchResult, other domain.SearchResult) {
+	if existing.Title == "" {
+		existing.Title = other.Title
+	}
+	if len(other.Description) > len(existing.Description) {
+		existing.Description = other.Description
+	}
+	if existing.SiteURL == "" {
+		existing.SiteURL = other.SiteURL
+	}
+	if existing.Language == "" {
+		existing.Language = other.Language
+	}
+	if existing.Author == "" {
+		existing.Author = other.Author
+	}
+	if existing.Image == "" {
+		existing.Image = other.Image
+	}
+}
+
+// uniqueTerms returns the distinct terms of a query
+func uniqueTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range tokenTerms(query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// tokenTerms returns the terms of a text in order
+func tokenTerms(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = tok.term
+	}
+	return terms
+}
+
+// containsString reports whether values contains v
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
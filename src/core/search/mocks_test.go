@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
 )
 
@@ -77,4 +78,19 @@ func (m *mockCache) Delete(ctx context.Context, key string) error {
 		return m.deleteFunc(ctx, key)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// mockSearchProvider is a mock implementation of the SearchProvider interface
+type mockSearchProvider struct {
+	name    string
+	results []domain.SearchResult
+	err     error
+}
+
+func (m *mockSearchProvider) Name() string {
+	return m.name
+}
+
+func (m *mockSearchProvider) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	return m.results, m.err
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Podcast Index-style provider for podcast directory search
+// ABOUTME: Queries /api/1.0/search/byterm and signs requests when credentials are configured
+
+package search
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// DefaultPodcastIndexBaseURL is the public Podcast Index API
+const DefaultPodcastIndexBaseURL = "https://api.podcastindex.org"
+
+// maxProviderResponseSize caps how much of a provider response is read
+const maxProviderResponseSize = 5 * 1024 * 1024
+
+// PodcastIndexProvider searches a Podcast Index API compatible directory
+type PodcastIndexProvider struct {
+	httpClient interfaces.HTTPClient
+	baseURL    string
+	apiKey     string
+	apiSecret  string
+	now        func() time.Time
+}
+
+// NewPodcastIndexProvider creates a provider for the given base URL
+// (DefaultPodcastIndexBaseURL when empty). Requests are only signed when
+// an API key is given, so unauthenticated local stand-ins work too.
+func NewPodcastIndexProvider(httpClient interfaces.HTTPClient, baseURL, apiKey, apiSecret string) *PodcastIndexProvider {
+	if baseURL == "" {
+		baseURL = DefaultPodcastIndexBaseURL
+	}
+
+	return &PodcastIndexProvider{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		now:        time.Now,
+	}
+}
+
+// Name returns the provider name
+func (p *PodcastIndexProvider) Name() string {
+	return "podcastindex"
+}
+
+// Search queries the directory for feeds matching the query
+func (p *PodcastIndexProvider) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("max", strconv.Itoa(limit))
+
+	var response struct {
+		Feeds []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Link        string `json:"link"`
+			Description string `json:"description"`
+			Author      string `json:"author"`
+			Image       string `json:"image"`
+			Artwork     string `json:"artwork"`
+			Language    string `json:"language"`
+		} `json:"feeds"`
+	}
+	if err := fetchProviderJSON(ctx, p.httpClient, p.baseURL+"/api/1.0/search/byterm?"+params.Encode(), p.authHeaders(), &response); err != nil {
+		return nil, fmt.Errorf("podcast index search failed: %w", err)
+	}
+
+	results := make([]domain.SearchResult, 0, len(response.Feeds))
+	for _, f := range response.Feeds {
+		if f.URL == "" {
+			continue
+		}
+
+		image := f.Artwork
+		if image == "" {
+			image = f.Image
+		}
+
+		results = append(results, domain.SearchResult{
+			Title:       f.Title,
+			Description: f.Description,
+			URL:         f.URL,
+			SiteURL:     f.Link,
+			Language:    f.Language,
+			Author:      f.Author,
+			Image:       image,
+		})
+	}
+
+	return results, nil
+}
+
+// authHeaders returns the Podcast Index authentication headers, if configured
+func (p *PodcastIndexProvider) authHeaders() map[string]string {
+	if p.apiKey == "" {
+		return nil
+	}
+
+	date := strconv.FormatInt(p.now().Unix(), 10)
+	sum := sha1.Sum([]byte(p.apiKey + p.apiSecret + date))
+
+	return map[string]string{
+		"X-Auth-Key":    p.apiKey,
+		"X-Auth-Date":   date,
+		"Authorization": hex.EncodeToString(sum[:]),
+	}
+}
+
+// fetchProviderJSON performs a GET request and decodes a JSON response.
+// Headers are only sent when the client supports them.
+func fetchProviderJSON(ctx context.Context, client interfaces.HTTPClient, apiURL string, headers map[string]string, v interface{}) error {
+	if client == nil {
+		return errors.New("HTTP client not configured")
+	}
+
+	var (
+		resp interfaces.Response
+		err  error
+	)
+	if headerClient, ok := client.(interfaces.HeaderHTTPClient); ok && len(headers) > 0 {
+		resp, err = headerClient.GetWithHeaders(ctx, apiURL, headers)
+	} else {
+		resp, err = client.Get(ctx, apiURL)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("API returned status %d", resp.StatusCode())
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body(), maxProviderResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
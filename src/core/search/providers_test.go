@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// headerHTTPClient records the headers of GetWithHeaders requests
+type headerHTTPClient struct {
+	mockHTTPClient
+	url     string
+	headers map[string]string
+	body    string
+}
+
+func (c *headerHTTPClient) GetWithHeaders(ctx context.Context, url string, headers map[string]string) (interfaces.Response, error) {
+	c.url = url
+	c.headers = headers
+	return &mockResponse{statusCode: 200, body: c.body}, nil
+}
+
+func TestSearchRSSFeeds_MergesProviders(t *testing.T) {
+	itunes := &mockSearchProvider{name: "itunes", results: []domain.SearchResult{
+		{Title: "Go Time", URL: "https://changelog.com/gotime/feed", Image: "https://cdn.example.com/gotime.jpg"},
+		{Title: "Cooking Hour", Description: "Go to the kitchen", URL: "https://cooking.example.com/rss"},
+	}}
+	directory := &mockSearchProvider{name: "directory", results: []domain.SearchResult{
+		{Title: "Go Time", Description: "Weekly Go podcast", URL: "http://www.changelog.com/gotime/feed/"},
+	}}
+	failing := &mockSearchProvider{name: "podcastindex", err: errors.New("unavailable")}
+
+	service := NewSearchService(interfaces.Dependencies{}, itunes, directory, failing)
+	results, err := service.SearchRSSFeeds(context.Background(), "go time")
+	if err != nil {
+		t.Fatalf("SearchRSSFeeds returned error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected duplicates to be merged, got %+v", results)
+	}
+	top := results[0]
+	if top.Title != "Go Time" || len(top.Providers) != 2 {
+		t.Errorf("unexpected top result: %+v", top)
+	}
+	if top.Description != "Weekly Go podcast" || top.Image == "" {
+		t.Errorf("merged result should combine fields: %+v", top)
+	}
+	if top.Score <= results[1].Score || top.Score == 0 || top.Score > 100 {
+		t.Errorf("unexpected scores: %d vs %d", top.Score, results[1].Score)
+	}
+}
+
+func TestSearchRSSFeeds_AllProvidersFail(t *testing.T) {
+	service := NewSearchService(interfaces.Dependencies{}, &mockSearchProvider{name: "itunes", err: errors.New("down")})
+	if _, err := service.SearchRSSFeeds(context.Background(), "golang"); err == nil {
+		t.Error("expected error when every provider fails")
+	}
+
+	if _, err := NewSearchService(interfaces.Dependencies{}).SearchRSSFeeds(context.Background(), "golang"); err == nil {
+		t.Error("expected error without providers")
+	}
+}
+
+func TestPodcastIndexProvider_SignsRequests(t *testing.T) {
+	client := &headerHTTPClient{body: `{"feeds": [{"title": "Go Time", "url": "https://changelog.com/gotime/feed", "artwork": "https://cdn.example.com/a.jpg", "language": "en"}]}`}
+	provider := NewPodcastIndexProvider(client, "http://localhost:9000/", "key", "secret")
+	provider.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	results, err := provider.Search(context.Background(), "go time", 10)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(results) != 1 || results[0].Image != "https://cdn.example.com/a.jpg" || results[0].Language != "en" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if client.url != "http://localhost:9000/api/1.0/search/byterm?max=10&q=go+time" {
+		t.Errorf("unexpected request URL: %s", client.url)
+	}
+	// sha1("key" + "secret" + "1700000000")
+	if client.headers["X-Auth-Date"] != "1700000000" || len(client.headers["Authorization"]) != 40 {
+		t.Errorf("unexpected auth headers: %v", client.headers)
+	}
+}
+
+func TestITunesProvider_SkipsEntriesWithoutFeeds(t *testing.T) {
+	var requested string
+	client := &mockHTTPClient{
+		getFunc: func(ctx context.Context, url string) (interfaces.Response, error) {
+			requested = url
+			return &mockResponse{statusCode: 200, body: `{"results": [
+				{"collectionName": "Go Time", "artistName": "Changelog", "feedUrl": "https://changelog.com/gotime/feed"},
+				{"collectionName": "No Feed"}
+			]}`}, nil
+		},
+	}
+
+	results, err := NewITunesProvider(client, "http://localhost:9001").Search(context.Background(), "go", 5)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(results) != 1 || results[0].Author != "Changelog" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if !strings.HasPrefix(requested, "http://localhost:9001/search?") || !strings.Contains(requested, "media=podcast") {
+		t.Errorf("unexpected request URL: %s", requested)
+	}
+}
+
+func TestDirectoryProvider_OPML(t *testing.T) {
+	opml := `<?xml version="1.0"?>
+<opml version="2.0">
+  <body>
+    <outline text="Programming">
+      <outline text="The Go Blog" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+      <outline text="Rust Blog" xmlUrl="https://blog.rust-lang.org/feed.xml"/>
+    </outline>
+    <outline text="Folder without feeds"/>
+  </body>
+</opml>`
+	path := filepath.Join(t.TempDir(), "directory.opml")
+	if err := os.WriteFile(path, []byte(opml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewDirectoryProvider(path)
+	if err != nil {
+		t.Fatalf("NewDirectoryProvider returned error: %v", err)
+	}
+	if provider.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", provider.Len())
+	}
+
+	results, _ := provider.Search(context.Background(), "programming go", 10)
+	if len(results) != 1 || results[0].URL != "https://go.dev/blog/feed.atom" {
+		t.Errorf("folder names should be searchable as categories: %+v", results)
+	}
+}
+
+func TestDirectoryProvider_JSON(t *testing.T) {
+	provider, err := ParseDirectory([]byte(`{"feeds": [
+		{"title": "Go Time", "url": "https://changelog.com/gotime/feed", "categories": ["podcast"]},
+		{"title": "Missing URL"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseDirectory returned error: %v", err)
+	}
+	if provider.Len() != 1 {
+		t.Errorf("entries without URLs should be dropped, Len() = %d", provider.Len())
+	}
+
+	results, _ := provider.Search(context.Background(), "PODCAST", 10)
+	if len(results) != 1 {
+		t.Errorf("expected case-insensitive category match, got %+v", results)
+	}
+
+	if _, err := ParseDirectory([]byte("  ")); err == nil {
+		t.Error("expected error for empty directory")
+	}
+}
@@ -1,4 +1,4 @@
-// ABOUTME: Search service handles RSS feed discovery through external APIs
+// ABOUTME: Search service handles RSS feed discovery across pluggable directory providers
 // ABOUTME: Provides business logic for feed search operations independent of HTTP layer
 
 package search
@@ -8,23 +8,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/url"
+	"sync"
 	"time"
 
 	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
 )
 
-// SearchService handles feed discovery operations
+// providerResultLimit is how many results are requested from each provider
+const providerResultLimit = 25
+
+// SearchService handles feed discovery operations by querying every
+// configured directory provider and merging their results
 type SearchService struct {
-	deps interfaces.Dependencies
+	deps      interfaces.Dependencies
+	providers []interfaces.SearchProvider
 }
 
 // NewSearchService creates a new search service instance
-func NewSearchService(deps interfaces.Dependencies) *SearchService {
+func NewSearchService(deps interfaces.Dependencies, providers ...interfaces.SearchProvider) *SearchService {
 	return &SearchService{
-		deps: deps,
+		deps:      deps,
+		providers: providers,
 	}
 }
 
@@ -65,65 +70,70 @@ func (s *SearchService) SearchRSSFeeds(ctx context.Context, query string) ([]dom
 		}
 	}
 
-	// Check if we have HTTP client
-	if s.deps.HTTPClient == nil {
-		return nil, errors.New("HTTP client not configured")
+	if len(s.providers) == 0 {
+		return nil, errors.New("no search providers configured")
 	}
 
-	// Call external API (using a mock URL for now)
-	// In a real implementation, this would be a configurable API endpoint
-	apiURL := fmt.Sprintf("https://api.feedsearch.example.com/search?q=%s", url.QueryEscape(query))
-	
-	resp, err := s.deps.HTTPClient.Get(ctx, apiURL)
+	results, err := s.searchProviders(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search feeds: %w", err)
+		return nil, err
 	}
-	defer resp.Body().Close()
 
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("search API returned status %d", resp.StatusCode())
+	// Cache results for 24 hours
+	if s.deps.Cache != nil && len(results) > 0 {
+		if data, err := json.Marshal(results); err == nil {
+			_ = s.deps.Cache.Set(ctx, cacheKey, data, 24*time.Hour)
+		}
 	}
 
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body())
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
+	return results, nil
+}
 
-	// Parse API response
-	var apiResponse struct {
-		Results []struct {
-			Title       string `json:"title"`
-			Description string `json:"description"`
-			FeedURL     string `json:"feedUrl"`
-			SiteURL     string `json:"siteUrl"`
-			Language    string `json:"language"`
-		} `json:"results"`
+// searchProviders queries all providers concurrently and merges their results.
+// A failing provider is logged and skipped unless every provider fails.
+func (s *SearchService) searchProviders(ctx context.Context, query string) ([]domain.SearchResult, error) {
+	type providerResult struct {
+		results []domain.SearchResult
+		err     error
 	}
 
-	if err := json.Unmarshal(bodyBytes, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	responses := make([]providerResult, len(s.providers))
+	var wg sync.WaitGroup
+	for i, provider := range s.providers {
+		wg.Add(1)
+		go func(i int, provider interfaces.SearchProvider) {
+			defer wg.Done()
+			results, err := provider.Search(ctx, query, providerResultLimit)
+			responses[i] = providerResult{results: results, err: err}
+		}(i, provider)
 	}
-
-	// Convert to domain model
-	results := make([]domain.SearchResult, 0, len(apiResponse.Results))
-	for _, r := range apiResponse.Results {
-		results = append(results, domain.SearchResult{
-			Title:       r.Title,
-			Description: r.Description,
-			URL:         r.FeedURL,
-			SiteURL:     r.SiteURL,
-			Language:    r.Language,
-			Score:       0, // API doesn't provide scores in this example
-		})
+	wg.Wait()
+
+	var (
+		lists    [][]domain.SearchResult
+		names    []string
+		firstErr error
+	)
+	for i, response := range responses {
+		if response.err != nil {
+			if firstErr == nil {
+				firstErr = response.err
+			}
+			if s.deps.Logger != nil {
+				s.deps.Logger.Warn("Search provider failed", map[string]interface{}{
+					"provider": s.providers[i].Name(),
+					"error":    response.err.Error(),
+				})
+			}
+			continue
+		}
+		lists = append(lists, response.results)
+		names = append(names, s.providers[i].Name())
 	}
 
-	// Cache results for 24 hours
-	if s.deps.Cache != nil && len(results) > 0 {
-		if data, err := json.Marshal(results); err == nil {
-			_ = s.deps.Cache.Set(ctx, cacheKey, data, 24*time.Hour)
-		}
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("failed to search feeds: %w", firstErr)
 	}
 
-	return results, nil
-}
\ No newline at end of file
+	return mergeResults(query, names, lists), nil
+}
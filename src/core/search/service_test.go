@@ -147,11 +147,10 @@ func TestSearchRSSFeeds_CallsExternalAPI(t *testing.T) {
 	apiResponse := `{
 		"results": [
 			{
-				"title": "Go Blog",
-				"description": "Official Go Blog",
+				"collectionName": "Go Blog",
+				"artistName": "The Go Authors",
 				"feedUrl": "https://blog.golang.org/feed.atom",
-				"siteUrl": "https://blog.golang.org",
-				"language": "en"
+				"collectionViewUrl": "https://blog.golang.org"
 			}
 		]
 	}`
@@ -182,7 +181,7 @@ func TestSearchRSSFeeds_CallsExternalAPI(t *testing.T) {
 		Cache:      mockCache,
 		HTTPClient: mockClient,
 	}
-	service := NewSearchService(deps)
+	service := NewSearchService(deps, NewITunesProvider(mockClient, ""))
 	
 	ctx := context.Background()
 	results, err := service.SearchRSSFeeds(ctx, "golang")
@@ -205,7 +204,7 @@ func TestSearchRSSFeeds_CachesResults(t *testing.T) {
 	cacheCalled := false
 	var capturedTTL time.Duration
 	
-	apiResponse := `{"results": [{"title": "Test Feed", "feedUrl": "https://test.com/feed.xml"}]}`
+	apiResponse := `{"results": [{"collectionName": "Test Feed", "feedUrl": "https://test.com/feed.xml"}]}`
 	
 	mockCache := &mockCache{
 		getFunc: func(ctx context.Context, key string) ([]byte, error) {
@@ -235,7 +234,7 @@ func TestSearchRSSFeeds_CachesResults(t *testing.T) {
 		Cache:      mockCache,
 		HTTPClient: mockClient,
 	}
-	service := NewSearchService(deps)
+	service := NewSearchService(deps, NewITunesProvider(mockClient, ""))
 	
 	ctx := context.Background()
 	_, err := service.SearchRSSFeeds(ctx, "test query")
@@ -276,7 +275,7 @@ func TestSearchRSSFeeds_EmptyResults(t *testing.T) {
 		Cache:      mockCache,
 		HTTPClient: mockClient,
 	}
-	service := NewSearchService(deps)
+	service := NewSearchService(deps, NewITunesProvider(mockClient, ""))
 	
 	ctx := context.Background()
 	results, err := service.SearchRSSFeeds(ctx, "nonexistent")
@@ -0,0 +1,152 @@
+// ABOUTME: Text analysis for the search index: tokenizing, HTML stripping and highlighting
+// ABOUTME: Produces HTML-escaped titles and snippets with matched terms wrapped in <mark>
+
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// snippetTokens is roughly how many words a snippet spans
+const snippetTokens = 30
+
+// token is a normalized term with its byte span in the source text
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// tokenize splits text into lower-cased alphanumeric terms
+func tokenize(text string) []token {
+	var tokens []token
+
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// truncateText cuts text to at most limit bytes without splitting a rune
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
+// plainText converts HTML into whitespace-collapsed plain text
+func plainText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	doc.Find("script, style").Remove()
+
+	// Separate block elements so adjacent paragraphs don't merge words
+	doc.Find("p, div, br, li, h1, h2, h3, h4, h5, h6, td, blockquote").Each(func(_ int, sel *goquery.Selection) {
+		sel.AppendHtml(" ")
+	})
+
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+// highlightText escapes text and wraps the given terms in <mark>
+func highlightText(text string, terms map[string]bool) string {
+	var b strings.Builder
+
+	last := 0
+	for _, tok := range tokenize(text) {
+		if !terms[tok.term] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:tok.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		b.WriteString("</mark>")
+		last = tok.end
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+
+	return b.String()
+}
+
+// snippet returns the window of body text with the most matched terms, highlighted
+func snippet(body string, terms map[string]bool) string {
+	tokens := tokenize(body)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	best, bestCount := 0, -1
+	count := 0
+	for i := range tokens {
+		if terms[tokens[i].term] {
+			count++
+		}
+		if i >= snippetTokens && terms[tokens[i-snippetTokens].term] {
+			count--
+		}
+		windowStart := i - snippetTokens + 1
+		if windowStart < 0 {
+			windowStart = 0
+		}
+		if count > bestCount {
+			best, bestCount = windowStart, count
+		}
+	}
+
+	// Start a few words before the first match for context
+	if bestCount > 0 {
+		for i := best; i < len(tokens) && i < best+snippetTokens; i++ {
+			if terms[tokens[i].term] {
+				best = i - 5
+				break
+			}
+		}
+		if best < 0 {
+			best = 0
+		}
+	}
+
+	endToken := best + snippetTokens - 1
+	if endToken >= len(tokens) {
+		endToken = len(tokens) - 1
+	}
+
+	start := tokens[best].start
+	end := tokens[endToken].end
+	out := highlightText(body[start:end], terms)
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(body) {
+		out += "…"
+	}
+
+	return out
+}
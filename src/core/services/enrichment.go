@@ -5,15 +5,17 @@ package services
 
 import (
 	"context"
+	"net/http"
 	"time"
 	
 	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
+	"digests-app-api/core/metadata"
 )
 
 // ContentEnrichmentService combines metadata and color extraction
 type ContentEnrichmentService struct {
-	metadata      *MetadataService
+	metadata      *metadata.Service
 	thumbnailColor *ThumbnailColorService
 	colorCacheTTL time.Duration
 }
@@ -24,20 +26,20 @@ func NewContentEnrichmentService(deps interfaces.Dependencies, colorCacheTTL tim
 	thumbnailService.cacheTTL = colorCacheTTL
 	
 	return &ContentEnrichmentService{
-		metadata:       NewMetadataService(deps),
+		metadata:       metadata.NewService(deps),
 		thumbnailColor: thumbnailService,
 		colorCacheTTL:  colorCacheTTL,
 	}
 }
 
 // ExtractMetadata extracts metadata from a URL
-func (s *ContentEnrichmentService) ExtractMetadata(ctx context.Context, url string) (*interfaces.MetadataResult, error) {
-	return s.metadata.ExtractMetadata(ctx, url)
+func (s *ContentEnrichmentService) ExtractMetadata(ctx context.Context, url string) (*domain.PageMetadata, error) {
+	return s.metadata.Extract(ctx, url)
 }
 
 // ExtractMetadataBatch extracts metadata for multiple URLs
-func (s *ContentEnrichmentService) ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*interfaces.MetadataResult {
-	return s.metadata.ExtractMetadataBatch(ctx, urls)
+func (s *ContentEnrichmentService) ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*domain.PageMetadata {
+	return s.metadata.ExtractBatch(ctx, urls)
 }
 
 // ExtractColor extracts the prominent color from an image URL
@@ -55,9 +57,67 @@ func (s *ContentEnrichmentService) GetCachedColor(ctx context.Context, imageURL
 	return s.thumbnailColor.GetCachedColor(ctx, imageURL)
 }
 
+// ExtractPalette extracts the color palette of an image URL
+func (s *ContentEnrichmentService) ExtractPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	return s.thumbnailColor.ExtractPalette(ctx, imageURL)
+}
+
+// ExtractPaletteBatch extracts palettes for multiple URLs
+func (s *ContentEnrichmentService) ExtractPaletteBatch(ctx context.Context, imageURLs []string) map[string]*domain.ColorPalette {
+	return s.thumbnailColor.ExtractPaletteBatch(ctx, imageURLs)
+}
+
+// GetCachedPalette retrieves a cached palette without computing
+func (s *ContentEnrichmentService) GetCachedPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	return s.thumbnailColor.GetCachedPalette(ctx, imageURL)
+}
+
+// GetCachedPlaceholder retrieves a cached image placeholder without computing
+func (s *ContentEnrichmentService) GetCachedPlaceholder(ctx context.Context, imageURL string) (*domain.ImagePlaceholder, error) {
+	return s.thumbnailColor.GetCachedPlaceholder(ctx, imageURL)
+}
+
+// SelectThumbnails picks each item's thumbnail from its candidates using
+// cached image checks
+func (s *ContentEnrichmentService) SelectThumbnails(ctx context.Context, feeds []*domain.Feed) {
+	s.thumbnailColor.SelectThumbnails(ctx, feeds)
+}
+
 // SetColorCacheTTL updates the cache duration for colors
 func (s *ContentEnrichmentService) SetColorCacheTTL(ttl time.Duration) {
 	s.colorCacheTTL = ttl
 	// Pass to thumbnail service if needed
 	s.thumbnailColor.cacheTTL = ttl
-}
\ No newline at end of file
+}
+
+// SetSiteRules sets the per-site rules applied before generic metadata extraction
+func (s *ContentEnrichmentService) SetSiteRules(rules interfaces.SiteRuleMatcher) {
+	s.metadata.SetSiteRules(rules)
+}
+
+// SetImageHTTPClient sets the client thumbnails are downloaded with
+func (s *ContentEnrichmentService) SetImageHTTPClient(client interfaces.HTTPClient) {
+	s.thumbnailColor.SetHTTPClient(client)
+}
+
+// SetPageTransport sets the transport pages are fetched with for metadata
+func (s *ContentEnrichmentService) SetPageTransport(transport http.RoundTripper) {
+	s.metadata.SetTransport(transport)
+}
+
+// SetJobQueue sets the queue failed metadata extractions are retried through
+func (s *ContentEnrichmentService) SetJobQueue(queue interfaces.JobQueue) {
+	s.metadata.SetJobQueue(queue)
+}
+
+// RefreshMetadata extracts and caches the metadata of a URL, returning why
+// it failed instead
+func (s *ContentEnrichmentService) RefreshMetadata(ctx context.Context, url string) error {
+	return s.metadata.Refresh(ctx, url)
+}
+
+// RefreshThumbnail computes and caches the palette and placeholder of an
+// image, returning failures that may pass instead of caching them
+func (s *ContentEnrichmentService) RefreshThumbnail(ctx context.Context, imageURL string) error {
+	return s.thumbnailColor.RefreshThumbnail(ctx, imageURL)
+}
@@ -0,0 +1,125 @@
+// ABOUTME: Enrichment job service queuing thumbnail enrichment in the background
+// ABOUTME: Keeps each job's URLs in the cache and reports results from the thumbnail cache and job queue
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/errors"
+	"digests-app-api/core/interfaces"
+	"github.com/google/uuid"
+)
+
+// enrichmentJobTTL is how long a job's results can be polled
+const enrichmentJobTTL = time.Hour
+
+// EnrichmentJobService runs thumbnail enrichment as jobs clients can poll.
+// Each image is queued as a color job; a job is a view over their progress.
+type EnrichmentJobService struct {
+	deps       interfaces.Dependencies
+	thumbnails *ThumbnailColorService
+	queue      interfaces.JobQueue
+}
+
+// NewEnrichmentJobService creates a new enrichment job service queuing
+// images on the given queue, whose color jobs are expected to be processed
+// by the enrichment service's RefreshThumbnail so results share its cache
+func NewEnrichmentJobService(deps interfaces.Dependencies, enrichment *ContentEnrichmentService, queue interfaces.JobQueue) *EnrichmentJobService {
+	return &EnrichmentJobService{
+		deps:       deps,
+		thumbnails: enrichment.thumbnailColor,
+		queue:      queue,
+	}
+}
+
+// StartThumbnailJob queues the images for their palettes and placeholders to
+// be computed in the background, and returns the job with every image pending
+func (s *EnrichmentJobService) StartThumbnailJob(ctx context.Context, imageURLs []string) (*domain.EnrichmentJob, error) {
+	if s.deps.Cache == nil {
+		return nil, fmt.Errorf("enrichment jobs need a cache")
+	}
+
+	for _, imageURL := range imageURLs {
+		if err := s.queue.Enqueue(ctx, domain.JobTypeColor, imageURL); err != nil {
+			return nil, fmt.Errorf("failed to queue thumbnail: %w", err)
+		}
+	}
+
+	job := domain.NewEnrichmentJob(uuid.New().String(), imageURLs)
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deps.Cache.Set(ctx, enrichmentJobKey(job.ID), data, enrichmentJobTTL); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJob returns a job with the results computed so far. Images are done
+// once their thumbnail is cached, and failed once their queued job is dead.
+func (s *EnrichmentJobService) GetJob(ctx context.Context, id string) (*domain.EnrichmentJob, error) {
+	if s.deps.Cache == nil {
+		return nil, &errors.NotFoundError{Resource: "enrichment job", ID: id}
+	}
+
+	data, err := s.deps.Cache.Get(ctx, enrichmentJobKey(id))
+	if err != nil || data == nil {
+		return nil, &errors.NotFoundError{Resource: "enrichment job", ID: id}
+	}
+
+	var job domain.EnrichmentJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode enrichment job: %w", err)
+	}
+
+	for _, imageURL := range job.URLs {
+		job.Results[imageURL] = s.imageResult(ctx, imageURL)
+	}
+	return &job, nil
+}
+
+// imageResult returns the progress of one image of a job
+func (s *EnrichmentJobService) imageResult(ctx context.Context, imageURL string) *domain.EnrichmentResult {
+	if thumbnail, current := s.thumbnails.readCachedThumbnail(ctx, imageURL); current {
+		return thumbnailResult(thumbnail)
+	}
+
+	queued, err := s.queue.Job(ctx, domain.JobTypeColor, imageURL)
+	if err == nil && queued != nil && queued.Status == domain.JobDead {
+		return &domain.EnrichmentResult{
+			Status: domain.EnrichmentFailed,
+			Error:  queued.LastError,
+		}
+	}
+	return &domain.EnrichmentResult{Status: domain.EnrichmentPending}
+}
+
+// thumbnailResult converts what was computed from a thumbnail to a job result
+func thumbnailResult(thumbnail *cachedThumbnail) *domain.EnrichmentResult {
+	if thumbnail.failed() {
+		return &domain.EnrichmentResult{
+			Status:    domain.EnrichmentFailed,
+			Rejection: thumbnail.Rejection,
+			Error:     thumbnail.Rejection.Detail,
+		}
+	}
+
+	palette := thumbnail.Palette
+	return &domain.EnrichmentResult{
+		Status:      domain.EnrichmentDone,
+		Palette:     &palette,
+		Placeholder: thumbnail.Placeholder,
+		Rejection:   thumbnail.Rejection,
+	}
+}
+
+func enrichmentJobKey(id string) string {
+	return fmt.Sprintf("enrichmentJob:%s", id)
+}
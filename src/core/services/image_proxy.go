@@ -0,0 +1,290 @@
+// ABOUTME: Image proxy service that fetches, resizes and re-encodes thumbnails
+// ABOUTME: Caches the processed bytes; metadata such as EXIF is dropped by re-encoding
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/errors"
+	"digests-app-api/core/interfaces"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// MaxImageDimension is the largest width or height an image is served at
+	MaxImageDimension = 2000
+
+	// DefaultImageQuality is the JPEG quality used when none is requested
+	DefaultImageQuality = 80
+
+	// maxProxyImageBytes caps the size of a downloaded image
+	maxProxyImageBytes = 15 << 20
+
+	// maxProxyImagePixels caps the decoded size of an image, so small files
+	// that decode to huge images are refused before they are decoded
+	maxProxyImagePixels = 40_000_000
+)
+
+// ImageProxyService fetches images and serves them resized and re-encoded.
+// Decoders for JPEG, PNG, GIF and WebP are registered by thumbnail_color.go.
+type ImageProxyService struct {
+	deps       interfaces.Dependencies
+	httpClient interfaces.HTTPClient
+	cacheTTL   time.Duration
+}
+
+// NewImageProxyService creates a new image proxy service
+func NewImageProxyService(deps interfaces.Dependencies) *ImageProxyService {
+	return &ImageProxyService{
+		deps:       deps,
+		httpClient: deps.HTTPClient,
+		cacheTTL:   30 * 24 * time.Hour,
+	}
+}
+
+// SetHTTPClient sets the client images are fetched with. As image URLs come
+// from clients, it should refuse connections to internal addresses.
+func (s *ImageProxyService) SetHTTPClient(client interfaces.HTTPClient) {
+	s.httpClient = client
+}
+
+// SetCacheTTL sets how long processed images are cached
+func (s *ImageProxyService) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL = ttl
+}
+
+// ProcessImage returns an image resized and re-encoded as the options ask,
+// from cache when it has been processed before
+func (s *ImageProxyService) ProcessImage(ctx context.Context, imageURL string, opts domain.ImageOptions) (*domain.ProcessedImage, error) {
+	if err := validateImageRequest(imageURL, &opts); err != nil {
+		return nil, err
+	}
+
+	// Check cache first
+	cacheKey := imageCacheKey(imageURL, opts)
+	if s.deps.Cache != nil {
+		if data, err := s.deps.Cache.Get(ctx, cacheKey); err == nil && data != nil {
+			if processed := decodeCachedImage(data); processed != nil {
+				return processed, nil
+			}
+		}
+	}
+
+	data, err := s.fetchImage(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, &errors.ValidationError{Field: "url", Message: err.Error()}
+	}
+
+	processed, err := encodeImage(resizeImage(img, opts), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the result
+	if s.deps.Cache != nil {
+		_ = s.deps.Cache.Set(ctx, cacheKey, encodeCachedImage(processed), s.cacheTTL)
+	}
+
+	s.deps.Logger.Debug("Processed proxied image", map[string]interface{}{
+		"url":        imageURL,
+		"original":   len(data),
+		"processed":  len(processed.Data),
+		"dimensions": fmt.Sprintf("%dx%d", opts.Width, opts.Height),
+	})
+
+	return processed, nil
+}
+
+// validateImageRequest checks the image URL and fills in default options
+func validateImageRequest(imageURL string, opts *domain.ImageOptions) error {
+	parsedURL, err := url.Parse(imageURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return &errors.ValidationError{Field: "url", Message: "must be an http or https URL"}
+	}
+
+	if opts.Width < 0 || opts.Width > MaxImageDimension || opts.Height < 0 || opts.Height > MaxImageDimension {
+		return &errors.ValidationError{Field: "size", Message: fmt.Sprintf("width and height must be between 0 and %d", MaxImageDimension)}
+	}
+
+	switch opts.Fit {
+	case "":
+		opts.Fit = domain.ImageFitContain
+	case domain.ImageFitContain, domain.ImageFitCover:
+	default:
+		return &errors.ValidationError{Field: "fit", Message: "must be contain or cover"}
+	}
+
+	switch opts.Format {
+	case domain.ImageFormatAuto, domain.ImageFormatJPEG, domain.ImageFormatPNG:
+	default:
+		return &errors.ValidationError{Field: "format", Message: "must be jpeg or png"}
+	}
+
+	if opts.Quality == 0 {
+		opts.Quality = DefaultImageQuality
+	}
+	if opts.Quality < 1 || opts.Quality > 100 {
+		return &errors.ValidationError{Field: "quality", Message: "must be between 1 and 100"}
+	}
+
+	return nil
+}
+
+// fetchImage downloads an image, refusing anything larger than the limit
+func (s *ImageProxyService) fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	resp, err := s.httpClient.Get(ctx, imageURL)
+	if err != nil {
+		return nil, &errors.ExternalAPIError{StatusCode: http.StatusBadGateway, Message: err.Error(), API: "image"}
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, &errors.ExternalAPIError{StatusCode: resp.StatusCode(), Message: "image could not be fetched", API: "image"}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body(), maxProxyImageBytes+1))
+	if err != nil {
+		return nil, &errors.ExternalAPIError{StatusCode: http.StatusBadGateway, Message: err.Error(), API: "image"}
+	}
+	if len(data) > maxProxyImageBytes {
+		return nil, &errors.ValidationError{Field: "url", Message: "image is too large"}
+	}
+	return data, nil
+}
+
+// decodeImage decodes an image in any registered format and turns it the
+// way its EXIF orientation says, as the orientation is lost on re-encoding
+func decodeImage(data []byte) (*image.NRGBA, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image format")
+	}
+	if config.Width*config.Height > maxProxyImagePixels {
+		return nil, fmt.Errorf("image has too many pixels")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("image has empty bounds")
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	return applyOrientation(nrgba, jpegOrientation(data)), nil
+}
+
+// resizeImage scales an image down to the requested size. Contain fits the
+// image inside the box; cover fills the box and crops the overflow from
+// the center. Images are never enlarged.
+func resizeImage(img *image.NRGBA, opts domain.ImageOptions) *image.NRGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	source := img.Bounds()
+
+	if opts.Fit == domain.ImageFitCover && opts.Width > 0 && opts.Height > 0 {
+		// Crop the source to the box's aspect ratio first
+		aspect := float64(opts.Width) / float64(opts.Height)
+		if float64(width)/float64(height) > aspect {
+			cropWidth := int(math.Round(float64(height) * aspect))
+			source = image.Rect((width-cropWidth)/2, 0, (width-cropWidth)/2+cropWidth, height)
+		} else {
+			cropHeight := int(math.Round(float64(width) / aspect))
+			source = image.Rect(0, (height-cropHeight)/2, width, (height-cropHeight)/2+cropHeight)
+		}
+		width, height = source.Dx(), source.Dy()
+	}
+
+	scale := 1.0
+	if opts.Width > 0 {
+		scale = math.Min(scale, float64(opts.Width)/float64(width))
+	}
+	if opts.Height > 0 {
+		scale = math.Min(scale, float64(opts.Height)/float64(height))
+	}
+
+	outWidth := int(math.Max(1, math.Round(float64(width)*scale)))
+	outHeight := int(math.Max(1, math.Round(float64(height)*scale)))
+	if source == img.Bounds() && outWidth == width && outHeight == height {
+		return img
+	}
+
+	resized := image.NewNRGBA(image.Rect(0, 0, outWidth, outHeight))
+	xdraw.CatmullRom.Scale(resized, resized.Bounds(), img, source, xdraw.Src, nil)
+	return resized
+}
+
+// encodeImage encodes an image as JPEG or PNG. Without a requested format,
+// images with transparency stay PNG and others become JPEG.
+func encodeImage(img *image.NRGBA, opts domain.ImageOptions) (*domain.ProcessedImage, error) {
+	format := opts.Format
+	if format == domain.ImageFormatAuto {
+		format = domain.ImageFormatJPEG
+		if !img.Opaque() {
+			format = domain.ImageFormatPNG
+		}
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case domain.ImageFormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		return &domain.ProcessedImage{Data: buf.Bytes(), ContentType: "image/png"}, nil
+	default:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		return &domain.ProcessedImage{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+	}
+}
+
+// imageCacheKey identifies a processed image by its URL and options
+func imageCacheKey(imageURL string, opts domain.ImageOptions) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s|%s|%d", imageURL, opts.Width, opts.Height, opts.Fit, opts.Format, opts.Quality)))
+	return "image:" + hex.EncodeToString(sum[:16])
+}
+
+// encodeCachedImage stores a processed image as its content type, a
+// newline and the image bytes
+func encodeCachedImage(processed *domain.ProcessedImage) []byte {
+	data := make([]byte, 0, len(processed.ContentType)+1+len(processed.Data))
+	data = append(data, processed.ContentType...)
+	data = append(data, '\n')
+	return append(data, processed.Data...)
+}
+
+// decodeCachedImage reads an image stored by encodeCachedImage
+func decodeCachedImage(data []byte) *domain.ProcessedImage {
+	i := bytes.IndexByte(data, '\n')
+	if i <= 0 || !strings.HasPrefix(string(data[:i]), "image/") {
+		return nil
+	}
+	return &domain.ProcessedImage{ContentType: string(data[:i]), Data: data[i+1:]}
+}
@@ -0,0 +1,200 @@
+// ABOUTME: Color palette extraction picking named swatches from an image's colors
+// ABOUTME: Also picks a text color meeting WCAG contrast over the dominant color
+
+package services
+
+import (
+	"image"
+	"math"
+
+	"digests-app-api/core/domain"
+)
+
+const (
+	// paletteSamples is about how many pixels are sampled along each side
+	paletteSamples = 100
+
+	// minTextContrast is the WCAG AA contrast ratio for body text
+	minTextContrast = 4.5
+)
+
+// swatch is a group of similar colors in an image
+type swatch struct {
+	color      domain.RGBColor
+	population int
+	saturation float64
+	lightness  float64
+}
+
+// swatchTarget describes the lightness and saturation a named swatch
+// should have, in HSL terms between 0 and 1
+type swatchTarget struct {
+	minLightness, targetLightness, maxLightness    float64
+	minSaturation, targetSaturation, maxSaturation float64
+}
+
+var (
+	vibrantTarget     = swatchTarget{0.3, 0.5, 0.7, 0.35, 1, 1}
+	darkVibrantTarget = swatchTarget{0, 0.26, 0.45, 0.35, 1, 1}
+	mutedTarget       = swatchTarget{0.3, 0.5, 0.7, 0, 0.3, 0.4}
+	lightMutedTarget  = swatchTarget{0.55, 0.74, 1, 0, 0.3, 0.4}
+)
+
+// newPalette picks the named swatches of an image and the text color to
+// draw over it. The dominant color comes from k-means clustering, which
+// the swatches do not replace.
+func newPalette(img image.Image, dominant domain.RGBColor) *domain.ColorPalette {
+	swatches := quantize(img)
+	used := make(map[int]bool)
+
+	palette := &domain.ColorPalette{Dominant: dominant}
+	palette.Vibrant = pickSwatch(swatches, vibrantTarget, used)
+	palette.DarkVibrant = pickSwatch(swatches, darkVibrantTarget, used)
+	palette.Muted = pickSwatch(swatches, mutedTarget, used)
+	palette.LightMuted = pickSwatch(swatches, lightMutedTarget, used)
+	palette.TextColor, palette.TextContrast = textColor(palette)
+
+	return palette
+}
+
+// quantize groups the colors of an image into swatches by keeping the top
+// four bits of each channel. Transparent pixels are skipped, and large
+// images are sampled.
+func quantize(img image.Image) []swatch {
+	bounds := img.Bounds()
+	step := bounds.Dx() / paletteSamples
+	if rows := bounds.Dy() / paletteSamples; rows > step {
+		step = rows
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	type bin struct{ r, g, b, count int }
+	bins := make(map[int]*bin)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			r, g, b = r*0xffff/a>>8, g*0xffff/a>>8, b*0xffff/a>>8
+			key := int(r>>4)<<8 | int(g>>4)<<4 | int(b>>4)
+			if bins[key] == nil {
+				bins[key] = &bin{}
+			}
+			bins[key].r += int(r)
+			bins[key].g += int(g)
+			bins[key].b += int(b)
+			bins[key].count++
+		}
+	}
+
+	swatches := make([]swatch, 0, len(bins))
+	for _, bin := range bins {
+		color := domain.RGBColor{
+			R: uint8(bin.r / bin.count),
+			G: uint8(bin.g / bin.count),
+			B: uint8(bin.b / bin.count),
+		}
+		saturation, lightness := hsl(color)
+		swatches = append(swatches, swatch{color: color, population: bin.count, saturation: saturation, lightness: lightness})
+	}
+	return swatches
+}
+
+// pickSwatch returns the unused swatch closest to a target, weighing
+// lightness most, then saturation and population. It returns nil when no
+// swatch is within the target's bounds.
+func pickSwatch(swatches []swatch, target swatchTarget, used map[int]bool) *domain.RGBColor {
+	maxPopulation := 0
+	for _, s := range swatches {
+		if s.population > maxPopulation {
+			maxPopulation = s.population
+		}
+	}
+
+	best, bestScore := -1, 0.0
+	for i, s := range swatches {
+		if used[i] ||
+			s.lightness < target.minLightness || s.lightness > target.maxLightness ||
+			s.saturation < target.minSaturation || s.saturation > target.maxSaturation {
+			continue
+		}
+		score := 0.24*(1-math.Abs(s.saturation-target.targetSaturation)) +
+			0.52*(1-math.Abs(s.lightness-target.targetLightness)) +
+			0.24*float64(s.population)/float64(maxPopulation)
+		if best < 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	used[best] = true
+	color := swatches[best].color
+	return &color
+}
+
+// textColor picks a color for text over the image. A tint from the palette
+// is preferred when it meets WCAG AA contrast on the dominant color;
+// otherwise white or black, whichever contrasts more.
+func textColor(palette *domain.ColorPalette) (domain.RGBColor, float64) {
+	for _, tint := range []*domain.RGBColor{palette.LightMuted, palette.DarkVibrant} {
+		if tint == nil {
+			continue
+		}
+		if contrast := contrastRatio(*tint, palette.Dominant); contrast >= minTextContrast {
+			return *tint, round2(contrast)
+		}
+	}
+
+	white := domain.RGBColor{R: 255, G: 255, B: 255}
+	black := domain.RGBColor{}
+	onWhite, onBlack := contrastRatio(white, palette.Dominant), contrastRatio(black, palette.Dominant)
+	if onWhite >= onBlack {
+		return white, round2(onWhite)
+	}
+	return black, round2(onBlack)
+}
+
+// contrastRatio returns the WCAG 2 contrast ratio of two colors, from 1 to 21
+func contrastRatio(a, b domain.RGBColor) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// relativeLuminance returns the WCAG 2 relative luminance of an sRGB color
+func relativeLuminance(c domain.RGBColor) float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// hsl returns the HSL saturation and lightness of a color
+func hsl(c domain.RGBColor) (saturation, lightness float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	lightness = (max + min) / 2
+	if max == min {
+		return 0, lightness
+	}
+	delta := max - min
+	if lightness > 0.5 {
+		return delta / (2 - max - min), lightness
+	}
+	return delta / (max + min), lightness
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Image placeholders computed from decoded thumbnails
+// ABOUTME: Encodes a BlurHash and a tiny base64 JPEG shown while the image loads
+
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"math"
+	"strings"
+
+	"digests-app-api/core/domain"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// blurHashSize is the longest side images are scaled to before encoding;
+	// a BlurHash has too few components to need more
+	blurHashSize = 32
+
+	// maxBlurHashComponents is the number of components along the longer
+	// side of a BlurHash; the shorter side gets fewer, keeping the aspect
+	maxBlurHashComponents = 4
+
+	// lqipSize is the longest side of the low-quality preview
+	lqipSize = 16
+
+	// lqipQuality is the JPEG quality of the preview; it is shown blurred
+	lqipQuality = 40
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// newPlaceholder computes the BlurHash and preview of a decoded image
+func newPlaceholder(img image.Image) (*domain.ImagePlaceholder, error) {
+	bounds := img.Bounds()
+	placeholder := &domain.ImagePlaceholder{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	placeholder.BlurHash = blurHash(scaleDown(img, blurHashSize))
+
+	var preview bytes.Buffer
+	if err := jpeg.Encode(&preview, scaleDown(img, lqipSize), &jpeg.Options{Quality: lqipQuality}); err != nil {
+		return nil, err
+	}
+	placeholder.LQIP = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(preview.Bytes())
+
+	return placeholder, nil
+}
+
+// scaleDown scales an image so its longer side is at most size pixels
+func scaleDown(img image.Image, size int) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, int(math.Max(1, math.Round(float64(height)*float64(size)/float64(width))))
+		} else {
+			width, height = int(math.Max(1, math.Round(float64(width)*float64(size)/float64(height)))), size
+		}
+	}
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.BiLinear.Scale(scaled, scaled.Bounds(), img, bounds, xdraw.Src, nil)
+	return scaled
+}
+
+// blurHash encodes an image as a BlurHash (https://blurha.sh): the first
+// cosine components of the image in linear RGB, quantized to base 83
+func blurHash(img *image.NRGBA) string {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	componentsX, componentsY := maxBlurHashComponents, maxBlurHashComponents
+	if width > height {
+		componentsY = int(math.Max(1, math.Round(float64(maxBlurHashComponents*height)/float64(width))))
+	} else {
+		componentsX = int(math.Max(1, math.Round(float64(maxBlurHashComponents*width)/float64(height))))
+	}
+
+	factors := make([][3]float64, 0, componentsX*componentsY)
+	for j := 0; j < componentsY; j++ {
+		for i := 0; i < componentsX; i++ {
+			normalization := 2.0
+			if i == 0 && j == 0 {
+				normalization = 1
+			}
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i*x)/float64(width)) * math.Cos(math.Pi*float64(j*y)/float64(height))
+					pixel := img.NRGBAAt(x, y)
+					factor[0] += basis * sRGBToLinear(pixel.R)
+					factor[1] += basis * sRGBToLinear(pixel.G)
+					factor[2] += basis * sRGBToLinear(pixel.B)
+				}
+			}
+			scale := normalization / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((componentsX-1)+(componentsY-1)*9, 1))
+
+	maxValue := 1.0
+	if len(factors) > 1 {
+		actualMax := 0.0
+		for _, factor := range factors[1:] {
+			for _, v := range factor {
+				actualMax = math.Max(actualMax, math.Abs(v))
+			}
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		hash.WriteString(encode83(quantisedMax, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	dc := factors[0]
+	hash.WriteString(encode83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+
+	for _, factor := range factors[1:] {
+		quantise := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		hash.WriteString(encode83(quantise(factor[0])*19*19+quantise(factor[1])*19+quantise(factor[2]), 2))
+	}
+
+	return hash.String()
+}
+
+// encode83 writes a value as length base 83 digits
+func encode83(value, length int) string {
+	digits := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		digits[i] = base83Chars[value%83]
+		value /= 83
+	}
+	return string(digits)
+}
+
+func sRGBToLinear(v uint8) float64 {
+	s := float64(v) / 255
+	if s <= 0.04045 {
+		return s / 12.92
+	}
+	return math.Pow((s+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
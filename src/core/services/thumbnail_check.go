@@ -0,0 +1,143 @@
+// ABOUTME: Thumbnail validation that skips broken, tiny, blank and repeated images
+// ABOUTME: Picks each item's thumbnail from its candidates using cached image checks
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"digests-app-api/core/domain"
+)
+
+const (
+	// minThumbnailSide is the smallest width or height of a usable
+	// thumbnail; spacers, tracking pixels and favicons are smaller
+	minThumbnailSide = 50
+
+	// maxUniformSpread is the largest standard deviation of any color
+	// channel for an image to count as a single color
+	maxUniformSpread = 6.0
+
+	// minRepeatedItems is how many items a feed needs before an image on
+	// all of them is taken for a logo rather than a coincidence
+	minRepeatedItems = 3
+)
+
+// checkThumbnailImage returns why a decoded image is not worth showing, or
+// an empty reason when it is
+func checkThumbnailImage(img *image.NRGBA) (reason, detail string) {
+	bounds := img.Bounds()
+	if bounds.Dx() < minThumbnailSide || bounds.Dy() < minThumbnailSide {
+		return domain.ThumbnailTooSmall, fmt.Sprintf("%dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	step := bounds.Dx() / paletteSamples
+	if rows := bounds.Dy() / paletteSamples; rows > step {
+		step = rows
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	// Standard deviation of each channel over the opaque pixels
+	var n float64
+	var sum, sumSquares [3]float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			pixel := img.NRGBAAt(x, y)
+			if pixel.A < 0x80 {
+				continue
+			}
+			for i, v := range [3]float64{float64(pixel.R), float64(pixel.G), float64(pixel.B)} {
+				sum[i] += v
+				sumSquares[i] += v * v
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return domain.ThumbnailUniform, "fully transparent"
+	}
+
+	spread := 0.0
+	for i := range sum {
+		mean := sum[i] / n
+		spread = math.Max(spread, math.Sqrt(math.Max(0, sumSquares[i]/n-mean*mean)))
+	}
+	if spread <= maxUniformSpread {
+		return domain.ThumbnailUniform, fmt.Sprintf("color spread %.1f", spread)
+	}
+	return "", ""
+}
+
+// SelectThumbnails picks each item's thumbnail as its first candidate not
+// known to be unfit, recording why earlier candidates were skipped. Images
+// are only judged from cached checks, so candidates not checked yet are
+// used as they are until their palettes are extracted, after which later
+// requests skip them if they are unfit.
+func (s *ThumbnailColorService) SelectThumbnails(ctx context.Context, feeds []*domain.Feed) {
+	for _, feed := range feeds {
+		repeated := repeatedThumbnails(feed)
+
+		for i := range feed.Items {
+			item := &feed.Items[i]
+			candidates := item.ThumbnailCandidates
+			if len(candidates) == 0 && item.Thumbnail != "" {
+				candidates = []string{item.Thumbnail}
+			}
+
+			item.Thumbnail = ""
+			item.ThumbnailRejections = nil
+			for _, candidate := range candidates {
+				if repeated[candidate] {
+					item.ThumbnailRejections = append(item.ThumbnailRejections, domain.ThumbnailRejection{
+						URL:    candidate,
+						Reason: domain.ThumbnailRepeated,
+						Detail: fmt.Sprintf("on all %d items of the feed", len(feed.Items)),
+					})
+					continue
+				}
+
+				if rejection := s.getCachedRejection(ctx, candidate); rejection != nil {
+					rejected := *rejection
+					rejected.URL = candidate
+					item.ThumbnailRejections = append(item.ThumbnailRejections, rejected)
+					continue
+				}
+
+				item.Thumbnail = candidate
+				break
+			}
+		}
+	}
+}
+
+// repeatedThumbnails returns the candidates every item of a feed shares.
+// Podcasts are left out, as episodes commonly use the show's artwork.
+func repeatedThumbnails(feed *domain.Feed) map[string]bool {
+	if len(feed.Items) < minRepeatedItems || feed.FeedType == "podcast" {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, item := range feed.Items {
+		candidates := item.ThumbnailCandidates
+		if len(candidates) == 0 && item.Thumbnail != "" {
+			candidates = []string{item.Thumbnail}
+		}
+		for _, candidate := range candidates {
+			counts[candidate]++
+		}
+	}
+
+	repeated := make(map[string]bool)
+	for candidate, count := range counts {
+		if count == len(feed.Items) {
+			repeated[candidate] = true
+		}
+	}
+	return repeated
+}
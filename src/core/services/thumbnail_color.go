@@ -1,16 +1,17 @@
-// ABOUTME: Thumbnail color extraction service for extracting prominent colors from images
-// ABOUTME: Uses K-means clustering to find the most prominent color in thumbnails
+// ABOUTME: Thumbnail color extraction service for extracting color palettes from images
+// ABOUTME: Caches palettes and validity checks in a versioned format, still reading older "R,G,B" colors
 
 package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"image"
-	"image/draw"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -18,6 +19,7 @@ import (
 	"time"
 	
 	"digests-app-api/core/domain"
+	coreerrors "digests-app-api/core/errors"
 	"digests-app-api/core/interfaces"
 	"github.com/EdlinOrg/prominentcolor"
 	_ "golang.org/x/image/webp" // WebP support
@@ -25,73 +27,207 @@ import (
 
 const (
 	defaultColorValue = 128
-	httpTimeout      = 10 * time.Second
-	userAgent        = "Mozilla/5.0 (compatible; FeedParser/1.0)"
 )
 
 // ThumbnailColorService handles color extraction from images
 type ThumbnailColorService struct {
 	deps       interfaces.Dependencies
-	httpClient *http.Client
+	httpClient interfaces.HTTPClient
 	cacheTTL   time.Duration
 }
 
 // NewThumbnailColorService creates a new thumbnail color service
 func NewThumbnailColorService(deps interfaces.Dependencies) *ThumbnailColorService {
 	return &ThumbnailColorService{
-		deps: deps,
-		httpClient: &http.Client{
-			Timeout: httpTimeout,
-		},
-		cacheTTL: 7 * 24 * time.Hour, // Default 7 days
+		deps:       deps,
+		httpClient: deps.HTTPClient,
+		cacheTTL:   7 * 24 * time.Hour, // Default 7 days
 	}
 }
 
+// SetHTTPClient sets the client images are fetched with. Image URLs come
+// from clients and feeds, so it should refuse internal addresses.
+func (s *ThumbnailColorService) SetHTTPClient(client interfaces.HTTPClient) {
+	s.httpClient = client
+}
+
+// thumbnailCacheVersion is the version of the cached thumbnail format. It
+// is raised whenever what is computed from thumbnails changes, so older
+// entries are recomputed.
+const thumbnailCacheVersion = 3
+
+// cachedThumbnail is what is computed from a thumbnail, as it is stored in
+// the cache. Rejection is set for images not fit to be shown. Entries from
+// before palettes hold only the dominant color, as "R,G,B".
+type cachedThumbnail struct {
+	Version     int                        `json:"version"`
+	Palette     domain.ColorPalette        `json:"palette"`
+	Placeholder *domain.ImagePlaceholder   `json:"placeholder,omitempty"`
+	Rejection   *domain.ThumbnailRejection `json:"rejection,omitempty"`
+}
+
+// thumbnailError is a failure to use an image, with the reason it is
+// recorded under. Transient failures may pass when the image is tried again.
+type thumbnailError struct {
+	reason    string
+	detail    string
+	transient bool
+}
+
+func (e *thumbnailError) Error() string {
+	return fmt.Sprintf("%s: %s", e.reason, e.detail)
+}
+
+// failed reports whether no palette could be computed from the image, so
+// the palette is the default gray
+func (t *cachedThumbnail) failed() bool {
+	return t.Rejection != nil && t.Rejection.Reason != domain.ThumbnailUniform
+}
+
 // ExtractColor extracts the prominent color from an image URL
 func (s *ThumbnailColorService) ExtractColor(ctx context.Context, imageURL string) (*domain.RGBColor, error) {
+	palette, err := s.ExtractPalette(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+	return &palette.Dominant, nil
+}
+
+// ExtractPalette extracts the color palette of an image URL. The image's
+// placeholder is computed and cached along with it. Images that cannot be
+// used return why instead of the default palette.
+func (s *ThumbnailColorService) ExtractPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	thumbnail := s.extractThumbnail(ctx, imageURL)
+	if thumbnail.failed() {
+		return nil, rejectionError(thumbnail.Rejection)
+	}
+	return &thumbnail.Palette, nil
+}
+
+// rejectionError converts why an image cannot be used into a domain error
+func rejectionError(rejection *domain.ThumbnailRejection) error {
+	message := fmt.Sprintf("image cannot be used: %s", rejection.Reason)
+	if rejection.Detail != "" {
+		message += " (" + rejection.Detail + ")"
+	}
+
+	switch rejection.Reason {
+	case domain.ThumbnailUnreachable:
+		return &coreerrors.ExternalAPIError{StatusCode: http.StatusBadGateway, Message: message, API: "image"}
+	case domain.ThumbnailBadStatus:
+		status := http.StatusBadGateway
+		_, _ = fmt.Sscanf(rejection.Detail, "status %d", &status)
+		return &coreerrors.ExternalAPIError{StatusCode: status, Message: message, API: "image"}
+	default:
+		return &coreerrors.ValidationError{Field: "url", Message: message}
+	}
+}
+
+// extractThumbnail returns what is computed from an image URL, from cache
+// when possible
+func (s *ThumbnailColorService) extractThumbnail(ctx context.Context, imageURL string) *cachedThumbnail {
 	if imageURL == "" {
-		return s.defaultColor(), nil
+		return s.defaultThumbnail()
 	}
 
 	// Check cache first
-	if s.deps.Cache != nil {
-		cacheKey := fmt.Sprintf("thumbnailColor:%s", imageURL)
-		if data, err := s.deps.Cache.Get(ctx, cacheKey); err == nil && data != nil {
-			var color domain.RGBColor
-			// Simple parsing - assumes format "R,G,B"
-			if _, err := fmt.Sscanf(string(data), "%d,%d,%d", &color.R, &color.G, &color.B); err == nil {
-				return &color, nil
-			}
-		}
+	if thumbnail, current := s.readCachedThumbnail(ctx, imageURL); current {
+		return thumbnail
 	}
 
-	// Extract color
-	color, err := s.extractColorFromURL(ctx, imageURL)
+	thumbnail, _ := s.computeThumbnail(ctx, imageURL)
+	s.cacheThumbnail(ctx, imageURL, thumbnail)
+
+	return thumbnail
+}
+
+// RefreshThumbnail computes and caches the palette and placeholder of an
+// image unless they are cached. Failures that may pass, such as an
+// unreachable host, are returned instead of cached so the image is tried again.
+func (s *ThumbnailColorService) RefreshThumbnail(ctx context.Context, imageURL string) error {
+	if _, current := s.readCachedThumbnail(ctx, imageURL); current || imageURL == "" {
+		return nil
+	}
+
+	thumbnail, err := s.computeThumbnail(ctx, imageURL)
+	if err != nil {
+		return err
+	}
+	s.cacheThumbnail(ctx, imageURL, thumbnail)
+
+	return nil
+}
+
+// computeThumbnail extracts the palette and placeholder of an image. Images
+// that cannot be used get the default palette and the reason as a
+// rejection; the error is only set for failures that may pass.
+func (s *ThumbnailColorService) computeThumbnail(ctx context.Context, imageURL string) (*cachedThumbnail, error) {
+	thumbnail, err := s.extractThumbnailFromURL(ctx, imageURL)
 	if err != nil {
 		s.deps.Logger.Debug("Failed to extract color from thumbnail", map[string]interface{}{
 			"url":   imageURL,
 			"error": err.Error(),
 		})
-		color = s.defaultColor()
+		thumbnail = s.defaultThumbnail()
+		rejection := &domain.ThumbnailRejection{URL: imageURL, Reason: domain.ThumbnailUndecodable, Detail: err.Error()}
+		var thumbErr *thumbnailError
+		if errors.As(err, &thumbErr) {
+			rejection.Reason, rejection.Detail = thumbErr.reason, thumbErr.detail
+		}
+		thumbnail.Rejection = rejection
+
+		if thumbErr != nil && thumbErr.transient {
+			return thumbnail, err
+		}
 	}
 	
-	// Ensure color is not nil
-	if color == nil {
-		color = s.defaultColor()
+	// Ensure thumbnail is not nil
+	if thumbnail == nil {
+		thumbnail = s.defaultThumbnail()
 	}
 
-	// Cache the result
-	if s.deps.Cache != nil && color != nil {
+	return thumbnail, nil
+}
+
+// cacheThumbnail stores what was computed from an image
+func (s *ThumbnailColorService) cacheThumbnail(ctx context.Context, imageURL string, thumbnail *cachedThumbnail) {
+	if s.deps.Cache != nil {
 		cacheKey := fmt.Sprintf("thumbnailColor:%s", imageURL)
-		cacheData := fmt.Sprintf("%d,%d,%d", color.R, color.G, color.B)
-		_ = s.deps.Cache.Set(ctx, cacheKey, []byte(cacheData), s.cacheTTL)
+		if cacheData, err := json.Marshal(thumbnail); err == nil {
+			_ = s.deps.Cache.Set(ctx, cacheKey, cacheData, s.cacheTTL)
+		}
+	}
+}
+
+// readCachedThumbnail reads a cached thumbnail. current is false for entries
+// in an older format, which may only hold the dominant color.
+func (s *ThumbnailColorService) readCachedThumbnail(ctx context.Context, imageURL string) (thumbnail *cachedThumbnail, current bool) {
+	if s.deps.Cache == nil {
+		return nil, false
+	}
+
+	cacheKey := fmt.Sprintf("thumbnailColor:%s", imageURL)
+	data, err := s.deps.Cache.Get(ctx, cacheKey)
+	if err != nil || data == nil {
+		return nil, false
 	}
 
-	return color, nil
+	var cached cachedThumbnail
+	if err := json.Unmarshal(data, &cached); err == nil {
+		return &cached, cached.Version == thumbnailCacheVersion
+	}
+
+	// Entries from before palettes: "R,G,B"
+	var color domain.RGBColor
+	if _, err := fmt.Sscanf(string(data), "%d,%d,%d", &color.R, &color.G, &color.B); err == nil {
+		return &cachedThumbnail{Palette: domain.ColorPalette{Dominant: color}}, false
+	}
+	return nil, false
 }
 
-// extractColorFromURL downloads and extracts color from image
-func (s *ThumbnailColorService) extractColorFromURL(ctx context.Context, imageURL string) (color *domain.RGBColor, err error) {
+// extractThumbnailFromURL downloads an image and extracts its palette and
+// placeholder
+func (s *ThumbnailColorService) extractThumbnailFromURL(ctx context.Context, imageURL string) (thumbnail *cachedThumbnail, err error) {
 	// Add panic recovery like the original code
 	defer func() {
 		if rec := recover(); rec != nil {
@@ -99,61 +235,60 @@ func (s *ThumbnailColorService) extractColorFromURL(ctx context.Context, imageUR
 				"url":   imageURL,
 				"panic": fmt.Sprintf("%v", rec),
 			})
-			// Return default color on panic
-			color = s.defaultColor()
-			err = fmt.Errorf("panic recovered: %v", rec)
+			// Return default palette on panic
+			thumbnail = s.defaultThumbnail()
+			err = &thumbnailError{reason: domain.ThumbnailUndecodable, detail: fmt.Sprintf("panic recovered: %v", rec)}
 		}
 	}()
 
 	// Validate URL
 	parsedURL, parseErr := url.Parse(imageURL)
 	if parseErr != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return nil, fmt.Errorf("invalid image URL: %s", imageURL)
+		return nil, &thumbnailError{reason: domain.ThumbnailInvalidURL, detail: imageURL}
 	}
 	
 	// Skip SVG files as they can't be decoded as raster images
 	if strings.HasSuffix(strings.ToLower(imageURL), ".svg") {
-		return nil, fmt.Errorf("SVG images are not supported")
+		return nil, &thumbnailError{reason: domain.ThumbnailUndecodable, detail: "SVG images are not supported"}
 	}
 
-	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if s.httpClient == nil {
+		return nil, &thumbnailError{reason: domain.ThumbnailUnreachable, detail: "HTTP client not configured"}
 	}
 
-	// Set user agent from original code
-	req.Header.Set("User-Agent", userAgent)
-
 	// Download image
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.httpClient.Get(ctx, imageURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download image: %w", err)
+		return nil, &thumbnailError{reason: domain.ThumbnailUnreachable, detail: err.Error(), transient: true}
 	}
-	defer resp.Body.Close()
+	defer resp.Body().Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if resp.StatusCode() != http.StatusOK {
+		transient := resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests
+		return nil, &thumbnailError{reason: domain.ThumbnailBadStatus, detail: fmt.Sprintf("status %d", resp.StatusCode()), transient: transient}
 	}
 
-	// Decode image
-	img, _, err := image.Decode(resp.Body)
+	// Images are capped in bytes and in pixels before being decoded, as a
+	// small file may declare a huge image
+	data, err := io.ReadAll(io.LimitReader(resp.Body(), maxProxyImageBytes+1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, &thumbnailError{reason: domain.ThumbnailUnreachable, detail: err.Error(), transient: true}
+	}
+	if len(data) > maxProxyImageBytes {
+		return nil, &thumbnailError{reason: domain.ThumbnailUndecodable, detail: "image is too large"}
 	}
 
-	// Convert to NRGBA for processing
-	bounds := img.Bounds()
-	if bounds.Empty() {
-		return nil, fmt.Errorf("image has empty bounds")
+	imgNRGBA, err := decodeImage(data)
+	if err != nil {
+		return nil, &thumbnailError{reason: domain.ThumbnailUndecodable, detail: err.Error()}
 	}
-	
-	imgNRGBA := image.NewNRGBA(bounds)
-	if imgNRGBA == nil {
-		return nil, fmt.Errorf("failed to create NRGBA image")
+
+	// Spacers, icons and blank placeholders decode fine but are not worth
+	// showing. Tiny images are not worth a palette either.
+	reason, detail := checkThumbnailImage(imgNRGBA)
+	if reason == domain.ThumbnailTooSmall {
+		return nil, &thumbnailError{reason: reason, detail: detail}
 	}
-	
-	draw.Draw(imgNRGBA, bounds, img, bounds.Min, draw.Src)
 
 	// Try to extract color with masks first
 	var colors []prominentcolor.ColorItem
@@ -191,21 +326,48 @@ func (s *ThumbnailColorService) extractColorFromURL(ctx context.Context, imageUR
 		return nil, fmt.Errorf("color array is empty")
 	}
 
-	// Return the most prominent color
-	return &domain.RGBColor{
+	// The most prominent color is the dominant color of the palette
+	dominant := domain.RGBColor{
 		R: uint8(colors[0].Color.R),
 		G: uint8(colors[0].Color.G),
 		B: uint8(colors[0].Color.B),
-	}, nil
+	}
+	thumbnail = &cachedThumbnail{
+		Version: thumbnailCacheVersion,
+		Palette: *newPalette(imgNRGBA, dominant),
+	}
+
+	// The decoded image also gives the placeholder shown while it loads
+	thumbnail.Placeholder, err = newPlaceholder(imgNRGBA)
+	if err != nil {
+		s.deps.Logger.Debug("Failed to create thumbnail placeholder", map[string]interface{}{
+			"url":   imageURL,
+			"error": err.Error(),
+		})
+	}
+
+	if reason != "" {
+		thumbnail.Rejection = &domain.ThumbnailRejection{URL: imageURL, Reason: reason, Detail: detail}
+	}
+
+	return thumbnail, nil
 }
 
-// defaultColor returns the default gray color
-func (s *ThumbnailColorService) defaultColor() *domain.RGBColor {
-	return &domain.RGBColor{
-		R: defaultColorValue,
-		G: defaultColorValue,
-		B: defaultColorValue,
+// defaultThumbnail returns a palette of the default gray color, without
+// a placeholder
+func (s *ThumbnailColorService) defaultThumbnail() *cachedThumbnail {
+	thumbnail := &cachedThumbnail{
+		Version: thumbnailCacheVersion,
+		Palette: domain.ColorPalette{
+			Dominant: domain.RGBColor{
+				R: defaultColorValue,
+				G: defaultColorValue,
+				B: defaultColorValue,
+			},
+		},
 	}
+	thumbnail.Palette.TextColor, thumbnail.Palette.TextContrast = textColor(&thumbnail.Palette)
+	return thumbnail
 }
 
 // GetCachedColor retrieves a color from cache without computing it
@@ -214,24 +376,64 @@ func (s *ThumbnailColorService) GetCachedColor(ctx context.Context, imageURL str
 		return nil, fmt.Errorf("empty image URL")
 	}
 
-	// Check cache only
-	if s.deps.Cache != nil {
-		cacheKey := fmt.Sprintf("thumbnailColor:%s", imageURL)
-		if data, err := s.deps.Cache.Get(ctx, cacheKey); err == nil && data != nil {
-			var color domain.RGBColor
-			// Simple parsing - assumes format "R,G,B"
-			if _, err := fmt.Sscanf(string(data), "%d,%d,%d", &color.R, &color.G, &color.B); err == nil {
-				return &color, nil
-			}
-		}
+	// Entries in older formats still hold the dominant color
+	if thumbnail, _ := s.readCachedThumbnail(ctx, imageURL); thumbnail != nil {
+		return &thumbnail.Palette.Dominant, nil
 	}
 
 	return nil, fmt.Errorf("color not found in cache")
 }
 
+// GetCachedPalette retrieves a palette from cache without computing it.
+// Entries in an older format are treated as missing.
+func (s *ThumbnailColorService) GetCachedPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	if imageURL == "" {
+		return nil, fmt.Errorf("empty image URL")
+	}
+
+	if thumbnail, current := s.readCachedThumbnail(ctx, imageURL); current {
+		return &thumbnail.Palette, nil
+	}
+
+	return nil, fmt.Errorf("palette not found in cache")
+}
+
+// GetCachedPlaceholder retrieves an image placeholder from cache without
+// computing it. Images that could not be decoded have none.
+func (s *ThumbnailColorService) GetCachedPlaceholder(ctx context.Context, imageURL string) (*domain.ImagePlaceholder, error) {
+	if imageURL == "" {
+		return nil, fmt.Errorf("empty image URL")
+	}
+
+	if thumbnail, current := s.readCachedThumbnail(ctx, imageURL); current && thumbnail.Placeholder != nil {
+		return thumbnail.Placeholder, nil
+	}
+
+	return nil, fmt.Errorf("placeholder not found in cache")
+}
+
+// getCachedRejection returns why a checked image was found unfit to show,
+// or nil when it is fine or has not been checked yet
+func (s *ThumbnailColorService) getCachedRejection(ctx context.Context, imageURL string) *domain.ThumbnailRejection {
+	if thumbnail, current := s.readCachedThumbnail(ctx, imageURL); current {
+		return thumbnail.Rejection
+	}
+	return nil
+}
+
 // ExtractColorBatch extracts colors for multiple URLs concurrently
 func (s *ThumbnailColorService) ExtractColorBatch(ctx context.Context, imageURLs []string) map[string]*domain.RGBColor {
-	results := make(map[string]*domain.RGBColor)
+	palettes := s.ExtractPaletteBatch(ctx, imageURLs)
+	results := make(map[string]*domain.RGBColor, len(palettes))
+	for imageURL, palette := range palettes {
+		results[imageURL] = &palette.Dominant
+	}
+	return results
+}
+
+// ExtractPaletteBatch extracts palettes for multiple URLs concurrently
+func (s *ThumbnailColorService) ExtractPaletteBatch(ctx context.Context, imageURLs []string) map[string]*domain.ColorPalette {
+	results := make(map[string]*domain.ColorPalette)
 	resultsMutex := sync.Mutex{}
 	
 	// Log batch processing
@@ -253,8 +455,8 @@ func (s *ThumbnailColorService) ExtractColorBatch(ctx context.Context, imageURLs
 			case semaphore <- struct{}{}:
 				defer func() { <-semaphore }()
 				
-				// Extract color - will use cache if available
-				color, err := s.ExtractColor(ctx, imageURL)
+				// Extract palette - will use cache if available
+				palette, err := s.ExtractPalette(ctx, imageURL)
 				if err != nil {
 					// Log error but don't store default - let it compute next time
 					s.deps.Logger.Debug("Failed to extract color in batch", map[string]interface{}{
@@ -265,7 +467,7 @@ func (s *ThumbnailColorService) ExtractColorBatch(ctx context.Context, imageURLs
 				}
 				
 				resultsMutex.Lock()
-				results[imageURL] = color
+				results[imageURL] = palette
 				resultsMutex.Unlock()
 				
 			case <-ctx.Done():
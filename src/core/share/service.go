@@ -8,10 +8,14 @@ import (
 	"errors"
 
 	"digests-app-api/core/domain"
+	coreerrors "digests-app-api/core/errors"
 	"digests-app-api/core/interfaces"
 	"github.com/google/uuid"
 )
 
+// ErrShareExpired is returned when a share exists but has expired
+var ErrShareExpired = errors.New("share has expired")
+
 // ShareService handles share operations
 type ShareService struct {
 	storage interfaces.ShareStorage
@@ -26,10 +30,15 @@ func NewShareService(storage interfaces.ShareStorage) *ShareService {
 
 // CreateShare creates a new share with the given URLs
 func (s *ShareService) CreateShare(ctx context.Context, urls []string) (*domain.Share, error) {
+	return s.CreateShareWithOptions(ctx, urls, domain.ShareOptions{})
+}
+
+// CreateShareWithOptions creates a new share with a title, description and expiry
+func (s *ShareService) CreateShareWithOptions(ctx context.Context, urls []string, opts domain.ShareOptions) (*domain.Share, error) {
 	// Use domain model's validation
-	share, err := domain.NewShare(urls)
+	share, err := domain.NewShareWithOptions(urls, opts)
 	if err != nil {
-		return nil, err
+		return nil, &coreerrors.ValidationError{Field: "share", Message: err.Error()}
 	}
 
 	// Save to storage
@@ -40,15 +49,16 @@ func (s *ShareService) CreateShare(ctx context.Context, urls []string) (*domain.
 	return share, nil
 }
 
-// GetShare retrieves a share by ID
+// GetShare retrieves a share by ID. IDs that are not share IDs are
+// reported as not found; a valid ID with no share returns nil.
 func (s *ShareService) GetShare(ctx context.Context, id string) (*domain.Share, error) {
 	if id == "" {
-		return nil, errors.New("share ID cannot be empty")
+		return nil, &coreerrors.NotFoundError{Resource: "share", ID: id}
 	}
 
 	// Validate UUID format
 	if _, err := uuid.Parse(id); err != nil {
-		return nil, errors.New("invalid share ID format")
+		return nil, &coreerrors.NotFoundError{Resource: "share", ID: id}
 	}
 
 	// Get from storage
@@ -64,8 +74,8 @@ func (s *ShareService) GetShare(ctx context.Context, id string) (*domain.Share,
 
 	// Check if share is expired
 	if share.IsExpired() {
-		return nil, errors.New("share has expired")
+		return nil, ErrShareExpired
 	}
 
 	return share, nil
-}
\ No newline at end of file
+}
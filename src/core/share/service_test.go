@@ -267,4 +267,30 @@ func TestGetShare_ExpiredShare(t *testing.T) {
 	if share != nil {
 		t.Error("GetShare should return nil for expired share")
 	}
-}
\ No newline at end of file
+	if !errors.Is(err, ErrShareExpired) {
+		t.Errorf("GetShare error = %v, want ErrShareExpired", err)
+	}
+}
+
+func TestCreateShareWithOptions_SavesOptions(t *testing.T) {
+	var saved *domain.Share
+	storage := &mockShareStorage{
+		saveFunc: func(ctx context.Context, share *domain.Share) error {
+			saved = share
+			return nil
+		},
+	}
+	service := NewShareService(storage)
+
+	expiresAt := time.Now().Add(time.Hour)
+	share, err := service.CreateShareWithOptions(context.Background(), []string{"https://example.com/feed"}, domain.ShareOptions{
+		Title:     "Weekend reading",
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("CreateShareWithOptions returned error: %v", err)
+	}
+	if saved != share || saved.Title != "Weekend reading" || saved.ExpiresAt == nil {
+		t.Errorf("options not saved: %+v", saved)
+	}
+}
@@ -0,0 +1,268 @@
+// ABOUTME: Registry of per-site extraction rules keyed by host glob
+// ABOUTME: Loads rules from a directory of YAML/JSON files and reloads them at runtime
+
+package siterules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+// Registry matches hosts to site rules. It is safe for concurrent use and
+// rules can be swapped while requests are being served.
+type Registry struct {
+	mu     sync.RWMutex
+	rules  []domain.SiteRule
+	dir    string
+	loaded time.Time
+	logger interfaces.Logger
+}
+
+// NewRegistry creates a registry holding the given rules
+func NewRegistry(rules ...domain.SiteRule) (*Registry, error) {
+	r := &Registry{}
+	if err := r.Replace(rules); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewDirRegistry creates an empty registry backed by a directory of rule
+// files; call Reload to load them. An empty dir never loads any rules.
+func NewDirRegistry(dir string, logger interfaces.Logger) *Registry {
+	return &Registry{dir: dir, logger: logger}
+}
+
+// Match returns the most specific rule for a host, or nil
+func (r *Registry) Match(host string) *domain.SiteRule {
+	if r == nil || host == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var best *domain.SiteRule
+	bestScore := 0
+	for i := range r.rules {
+		if score := r.rules[i].MatchHost(host); score > bestScore {
+			best, bestScore = &r.rules[i], score
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	rule := *best
+	return &rule
+}
+
+// Rules returns a copy of the loaded rules
+func (r *Registry) Rules() []domain.SiteRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]domain.SiteRule(nil), r.rules...)
+}
+
+// Len returns the number of loaded rules
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.rules)
+}
+
+// Replace validates and swaps in a new set of rules
+func (r *Registry) Replace(rules []domain.SiteRule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("site rule %q: %w", rules[i].Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.rules = append([]domain.SiteRule(nil), rules...)
+	r.loaded = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Reload re-reads the rule directory. On error the current rules are kept.
+func (r *Registry) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+
+	rules, err := LoadDir(r.dir)
+	if err != nil {
+		return err
+	}
+	return r.Replace(rules)
+}
+
+// Watch reloads the rule directory whenever a rule file changes, checking
+// every interval until the context is cancelled
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	if r.dir == "" || interval <= 0 {
+		return
+	}
+
+	r.mu.RLock()
+	since := r.loaded
+	r.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := dirChangedSince(r.dir, since)
+			if err != nil || !changed {
+				continue
+			}
+			// A broken file is reported once, not on every tick
+			since = time.Now()
+			if err := r.Reload(); err != nil {
+				r.logError("Failed to reload site rules", err)
+				continue
+			}
+			if r.logger != nil {
+				r.logger.Info("Reloaded site rules", map[string]interface{}{
+					"dir":   r.dir,
+					"rules": r.Len(),
+				})
+			}
+		}
+	}
+}
+
+func (r *Registry) logError(msg string, err error) {
+	if r.logger != nil {
+		r.logger.Error(msg, map[string]interface{}{
+			"dir":   r.dir,
+			"error": err.Error(),
+		})
+	}
+}
+
+// LoadDir reads every .yaml, .yml and .json file in a directory. A file
+// holds one rule or a list of rules; rules without a name are named after
+// their file.
+func LoadDir(dir string) ([]domain.SiteRule, error) {
+	files, err := ruleFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []domain.SiteRule
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		parsed, err := ParseRules(data, filepath.Ext(file))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		for i := range parsed {
+			if parsed[i].Name == "" {
+				parsed[i].Name = name
+			}
+			if err := parsed[i].Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+			}
+		}
+		rules = append(rules, parsed...)
+	}
+
+	return rules, nil
+}
+
+// ParseRules decodes one rule or a list of rules from YAML or JSON,
+// chosen by file extension
+func ParseRules(data []byte, ext string) ([]domain.SiteRule, error) {
+	unmarshal := yaml.Unmarshal
+	if strings.EqualFold(ext, ".json") {
+		unmarshal = json.Unmarshal
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	var list []domain.SiteRule
+	if err := unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var rule domain.SiteRule
+	if err := unmarshal(data, &rule); err != nil {
+		return nil, fmt.Errorf("invalid site rule: %w", err)
+	}
+	return []domain.SiteRule{rule}, nil
+}
+
+// ruleFiles lists the rule files of a directory in name order
+func ruleFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site rules directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// dirChangedSince reports whether rule files were added, removed or
+// modified after the given time
+func dirChangedSince(dir string, since time.Time) (bool, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().After(since) {
+		return true, nil
+	}
+
+	files, err := ruleFiles(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err == nil && info.ModTime().After(since) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,120 @@
+package siterules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestParseRules(t *testing.T) {
+	yamlRules := `
+- name: news
+  hosts: ["*.news.example.com"]
+  content: ["article .story-body"]
+  remove: [".cookie-banner", ".newsletter"]
+  heroImage: figure.lead img
+- hosts: [blog.example.org]
+  author: .byline
+`
+	rules, err := ParseRules([]byte(yamlRules), ".yaml")
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].HeroImage != "figure.lead img" || len(rules[0].Remove) != 2 || rules[1].Author != ".byline" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	rules, err = ParseRules([]byte(`{"hosts": ["example.com"], "title": "h1.headline"}`), ".json")
+	if err != nil || len(rules) != 1 || rules[0].Title != "h1.headline" {
+		t.Errorf("single JSON rule = %+v, %v", rules, err)
+	}
+}
+
+func TestRegistry_Match(t *testing.T) {
+	registry, err := NewRegistry(
+		domain.SiteRule{Name: "wildcard", Hosts: []string{"*.example.com"}, Title: "h1"},
+		domain.SiteRule{Name: "exact", Hosts: []string{"news.example.com"}, Title: "h2"},
+		domain.SiteRule{Name: "other", Hosts: []string{"example.org"}, Title: "h3"},
+		domain.SiteRule{Name: "fallback", Hosts: []string{"*"}, Title: "h4"},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"news.example.com", "exact"},
+		{"www.news.example.com:443", "exact"},
+		{"blog.example.com", "wildcard"},
+		{"a.b.example.com", "wildcard"},
+		{"example.com", "wildcard"},
+		{"badexample.com", "fallback"},
+		{"WWW.EXAMPLE.ORG", "other"},
+		{"example.net", "fallback"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if rule := registry.Match(tt.host); rule != nil {
+			got = rule.Name
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	if _, err := NewRegistry(domain.SiteRule{Hosts: []string{"example.com"}, Content: []string{"div["}}); err == nil {
+		t.Error("expected an invalid selector to be rejected")
+	}
+}
+
+func TestRegistry_ReloadAndWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeRule := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRule("example.yaml", "hosts: [example.com]\ntitle: h1\n")
+	writeRule("notes.txt", "not a rule")
+
+	registry := NewDirRegistry(dir, nil)
+	if err := registry.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if rule := registry.Match("example.com"); rule == nil || rule.Name != "example" {
+		t.Fatalf("expected the rule to be named after its file, got %+v", rule)
+	}
+
+	// A broken file keeps the rules that are already loaded
+	writeRule("broken.json", `{"hosts": []}`)
+	if err := registry.Reload(); err == nil {
+		t.Error("expected Reload() to fail on an invalid rule")
+	}
+	if registry.Len() != 1 {
+		t.Errorf("Len() = %d after a failed reload, want 1", registry.Len())
+	}
+	os.Remove(filepath.Join(dir, "broken.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go registry.Watch(ctx, 10*time.Millisecond)
+
+	writeRule("more.json", `[{"hosts": ["example.org"]}, {"hosts": ["example.net"]}]`)
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(filepath.Join(dir, "more.json"), future, future)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.Len() != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if registry.Len() != 3 {
+		t.Errorf("Len() = %d, want the watcher to load 3 rules", registry.Len())
+	}
+}
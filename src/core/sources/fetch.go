@@ -0,0 +1,68 @@
+// ABOUTME: Shared fetching helpers for feed source adapters
+// ABOUTME: Reads remote documents through the injected HTTP client with size limits
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"digests-app-api/core/interfaces"
+)
+
+// maxDocumentSize caps how much of a remote document a source will read
+const maxDocumentSize = 10 * 1024 * 1024
+
+// fetchDocument downloads a document and returns its body
+func fetchDocument(ctx context.Context, client interfaces.HTTPClient, documentURL string) ([]byte, error) {
+	if client == nil {
+		return nil, errors.New("HTTP client not configured")
+	}
+
+	resp, err := client.Get(ctx, documentURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", documentURL, err)
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("%s returned status code %d", documentURL, resp.StatusCode())
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body(), maxDocumentSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return body, nil
+}
+
+// stripSchemePrefix turns "scheme+https://host/path" into "https://host/path"
+// and checks that the remaining URL is a fetchable HTTP(S) URL
+func stripSchemePrefix(sourceURL, scheme string) (string, error) {
+	target := sourceURL
+	prefix := scheme + "+"
+	if len(target) > len(prefix) && strings.EqualFold(target[:len(prefix)], prefix) {
+		target = target[len(prefix):]
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid %s source URL: %s", scheme, sourceURL)
+	}
+
+	return target, nil
+}
+
+// siteRoot returns the scheme and host of a URL, e.g. "https://example.com"
+func siteRoot(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
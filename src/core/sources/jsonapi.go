@@ -0,0 +1,286 @@
+// ABOUTME: JSON API source maps JSON documents into feeds using JSONPath-style expressions
+// ABOUTME: Manages stored mappings and previews what a mapping extracts
+
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	coreerrors "digests-app-api/core/errors"
+	"digests-app-api/core/interfaces"
+	utiltime "digests-app-api/pkg/utils/time"
+)
+
+const (
+	// JSONAPIScheme is the source URL scheme handled by JSONAPISource,
+	// e.g. "jsonapi://<mapping id>"
+	JSONAPIScheme = "jsonapi"
+
+	// maxJSONItems limits how many matched values become feed items
+	maxJSONItems = 100
+)
+
+// JSONAPISource turns stored JSON mappings into feeds
+type JSONAPISource struct {
+	deps    interfaces.Dependencies
+	storage interfaces.JSONMappingStorage
+}
+
+// NewJSONAPISource creates a new JSON API source backed by the given storage
+func NewJSONAPISource(deps interfaces.Dependencies, storage interfaces.JSONMappingStorage) *JSONAPISource {
+	return &JSONAPISource{
+		deps:    deps,
+		storage: storage,
+	}
+}
+
+// Scheme returns the URL scheme handled by this source
+func (s *JSONAPISource) Scheme() string {
+	return JSONAPIScheme
+}
+
+// FetchFeed maps the document of the mapping behind a "jsonapi://<id>" URL
+func (s *JSONAPISource) FetchFeed(ctx context.Context, sourceURL string) (*domain.Feed, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || !strings.EqualFold(u.Scheme, JSONAPIScheme) || u.Host == "" {
+		return nil, fmt.Errorf("invalid jsonapi source URL: %s", sourceURL)
+	}
+
+	mapping, err := s.GetMapping(ctx, u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fetchAndMap(ctx, mapping, sourceURL)
+}
+
+// CreateMapping validates and stores a new mapping
+func (s *JSONAPISource) CreateMapping(ctx context.Context, mapping domain.JSONMapping) (*domain.JSONMapping, error) {
+	created, err := domain.NewJSONMapping(mapping)
+	if err != nil {
+		return nil, &coreerrors.ValidationError{Field: "mapping", Message: err.Error()}
+	}
+	if err := validateMappingPaths(created); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Save(ctx, created); err != nil {
+		return nil, fmt.Errorf("failed to save mapping: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetMapping retrieves a mapping by ID
+func (s *JSONAPISource) GetMapping(ctx context.Context, id string) (*domain.JSONMapping, error) {
+	mapping, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mapping: %w", err)
+	}
+	if mapping == nil {
+		return nil, &coreerrors.NotFoundError{Resource: "mapping", ID: id}
+	}
+
+	return mapping, nil
+}
+
+// ListMappings returns all stored mappings
+func (s *JSONAPISource) ListMappings(ctx context.Context) ([]*domain.JSONMapping, error) {
+	return s.storage.List(ctx)
+}
+
+// DeleteMapping removes a mapping and its cached feed
+func (s *JSONAPISource) DeleteMapping(ctx context.Context, id string) error {
+	mapping, err := s.GetMapping(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete mapping: %w", err)
+	}
+
+	if s.deps.Cache != nil {
+		_ = s.deps.Cache.Delete(ctx, fmt.Sprintf("feed:%s", mapping.FeedURL()))
+	}
+
+	return nil
+}
+
+// PreviewMapping maps the live document without storing the mapping
+func (s *JSONAPISource) PreviewMapping(ctx context.Context, mapping domain.JSONMapping) (*domain.Feed, error) {
+	mapping.URL = strings.TrimSpace(mapping.URL)
+	if err := mapping.Validate(); err != nil {
+		return nil, &coreerrors.ValidationError{Field: "mapping", Message: err.Error()}
+	}
+	if err := validateMappingPaths(&mapping); err != nil {
+		return nil, err
+	}
+
+	return s.fetchAndMap(ctx, &mapping, mapping.URL)
+}
+
+// fetchAndMap fetches the mapping's document and maps it into a feed
+func (s *JSONAPISource) fetchAndMap(ctx context.Context, mapping *domain.JSONMapping, feedURL string) (*domain.Feed, error) {
+	body, err := fetchDocument(ctx, s.deps.HTTPClient, mapping.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON document: %w", err)
+	}
+
+	return mapJSONFeed(doc, mapping, feedURL)
+}
+
+// validateMappingPaths checks that every configured path parses
+func validateMappingPaths(mapping *domain.JSONMapping) error {
+	paths := map[string]string{
+		"itemsPath":   mapping.ItemsPath,
+		"idPath":      mapping.IDPath,
+		"titlePath":   mapping.TitlePath,
+		"linkPath":    mapping.LinkPath,
+		"datePath":    mapping.DatePath,
+		"contentPath": mapping.ContentPath,
+		"summaryPath": mapping.SummaryPath,
+		"authorPath":  mapping.AuthorPath,
+		"imagePath":   mapping.ImagePath,
+	}
+
+	for field, expr := range paths {
+		if expr == "" {
+			continue
+		}
+		if _, err := parseJSONPath(expr); err != nil {
+			return &coreerrors.ValidationError{Field: field, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// mapJSONFeed applies a mapping to a decoded JSON document
+func mapJSONFeed(doc interface{}, mapping *domain.JSONMapping, feedURL string) (*domain.Feed, error) {
+	documentURL, err := url.Parse(mapping.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mapping URL: %w", err)
+	}
+
+	items, err := evalJSONPath(doc, mapping.ItemsPath)
+	if err != nil {
+		return nil, err
+	}
+	// A path pointing at the array itself selects its elements
+	if len(items) == 1 {
+		if list, ok := items[0].([]interface{}); ok {
+			items = list
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("items path %q matched nothing", mapping.ItemsPath)
+	}
+
+	feed := &domain.Feed{
+		ID:          mapping.URL,
+		Title:       mapping.Name,
+		URL:         feedURL,
+		Link:        siteRoot(mapping.URL),
+		Items:       make([]domain.FeedItem, 0, len(items)),
+		FeedType:    "article",
+		LastUpdated: time.Now(),
+	}
+	if feed.Title == "" {
+		feed.Title = documentURL.Host
+	}
+
+	var newest time.Time
+	for _, raw := range items {
+		item, ok := mapJSONItem(raw, mapping, documentURL)
+		if !ok {
+			continue
+		}
+		feed.Items = append(feed.Items, item)
+		if item.Published.After(newest) {
+			newest = item.Published
+		}
+		if len(feed.Items) >= maxJSONItems {
+			break
+		}
+	}
+
+	if len(feed.Items) == 0 {
+		return nil, errors.New("no items with a title or link were found")
+	}
+	if !newest.IsZero() {
+		feed.LastUpdated = newest
+	}
+
+	return feed, nil
+}
+
+// mapJSONItem maps a single JSON value into a feed item.
+// Items with neither a title nor a link are skipped.
+func mapJSONItem(raw interface{}, mapping *domain.JSONMapping, documentURL *url.URL) (domain.FeedItem, bool) {
+	title := jsonPathString(raw, mapping.TitlePath)
+	link := resolveLink(documentURL, jsonPathString(raw, mapping.LinkPath))
+
+	if title == "" && link == "" {
+		return domain.FeedItem{}, false
+	}
+	if title == "" {
+		title = titleFromURL(link)
+	}
+
+	item := domain.FeedItem{
+		ID:      jsonPathString(raw, mapping.IDPath),
+		Title:   title,
+		Link:    link,
+		Author:  jsonPathString(raw, mapping.AuthorPath),
+		Content: jsonPathString(raw, mapping.ContentPath),
+	}
+
+	if item.ID == "" {
+		item.ID = link
+	}
+	if item.ID == "" {
+		sum := sha256.Sum256([]byte(mapping.URL + "|" + title))
+		item.ID = hex.EncodeToString(sum[:])
+	}
+
+	item.Description = jsonPathString(raw, mapping.SummaryPath)
+	if item.Description == "" {
+		item.Description = item.Content
+	}
+
+	if date := jsonPathString(raw, mapping.DatePath); date != "" {
+		published, ok := jsonTimestamp(date)
+		if !ok {
+			published = utiltime.ParseFlexibleTime(date)
+		}
+		item.Published = published
+		if !published.IsZero() {
+			item.Created = &published
+		}
+	}
+
+	if image := jsonPathString(raw, mapping.ImagePath); image != "" {
+		item.Thumbnail = resolveLink(documentURL, image)
+	}
+
+	return item, true
+}
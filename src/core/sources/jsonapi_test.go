@@ -0,0 +1,251 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/feed"
+	"digests-app-api/core/interfaces"
+	stdhttp "digests-app-api/infrastructure/http/standard"
+)
+
+const postsDocument = `{
+  "meta": {"count": 3},
+  "data": {
+    "posts": [
+      {
+        "id": 9007199254740993,
+        "attributes": {"headline": "Launch day", "body": "<p>We launched</p>", "teaser": "Short teaser"},
+        "url": "/posts/launch",
+        "published_at": 1709287200,
+        "author": {"name": "Ada"},
+        "images": [{"src": "https://cdn.example.com/launch.png"}]
+      },
+      {
+        "id": 2,
+        "attributes": {"headline": "Hello", "body": "First post"},
+        "url": "https://api.example.com/posts/hello",
+        "published_at": "2024-01-05T09:00:00Z"
+      },
+      {"id": 3, "attributes": {}}
+    ]
+  }
+}`
+
+func postsMapping() domain.JSONMapping {
+	return domain.JSONMapping{
+		Name:        "Example Posts",
+		URL:         "https://api.example.com/v1/posts",
+		ItemsPath:   "$.data.posts[*]",
+		IDPath:      "$.id",
+		TitlePath:   "$.attributes.headline",
+		LinkPath:    "url",
+		DatePath:    "$.published_at",
+		ContentPath: "$['attributes']['body']",
+		SummaryPath: "$.attributes.teaser",
+		AuthorPath:  "author.name",
+		ImagePath:   "$.images[0].src",
+	}
+}
+
+func TestJSONAPISource_Preview(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://api.example.com/v1/posts": postsDocument,
+	}}
+	source := NewJSONAPISource(interfaces.Dependencies{HTTPClient: client}, &mockJSONMappingStorage{})
+
+	feed, err := source.PreviewMapping(context.Background(), postsMapping())
+	if err != nil {
+		t.Fatalf("PreviewMapping returned error: %v", err)
+	}
+
+	if feed.Title != "Example Posts" || feed.Link != "https://api.example.com" {
+		t.Errorf("unexpected feed metadata: %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2 (item without title or link skipped)", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.ID != "9007199254740993" {
+		t.Errorf("ID = %q, want large numeric ID preserved", first.ID)
+	}
+	if first.Link != "https://api.example.com/posts/launch" {
+		t.Errorf("link = %q, want resolved relative link", first.Link)
+	}
+	if first.Published.Unix() != 1709287200 {
+		t.Errorf("published = %v, want Unix timestamp", first.Published)
+	}
+	if first.Author != "Ada" || first.Thumbnail != "https://cdn.example.com/launch.png" {
+		t.Errorf("unexpected author or thumbnail: %+v", first)
+	}
+	if first.Description != "Short teaser" || first.Content != "<p>We launched</p>" {
+		t.Errorf("unexpected description or content: %+v", first)
+	}
+
+	second := feed.Items[1]
+	if second.Description != "First post" {
+		t.Errorf("description = %q, want content fallback", second.Description)
+	}
+	if second.Published.IsZero() {
+		t.Error("expected RFC 3339 date to be parsed")
+	}
+}
+
+func TestJSONAPISource_PreviewRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("JSON API source reached a loopback server")
+		w.Write([]byte(postsDocument))
+	}))
+	defer server.Close()
+
+	source := NewJSONAPISource(interfaces.Dependencies{HTTPClient: stdhttp.NewGuardedHTTPClient(5 * time.Second)}, &mockJSONMappingStorage{})
+
+	mapping := postsMapping()
+	mapping.URL = server.URL + "/v1/posts"
+	if _, err := source.PreviewMapping(context.Background(), mapping); err == nil {
+		t.Error("expected the loopback URL to be refused")
+	}
+}
+
+func TestJSONAPISource_ItemsPathToArray(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/items.json": `[{"title": "A", "link": "https://example.com/a", "ts": 1709287200000}]`,
+	}}
+	source := NewJSONAPISource(interfaces.Dependencies{HTTPClient: client}, &mockJSONMappingStorage{})
+
+	feed, err := source.PreviewMapping(context.Background(), domain.JSONMapping{
+		URL:       "https://example.com/items.json",
+		ItemsPath: "$",
+		TitlePath: "title",
+		LinkPath:  "link",
+		DatePath:  "ts",
+	})
+	if err != nil {
+		t.Fatalf("PreviewMapping returned error: %v", err)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Published.Unix() != 1709287200 {
+		t.Errorf("unexpected items: %+v", feed.Items)
+	}
+	if feed.Title != "example.com" {
+		t.Errorf("feed title = %q, want host fallback", feed.Title)
+	}
+}
+
+func TestJSONAPISource_Validation(t *testing.T) {
+	source := NewJSONAPISource(interfaces.Dependencies{HTTPClient: &mockHTTPClient{}}, &mockJSONMappingStorage{})
+
+	mapping := postsMapping()
+	mapping.TitlePath, mapping.LinkPath = "", ""
+	if _, err := source.CreateMapping(context.Background(), mapping); err == nil {
+		t.Error("expected error without title or link path")
+	}
+
+	mapping = postsMapping()
+	mapping.DatePath = "$..date"
+	if _, err := source.PreviewMapping(context.Background(), mapping); err == nil {
+		t.Error("expected error for unsupported path")
+	}
+}
+
+func TestJSONAPISource_StoredMappingThroughFeedService(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://api.example.com/v1/posts": postsDocument,
+	}}
+	deps := interfaces.Dependencies{HTTPClient: client}
+	source := NewJSONAPISource(deps, &mockJSONMappingStorage{})
+
+	mapping, err := source.CreateMapping(context.Background(), postsMapping())
+	if err != nil {
+		t.Fatalf("CreateMapping returned error: %v", err)
+	}
+
+	service := feed.NewFeedService(deps)
+	service.RegisterSource(source)
+
+	feeds, err := service.ParseFeeds(context.Background(), []string{mapping.FeedURL()})
+	if err != nil {
+		t.Fatalf("ParseFeeds returned error: %v", err)
+	}
+	if len(feeds) != 1 || len(feeds[0].Items) != 2 {
+		t.Fatalf("unexpected feeds: %+v", feeds)
+	}
+
+	if err := source.DeleteMapping(context.Background(), mapping.ID); err != nil {
+		t.Fatalf("DeleteMapping returned error: %v", err)
+	}
+	if _, err := source.GetMapping(context.Background(), mapping.ID); err == nil {
+		t.Error("expected error for deleted mapping")
+	}
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"a": {"b": [{"c": 1}, {"c": 2}, {"c": 3}]}, "key.with.dots": "x"}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"$.a.b[*].c", 3},
+		{"a.b[1].c", 1},
+		{"$.a.b[-1]", 1},
+		{"$.a.b[5]", 0},
+		{"$.a.*", 1},
+		{"$['key.with.dots']", 1},
+		{"$.missing.path", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := evalJSONPath(doc, tt.expr)
+		if err != nil {
+			t.Errorf("evalJSONPath(%q) returned error: %v", tt.expr, err)
+			continue
+		}
+		if len(got) != tt.want {
+			t.Errorf("evalJSONPath(%q) matched %d values, want %d", tt.expr, len(got), tt.want)
+		}
+	}
+
+	if got := jsonPathString(doc, "$.a.b[-1].c"); got != "3" {
+		t.Errorf("jsonPathString = %q, want 3", got)
+	}
+
+	for _, bad := range []string{"$..a", "$.a[", "$.a[x]", "$.a."} {
+		if _, err := parseJSONPath(bad); err == nil {
+			t.Errorf("parseJSONPath(%q) expected error", bad)
+		}
+	}
+}
+
+func TestEvalJSONPath_ObjectWildcardIsOrdered(t *testing.T) {
+	object := make(map[string]interface{})
+	for i := 0; i < 150; i++ {
+		key := fmt.Sprintf("post-%03d", i)
+		object[key] = key
+	}
+	doc := map[string]interface{}{"posts": object}
+
+	for run := 0; run < 5; run++ {
+		got, err := evalJSONPath(doc, "$.posts.*")
+		if err != nil {
+			t.Fatalf("evalJSONPath returned error: %v", err)
+		}
+		if len(got) != 150 {
+			t.Fatalf("matched %d values, want 150", len(got))
+		}
+		for i, value := range got {
+			if want := fmt.Sprintf("post-%03d", i); value != want {
+				t.Fatalf("run %d: value %d = %v, want %s in key order", run, i, value, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// ABOUTME: Minimal JSONPath-style expression evaluator for JSON source mappings
+// ABOUTME: Supports child keys, quoted keys, array indexes and wildcards
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonPathSegment is one step of a parsed path
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses expressions such as "$.data.posts[*]", "$['title']",
+// "items[0].url" or "author.name". The leading "$" is optional.
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+
+	var segments []jsonPathSegment
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("recursive descent is not supported in %q", expr)
+
+		case rest[0] == '.':
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("path %q ends with '.'", expr)
+			}
+
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' in %q", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in %q", inner, expr)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+			continue
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+
+		if name == "*" {
+			segments = append(segments, jsonPathSegment{wildcard: true})
+		} else if name != "" {
+			segments = append(segments, jsonPathSegment{key: name})
+		}
+	}
+
+	return segments, nil
+}
+
+// evalJSONPath returns every value matched by expr in a decoded JSON document
+func evalJSONPath(doc interface{}, expr string) ([]interface{}, error) {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []interface{}{doc}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, value := range current {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if seg.wildcard {
+					// Keys are visited in sorted order so that matches, and the
+					// items kept when they are truncated, are the same every time
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				} else if child, ok := v[seg.key]; ok && !seg.isIndex {
+					next = append(next, child)
+				}
+			case []interface{}:
+				switch {
+				case seg.wildcard:
+					next = append(next, v...)
+				case seg.isIndex:
+					index := seg.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+// jsonPathString returns the first value matched by expr as a string,
+// or "" when the path is empty or matches nothing
+func jsonPathString(doc interface{}, expr string) string {
+	if strings.TrimSpace(expr) == "" {
+		return ""
+	}
+
+	values, err := evalJSONPath(doc, expr)
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+
+	return jsonScalarString(values[0])
+}
+
+// jsonScalarString converts a scalar JSON value to a string.
+// Documents are decoded with UseNumber so large numeric IDs keep their precision.
+func jsonScalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// jsonTimestamp interprets a numeric string as a Unix timestamp in seconds
+// or milliseconds
+func jsonTimestamp(value string) (time.Time, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+
+	// Millisecond timestamps are past the year 33658 when read as seconds
+	if n > 1e12 {
+		return time.UnixMilli(n).UTC(), true
+	}
+	return time.Unix(n, 0).UTC(), true
+}
@@ -0,0 +1,162 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// mockHTTPClient serves canned responses keyed by URL
+type mockHTTPClient struct {
+	mu        sync.Mutex
+	responses map[string]string
+	requested []string
+}
+
+func (m *mockHTTPClient) Get(ctx context.Context, url string) (interfaces.Response, error) {
+	m.mu.Lock()
+	m.requested = append(m.requested, url)
+	m.mu.Unlock()
+
+	body, ok := m.responses[url]
+	if !ok {
+		return &mockResponse{statusCode: 404}, nil
+	}
+	return &mockResponse{statusCode: 200, body: body}, nil
+}
+
+func (m *mockHTTPClient) Post(ctx context.Context, url string, body io.Reader) (interfaces.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+// mockResponse is a mock implementation of the Response interface
+type mockResponse struct {
+	statusCode int
+	body       string
+}
+
+func (m *mockResponse) StatusCode() int {
+	return m.statusCode
+}
+
+func (m *mockResponse) Body() io.ReadCloser {
+	return io.NopCloser(strings.NewReader(m.body))
+}
+
+func (m *mockResponse) Header(key string) string {
+	return ""
+}
+
+// mockEnrichmentService returns canned metadata keyed by URL
+type mockEnrichmentService struct {
+	metadata map[string]*domain.PageMetadata
+}
+
+func (m *mockEnrichmentService) ExtractMetadata(ctx context.Context, url string) (*domain.PageMetadata, error) {
+	return m.metadata[url], nil
+}
+
+func (m *mockEnrichmentService) ExtractMetadataBatch(ctx context.Context, urls []string) map[string]*domain.PageMetadata {
+	results := make(map[string]*domain.PageMetadata)
+	for _, u := range urls {
+		if meta, ok := m.metadata[u]; ok {
+			results[u] = meta
+		}
+	}
+	return results
+}
+
+func (m *mockEnrichmentService) ExtractColor(ctx context.Context, imageURL string) (*domain.RGBColor, error) {
+	return nil, nil
+}
+
+func (m *mockEnrichmentService) ExtractColorBatch(ctx context.Context, imageURLs []string) map[string]*domain.RGBColor {
+	return nil
+}
+
+func (m *mockEnrichmentService) GetCachedColor(ctx context.Context, imageURL string) (*domain.RGBColor, error) {
+	return nil, errors.New("not cached")
+}
+
+func (m *mockEnrichmentService) ExtractPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	return nil, nil
+}
+
+func (m *mockEnrichmentService) ExtractPaletteBatch(ctx context.Context, imageURLs []string) map[string]*domain.ColorPalette {
+	return nil
+}
+
+func (m *mockEnrichmentService) GetCachedPalette(ctx context.Context, imageURL string) (*domain.ColorPalette, error) {
+	return nil, errors.New("not cached")
+}
+
+func (m *mockEnrichmentService) GetCachedPlaceholder(ctx context.Context, imageURL string) (*domain.ImagePlaceholder, error) {
+	return nil, errors.New("not cached")
+}
+
+func (m *mockEnrichmentService) SelectThumbnails(ctx context.Context, feeds []*domain.Feed) {}
+
+// mockScraperStorage keeps scraper definitions in a map
+type mockScraperStorage struct {
+	defs map[string]*domain.ScraperDefinition
+}
+
+func (m *mockScraperStorage) Save(ctx context.Context, def *domain.ScraperDefinition) error {
+	if m.defs == nil {
+		m.defs = make(map[string]*domain.ScraperDefinition)
+	}
+	m.defs[def.ID] = def
+	return nil
+}
+
+func (m *mockScraperStorage) Get(ctx context.Context, id string) (*domain.ScraperDefinition, error) {
+	return m.defs[id], nil
+}
+
+func (m *mockScraperStorage) List(ctx context.Context) ([]*domain.ScraperDefinition, error) {
+	defs := make([]*domain.ScraperDefinition, 0, len(m.defs))
+	for _, def := range m.defs {
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func (m *mockScraperStorage) Delete(ctx context.Context, id string) error {
+	delete(m.defs, id)
+	return nil
+}
+
+// mockJSONMappingStorage keeps JSON mappings in a map
+type mockJSONMappingStorage struct {
+	mappings map[string]*domain.JSONMapping
+}
+
+func (m *mockJSONMappingStorage) Save(ctx context.Context, mapping *domain.JSONMapping) error {
+	if m.mappings == nil {
+		m.mappings = make(map[string]*domain.JSONMapping)
+	}
+	m.mappings[mapping.ID] = mapping
+	return nil
+}
+
+func (m *mockJSONMappingStorage) Get(ctx context.Context, id string) (*domain.JSONMapping, error) {
+	return m.mappings[id], nil
+}
+
+func (m *mockJSONMappingStorage) List(ctx context.Context) ([]*domain.JSONMapping, error) {
+	mappings := make([]*domain.JSONMapping, 0, len(m.mappings))
+	for _, mapping := range m.mappings {
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func (m *mockJSONMappingStorage) Delete(ctx context.Context, id string) error {
+	delete(m.mappings, id)
+	return nil
+}
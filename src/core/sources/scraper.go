@@ -0,0 +1,298 @@
+// ABOUTME: Scraper source builds feeds from ordinary web pages using CSS selectors
+// ABOUTME: Manages stored scraper definitions and previews what a definition extracts
+
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"digests-app-api/core/domain"
+	coreerrors "digests-app-api/core/errors"
+	"digests-app-api/core/interfaces"
+	utiltime "digests-app-api/pkg/utils/time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// ScraperScheme is the source URL scheme handled by ScraperSource,
+	// e.g. "scraper://<definition id>"
+	ScraperScheme = "scraper"
+
+	// maxScrapedItems limits how many matched elements become feed items
+	maxScrapedItems = 50
+)
+
+// ScraperSource turns stored scraper definitions into feeds
+type ScraperSource struct {
+	deps    interfaces.Dependencies
+	storage interfaces.ScraperStorage
+}
+
+// NewScraperSource creates a new scraper source backed by the given storage
+func NewScraperSource(deps interfaces.Dependencies, storage interfaces.ScraperStorage) *ScraperSource {
+	return &ScraperSource{
+		deps:    deps,
+		storage: storage,
+	}
+}
+
+// Scheme returns the URL scheme handled by this source
+func (s *ScraperSource) Scheme() string {
+	return ScraperScheme
+}
+
+// FetchFeed scrapes the page of the definition behind a "scraper://<id>" URL
+func (s *ScraperSource) FetchFeed(ctx context.Context, sourceURL string) (*domain.Feed, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || !strings.EqualFold(u.Scheme, ScraperScheme) || u.Host == "" {
+		return nil, fmt.Errorf("invalid scraper source URL: %s", sourceURL)
+	}
+
+	def, err := s.GetScraper(ctx, u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scrape(ctx, def, sourceURL)
+}
+
+// CreateScraper validates and stores a new scraper definition
+func (s *ScraperSource) CreateScraper(ctx context.Context, def domain.ScraperDefinition) (*domain.ScraperDefinition, error) {
+	created, err := domain.NewScraperDefinition(def)
+	if err != nil {
+		return nil, &coreerrors.ValidationError{Field: "scraper", Message: err.Error()}
+	}
+
+	if err := s.storage.Save(ctx, created); err != nil {
+		return nil, fmt.Errorf("failed to save scraper: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetScraper retrieves a scraper definition by ID
+func (s *ScraperSource) GetScraper(ctx context.Context, id string) (*domain.ScraperDefinition, error) {
+	def, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scraper: %w", err)
+	}
+	if def == nil {
+		return nil, &coreerrors.NotFoundError{Resource: "scraper", ID: id}
+	}
+
+	return def, nil
+}
+
+// ListScrapers returns all stored scraper definitions
+func (s *ScraperSource) ListScrapers(ctx context.Context) ([]*domain.ScraperDefinition, error) {
+	return s.storage.List(ctx)
+}
+
+// DeleteScraper removes a scraper definition and its cached feed
+func (s *ScraperSource) DeleteScraper(ctx context.Context, id string) error {
+	def, err := s.GetScraper(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete scraper: %w", err)
+	}
+
+	if s.deps.Cache != nil {
+		_ = s.deps.Cache.Delete(ctx, fmt.Sprintf("feed:%s", def.FeedURL()))
+	}
+
+	return nil
+}
+
+// PreviewScraper scrapes the live page without storing the definition
+func (s *ScraperSource) PreviewScraper(ctx context.Context, def domain.ScraperDefinition) (*domain.Feed, error) {
+	def.URL = strings.TrimSpace(def.URL)
+	if err := def.Validate(); err != nil {
+		return nil, &coreerrors.ValidationError{Field: "scraper", Message: err.Error()}
+	}
+
+	return s.scrape(ctx, &def, def.URL)
+}
+
+// scrape fetches the definition's page and extracts a feed from it
+func (s *ScraperSource) scrape(ctx context.Context, def *domain.ScraperDefinition, feedURL string) (*domain.Feed, error) {
+	body, err := fetchDocument(ctx, s.deps.HTTPClient, def.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	return extractScrapedFeed(doc, def, feedURL)
+}
+
+// extractScrapedFeed applies the definition's selectors to a parsed page
+func extractScrapedFeed(doc *goquery.Document, def *domain.ScraperDefinition, feedURL string) (*domain.Feed, error) {
+	pageURL, err := url.Parse(def.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scraper URL: %w", err)
+	}
+
+	matches := doc.Find(def.ItemSelector)
+	if matches.Length() == 0 {
+		return nil, fmt.Errorf("item selector %q matched nothing on %s", def.ItemSelector, def.URL)
+	}
+
+	feed := &domain.Feed{
+		ID:          def.URL,
+		Title:       def.Name,
+		Description: strings.TrimSpace(doc.Find(`meta[name="description"]`).AttrOr("content", "")),
+		URL:         feedURL,
+		Link:        def.URL,
+		Items:       make([]domain.FeedItem, 0, matches.Length()),
+		FeedType:    "article",
+		LastUpdated: time.Now(),
+	}
+	if feed.Title == "" {
+		feed.Title = collapseSpace(doc.Find("title").First().Text())
+	}
+	if feed.Title == "" {
+		feed.Title = pageURL.Host
+	}
+	if lang, ok := doc.Find("html").Attr("lang"); ok {
+		feed.Language = strings.TrimSpace(lang)
+	}
+
+	var newest time.Time
+	matches.EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		item, ok := extractScrapedItem(sel, def, pageURL)
+		if ok {
+			feed.Items = append(feed.Items, item)
+			if item.Published.After(newest) {
+				newest = item.Published
+			}
+		}
+		return len(feed.Items) < maxScrapedItems
+	})
+
+	if len(feed.Items) == 0 {
+		return nil, errors.New("no items with a title or link were found")
+	}
+	if !newest.IsZero() {
+		feed.LastUpdated = newest
+	}
+
+	return feed, nil
+}
+
+// extractScrapedItem builds a feed item from a single matched container.
+// Items with neither a title nor a link are skipped.
+func extractScrapedItem(sel *goquery.Selection, def *domain.ScraperDefinition, pageURL *url.URL) (domain.FeedItem, bool) {
+	attr := def.LinkAttribute
+	if attr == "" {
+		attr = "href"
+	}
+
+	var linkSel *goquery.Selection
+	switch {
+	case def.LinkSelector != "":
+		linkSel = sel.Find(def.LinkSelector).First()
+	case sel.Is("a[href]"):
+		linkSel = sel
+	default:
+		linkSel = sel.Find("a[href]").First()
+	}
+
+	link := resolveLink(pageURL, linkSel.AttrOr(attr, ""))
+
+	title := ""
+	if def.TitleSelector != "" {
+		title = collapseSpace(sel.Find(def.TitleSelector).First().Text())
+	}
+	if title == "" {
+		title = collapseSpace(linkSel.Text())
+	}
+
+	if title == "" && link == "" {
+		return domain.FeedItem{}, false
+	}
+	if title == "" {
+		title = titleFromURL(link)
+	}
+
+	item := domain.FeedItem{
+		ID:    link,
+		Title: title,
+		Link:  link,
+	}
+	if item.ID == "" {
+		sum := sha256.Sum256([]byte(def.URL + "|" + title))
+		item.ID = hex.EncodeToString(sum[:])
+	}
+
+	if def.DateSelector != "" {
+		published := utiltime.ParseFlexibleTime(selectorDate(sel.Find(def.DateSelector).First()))
+		item.Published = published
+		if !published.IsZero() {
+			item.Created = &published
+		}
+	}
+
+	if def.SummarySelector != "" {
+		summarySel := sel.Find(def.SummarySelector).First()
+		item.Description = collapseSpace(summarySel.Text())
+		if html, err := summarySel.Html(); err == nil {
+			item.Content = strings.TrimSpace(html)
+		}
+	}
+
+	imageSel := sel.Find("img").First()
+	if def.ImageSelector != "" {
+		imageSel = sel.Find(def.ImageSelector).First()
+	}
+	if src := imageSel.AttrOr("src", imageSel.AttrOr("data-src", "")); src != "" {
+		item.Thumbnail = resolveLink(pageURL, src)
+	}
+
+	return item, true
+}
+
+// selectorDate returns the machine-readable date of an element when it has one,
+// falling back to its text
+func selectorDate(sel *goquery.Selection) string {
+	for _, attr := range []string{"datetime", "content", "title"} {
+		if value, ok := sel.Attr(attr); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return collapseSpace(sel.Text())
+}
+
+// resolveLink resolves a possibly relative link against the page URL
+func resolveLink(pageURL *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "javascript:") || strings.HasPrefix(href, "#") {
+		return ""
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	return pageURL.ResolveReference(ref).String()
+}
+
+// collapseSpace trims text and collapses runs of whitespace
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
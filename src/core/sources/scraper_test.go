@@ -0,0 +1,188 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/feed"
+	"digests-app-api/core/interfaces"
+	stdhttp "digests-app-api/infrastructure/http/standard"
+)
+
+const changelogPage = `<html lang="en"><head>
+  <title>Changelog | Example</title>
+  <meta name="description" content="Product updates">
+</head><body>
+  <ul class="releases">
+    <li class="release">
+      <h2><a href="/changelog/v2">Version 2.0</a></h2>
+      <time datetime="2024-03-01T10:00:00Z">March 1</time>
+      <div class="notes"><p>New <b>dashboard</b></p></div>
+      <img src="/img/v2.png">
+    </li>
+    <li class="release">
+      <h2><a href="https://example.com/changelog/v1">Version 1.0</a></h2>
+      <span class="date">January 5, 2024</span>
+      <div class="notes"><p>Initial release</p></div>
+    </li>
+    <li class="release"><h2></h2></li>
+  </ul>
+</body></html>`
+
+func changelogDefinition() domain.ScraperDefinition {
+	return domain.ScraperDefinition{
+		Name:            "Example Changelog",
+		URL:             "https://example.com/changelog",
+		ItemSelector:    "li.release",
+		TitleSelector:   "h2",
+		DateSelector:    "time, .date",
+		SummarySelector: ".notes",
+	}
+}
+
+func TestScraperSource_Preview(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/changelog": changelogPage,
+	}}
+	source := NewScraperSource(interfaces.Dependencies{HTTPClient: client}, &mockScraperStorage{})
+
+	feed, err := source.PreviewScraper(context.Background(), changelogDefinition())
+	if err != nil {
+		t.Fatalf("PreviewScraper returned error: %v", err)
+	}
+
+	if feed.Title != "Example Changelog" || feed.Description != "Product updates" || feed.Language != "en" {
+		t.Errorf("unexpected feed metadata: %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2 (empty container skipped)", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.Title != "Version 2.0" || first.Link != "https://example.com/changelog/v2" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	if first.Published.IsZero() || first.Published.Day() != 1 {
+		t.Errorf("expected datetime attribute to be parsed, got %v", first.Published)
+	}
+	if first.Description != "New dashboard" {
+		t.Errorf("description = %q, want summary text", first.Description)
+	}
+	if first.Thumbnail != "https://example.com/img/v2.png" {
+		t.Errorf("thumbnail = %q, want resolved image", first.Thumbnail)
+	}
+	if feed.Items[1].Published.Day() != 5 {
+		t.Errorf("expected text date to be parsed, got %v", feed.Items[1].Published)
+	}
+	if !feed.LastUpdated.Equal(first.Published) {
+		t.Errorf("LastUpdated = %v, want newest item date", feed.LastUpdated)
+	}
+}
+
+func TestScraperSource_PreviewValidation(t *testing.T) {
+	source := NewScraperSource(interfaces.Dependencies{HTTPClient: &mockHTTPClient{}}, &mockScraperStorage{})
+
+	def := changelogDefinition()
+	def.ItemSelector = ""
+	if _, err := source.PreviewScraper(context.Background(), def); err == nil {
+		t.Error("expected error for missing item selector")
+	}
+
+	def = changelogDefinition()
+	def.URL = "ftp://example.com/changelog"
+	if _, err := source.PreviewScraper(context.Background(), def); err == nil {
+		t.Error("expected error for non-HTTP URL")
+	}
+}
+
+func TestScraperSource_PreviewRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("scraper reached a loopback server")
+		w.Write([]byte(changelogPage))
+	}))
+	defer server.Close()
+
+	source := NewScraperSource(interfaces.Dependencies{HTTPClient: stdhttp.NewGuardedHTTPClient(5 * time.Second)}, &mockScraperStorage{})
+
+	def := changelogDefinition()
+	def.URL = server.URL + "/changelog"
+	if _, err := source.PreviewScraper(context.Background(), def); err == nil {
+		t.Error("expected the loopback URL to be refused")
+	}
+}
+
+func TestScraperSource_SelectorMatchesNothing(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/changelog": changelogPage,
+	}}
+	source := NewScraperSource(interfaces.Dependencies{HTTPClient: client}, &mockScraperStorage{})
+
+	def := changelogDefinition()
+	def.ItemSelector = "article.post"
+	if _, err := source.PreviewScraper(context.Background(), def); err == nil {
+		t.Error("expected error when item selector matches nothing")
+	}
+}
+
+func TestScraperSource_StoredDefinitionThroughFeedService(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/changelog": changelogPage,
+	}}
+	deps := interfaces.Dependencies{HTTPClient: client}
+	source := NewScraperSource(deps, &mockScraperStorage{})
+
+	def, err := source.CreateScraper(context.Background(), changelogDefinition())
+	if err != nil {
+		t.Fatalf("CreateScraper returned error: %v", err)
+	}
+	if def.ID == "" || def.FeedURL() != "scraper://"+def.ID {
+		t.Fatalf("unexpected definition: %+v", def)
+	}
+
+	service := feed.NewFeedService(deps)
+	service.RegisterSource(source)
+
+	feeds, err := service.ParseFeeds(context.Background(), []string{def.FeedURL()})
+	if err != nil {
+		t.Fatalf("ParseFeeds returned error: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != def.FeedURL() || len(feeds[0].Items) != 2 {
+		t.Fatalf("unexpected feeds: %+v", feeds)
+	}
+
+	if err := source.DeleteScraper(context.Background(), def.ID); err != nil {
+		t.Fatalf("DeleteScraper returned error: %v", err)
+	}
+	if _, err := source.FetchFeed(context.Background(), def.FeedURL()); err == nil {
+		t.Error("expected error for deleted scraper")
+	}
+}
+
+func TestScraperSource_DefaultLinkAndTitle(t *testing.T) {
+	page := `<html><body>
+		<div class="board">
+			<a class="thread" href="/t/1">First thread</a>
+			<a class="thread" href="/t/2">Second thread</a>
+		</div>
+	</body></html>`
+	client := &mockHTTPClient{responses: map[string]string{"https://forum.example.com/": page}}
+	source := NewScraperSource(interfaces.Dependencies{HTTPClient: client}, &mockScraperStorage{})
+
+	feed, err := source.PreviewScraper(context.Background(), domain.ScraperDefinition{
+		URL:          "https://forum.example.com/",
+		ItemSelector: "a.thread",
+	})
+	if err != nil {
+		t.Fatalf("PreviewScraper returned error: %v", err)
+	}
+	if len(feed.Items) != 2 || feed.Items[1].Title != "Second thread" || feed.Items[1].Link != "https://forum.example.com/t/2" {
+		t.Errorf("unexpected items: %+v", feed.Items)
+	}
+	if feed.Title != "forum.example.com" {
+		t.Errorf("feed title = %q, want host fallback", feed.Title)
+	}
+}
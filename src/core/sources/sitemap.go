@@ -0,0 +1,367 @@
+// ABOUTME: Sitemap source adapter builds pseudo-feeds for sites that publish no RSS
+// ABOUTME: Reads sitemap indexes and Google News sitemaps and enriches entries via metadata
+
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+	utiltime "digests-app-api/pkg/utils/time"
+)
+
+const (
+	// SitemapScheme is the source URL prefix handled by SitemapSource,
+	// e.g. "sitemap+https://example.com/sitemap.xml"
+	SitemapScheme = "sitemap"
+
+	// defaultSitemapItems is how many of the newest entries become feed items
+	defaultSitemapItems = 25
+
+	// maxChildSitemaps limits how many sitemaps of an index are read
+	maxChildSitemaps = 5
+
+	// maxSitemapDepth limits how deep nested sitemap indexes are followed
+	maxSitemapDepth = 2
+)
+
+// sitemapDocument covers both <urlset> and <sitemapindex> documents
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapPage `xml:"url"`
+	Sitemaps []sitemapRef  `xml:"sitemap"`
+}
+
+// sitemapRef is a child sitemap listed in a sitemap index
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemapPage is a single page entry, optionally with news and image extensions
+type sitemapPage struct {
+	Loc     string         `xml:"loc"`
+	LastMod string         `xml:"lastmod"`
+	News    *sitemapNews   `xml:"news"`
+	Images  []sitemapImage `xml:"image"`
+}
+
+// sitemapNews holds the Google News sitemap extension
+type sitemapNews struct {
+	Title           string `xml:"title"`
+	PublicationDate string `xml:"publication_date"`
+	Keywords        string `xml:"keywords"`
+	Publication     struct {
+		Name     string `xml:"name"`
+		Language string `xml:"language"`
+	} `xml:"publication"`
+}
+
+// sitemapImage holds the image sitemap extension
+type sitemapImage struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapEntry is a page entry with its resolved date
+type sitemapEntry struct {
+	url  sitemapPage
+	date time.Time
+}
+
+// SitemapSource turns sitemaps into feeds
+type SitemapSource struct {
+	deps       interfaces.Dependencies
+	enrichment interfaces.ContentEnrichmentService
+	maxItems   int
+}
+
+// NewSitemapSource creates a new sitemap source.
+// The enrichment service fills in titles, descriptions and thumbnails and may be nil.
+func NewSitemapSource(deps interfaces.Dependencies, enrichment interfaces.ContentEnrichmentService) *SitemapSource {
+	return &SitemapSource{
+		deps:       deps,
+		enrichment: enrichment,
+		maxItems:   defaultSitemapItems,
+	}
+}
+
+// Scheme returns the URL scheme prefix handled by this source
+func (s *SitemapSource) Scheme() string {
+	return SitemapScheme
+}
+
+// FetchFeed reads the sitemap behind a "sitemap+https://..." URL and builds a
+// feed from its newest entries
+func (s *SitemapSource) FetchFeed(ctx context.Context, sourceURL string) (*domain.Feed, error) {
+	sitemapURL, err := stripSchemePrefix(sourceURL, SitemapScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.collectEntries(ctx, sitemapURL, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("sitemap contains no entries: %s", sitemapURL)
+	}
+
+	// Newest first; undated entries sink to the bottom
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].date.After(entries[j].date)
+	})
+	if len(entries) > s.maxItems {
+		entries = entries[:s.maxItems]
+	}
+
+	feed := s.buildFeed(sourceURL, sitemapURL, entries)
+	s.enrichItems(ctx, feed)
+
+	return feed, nil
+}
+
+// collectEntries reads a sitemap, following sitemap indexes up to maxSitemapDepth
+func (s *SitemapSource) collectEntries(ctx context.Context, sitemapURL string, depth int) ([]sitemapEntry, error) {
+	doc, err := s.fetchSitemap(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(doc.Sitemaps) == 0 {
+		entries := make([]sitemapEntry, 0, len(doc.URLs))
+		for _, u := range doc.URLs {
+			if strings.TrimSpace(u.Loc) == "" {
+				continue
+			}
+			u.Loc = strings.TrimSpace(u.Loc)
+			entries = append(entries, sitemapEntry{url: u, date: entryDate(u)})
+		}
+		return entries, nil
+	}
+
+	if depth >= maxSitemapDepth {
+		return nil, nil
+	}
+
+	// Read the most recently modified child sitemaps
+	children := doc.Sitemaps
+	sort.SliceStable(children, func(i, j int) bool {
+		return utiltime.ParseFlexibleTime(children[i].LastMod).After(utiltime.ParseFlexibleTime(children[j].LastMod))
+	})
+	if len(children) > maxChildSitemaps {
+		children = children[:maxChildSitemaps]
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		entries []sitemapEntry
+	)
+	for _, child := range children {
+		loc := strings.TrimSpace(child.Loc)
+		if loc == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(childURL string) {
+			defer wg.Done()
+
+			childEntries, err := s.collectEntries(ctx, childURL, depth+1)
+			if err != nil {
+				if s.deps.Logger != nil {
+					s.deps.Logger.Debug("Failed to read child sitemap", map[string]interface{}{
+						"url":   childURL,
+						"error": err.Error(),
+					})
+				}
+				return
+			}
+
+			mu.Lock()
+			entries = append(entries, childEntries...)
+			mu.Unlock()
+		}(loc)
+	}
+	wg.Wait()
+
+	return entries, nil
+}
+
+// fetchSitemap downloads and decodes a sitemap, transparently handling gzip
+func (s *SitemapSource) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapDocument, error) {
+	body, err := fetchDocument(ctx, s.deps.HTTPClient, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer gz.Close()
+		reader = io.LimitReader(gz, maxDocumentSize)
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(reader).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+
+	switch doc.XMLName.Local {
+	case "urlset", "sitemapindex":
+		return &doc, nil
+	default:
+		return nil, fmt.Errorf("not a sitemap: unexpected root element <%s>", doc.XMLName.Local)
+	}
+}
+
+// buildFeed converts sitemap entries into a feed
+func (s *SitemapSource) buildFeed(sourceURL, sitemapURL string, entries []sitemapEntry) *domain.Feed {
+	root := siteRoot(sitemapURL)
+	host := strings.TrimPrefix(strings.TrimPrefix(root, "https://"), "http://")
+
+	feed := &domain.Feed{
+		ID:          root,
+		Title:       host,
+		Description: fmt.Sprintf("Latest pages from the %s sitemap", host),
+		URL:         sourceURL,
+		Link:        root,
+		Items:       make([]domain.FeedItem, 0, len(entries)),
+		FeedType:    "article",
+		LastUpdated: time.Now(),
+	}
+
+	if !entries[0].date.IsZero() {
+		feed.LastUpdated = entries[0].date
+	}
+
+	for _, entry := range entries {
+		u := entry.url
+		item := domain.FeedItem{
+			ID:        u.Loc,
+			Title:     titleFromURL(u.Loc),
+			Link:      u.Loc,
+			Published: entry.date,
+		}
+		if !entry.date.IsZero() {
+			created := entry.date
+			item.Created = &created
+		}
+
+		if u.News != nil {
+			if title := strings.TrimSpace(u.News.Title); title != "" {
+				item.Title = title
+			}
+			if name := strings.TrimSpace(u.News.Publication.Name); name != "" {
+				feed.Title = name
+			}
+			if lang := strings.TrimSpace(u.News.Publication.Language); lang != "" && feed.Language == "" {
+				feed.Language = lang
+			}
+			for _, keyword := range strings.Split(u.News.Keywords, ",") {
+				if keyword = strings.TrimSpace(keyword); keyword != "" {
+					item.Categories = append(item.Categories, keyword)
+				}
+			}
+		}
+
+		if len(u.Images) > 0 && strings.TrimSpace(u.Images[0].Loc) != "" {
+			item.Thumbnail = strings.TrimSpace(u.Images[0].Loc)
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed
+}
+
+// enrichItems fills in item details from each page's metadata
+func (s *SitemapSource) enrichItems(ctx context.Context, feed *domain.Feed) {
+	if s.enrichment == nil || len(feed.Items) == 0 {
+		return
+	}
+
+	links := make([]string, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		links = append(links, item.Link)
+	}
+
+	metadata := s.enrichment.ExtractMetadataBatch(ctx, links)
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		meta, ok := metadata[item.Link]
+		if !ok || meta == nil {
+			continue
+		}
+
+		// News sitemap titles are authoritative; otherwise prefer the page title
+		if meta.Title != "" && (item.Title == titleFromURL(item.Link) || item.Title == "") {
+			item.Title = meta.Title
+		}
+		if item.Description == "" {
+			item.Description = meta.Description
+			item.Content = meta.Description
+		}
+		if item.Thumbnail == "" {
+			item.Thumbnail = meta.Thumbnail
+		}
+		if item.Author == "" {
+			item.Author = meta.Author
+		}
+		if item.Published.IsZero() && meta.Published != "" {
+			if published := utiltime.ParseFlexibleTime(meta.Published); !published.IsZero() {
+				item.Published = published
+				item.Created = &published
+			}
+		}
+		if feed.Favicon == "" && meta.Favicon != "" {
+			feed.Favicon = meta.Favicon
+		}
+	}
+}
+
+// entryDate picks the most specific date available for an entry
+func entryDate(u sitemapPage) time.Time {
+	if u.News != nil {
+		if t := utiltime.ParseFlexibleTime(u.News.PublicationDate); !t.IsZero() {
+			return t
+		}
+	}
+	return utiltime.ParseFlexibleTime(u.LastMod)
+}
+
+// titleFromURL derives a readable title from the last path segment of a URL
+func titleFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	segment := path.Base(strings.TrimSuffix(u.Path, "/"))
+	if segment == "." || segment == "/" || segment == "" {
+		return u.Host
+	}
+
+	segment = strings.TrimSuffix(segment, path.Ext(segment))
+	segment = strings.NewReplacer("-", " ", "_", " ").Replace(segment)
+	segment = strings.TrimSpace(segment)
+	if segment == "" {
+		return u.Host
+	}
+
+	return strings.ToUpper(segment[:1]) + segment[1:]
+}
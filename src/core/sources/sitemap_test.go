@@ -0,0 +1,187 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/feed"
+	"digests-app-api/core/interfaces"
+)
+
+const newsSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
+        xmlns:news="http://www.google.com/schemas/sitemap-news/0.9"
+        xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">
+  <url>
+    <loc>https://news.example.com/2024/older-story</loc>
+    <news:news>
+      <news:publication>
+        <news:name>Example News</news:name>
+        <news:language>en</news:language>
+      </news:publication>
+      <news:publication_date>2024-01-01T08:00:00Z</news:publication_date>
+      <news:title>An Older Story</news:title>
+      <news:keywords>politics, economy</news:keywords>
+    </news:news>
+  </url>
+  <url>
+    <loc>https://news.example.com/2024/newest-story</loc>
+    <news:news>
+      <news:publication>
+        <news:name>Example News</news:name>
+        <news:language>en</news:language>
+      </news:publication>
+      <news:publication_date>2024-01-03T08:00+00:00</news:publication_date>
+      <news:title>The Newest Story</news:title>
+    </news:news>
+    <image:image>
+      <image:loc>https://news.example.com/img/newest.jpg</image:loc>
+    </image:image>
+  </url>
+</urlset>`
+
+func TestSitemapSource_NewsSitemap(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://news.example.com/news-sitemap.xml": newsSitemap,
+	}}
+	source := NewSitemapSource(interfaces.Dependencies{HTTPClient: client}, nil)
+
+	feed, err := source.FetchFeed(context.Background(), "sitemap+https://news.example.com/news-sitemap.xml")
+	if err != nil {
+		t.Fatalf("FetchFeed returned error: %v", err)
+	}
+
+	if feed.Title != "Example News" {
+		t.Errorf("feed title = %q, want Example News", feed.Title)
+	}
+	if feed.Language != "en" {
+		t.Errorf("feed language = %q, want en", feed.Language)
+	}
+	if feed.URL != "sitemap+https://news.example.com/news-sitemap.xml" {
+		t.Errorf("feed URL = %q, want the source URL", feed.URL)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+
+	newest := feed.Items[0]
+	if newest.Title != "The Newest Story" {
+		t.Errorf("first item title = %q, want newest story first", newest.Title)
+	}
+	if newest.Thumbnail != "https://news.example.com/img/newest.jpg" {
+		t.Errorf("thumbnail = %q, want image sitemap loc", newest.Thumbnail)
+	}
+	if newest.Published.IsZero() {
+		t.Error("expected publication date to be parsed")
+	}
+	if newest.Created == nil || !newest.Created.Equal(newest.Published) {
+		t.Errorf("Created = %v, want the publication date", newest.Created)
+	}
+	if feed.Items[0].Created == &feed.Items[0].Published {
+		t.Error("Created must not alias the item's Published field")
+	}
+	if len(feed.Items[1].Categories) != 2 {
+		t.Errorf("expected keywords as categories, got %v", feed.Items[1].Categories)
+	}
+}
+
+func TestSitemapSource_IndexAndEnrichment(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/blog/hello-world</loc><lastmod>2024-02-01</lastmod></url>
+  <url><loc>https://example.com/blog/second_post</loc><lastmod>2024-03-01</lastmod></url>
+</urlset>`))
+	_ = w.Close()
+
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/sitemap.xml": `<?xml version="1.0"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/posts.xml.gz</loc><lastmod>2024-03-01</lastmod></sitemap>
+  <sitemap><loc>https://example.com/missing.xml</loc><lastmod>2023-01-01</lastmod></sitemap>
+</sitemapindex>`,
+		"https://example.com/posts.xml.gz": gz.String(),
+	}}
+	enrichment := &mockEnrichmentService{metadata: map[string]*domain.PageMetadata{
+		"https://example.com/blog/hello-world": {
+			Title:       "Hello, World!",
+			Description: "The first post",
+			Thumbnail:   "https://example.com/hello.png",
+			Favicon:     "https://example.com/favicon.ico",
+		},
+	}}
+	source := NewSitemapSource(interfaces.Dependencies{HTTPClient: client}, enrichment)
+
+	feed, err := source.FetchFeed(context.Background(), "sitemap+https://example.com/sitemap.xml")
+	if err != nil {
+		t.Fatalf("FetchFeed returned error: %v", err)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+
+	if feed.Items[0].Title != "Second post" {
+		t.Errorf("first item title = %q, want title derived from URL", feed.Items[0].Title)
+	}
+	if feed.Items[1].Title != "Hello, World!" || feed.Items[1].Description != "The first post" {
+		t.Errorf("second item not enriched: %+v", feed.Items[1])
+	}
+	if feed.Favicon != "https://example.com/favicon.ico" {
+		t.Errorf("favicon = %q, want metadata favicon", feed.Favicon)
+	}
+}
+
+func TestSitemapSource_InvalidSourceURL(t *testing.T) {
+	source := NewSitemapSource(interfaces.Dependencies{HTTPClient: &mockHTTPClient{}}, nil)
+
+	if _, err := source.FetchFeed(context.Background(), "sitemap+ftp://example.com/sitemap.xml"); err == nil {
+		t.Error("expected error for non-HTTP sitemap URL")
+	}
+}
+
+func TestSitemapSource_NotASitemap(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://example.com/feed.xml": `<rss version="2.0"><channel><title>Feed</title></channel></rss>`,
+	}}
+	source := NewSitemapSource(interfaces.Dependencies{HTTPClient: client}, nil)
+
+	if _, err := source.FetchFeed(context.Background(), "sitemap+https://example.com/feed.xml"); err == nil {
+		t.Error("expected error for non-sitemap document")
+	}
+}
+
+func TestFeedService_RoutesSitemapSource(t *testing.T) {
+	client := &mockHTTPClient{responses: map[string]string{
+		"https://news.example.com/news-sitemap.xml": newsSitemap,
+	}}
+	deps := interfaces.Dependencies{HTTPClient: client}
+
+	service := feed.NewFeedService(deps)
+	service.RegisterSource(NewSitemapSource(deps, nil))
+
+	feeds, err := service.ParseFeeds(context.Background(), []string{"sitemap+https://news.example.com/news-sitemap.xml"})
+	if err != nil {
+		t.Fatalf("ParseFeeds returned error: %v", err)
+	}
+	if len(feeds) != 1 || len(feeds[0].Items) != 2 {
+		t.Fatalf("expected one feed with two items, got %+v", feeds)
+	}
+}
+
+func TestTitleFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/blog/my-first-post/": "My first post",
+		"https://example.com/news/story_two.html": "Story two",
+		"https://example.com/":                    "example.com",
+	}
+
+	for input, want := range tests {
+		if got := titleFromURL(input); got != want {
+			t.Errorf("titleFromURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
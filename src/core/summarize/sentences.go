@@ -0,0 +1,114 @@
+// ABOUTME: Splits plain text and HTML into sentences for summarization
+// ABOUTME: Avoids breaking on abbreviations, initials and decimal numbers
+
+package summarize
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// abbreviations end with a period without ending the sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "vs": true, "etc": true, "inc": true, "ltd": true, "co": true, "corp": true,
+	"fig": true, "approx": true, "e.g": true, "i.e": true, "u.s": true,
+	"u.k": true, "jan": true, "feb": true, "mar": true, "apr": true, "jun": true, "jul": true,
+	"aug": true, "sep": true, "sept": true, "oct": true, "nov": true, "dec": true,
+}
+
+// Sentences splits text into sentences. Line breaks always end a sentence,
+// so headings and list items are not run into the following paragraph.
+func Sentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		sentences = append(sentences, splitLine(strings.Join(strings.Fields(line), " "))...)
+	}
+	return sentences
+}
+
+// splitLine splits a single line of whitespace-normalized text into sentences
+func splitLine(line string) []string {
+	var sentences []string
+	runes := []rune(line)
+
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		fullWidth := r == '。' || r == '！' || r == '？'
+		if r != '.' && r != '!' && r != '?' && r != '…' && !fullWidth {
+			continue
+		}
+
+		// Take in repeated marks and closing quotes or brackets
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(".!?…。！？\"'”’)]»", runes[end]) {
+			end++
+		}
+
+		if !fullWidth {
+			if end < len(runes) && runes[end] != ' ' {
+				i = end - 1
+				continue
+			}
+			if r == '.' && !endsSentence(runes[start:i], runes[end:]) {
+				i = end - 1
+				continue
+			}
+		}
+
+		if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+		i = end - 1
+	}
+
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// endsSentence decides whether a period after before ends the sentence,
+// looking at the last word before it and the text after it
+func endsSentence(before, after []rune) bool {
+	word := string(before)
+	if i := strings.LastIndexAny(word, " (\"“"); i != -1 {
+		word = word[i+1:]
+	}
+	word = strings.ToLower(word)
+
+	// Initials such as "J." and abbreviations such as "Dr." or "e.g."
+	if len([]rune(word)) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return false
+	}
+	if abbreviations[word] {
+		return false
+	}
+
+	// A sentence that goes on continues with a lower-case word
+	rest := []rune(strings.TrimSpace(string(after)))
+	return len(rest) == 0 || !unicode.IsLower(rest[0])
+}
+
+// PlainText converts HTML to text with a line per block element, so block
+// boundaries end sentences
+func PlainText(content string) string {
+	if !strings.Contains(content, "<") {
+		return content
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	doc.Find("script, style, noscript, template, figure, figcaption").Remove()
+	doc.Find("p, div, br, li, h1, h2, h3, h4, h5, h6, blockquote, pre, td, tr").Each(func(_ int, sel *goquery.Selection) {
+		sel.AppendHtml("\n")
+	})
+
+	return doc.Text()
+}
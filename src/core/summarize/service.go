@@ -0,0 +1,99 @@
+// ABOUTME: Service layer for extractive summaries of articles and feed items
+// ABOUTME: Caches summaries by content hash so unchanged text is only ranked once
+
+package summarize
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+// summaryCacheTTL is how long a summary is cached; it is keyed by content,
+// so it never goes stale
+const summaryCacheTTL = 7 * 24 * time.Hour
+
+// Service summarizes text without leaving the process, caching results in
+// deps.Cache
+type Service struct {
+	deps      interfaces.Dependencies
+	sentences int
+}
+
+// NewService creates a summarization service
+func NewService(deps interfaces.Dependencies) *Service {
+	return &Service{
+		deps:      deps,
+		sentences: DefaultSentences,
+	}
+}
+
+// SetDefaultSentences sets the summary length used when a request asks for none
+func (s *Service) SetDefaultSentences(sentences int) {
+	if sentences < 1 || sentences > MaxSentences {
+		sentences = DefaultSentences
+	}
+	s.sentences = sentences
+}
+
+// Summarize returns the key sentences of a text; 0 sentences uses the
+// default length. Text too short to condense has no summary.
+func (s *Service) Summarize(ctx context.Context, text string, sentences int) []string {
+	if sentences <= 0 {
+		sentences = s.sentences
+	}
+	if sentences > MaxSentences {
+		sentences = MaxSentences
+	}
+
+	hash := sha256.Sum256([]byte(text))
+	cacheKey := fmt.Sprintf("summary:%d:%s", sentences, hex.EncodeToString(hash[:]))
+	if s.deps.Cache != nil {
+		if data, err := s.deps.Cache.Get(ctx, cacheKey); err == nil && data != nil {
+			var cached []string
+			if err := json.Unmarshal(data, &cached); err == nil {
+				return cached
+			}
+		}
+	}
+
+	summary := Summarize(text, sentences)
+
+	// Short texts are cached too, so they are not split again
+	if s.deps.Cache != nil {
+		if data, err := json.Marshal(summary); err == nil {
+			if err := s.deps.Cache.Set(ctx, cacheKey, data, summaryCacheTTL); err != nil {
+				s.deps.Logger.Debug("Failed to cache summary", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+
+	return summary
+}
+
+// SummarizeItems sets the key sentences of feed items from their content,
+// falling back to their description. The HTML content is preferred over the
+// stripped text, as its paragraphs mark where sentences end.
+func (s *Service) SummarizeItems(ctx context.Context, items []*domain.FeedItem, sentences int) {
+	for _, item := range items {
+		text := item.ContentEncoded
+		if text == "" {
+			text = item.Content
+		}
+		if text == "" {
+			text = item.Description
+		}
+		if text == "" {
+			continue
+		}
+		item.KeySentences = s.Summarize(ctx, PlainText(text), sentences)
+	}
+}
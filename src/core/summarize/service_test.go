@@ -0,0 +1,70 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/core/interfaces"
+)
+
+func TestService_Summarize_CachesResults(t *testing.T) {
+	cache := newMockCache()
+	service := NewService(interfaces.Dependencies{Cache: cache, Logger: &mockLogger{}})
+
+	first := service.Summarize(context.Background(), solarArticle, 2)
+	if len(first) != 2 {
+		t.Fatalf("Expected 2 sentences, got %d", len(first))
+	}
+	if cache.sets != 1 {
+		t.Fatalf("Expected the summary to be cached, got %d cache writes", cache.sets)
+	}
+
+	second := service.Summarize(context.Background(), solarArticle, 2)
+	if strings.Join(second, "|") != strings.Join(first, "|") {
+		t.Errorf("Expected cached summary %q, got %q", first, second)
+	}
+	if cache.sets != 1 {
+		t.Errorf("Expected the cached summary to be reused, got %d cache writes", cache.sets)
+	}
+
+	// A different length is a different summary
+	service.Summarize(context.Background(), solarArticle, 3)
+	if cache.sets != 2 {
+		t.Errorf("Expected a separate cache entry per length, got %d cache writes", cache.sets)
+	}
+}
+
+func TestService_SetDefaultSentences(t *testing.T) {
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+	service.SetDefaultSentences(1)
+
+	if summary := service.Summarize(context.Background(), solarArticle, 0); len(summary) != 1 {
+		t.Errorf("Expected the default of 1 sentence, got %d", len(summary))
+	}
+}
+
+func TestService_SummarizeItems(t *testing.T) {
+	service := NewService(interfaces.Dependencies{Logger: &mockLogger{}})
+
+	paragraphs := strings.Split(solarArticle, "\n")
+	items := []*domain.FeedItem{
+		{Title: "Full", ContentEncoded: "<p>" + strings.Join(paragraphs, "</p><p>") + "</p>"},
+		{Title: "Description only", Description: solarArticle},
+		{Title: "Teaser", Description: "Solar panels are coming to schools."},
+		{Title: "Empty"},
+	}
+	service.SummarizeItems(context.Background(), items, 2)
+
+	for _, item := range items[:2] {
+		if len(item.KeySentences) != 2 {
+			t.Errorf("Expected 2 key sentences for %q, got %q", item.Title, item.KeySentences)
+		}
+	}
+	for _, item := range items[2:] {
+		if item.KeySentences != nil {
+			t.Errorf("Expected no key sentences for %q, got %q", item.Title, item.KeySentences)
+		}
+	}
+}
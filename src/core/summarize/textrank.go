@@ -0,0 +1,263 @@
+// ABOUTME: Extractive summarization by ranking sentences with TextRank
+// ABOUTME: Sentences are scored by their TF-IDF similarity to the rest of the text
+
+package summarize
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultSentences is how many sentences a summary has when no count is given
+	DefaultSentences = 3
+
+	// MaxSentences is the longest summary that can be requested
+	MaxSentences = 10
+
+	// minSentenceWords and maxSentenceWords bound the sentences that can be
+	// picked; fragments and run-on lists make poor summaries
+	minSentenceWords = 5
+	maxSentenceWords = 80
+
+	// maxCandidates caps how many sentences are ranked, as ranking is quadratic
+	maxCandidates = 400
+
+	// damping, maxIterations and tolerance drive the PageRank iteration
+	damping       = 0.85
+	maxIterations = 100
+	tolerance     = 1e-6
+)
+
+// sentence is a candidate sentence with its weighted terms
+type sentence struct {
+	text  string
+	terms map[string]float64
+}
+
+// Summarize picks the count sentences that best represent the text, in
+// the order they appear. It returns nil when the text has no more
+// candidate sentences than requested, as there is nothing to condense.
+func Summarize(text string, count int) []string {
+	if count <= 0 {
+		count = DefaultSentences
+	}
+	if count > MaxSentences {
+		count = MaxSentences
+	}
+
+	candidates := candidateSentences(text)
+	if len(candidates) <= count {
+		return nil
+	}
+
+	scores := rank(candidates)
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	// Earlier sentences win ties, as articles tend to lead with their point
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	picked := order[:count]
+	sort.Ints(picked)
+
+	summary := make([]string, len(picked))
+	for i, index := range picked {
+		summary[i] = candidates[index].text
+	}
+	return summary
+}
+
+// candidateSentences splits text into sentences and keeps those that are
+// long enough to stand on their own, with TF-IDF weighted terms
+func candidateSentences(text string) []sentence {
+	var candidates []sentence
+	documentFrequency := make(map[string]int)
+
+	for _, text := range Sentences(text) {
+		words := strings.Fields(text)
+		if (len(words) < minSentenceWords && !hasCJK(text)) || len(words) > maxSentenceWords {
+			continue
+		}
+
+		counts := make(map[string]float64)
+		for _, term := range terms(text) {
+			counts[term]++
+		}
+		if len(counts) == 0 {
+			continue
+		}
+		for term := range counts {
+			documentFrequency[term]++
+		}
+
+		candidates = append(candidates, sentence{text: text, terms: counts})
+		if len(candidates) == maxCandidates {
+			break
+		}
+	}
+
+	total := float64(len(candidates))
+	for _, candidate := range candidates {
+		for term, count := range candidate.terms {
+			idf := math.Log(1 + total/float64(documentFrequency[term]))
+			candidate.terms[term] = count * idf
+		}
+	}
+
+	return candidates
+}
+
+// rank scores sentences with PageRank over a graph weighted by the cosine
+// similarity of their terms. Sentences with nothing in common with the
+// others score lowest.
+func rank(sentences []sentence) []float64 {
+	n := len(sentences)
+
+	norms := make([]float64, n)
+	for i, s := range sentences {
+		for _, weight := range s.terms {
+			norms[i] += weight * weight
+		}
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	similarity := make([][]float64, n)
+	outWeight := make([]float64, n) // total similarity of each sentence
+	for i := range similarity {
+		similarity[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sim := cosine(sentences[i].terms, sentences[j].terms, norms[i], norms[j])
+			similarity[i][j], similarity[j][i] = sim, sim
+			outWeight[i] += sim
+			outWeight[j] += sim
+		}
+	}
+
+	// Scores flow along similarities scaled by the largest total similarity,
+	// rather than by each sentence's own total as in plain PageRank. Weakly
+	// connected sentences then pass on little score, so a pair of off-topic
+	// sentences only similar to each other cannot rank with the main topic.
+	maxWeight := 0.0
+	for _, weight := range outWeight {
+		maxWeight = math.Max(maxWeight, weight)
+	}
+	if maxWeight == 0 {
+		return make([]float64, n)
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				sum += similarity[j][i] * scores[j]
+			}
+			next[i] = (1-damping)/float64(n) + damping*sum/maxWeight
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores, next = next, scores
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return scores
+}
+
+// cosine returns the cosine similarity of two weighted term vectors
+func cosine(a, b map[string]float64, normA, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	dot := 0.0
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot / (normA * normB)
+}
+
+// terms returns the lower-cased words of a sentence without stop words.
+// Han, Hiragana and Katakana characters are terms of their own, since
+// those scripts do not separate words with spaces.
+func terms(text string) []string {
+	var result []string
+
+	add := func(word string) {
+		if utf8.RuneCountInString(word) > 1 && !stopWords[word] {
+			result = append(result, word)
+		}
+	}
+
+	var word strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			add(word.String())
+			word.Reset()
+			result = append(result, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'':
+			word.WriteRune(r)
+		default:
+			add(strings.Trim(word.String(), "'"))
+			word.Reset()
+		}
+	}
+	add(strings.Trim(word.String(), "'"))
+
+	return result
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func hasCJK(text string) bool {
+	for _, r := range text {
+		if isCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopWords are common English words that say nothing about a sentence's topic
+var stopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "all": true, "also": true, "am": true, "an": true,
+	"and": true, "any": true, "are": true, "as": true, "at": true, "be": true, "because": true,
+	"been": true, "before": true, "being": true, "between": true, "both": true, "but": true,
+	"by": true, "can": true, "could": true, "did": true, "do": true, "does": true, "doing": true,
+	"down": true, "during": true, "each": true, "few": true, "for": true, "from": true,
+	"further": true, "had": true, "has": true, "have": true, "having": true, "he": true,
+	"her": true, "here": true, "hers": true, "him": true, "his": true, "how": true, "if": true,
+	"in": true, "into": true, "is": true, "it": true, "it's": true, "its": true, "just": true,
+	"me": true, "more": true, "most": true, "my": true, "no": true, "nor": true, "not": true,
+	"now": true, "of": true, "off": true, "on": true, "once": true, "only": true, "or": true,
+	"other": true, "our": true, "out": true, "over": true, "own": true, "said": true,
+	"same": true, "she": true, "should": true, "so": true, "some": true, "such": true,
+	"than": true, "that": true, "the": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "those": true, "through": true,
+	"to": true, "too": true, "under": true, "until": true, "up": true, "very": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "who": true, "whom": true, "why": true, "will": true, "with": true,
+	"would": true, "you": true, "your": true,
+}
@@ -0,0 +1,106 @@
+package summarize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const solarArticle = `The city council approved a plan to install solar panels on every public school roof.
+Solar panels on school roofs will cut the city's electricity bills by a third, the council said.
+Mayor Dr. Jane Smith opened the meeting at 7.30 p.m. with a minute of silence.
+The council expects the solar panels to pay for themselves within eight years of electricity savings.
+Parking near the town hall was limited because of road works on Main Street.
+Teachers plan lessons about how the solar panels on the school roofs produce electricity.
+A local bakery sold coffee and pastries to residents waiting outside the hall.`
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "sentence endings",
+			text: "The first sentence ends here. Does the second? It does! Yes.",
+			want: []string{"The first sentence ends here.", "Does the second?", "It does!", "Yes."},
+		},
+		{
+			name: "abbreviations and initials",
+			text: "Dr. Smith met J. R. Tolkien in the U.S. last year. They talked about books, maps etc. for hours.",
+			want: []string{"Dr. Smith met J. R. Tolkien in the U.S. last year.", "They talked about books, maps etc. for hours."},
+		},
+		{
+			name: "decimals and domains",
+			text: "Prices rose 2.5 percent at example.com this year. Nobody noticed.",
+			want: []string{"Prices rose 2.5 percent at example.com this year.", "Nobody noticed."},
+		},
+		{
+			name: "quotes and line breaks",
+			text: "He said \"stop.\" Then he left\nA heading\n\nThe next paragraph.",
+			want: []string{"He said \"stop.\"", "Then he left", "A heading", "The next paragraph."},
+		},
+		{
+			name: "full-width punctuation",
+			text: "今日は晴れです。明日は雨ですか？",
+			want: []string{"今日は晴れです。", "明日は雨ですか？"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sentences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize_PicksCentralSentencesInOrder(t *testing.T) {
+	summary := Summarize(solarArticle, 3)
+	if len(summary) != 3 {
+		t.Fatalf("Expected 3 sentences, got %d: %q", len(summary), summary)
+	}
+
+	for _, sentence := range summary {
+		if !strings.Contains(strings.ToLower(sentence), "solar panels") {
+			t.Errorf("Expected sentences about the solar panels, got %q", sentence)
+		}
+	}
+
+	// Sentences keep the order of the article
+	text := strings.Join(Sentences(solarArticle), "\n")
+	last := -1
+	for _, sentence := range summary {
+		index := strings.Index(text, sentence)
+		if index <= last {
+			t.Errorf("Expected summary in article order, got %q", summary)
+		}
+		last = index
+	}
+}
+
+func TestSummarize_ShortText(t *testing.T) {
+	text := "A short note about the weather today. It will rain in the afternoon for a while."
+	if summary := Summarize(text, 3); summary != nil {
+		t.Errorf("Expected no summary for short text, got %q", summary)
+	}
+}
+
+func TestSummarize_SentenceCount(t *testing.T) {
+	if summary := Summarize(solarArticle, 0); len(summary) != DefaultSentences {
+		t.Errorf("Expected %d sentences by default, got %d", DefaultSentences, len(summary))
+	}
+	if summary := Summarize(solarArticle, 1); len(summary) != 1 {
+		t.Errorf("Expected 1 sentence, got %d", len(summary))
+	}
+}
+
+func TestPlainText_BlocksEndSentences(t *testing.T) {
+	text := PlainText("<h2>Heading without a period</h2><p>First paragraph ends here</p><p>Second one.</p><script>var x = 1;</script>")
+
+	want := []string{"Heading without a period", "First paragraph ends here", "Second one."}
+	if got := Sentences(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sentences(PlainText()) = %q, want %q", got, want)
+	}
+}
@@ -1,238 +1,405 @@
-// ABOUTME: Enrichment worker handles background processing of metadata and color extraction
-// ABOUTME: Provides managed worker pools for asynchronous content enrichment
+// ABOUTME: Enrichment worker runs queued metadata, color and reader jobs in the background
+// ABOUTME: Claims jobs from persistent storage, retries failures with backoff and keeps jobs that keep failing as dead
 
 package workers
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
-	
+
+	"digests-app-api/core/domain"
 	"digests-app-api/core/interfaces"
 )
 
-// EnrichmentJob represents a job for enrichment processing
-type EnrichmentJob struct {
-	Type      JobType
-	URLs      []string
-	Context   context.Context
-	ResultCh  chan<- interface{}
-	ErrorCh   chan<- error
-}
+// JobProcessor does the work of a job for a URL. An error fails the attempt
+// and the job is retried later.
+type JobProcessor func(ctx context.Context, url string) error
 
-// JobType represents the type of enrichment job
-type JobType int
+// WorkerConfig holds configuration for the enrichment worker
+type WorkerConfig struct {
+	// Concurrency is how many jobs of each type run at once
+	Concurrency map[string]int
 
-const (
-	JobTypeMetadata JobType = iota
-	JobTypeColor
-)
+	// MaxAttempts is how often a job is tried before it is kept as dead
+	MaxAttempts int
 
-// EnrichmentWorker manages background enrichment processing
-type EnrichmentWorker struct {
-	enrichmentService interfaces.ContentEnrichmentService
-	jobQueue         chan *EnrichmentJob
-	maxWorkers       int
-	queueSize        int
-	workers          []*worker
-	wg               sync.WaitGroup
-	ctx              context.Context
-	cancel           context.CancelFunc
-	mu               sync.Mutex
-	running          bool
-}
-
-// worker represents an individual worker goroutine
-type worker struct {
-	id               int
-	jobQueue         <-chan *EnrichmentJob
-	enrichmentService interfaces.ContentEnrichmentService
-	ctx              context.Context
-	wg               *sync.WaitGroup
-}
+	// BaseBackoff is the delay before the first retry; it doubles with each
+	// attempt up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
 
-// WorkerConfig holds configuration for the enrichment worker
-type WorkerConfig struct {
-	MaxWorkers int
-	QueueSize  int
+	// Lease is how long a job may run before it is considered abandoned,
+	// e.g. by a crashed instance, and claimed again
+	Lease time.Duration
+
+	// PollInterval is how often the queue is checked for due jobs when no
+	// job was just enqueued
+	PollInterval time.Duration
+
+	// DeadRetention is how long a dead job is kept before it is purged and
+	// its URL may be queued again
+	DeadRetention time.Duration
 }
 
+// maxPurgeInterval bounds how long purged-by-age dead jobs may linger
+const maxPurgeInterval = time.Hour
+
 // DefaultWorkerConfig returns the default worker configuration
 func DefaultWorkerConfig() WorkerConfig {
 	return WorkerConfig{
-		MaxWorkers: 10,
-		QueueSize:  100,
+		Concurrency: map[string]int{
+			domain.JobTypeColor:    5,
+			domain.JobTypeMetadata: 2,
+			domain.JobTypeReader:   2,
+		},
+		MaxAttempts:   5,
+		BaseBackoff:   30 * time.Second,
+		MaxBackoff:    30 * time.Minute,
+		Lease:         5 * time.Minute,
+		PollInterval:  5 * time.Second,
+		DeadRetention: 24 * time.Hour,
 	}
 }
 
-// NewEnrichmentWorker creates a new enrichment worker
-func NewEnrichmentWorker(enrichmentService interfaces.ContentEnrichmentService, config WorkerConfig) *EnrichmentWorker {
-	ctx, cancel := context.WithCancel(context.Background())
-	
-	if config.MaxWorkers <= 0 {
-		config.MaxWorkers = DefaultWorkerConfig().MaxWorkers
+// EnrichmentWorker runs queued enrichment jobs. Jobs are kept in storage
+// until they succeed, so with persistent storage they survive restarts.
+type EnrichmentWorker struct {
+	queue      interfaces.JobQueueStorage
+	logger     interfaces.Logger
+	config     WorkerConfig
+	processors map[string]JobProcessor
+	wake       map[string]chan struct{}
+	now        func() time.Time
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+	mu         sync.Mutex
+	running    bool
+}
+
+// NewEnrichmentWorker creates a new enrichment worker on top of job storage
+func NewEnrichmentWorker(queue interfaces.JobQueueStorage, logger interfaces.Logger, config WorkerConfig) *EnrichmentWorker {
+	defaults := DefaultWorkerConfig()
+	if config.Concurrency == nil {
+		config.Concurrency = defaults.Concurrency
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = defaults.BaseBackoff
 	}
-	if config.QueueSize <= 0 {
-		config.QueueSize = DefaultWorkerConfig().QueueSize
+	if config.MaxBackoff < config.BaseBackoff {
+		config.MaxBackoff = config.BaseBackoff
 	}
-	
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.DeadRetention <= 0 {
+		config.DeadRetention = defaults.DeadRetention
+	}
+
 	return &EnrichmentWorker{
-		enrichmentService: enrichmentService,
-		jobQueue:         make(chan *EnrichmentJob, config.QueueSize),
-		maxWorkers:       config.MaxWorkers,
-		queueSize:        config.QueueSize,
-		workers:          make([]*worker, 0, config.MaxWorkers),
-		ctx:              ctx,
-		cancel:           cancel,
-		running:          false,
+		queue:      queue,
+		logger:     logger,
+		config:     config,
+		processors: make(map[string]JobProcessor),
+		wake:       make(map[string]chan struct{}),
+		now:        time.Now,
 	}
 }
 
-// Start starts the worker pool
+// Handle registers the processor for a job type. Processors must be
+// registered before the worker is started.
+func (ew *EnrichmentWorker) Handle(jobType string, processor JobProcessor) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	ew.processors[jobType] = processor
+	ew.wake[jobType] = make(chan struct{}, 1)
+}
+
+// Start starts the configured number of workers for each job type
 func (ew *EnrichmentWorker) Start() error {
 	ew.mu.Lock()
 	defer ew.mu.Unlock()
-	
+
 	if ew.running {
 		return nil
 	}
-	
-	// Create and start workers
-	for i := 0; i < ew.maxWorkers; i++ {
-		w := &worker{
-			id:                i,
-			jobQueue:          ew.jobQueue,
-			enrichmentService: ew.enrichmentService,
-			ctx:               ew.ctx,
-			wg:                &ew.wg,
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ew.cancel = cancel
+
+	for jobType, processor := range ew.processors {
+		concurrency := ew.config.Concurrency[jobType]
+		if concurrency <= 0 {
+			concurrency = 1
+		}
+		for i := 0; i < concurrency; i++ {
+			ew.wg.Add(1)
+			go ew.run(ctx, jobType, processor)
 		}
-		ew.workers = append(ew.workers, w)
-		ew.wg.Add(1)
-		go w.run()
 	}
-	
+
+	ew.wg.Add(1)
+	go ew.purgeLoop(ctx)
+
 	ew.running = true
 	return nil
 }
 
-// Stop stops the worker pool gracefully
+// Stop stops the workers and waits for running jobs to finish. Jobs cut
+// short are put back in the queue without counting the attempt.
 func (ew *EnrichmentWorker) Stop() error {
 	ew.mu.Lock()
 	defer ew.mu.Unlock()
-	
+
 	if !ew.running {
 		return nil
 	}
-	
-	// Cancel context to signal workers to stop
+
 	ew.cancel()
-	
-	// Close job queue
-	close(ew.jobQueue)
-	
-	// Wait for all workers to finish
 	ew.wg.Wait()
-	
+
 	ew.running = false
 	return nil
 }
 
-// SubmitJob submits a job to the worker pool
-func (ew *EnrichmentWorker) SubmitJob(job *EnrichmentJob) error {
+// Enqueue queues a job for a URL unless one is already queued. A dead job
+// blocks its URL until it is older than the dead retention.
+func (ew *EnrichmentWorker) Enqueue(ctx context.Context, jobType, url string) error {
 	ew.mu.Lock()
-	if !ew.running {
-		ew.mu.Unlock()
-		return ErrWorkerNotRunning
-	}
+	wake, ok := ew.wake[jobType]
 	ew.mu.Unlock()
-	
+	if !ok {
+		return fmt.Errorf("unknown job type %q", jobType)
+	}
+
+	now := ew.now()
+	added, err := ew.queue.Enqueue(ctx, domain.NewQueuedJob(jobType, url, now))
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		existing, err := ew.queue.Get(ctx, domain.QueuedJobID(jobType, url))
+		if err != nil || existing == nil || existing.Status != domain.JobDead || now.Sub(existing.UpdatedAt) < ew.config.DeadRetention {
+			return err
+		}
+		if err := ew.queue.Delete(ctx, existing.ID); err != nil {
+			return err
+		}
+		if added, err = ew.queue.Enqueue(ctx, domain.NewQueuedJob(jobType, url, now)); err != nil || !added {
+			return err
+		}
+	}
+
+	// Let an idle worker pick the job up without waiting for the next poll
 	select {
-	case ew.jobQueue <- job:
-		return nil
-	case <-time.After(5 * time.Second):
-		return ErrQueueFull
+	case wake <- struct{}{}:
+	default:
 	}
+	return nil
 }
 
-// ExtractColorBatch submits a batch color extraction job
-func (ew *EnrichmentWorker) ExtractColorBatch(ctx context.Context, imageURLs []string) {
-	job := &EnrichmentJob{
-		Type:    JobTypeColor,
-		URLs:    imageURLs,
-		Context: ctx,
-	}
-	
-	// Submit job and ignore errors for backward compatibility
-	_ = ew.SubmitJob(job)
+// Job returns the queued job of a type for a URL, or nil when none is queued
+func (ew *EnrichmentWorker) Job(ctx context.Context, jobType, url string) (*domain.QueuedJob, error) {
+	return ew.queue.Get(ctx, domain.QueuedJobID(jobType, url))
 }
 
-// ExtractMetadataBatch submits a batch metadata extraction job
-func (ew *EnrichmentWorker) ExtractMetadataBatch(ctx context.Context, urls []string) {
-	job := &EnrichmentJob{
-		Type:    JobTypeMetadata,
-		URLs:    urls,
-		Context: ctx,
+// Stats returns the number of jobs by type and status, and the dead jobs
+// with the most recent failures first
+func (ew *EnrichmentWorker) Stats(ctx context.Context) (*domain.QueueStats, error) {
+	counts, err := ew.queue.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dead, err := ew.queue.List(ctx, domain.JobDead)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.QueueStats{
+		Depth:    counts,
+		Failures: dead,
 	}
-	
-	// Submit job and ignore errors for backward compatibility
-	_ = ew.SubmitJob(job)
+	for _, jobType := range domain.JobTypes {
+		if stats.Depth[jobType] == nil {
+			stats.Depth[jobType] = make(map[string]int)
+		}
+		for _, status := range []string{domain.JobPending, domain.JobRunning, domain.JobDead} {
+			if _, ok := stats.Depth[jobType][status]; !ok {
+				stats.Depth[jobType][status] = 0
+			}
+		}
+	}
+	if stats.Failures == nil {
+		stats.Failures = []*domain.QueuedJob{}
+	}
+
+	sort.Slice(stats.Failures, func(i, j int) bool {
+		return stats.Failures[i].UpdatedAt.After(stats.Failures[j].UpdatedAt)
+	})
+	return stats, nil
 }
 
-// run is the main loop for each worker
-func (w *worker) run() {
-	defer w.wg.Done()
-	
+// purgeLoop removes dead jobs once they are older than the dead retention,
+// so storage does not keep every URL that ever failed
+func (ew *EnrichmentWorker) purgeLoop(ctx context.Context) {
+	defer ew.wg.Done()
+
+	interval := ew.config.DeadRetention
+	if interval > maxPurgeInterval {
+		interval = maxPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
+		ew.purgeDead(ctx)
+
 		select {
-		case job, ok := <-w.jobQueue:
-			if !ok {
-				// Channel closed, exit
-				return
-			}
-			w.processJob(job)
-		case <-w.ctx.Done():
-			// Context cancelled, exit
+		case <-ctx.Done():
 			return
+		case <-ticker.C:
 		}
 	}
 }
 
-// processJob processes a single enrichment job
-func (w *worker) processJob(job *EnrichmentJob) {
-	switch job.Type {
-	case JobTypeColor:
-		// Process color extraction
-		results := w.enrichmentService.ExtractColorBatch(job.Context, job.URLs)
-		if job.ResultCh != nil {
-			select {
-			case job.ResultCh <- results:
-			case <-job.Context.Done():
-			}
+// purgeDead removes the dead jobs older than the dead retention
+func (ew *EnrichmentWorker) purgeDead(ctx context.Context) {
+	purged, err := ew.queue.PurgeDead(ctx, ew.now().Add(-ew.config.DeadRetention))
+	if err != nil {
+		if ctx.Err() == nil {
+			ew.logger.Error("Failed to purge dead jobs", map[string]interface{}{
+				"error": err.Error(),
+			})
 		}
-		
-	case JobTypeMetadata:
-		// Process metadata extraction
-		results := w.enrichmentService.ExtractMetadataBatch(job.Context, job.URLs)
-		if job.ResultCh != nil {
-			select {
-			case job.ResultCh <- results:
-			case <-job.Context.Done():
-			}
+		return
+	}
+	if purged > 0 {
+		ew.logger.Info("Purged dead jobs", map[string]interface{}{
+			"count": purged,
+		})
+	}
+}
+
+// run claims and processes jobs of a type until the worker is stopped
+func (ew *EnrichmentWorker) run(ctx context.Context, jobType string, processor JobProcessor) {
+	defer ew.wg.Done()
+
+	ticker := time.NewTicker(ew.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := ew.queue.Claim(ctx, jobType, ew.now(), ew.config.Lease)
+		if err != nil && ctx.Err() == nil {
+			ew.logger.Error("Failed to claim job", map[string]interface{}{
+				"type":  jobType,
+				"error": err.Error(),
+			})
+		}
+
+		if job != nil {
+			ew.process(ctx, job, processor)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ew.wake[jobType]:
+		case <-ticker.C:
 		}
 	}
 }
 
-// Error definitions
-var (
-	ErrWorkerNotRunning = &WorkerError{Message: "worker pool is not running"}
-	ErrQueueFull        = &WorkerError{Message: "job queue is full"}
-)
+// process runs a claimed job, then removes it when it succeeds or
+// reschedules it when it fails
+func (ew *EnrichmentWorker) process(ctx context.Context, job *domain.QueuedJob, processor JobProcessor) {
+	var err error
+	if job.Attempts > ew.config.MaxAttempts {
+		// Claimed again after its last attempt's lease expired
+		err = fmt.Errorf("job did not finish within its lease")
+	} else {
+		err = ew.runProcessor(ctx, job, processor)
+	}
+
+	// Jobs are updated even while stopping, so they are not left running
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err == nil {
+		if err := ew.queue.Delete(saveCtx, job.ID); err != nil {
+			ew.logger.Error("Failed to remove finished job", map[string]interface{}{
+				"job":   job.ID,
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	now := ew.now()
+	job.LastError = err.Error()
+	job.UpdatedAt = now
+	switch {
+	case ctx.Err() != nil:
+		// Stopped mid-job; the attempt does not count
+		job.Status = domain.JobPending
+		job.Attempts--
+		job.RunAt = now
+	case job.Attempts >= ew.config.MaxAttempts:
+		job.Status = domain.JobDead
+		ew.logger.Warn("Enrichment job failed permanently", map[string]interface{}{
+			"job":      job.ID,
+			"attempts": job.Attempts,
+			"error":    job.LastError,
+		})
+	default:
+		job.Status = domain.JobPending
+		job.RunAt = now.Add(ew.backoff(job.Attempts))
+		ew.logger.Debug("Enrichment job failed, retrying", map[string]interface{}{
+			"job":      job.ID,
+			"attempts": job.Attempts,
+			"retry_at": job.RunAt,
+			"error":    job.LastError,
+		})
+	}
+
+	if err := ew.queue.Save(saveCtx, job); err != nil {
+		ew.logger.Error("Failed to save failed job", map[string]interface{}{
+			"job":   job.ID,
+			"error": err.Error(),
+		})
+	}
+}
+
+// runProcessor runs a processor, turning a panic into a failed attempt
+func (ew *EnrichmentWorker) runProcessor(ctx context.Context, job *domain.QueuedJob, processor JobProcessor) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic recovered: %v", rec)
+		}
+	}()
 
-// WorkerError represents a worker-specific error
-type WorkerError struct {
-	Message string
+	return processor(ctx, job.URL)
 }
 
-func (e *WorkerError) Error() string {
-	return e.Message
-}
\ No newline at end of file
+// backoff returns the delay before retrying a job that failed its given
+// attempt: the base backoff doubled for each earlier attempt, up to the max
+func (ew *EnrichmentWorker) backoff(attempts int) time.Duration {
+	delay := ew.config.BaseBackoff
+	for i := 1; i < attempts && delay < ew.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > ew.config.MaxBackoff {
+		delay = ew.config.MaxBackoff
+	}
+	return delay
+}
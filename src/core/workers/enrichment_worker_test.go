@@ -0,0 +1,188 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/infrastructure/storage/memory"
+)
+
+// newTestWorker creates a worker with short delays on in-memory storage
+func newTestWorker(t *testing.T) (*EnrichmentWorker, *memory.JobQueueStorage) {
+	t.Helper()
+
+	queue := memory.NewJobQueueStorage()
+	worker := NewEnrichmentWorker(queue, &mockLogger{}, WorkerConfig{
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	return worker, queue
+}
+
+// waitFor polls a condition until it holds or a second has passed
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestEnrichmentWorker_ProcessesAndRemovesJobs(t *testing.T) {
+	worker, queue := newTestWorker(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	processed := map[string]int{}
+	worker.Handle(domain.JobTypeMetadata, func(ctx context.Context, url string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed[url]++
+		return nil
+	})
+	if err := worker.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+
+	if err := worker.Enqueue(ctx, domain.JobTypeMetadata, "https://example.com/a"); err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		jobs, _ := queue.List(ctx, "")
+		return len(jobs) == 0
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if processed["https://example.com/a"] != 1 {
+		t.Errorf("expected URL to be processed once, got %v", processed)
+	}
+}
+
+func TestEnrichmentWorker_DeduplicatesByURL(t *testing.T) {
+	worker, queue := newTestWorker(t)
+	ctx := context.Background()
+	worker.Handle(domain.JobTypeColor, func(ctx context.Context, url string) error { return nil })
+
+	// Not started, so jobs stay queued
+	for i := 0; i < 3; i++ {
+		if err := worker.Enqueue(ctx, domain.JobTypeColor, "https://example.com/a.jpg"); err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+	}
+	_ = worker.Enqueue(ctx, domain.JobTypeColor, "https://example.com/b.jpg")
+
+	if jobs, _ := queue.List(ctx, ""); len(jobs) != 2 {
+		t.Errorf("expected 2 queued jobs, got %d", len(jobs))
+	}
+
+	if err := worker.Enqueue(ctx, "unknown", "https://example.com"); err == nil {
+		t.Error("expected unknown job type to be refused")
+	}
+}
+
+func TestEnrichmentWorker_RetriesThenDeadLetters(t *testing.T) {
+	worker, queue := newTestWorker(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	attempts := 0
+	worker.Handle(domain.JobTypeReader, func(ctx context.Context, url string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return errors.New("status 503")
+	})
+	_ = worker.Start()
+	_ = worker.Enqueue(ctx, domain.JobTypeReader, "https://example.com/post")
+
+	waitFor(t, func() bool {
+		dead, _ := queue.List(ctx, domain.JobDead)
+		return len(dead) == 1
+	})
+
+	job, _ := worker.Job(ctx, domain.JobTypeReader, "https://example.com/post")
+	if job == nil || job.Attempts != 3 || job.LastError != "status 503" {
+		t.Errorf("unexpected dead job: %+v", job)
+	}
+	mu.Lock()
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	mu.Unlock()
+
+	stats, err := worker.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats returned error: %v", err)
+	}
+	if stats.Depth[domain.JobTypeReader][domain.JobDead] != 1 || len(stats.Failures) != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// The dead job blocks its URL until the retention passes
+	_ = worker.Enqueue(ctx, domain.JobTypeReader, "https://example.com/post")
+	if job, _ := worker.Job(ctx, domain.JobTypeReader, "https://example.com/post"); job.Status != domain.JobDead {
+		t.Errorf("expected job to stay dead, got %+v", job)
+	}
+	_ = worker.Stop()
+	worker.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	_ = worker.Enqueue(ctx, domain.JobTypeReader, "https://example.com/post")
+	if job, _ := worker.Job(ctx, domain.JobTypeReader, "https://example.com/post"); job == nil || job.Status == domain.JobDead {
+		t.Errorf("expected job to be queued again, got %+v", job)
+	}
+}
+
+func TestEnrichmentWorker_PurgesDeadJobs(t *testing.T) {
+	worker, queue := newTestWorker(t)
+	ctx := context.Background()
+	worker.Handle(domain.JobTypeColor, func(ctx context.Context, url string) error { return nil })
+
+	now := time.Now()
+	job := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/a.jpg", now)
+	job.Status = domain.JobDead
+	job.UpdatedAt = now
+	_, _ = queue.Enqueue(ctx, job)
+
+	worker.purgeDead(ctx)
+	if got, _ := worker.Job(ctx, domain.JobTypeColor, job.URL); got == nil {
+		t.Fatal("expected a recent dead job to be kept")
+	}
+
+	worker.now = func() time.Time { return now.Add(25 * time.Hour) }
+	worker.purgeDead(ctx)
+	if got, _ := worker.Job(ctx, domain.JobTypeColor, job.URL); got != nil {
+		t.Errorf("expected the dead job to be purged after the retention, got %+v", got)
+	}
+
+	stats, err := worker.Stats(ctx)
+	if err != nil || stats.Depth[domain.JobTypeColor][domain.JobDead] != 0 || len(stats.Failures) != 0 {
+		t.Errorf("unexpected stats after purge: %+v, %v", stats, err)
+	}
+}
+
+func TestEnrichmentWorker_Backoff(t *testing.T) {
+	worker := NewEnrichmentWorker(memory.NewJobQueueStorage(), &mockLogger{}, WorkerConfig{
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Second,
+	})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := worker.backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
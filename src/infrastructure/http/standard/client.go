@@ -34,6 +34,11 @@ func NewStandardHTTPClient(timeout time.Duration) *StandardHTTPClient {
 
 // Get performs an HTTP GET request
 func (c *StandardHTTPClient) Get(ctx context.Context, url string) (interfaces.Response, error) {
+	return c.GetWithHeaders(ctx, url, nil)
+}
+
+// GetWithHeaders performs an HTTP GET request with additional headers
+func (c *StandardHTTPClient) GetWithHeaders(ctx context.Context, url string, headers map[string]string) (interfaces.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -41,6 +46,9 @@ func (c *StandardHTTPClient) Get(ctx context.Context, url string) (interfaces.Re
 
 	// Set User-Agent
 	req.Header.Set("User-Agent", userAgent)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	// Perform request with retry logic
 	var resp *http.Response
@@ -3,6 +3,7 @@ package standard
 import (
 	"context"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -88,6 +89,32 @@ func TestStandardHTTPClient_Get_UserAgent(t *testing.T) {
 	}
 }
 
+func TestStandardHTTPClient_GetWithHeaders(t *testing.T) {
+	var capturedKey, capturedUserAgent string
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedKey = r.Header.Get("X-Auth-Key")
+		capturedUserAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	
+	client := NewStandardHTTPClient(10 * time.Second)
+	
+	resp, err := client.GetWithHeaders(context.Background(), server.URL, map[string]string{"X-Auth-Key": "secret"})
+	if err != nil {
+		t.Fatalf("GetWithHeaders returned error: %v", err)
+	}
+	resp.Body().Close()
+	
+	if capturedKey != "secret" {
+		t.Errorf("X-Auth-Key = %q, want secret", capturedKey)
+	}
+	if !strings.Contains(capturedUserAgent, "DigestsAPI") {
+		t.Errorf("User-Agent = %s, should still be set", capturedUserAgent)
+	}
+}
+
 func TestStandardHTTPClient_Get_ContextTimeout(t *testing.T) {
 	// Create slow server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -306,4 +333,27 @@ func TestStandardHTTPClient_Post_Success(t *testing.T) {
 	if capturedBody != "test post data" {
 		t.Errorf("Captured body = %s, want 'test post data'", capturedBody)
 	}
-}
\ No newline at end of file
+}
+
+func TestGuardedHTTPClient_RefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Guarded client reached a loopback server")
+	}))
+	defer server.Close()
+
+	client := NewGuardedHTTPClient(5 * time.Second)
+	if _, err := client.Get(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "non-public") {
+		t.Errorf("Expected the loopback connection to be refused, got %v", err)
+	}
+
+	for _, address := range []string{"10.1.2.3", "192.168.0.1", "169.254.169.254", "100.64.0.1", "::1", "fe80::1", "0.0.0.0"} {
+		if isPublicIP(net.ParseIP(address)) {
+			t.Errorf("Expected %s to be non-public", address)
+		}
+	}
+	for _, address := range []string{"93.184.216.34", "2606:2800:220:1::"} {
+		if !isPublicIP(net.ParseIP(address)) {
+			t.Errorf("Expected %s to be public", address)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Guarded HTTP client that only connects to public internet addresses
+// ABOUTME: Keeps URLs supplied by clients from reaching internal services
+
+package standard
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is
+// not public but not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// NewGuardedHTTPClient creates an HTTP client for fetching URLs supplied by
+// clients. Connections to loopback, private, link-local and other
+// non-public addresses are refused. The check is made on the resolved
+// address of every connection, so it also covers redirects and DNS names
+// pointing at internal hosts.
+func NewGuardedHTTPClient(timeout time.Duration) *StandardHTTPClient {
+	return &StandardHTTPClient{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: NewGuardedTransport(),
+		},
+	}
+}
+
+// NewGuardedTransport creates the transport of a guarded client, for
+// libraries that make their own requests
+func NewGuardedTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("connection to non-public address %s refused", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Transport{
+		// No proxy: the guard must see the address actually dialed
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// isPublicIP reports whether an address is reachable on the public internet
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,150 @@
+// ABOUTME: In-memory storage for background enrichment jobs
+// ABOUTME: Suitable for development and single-instance deployments; queued jobs are lost on restart
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+// JobQueueStorage implements interfaces.JobQueueStorage using a map
+type JobQueueStorage struct {
+	mu   sync.Mutex
+	jobs map[string]domain.QueuedJob
+}
+
+// NewJobQueueStorage creates a new in-memory job queue storage
+func NewJobQueueStorage() *JobQueueStorage {
+	return &JobQueueStorage{
+		jobs: make(map[string]domain.QueuedJob),
+	}
+}
+
+// Enqueue stores a copy of the job unless one with the same ID exists
+func (s *JobQueueStorage) Enqueue(ctx context.Context, job *domain.QueuedJob) (bool, error) {
+	if job == nil || job.ID == "" {
+		return false, errors.New("job must have an ID")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.ID]; exists {
+		return false, nil
+	}
+	s.jobs[job.ID] = *job
+
+	return true, nil
+}
+
+// Claim marks the due job of a type that has waited longest as running
+func (s *JobQueueStorage) Claim(ctx context.Context, jobType string, now time.Time, lease time.Duration) (*domain.QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *domain.QueuedJob
+	for _, job := range s.jobs {
+		if job.Type != jobType || !job.IsDue(now) {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) {
+			job := job
+			next = &job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Claim(now, lease)
+	s.jobs[next.ID] = *next
+
+	return next, nil
+}
+
+// Save updates a job
+func (s *JobQueueStorage) Save(ctx context.Context, job *domain.QueuedJob) error {
+	if job == nil || job.ID == "" {
+		return errors.New("job must have an ID")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+
+	return nil
+}
+
+// Get returns a copy of the job, or nil if it does not exist
+func (s *JobQueueStorage) Get(ctx context.Context, id string) (*domain.QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &job, nil
+}
+
+// Delete removes a job
+func (s *JobQueueStorage) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+
+	return nil
+}
+
+// List returns copies of the jobs with a status, or of all jobs
+func (s *JobQueueStorage) List(ctx context.Context, status string) ([]*domain.QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []*domain.QueuedJob
+	for _, job := range s.jobs {
+		if status == "" || job.Status == status {
+			job := job
+			jobs = append(jobs, &job)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Count returns the number of jobs by type and status
+func (s *JobQueueStorage) Count(ctx context.Context) (map[string]map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]map[string]int)
+	for _, job := range s.jobs {
+		if counts[job.Type] == nil {
+			counts[job.Type] = make(map[string]int)
+		}
+		counts[job.Type][job.Status]++
+	}
+
+	return counts, nil
+}
+
+// PurgeDead removes dead jobs that failed before the given time
+func (s *JobQueueStorage) PurgeDead(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for id, job := range s.jobs {
+		if job.Status == domain.JobDead && job.UpdatedAt.Before(before) {
+			delete(s.jobs, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
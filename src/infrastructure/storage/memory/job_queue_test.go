@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestJobQueueStorage_EnqueueClaim(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage()
+	now := time.Now()
+
+	first := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/a.jpg", now.Add(-time.Minute))
+	second := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/b.jpg", now)
+	for _, job := range []*domain.QueuedJob{second, first} {
+		if added, err := storage.Enqueue(ctx, job); !added || err != nil {
+			t.Fatalf("Enqueue returned %v, %v", added, err)
+		}
+	}
+
+	// Jobs are deduplicated by ID
+	if added, err := storage.Enqueue(ctx, domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/a.jpg", now)); added || err != nil {
+		t.Errorf("expected duplicate job to be ignored, got %v, %v", added, err)
+	}
+
+	// The job waiting longest is claimed first
+	claimed, err := storage.Claim(ctx, domain.JobTypeColor, now, time.Minute)
+	if err != nil || claimed == nil || claimed.ID != first.ID {
+		t.Fatalf("unexpected claimed job: %+v, %v", claimed, err)
+	}
+	if claimed.Status != domain.JobRunning || claimed.Attempts != 1 {
+		t.Errorf("expected running job on its first attempt, got %+v", claimed)
+	}
+
+	// Other types have nothing due
+	if job, _ := storage.Claim(ctx, domain.JobTypeReader, now, time.Minute); job != nil {
+		t.Errorf("expected no reader job, got %+v", job)
+	}
+
+	// A running job is not claimed again until its lease expires
+	next, _ := storage.Claim(ctx, domain.JobTypeColor, now.Add(time.Second), time.Minute)
+	if next == nil || next.ID != second.ID {
+		t.Fatalf("expected second job, got %+v", next)
+	}
+	if job, _ := storage.Claim(ctx, domain.JobTypeColor, now, time.Minute); job != nil {
+		t.Errorf("expected no due job, got %+v", job)
+	}
+	expired, _ := storage.Claim(ctx, domain.JobTypeColor, now.Add(2*time.Minute), time.Minute)
+	if expired == nil || expired.ID != first.ID || expired.Attempts != 2 {
+		t.Errorf("expected expired lease to be claimed again, got %+v", expired)
+	}
+}
+
+func TestJobQueueStorage_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage()
+	now := time.Now()
+
+	job := domain.NewQueuedJob(domain.JobTypeMetadata, "https://example.com/post", now)
+	_, _ = storage.Enqueue(ctx, job)
+
+	job.Status = domain.JobDead
+	job.LastError = "status 500"
+	if err := storage.Save(ctx, job); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	// Dead jobs are never claimed
+	if claimed, _ := storage.Claim(ctx, domain.JobTypeMetadata, now.Add(time.Hour), time.Minute); claimed != nil {
+		t.Errorf("expected dead job not to be claimed, got %+v", claimed)
+	}
+
+	dead, err := storage.List(ctx, domain.JobDead)
+	if err != nil || len(dead) != 1 || dead[0].LastError != "status 500" {
+		t.Errorf("unexpected dead jobs: %+v, %v", dead, err)
+	}
+	if pending, _ := storage.List(ctx, domain.JobPending); len(pending) != 0 {
+		t.Errorf("expected no pending jobs, got %+v", pending)
+	}
+
+	if err := storage.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if got, err := storage.Get(ctx, job.ID); got != nil || err != nil {
+		t.Errorf("expected deleted job to be gone, got %+v, %v", got, err)
+	}
+}
+
+func TestJobQueueStorage_CountPurgeDead(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage()
+	now := time.Now()
+
+	old := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/old.jpg", now)
+	recent := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/recent.jpg", now)
+	pending := domain.NewQueuedJob(domain.JobTypeReader, "https://example.com/post", now)
+	for _, job := range []*domain.QueuedJob{old, recent, pending} {
+		_, _ = storage.Enqueue(ctx, job)
+	}
+	old.Status, old.UpdatedAt = domain.JobDead, now.Add(-2*time.Hour)
+	recent.Status, recent.UpdatedAt = domain.JobDead, now
+	_ = storage.Save(ctx, old)
+	_ = storage.Save(ctx, recent)
+
+	counts, err := storage.Count(ctx)
+	if err != nil || counts[domain.JobTypeColor][domain.JobDead] != 2 || counts[domain.JobTypeReader][domain.JobPending] != 1 {
+		t.Errorf("unexpected counts: %v, %v", counts, err)
+	}
+
+	if purged, err := storage.PurgeDead(ctx, now.Add(-time.Hour)); purged != 1 || err != nil {
+		t.Fatalf("PurgeDead returned %d, %v", purged, err)
+	}
+	if got, _ := storage.Get(ctx, old.ID); got != nil {
+		t.Errorf("expected the old dead job to be purged, got %+v", got)
+	}
+	if got, _ := storage.Get(ctx, recent.ID); got == nil {
+		t.Error("expected the recent dead job to be kept")
+	}
+	if got, _ := storage.Get(ctx, pending.ID); got == nil {
+		t.Error("expected the pending job to be kept")
+	}
+}
@@ -0,0 +1,78 @@
+// ABOUTME: In-memory storage for JSON API mappings
+// ABOUTME: Suitable for development and single-instance deployments; data is lost on restart
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"digests-app-api/core/domain"
+)
+
+// JSONMappingStorage implements interfaces.JSONMappingStorage using a map
+type JSONMappingStorage struct {
+	mu       sync.RWMutex
+	mappings map[string]domain.JSONMapping
+}
+
+// NewJSONMappingStorage creates a new in-memory JSON mapping storage
+func NewJSONMappingStorage() *JSONMappingStorage {
+	return &JSONMappingStorage{
+		mappings: make(map[string]domain.JSONMapping),
+	}
+}
+
+// Save stores a copy of the mapping
+func (s *JSONMappingStorage) Save(ctx context.Context, mapping *domain.JSONMapping) error {
+	if mapping == nil || mapping.ID == "" {
+		return errors.New("mapping must have an ID")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mappings[mapping.ID] = *mapping
+
+	return nil
+}
+
+// Get returns a copy of the mapping, or nil if it does not exist
+func (s *JSONMappingStorage) Get(ctx context.Context, id string) (*domain.JSONMapping, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mapping, ok := s.mappings[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &mapping, nil
+}
+
+// List returns all mappings, oldest first
+func (s *JSONMappingStorage) List(ctx context.Context) ([]*domain.JSONMapping, error) {
+	s.mu.RLock()
+	mappings := make([]*domain.JSONMapping, 0, len(s.mappings))
+	for _, mapping := range s.mappings {
+		mapping := mapping
+		mappings = append(mappings, &mapping)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].CreatedAt.Before(mappings[j].CreatedAt)
+	})
+
+	return mappings, nil
+}
+
+// Delete removes a mapping; deleting a missing ID is not an error
+func (s *JSONMappingStorage) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mappings, id)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// ABOUTME: In-memory storage for scraper definitions
+// ABOUTME: Suitable for development and single-instance deployments; data is lost on restart
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"digests-app-api/core/domain"
+)
+
+// ScraperStorage implements interfaces.ScraperStorage using a map
+type ScraperStorage struct {
+	mu   sync.RWMutex
+	defs map[string]domain.ScraperDefinition
+}
+
+// NewScraperStorage creates a new in-memory scraper storage
+func NewScraperStorage() *ScraperStorage {
+	return &ScraperStorage{
+		defs: make(map[string]domain.ScraperDefinition),
+	}
+}
+
+// Save stores a copy of the definition
+func (s *ScraperStorage) Save(ctx context.Context, def *domain.ScraperDefinition) error {
+	if def == nil || def.ID == "" {
+		return errors.New("scraper definition must have an ID")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defs[def.ID] = *def
+
+	return nil
+}
+
+// Get returns a copy of the definition, or nil if it does not exist
+func (s *ScraperStorage) Get(ctx context.Context, id string) (*domain.ScraperDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	def, ok := s.defs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &def, nil
+}
+
+// List returns all definitions, oldest first
+func (s *ScraperStorage) List(ctx context.Context) ([]*domain.ScraperDefinition, error) {
+	s.mu.RLock()
+	defs := make([]*domain.ScraperDefinition, 0, len(s.defs))
+	for _, def := range s.defs {
+		def := def
+		defs = append(defs, &def)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].CreatedAt.Before(defs[j].CreatedAt)
+	})
+
+	return defs, nil
+}
+
+// Delete removes a definition; deleting a missing ID is not an error
+func (s *ScraperStorage) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.defs, id)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestScraperStorage_SaveGetListDelete(t *testing.T) {
+	ctx := context.Background()
+	storage := NewScraperStorage()
+
+	def := &domain.ScraperDefinition{ID: "a", URL: "https://example.com", ItemSelector: "li", CreatedAt: time.Now()}
+	if err := storage.Save(ctx, def); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	// Stored definitions are copies
+	def.Name = "changed"
+	got, _ := storage.Get(ctx, "a")
+	if got == nil || got.Name != "" {
+		t.Errorf("expected stored copy to be unaffected, got %+v", got)
+	}
+
+	if missing, err := storage.Get(ctx, "missing"); missing != nil || err != nil {
+		t.Errorf("expected nil for missing definition, got %+v, %v", missing, err)
+	}
+
+	defs, _ := storage.List(ctx)
+	if len(defs) != 1 {
+		t.Errorf("got %d definitions, want 1", len(defs))
+	}
+
+	_ = storage.Delete(ctx, "a")
+	if defs, _ := storage.List(ctx); len(defs) != 0 {
+		t.Errorf("expected empty storage after delete, got %d", len(defs))
+	}
+}
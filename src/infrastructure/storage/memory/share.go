@@ -0,0 +1,60 @@
+// ABOUTME: In-memory storage for shares
+// ABOUTME: Suitable for development and single-instance deployments; data is lost on restart
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"digests-app-api/core/domain"
+)
+
+// ShareStorage implements interfaces.ShareStorage using a map
+type ShareStorage struct {
+	mu     sync.RWMutex
+	shares map[string]domain.Share
+}
+
+// NewShareStorage creates a new in-memory share storage
+func NewShareStorage() *ShareStorage {
+	return &ShareStorage{
+		shares: make(map[string]domain.Share),
+	}
+}
+
+// Save stores a copy of the share, dropping any shares that have expired
+func (s *ShareStorage) Save(ctx context.Context, share *domain.Share) error {
+	if share == nil || share.ID == "" {
+		return errors.New("share must have an ID")
+	}
+
+	stored := *share
+	stored.URLs = append([]string(nil), share.URLs...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, existing := range s.shares {
+		if existing.IsExpired() {
+			delete(s.shares, id)
+		}
+	}
+	s.shares[share.ID] = stored
+
+	return nil
+}
+
+// Get returns a copy of the share, or nil if it does not exist
+func (s *ShareStorage) Get(ctx context.Context, id string) (*domain.Share, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	share, ok := s.shares[id]
+	if !ok {
+		return nil, nil
+	}
+	share.URLs = append([]string(nil), share.URLs...)
+
+	return &share, nil
+}
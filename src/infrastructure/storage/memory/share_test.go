@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestShareStorage_SaveGet(t *testing.T) {
+	ctx := context.Background()
+	storage := NewShareStorage()
+
+	share := &domain.Share{ID: "a", URLs: []string{"https://example.com/feed"}, CreatedAt: time.Now()}
+	if err := storage.Save(ctx, share); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	// Stored shares are copies
+	share.URLs[0] = "https://changed.example.com"
+	got, _ := storage.Get(ctx, "a")
+	if got == nil || got.URLs[0] != "https://example.com/feed" {
+		t.Errorf("expected stored copy to be unaffected, got %+v", got)
+	}
+
+	if missing, err := storage.Get(ctx, "missing"); missing != nil || err != nil {
+		t.Errorf("expected nil for missing share, got %+v, %v", missing, err)
+	}
+
+	// Expired shares are dropped on the next save
+	past := time.Now().Add(-time.Minute)
+	_ = storage.Save(ctx, &domain.Share{ID: "old", URLs: []string{"https://example.com"}, ExpiresAt: &past})
+	_ = storage.Save(ctx, &domain.Share{ID: "new", URLs: []string{"https://example.com"}})
+	if old, _ := storage.Get(ctx, "old"); old != nil {
+		t.Error("expected expired share to be removed")
+	}
+}
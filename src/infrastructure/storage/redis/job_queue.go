@@ -0,0 +1,279 @@
+// ABOUTME: Redis storage for background enrichment jobs
+// ABOUTME: Keeps jobs as JSON with sorted sets of due and dead jobs so several instances share one queue
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/pkg/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// jobKeyPrefix namespaces job keys
+	jobKeyPrefix = "queue:job:"
+
+	// dueKeyPrefix namespaces the per-type sets of pending and running
+	// jobs, scored by when they are due
+	dueKeyPrefix = "queue:due:"
+
+	// deadKey is the set of dead jobs, scored by when they failed
+	deadKey = "queue:dead"
+)
+
+// enqueueScript stores a job only if its key is free, and adds it to its set
+var enqueueScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX') then
+	redis.call('ZADD', KEYS[2], ARGV[2], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// claimScript moves the first due job of a set to the end of its lease and
+// returns its ID and data, so no other worker can claim it meanwhile
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+	return false
+end
+local data = redis.call('GET', ARGV[3] .. ids[1])
+if not data then
+	redis.call('ZREM', KEYS[1], ids[1])
+	return {ids[1], false}
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ids[1])
+return {ids[1], data}
+`)
+
+// purgeScript removes the dead jobs that failed before a time, and their keys
+var purgeScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1])
+for _, id in ipairs(ids) do
+	redis.call('DEL', ARGV[2] .. id)
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1])
+return #ids
+`)
+
+// JobQueueStorage implements interfaces.JobQueueStorage using Redis
+type JobQueueStorage struct {
+	client *redis.Client
+}
+
+// NewJobQueueStorage connects to Redis and creates job queue storage
+func NewJobQueueStorage(cfg config.RedisConfig) (*JobQueueStorage, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("redis address cannot be empty")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &JobQueueStorage{client: client}, nil
+}
+
+// Enqueue stores a job unless one with the same ID exists
+func (s *JobQueueStorage) Enqueue(ctx context.Context, job *domain.QueuedJob) (bool, error) {
+	if job == nil || job.ID == "" {
+		return false, errors.New("job must have an ID")
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	setKey, score := jobSet(job)
+	added, err := enqueueScript.Run(ctx, s.client, []string{jobKeyPrefix + job.ID, setKey}, data, score, job.ID).Int()
+	if err != nil {
+		return false, err
+	}
+	return added == 1, nil
+}
+
+// Claim marks the due job of a type that has waited longest as running
+func (s *JobQueueStorage) Claim(ctx context.Context, jobType string, now time.Time, lease time.Duration) (*domain.QueuedJob, error) {
+	result, err := claimScript.Run(ctx, s.client, []string{dueKeyPrefix + jobType},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), jobKeyPrefix).Slice()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := result[1].(string)
+	if !ok {
+		// The job was deleted but was still in the set
+		return nil, nil
+	}
+
+	var job domain.QueuedJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	job.Claim(now, lease)
+
+	if err := s.Save(ctx, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Save updates a job and moves it to the set matching its status
+func (s *JobQueueStorage) Save(ctx context.Context, job *domain.QueuedJob) error {
+	if job == nil || job.ID == "" {
+		return errors.New("job must have an ID")
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	setKey, score := jobSet(job)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, jobKeyPrefix+job.ID, data, 0)
+		pipe.ZRem(ctx, dueKeyPrefix+job.Type, job.ID)
+		pipe.ZRem(ctx, deadKey, job.ID)
+		pipe.ZAdd(ctx, setKey, redis.Z{Score: score, Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+// Get returns a job, or nil if it does not exist
+func (s *JobQueueStorage) Get(ctx context.Context, id string) (*domain.QueuedJob, error) {
+	data, err := s.client.Get(ctx, jobKeyPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var job domain.QueuedJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// Delete removes a job and takes it out of every set
+func (s *JobQueueStorage) Delete(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, jobKeyPrefix+id)
+		pipe.ZRem(ctx, deadKey, id)
+		for _, jobType := range domain.JobTypes {
+			pipe.ZRem(ctx, dueKeyPrefix+jobType, id)
+		}
+		return nil
+	})
+	return err
+}
+
+// List returns the jobs with a status, or all jobs
+func (s *JobQueueStorage) List(ctx context.Context, status string) ([]*domain.QueuedJob, error) {
+	setKeys := []string{deadKey}
+	if status != domain.JobDead {
+		setKeys = nil
+		for _, jobType := range domain.JobTypes {
+			setKeys = append(setKeys, dueKeyPrefix+jobType)
+		}
+		if status == "" {
+			setKeys = append(setKeys, deadKey)
+		}
+	}
+
+	var keys []string
+	for _, setKey := range setKeys {
+		ids, err := s.client.ZRange(ctx, setKey, 0, -1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			keys = append(keys, jobKeyPrefix+id)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*domain.QueuedJob
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var job domain.QueuedJob
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job: %w", err)
+		}
+		if status == "" || job.Status == status {
+			jobs = append(jobs, &job)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Count returns the number of jobs by type and status. Statuses and types
+// are only kept in the job data, so the jobs are read; purging dead jobs
+// keeps this bounded by the live queue.
+func (s *JobQueueStorage) Count(ctx context.Context) (map[string]map[string]int, error) {
+	jobs, err := s.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, job := range jobs {
+		if counts[job.Type] == nil {
+			counts[job.Type] = make(map[string]int)
+		}
+		counts[job.Type][job.Status]++
+	}
+
+	return counts, nil
+}
+
+// PurgeDead removes dead jobs that failed before the given time
+func (s *JobQueueStorage) PurgeDead(ctx context.Context, before time.Time) (int, error) {
+	return purgeScript.Run(ctx, s.client, []string{deadKey}, before.UnixMilli(), jobKeyPrefix).Int()
+}
+
+// Close closes the Redis connection
+func (s *JobQueueStorage) Close() error {
+	return s.client.Close()
+}
+
+// jobSet returns the set a job belongs in and its score there
+func jobSet(job *domain.QueuedJob) (string, float64) {
+	if job.Status == domain.JobDead {
+		return deadKey, float64(job.UpdatedAt.UnixMilli())
+	}
+	return dueKeyPrefix + job.Type, float64(job.RunAt.UnixMilli())
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/pkg/config"
+)
+
+func TestNewJobQueueStorage_InvalidAddress(t *testing.T) {
+	storage, err := NewJobQueueStorage(config.RedisConfig{})
+	if err == nil || storage != nil {
+		t.Error("NewJobQueueStorage should fail without an address")
+	}
+}
+
+func TestJobQueueStorage_RoundTrip(t *testing.T) {
+	if os.Getenv("REDIS_TEST") != "1" {
+		t.Skip("Skipping Redis integration tests - set REDIS_TEST=1 to run")
+	}
+
+	storage, err := NewJobQueueStorage(config.RedisConfig{Address: "localhost:6379"})
+	if err != nil {
+		t.Fatalf("NewJobQueueStorage returned error: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	job := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/test-"+now.Format(time.RFC3339Nano)+".jpg", now)
+	defer storage.Delete(ctx, job.ID)
+
+	if added, err := storage.Enqueue(ctx, job); !added || err != nil {
+		t.Fatalf("Enqueue returned %v, %v", added, err)
+	}
+	if added, _ := storage.Enqueue(ctx, job); added {
+		t.Error("expected duplicate job to be ignored")
+	}
+
+	claimed, err := storage.Claim(ctx, domain.JobTypeColor, now, time.Minute)
+	if err != nil || claimed == nil || claimed.ID != job.ID || claimed.Status != domain.JobRunning {
+		t.Fatalf("unexpected claimed job: %+v, %v", claimed, err)
+	}
+	if again, _ := storage.Claim(ctx, domain.JobTypeColor, now, time.Minute); again != nil && again.ID == job.ID {
+		t.Error("expected leased job not to be claimed again")
+	}
+
+	claimed.Status = domain.JobDead
+	claimed.LastError = "status 500"
+	if err := storage.Save(ctx, claimed); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	dead, err := storage.List(ctx, domain.JobDead)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	found := false
+	for _, d := range dead {
+		found = found || d.ID == job.ID
+	}
+	if !found {
+		t.Errorf("expected job among dead jobs, got %+v", dead)
+	}
+
+	if counts, err := storage.Count(ctx); err != nil || counts[domain.JobTypeColor][domain.JobDead] < 1 {
+		t.Errorf("expected the dead job to be counted, got %v, %v", counts, err)
+	}
+
+	if purged, err := storage.PurgeDead(ctx, claimed.UpdatedAt.Add(time.Millisecond)); err != nil || purged < 1 {
+		t.Fatalf("PurgeDead returned %d, %v", purged, err)
+	}
+	if got, err := storage.Get(ctx, job.ID); got != nil || err != nil {
+		t.Errorf("expected purged job to be gone, got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Redis storage for shares
+// ABOUTME: Stores shares as JSON and lets Redis expire them with the share
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/pkg/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// shareKeyPrefix namespaces share keys
+const shareKeyPrefix = "share:"
+
+// ShareStorage implements interfaces.ShareStorage using Redis
+type ShareStorage struct {
+	client *redis.Client
+}
+
+// NewShareStorage connects to Redis and creates share storage
+func NewShareStorage(cfg config.RedisConfig) (*ShareStorage, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("redis address cannot be empty")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &ShareStorage{client: client}, nil
+}
+
+// Save persists a share; shares with an expiry are removed by Redis when it passes
+func (s *ShareStorage) Save(ctx context.Context, share *domain.Share) error {
+	if share == nil || share.ID == "" {
+		return errors.New("share must have an ID")
+	}
+
+	data, err := json.Marshal(share)
+	if err != nil {
+		return fmt.Errorf("failed to encode share: %w", err)
+	}
+
+	var ttl time.Duration
+	if share.ExpiresAt != nil {
+		ttl = time.Until(*share.ExpiresAt)
+		if ttl <= 0 {
+			return errors.New("share has already expired")
+		}
+	}
+
+	return s.client.Set(ctx, shareKeyPrefix+share.ID, data, ttl).Err()
+}
+
+// Get returns a share, or nil if it does not exist
+func (s *ShareStorage) Get(ctx context.Context, id string) (*domain.Share, error) {
+	data, err := s.client.Get(ctx, shareKeyPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var share domain.Share
+	if err := json.Unmarshal(data, &share); err != nil {
+		return nil, fmt.Errorf("failed to decode share: %w", err)
+	}
+
+	return &share, nil
+}
+
+// Close closes the Redis connection
+func (s *ShareStorage) Close() error {
+	return s.client.Close()
+}
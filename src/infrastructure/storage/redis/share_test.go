@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+	"digests-app-api/pkg/config"
+)
+
+func TestNewShareStorage_InvalidAddress(t *testing.T) {
+	storage, err := NewShareStorage(config.RedisConfig{})
+	if err == nil || storage != nil {
+		t.Error("NewShareStorage should fail without an address")
+	}
+}
+
+func TestShareStorage_RoundTrip(t *testing.T) {
+	if os.Getenv("REDIS_TEST") != "1" {
+		t.Skip("Skipping Redis integration tests - set REDIS_TEST=1 to run")
+	}
+
+	storage, err := NewShareStorage(config.RedisConfig{Address: "localhost:6379"})
+	if err != nil {
+		t.Fatalf("NewShareStorage returned error: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+	share := &domain.Share{
+		ID:        "550e8400-e29b-41d4-a716-446655440000",
+		URLs:      []string{"https://example.com/feed"},
+		Title:     "Reading list",
+		CreatedAt: time.Now(),
+		ExpiresAt: &expiresAt,
+	}
+	if err := storage.Save(ctx, share); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	got, err := storage.Get(ctx, share.ID)
+	if err != nil || got == nil || got.Title != "Reading list" {
+		t.Errorf("unexpected share: %+v, %v", got, err)
+	}
+
+	if missing, err := storage.Get(ctx, "missing"); missing != nil || err != nil {
+		t.Errorf("expected nil for missing share, got %+v, %v", missing, err)
+	}
+}
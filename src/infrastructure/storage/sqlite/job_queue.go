@@ -0,0 +1,234 @@
+// ABOUTME: SQLite storage for background enrichment jobs
+// ABOUTME: Persists queued jobs so they survive restarts and are claimed once at a time
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+// maxClaimRetries bounds how often a claim is retried when another worker
+// claims the same job first
+const maxClaimRetries = 5
+
+// JobQueueStorage implements interfaces.JobQueueStorage on top of a Store
+type JobQueueStorage struct {
+	store *Store
+}
+
+// NewJobQueueStorage creates job queue storage backed by the given store
+func NewJobQueueStorage(store *Store) *JobQueueStorage {
+	return &JobQueueStorage{store: store}
+}
+
+// Enqueue stores a job unless one with the same ID exists
+func (s *JobQueueStorage) Enqueue(ctx context.Context, job *domain.QueuedJob) (bool, error) {
+	if job == nil || job.ID == "" {
+		return false, errors.New("job must have an ID")
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	query := `
+		INSERT OR IGNORE INTO jobs (id, type, status, run_at, data)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := s.store.db.ExecContext(ctx, query, job.ID, job.Type, job.Status, runAtColumn(job), data)
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return added > 0, nil
+}
+
+// Claim marks the due job of a type that has waited longest as running. The
+// update only applies if the job is unchanged since it was read, so two
+// workers never claim the same job.
+func (s *JobQueueStorage) Claim(ctx context.Context, jobType string, now time.Time, lease time.Duration) (*domain.QueuedJob, error) {
+	query := `
+		SELECT data FROM jobs
+		WHERE type = ? AND status IN (?, ?) AND run_at <= ?
+		ORDER BY run_at
+		LIMIT 1
+	`
+
+	for i := 0; i < maxClaimRetries; i++ {
+		var data []byte
+		err := s.store.db.QueryRowContext(ctx, query, jobType, domain.JobPending, domain.JobRunning, now.UnixNano()).Scan(&data)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim job: %w", err)
+		}
+
+		var job domain.QueuedJob
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job: %w", err)
+		}
+		previousStatus, previousRunAt := job.Status, job.RunAt
+		job.Claim(now, lease)
+
+		claimed, err := s.update(ctx, &job, "AND status = ? AND run_at = ?", previousStatus, previousRunAt.UnixNano())
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return &job, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Save updates a job
+func (s *JobQueueStorage) Save(ctx context.Context, job *domain.QueuedJob) error {
+	if job == nil || job.ID == "" {
+		return errors.New("job must have an ID")
+	}
+
+	_, err := s.update(ctx, job, "")
+	return err
+}
+
+// update writes a job over the stored one, if it matches the extra
+// condition, and reports whether it was written
+func (s *JobQueueStorage) update(ctx context.Context, job *domain.QueuedJob, condition string, args ...interface{}) (bool, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	query := "UPDATE jobs SET status = ?, run_at = ?, data = ? WHERE id = ? " + condition
+	result, err := s.store.db.ExecContext(ctx, query, append([]interface{}{job.Status, runAtColumn(job), data, job.ID}, args...)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save job: %w", err)
+	}
+	return updated > 0, nil
+}
+
+// Get returns a job, or nil if it does not exist
+func (s *JobQueueStorage) Get(ctx context.Context, id string) (*domain.QueuedJob, error) {
+	var data []byte
+	err := s.store.db.QueryRowContext(ctx, "SELECT data FROM jobs WHERE id = ?", id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	var job domain.QueuedJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// Delete removes a job
+func (s *JobQueueStorage) Delete(ctx context.Context, id string) error {
+	if _, err := s.store.db.ExecContext(ctx, "DELETE FROM jobs WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the jobs with a status, or all jobs, in the order they are due
+func (s *JobQueueStorage) List(ctx context.Context, status string) ([]*domain.QueuedJob, error) {
+	query := "SELECT data FROM jobs ORDER BY run_at"
+	var args []interface{}
+	if status != "" {
+		query = "SELECT data FROM jobs WHERE status = ? ORDER BY run_at"
+		args = append(args, status)
+	}
+
+	rows, err := s.store.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.QueuedJob
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+
+		var job domain.QueuedJob
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Count returns the number of jobs by type and status
+func (s *JobQueueStorage) Count(ctx context.Context) (map[string]map[string]int, error) {
+	rows, err := s.store.db.QueryContext(ctx, "SELECT type, status, COUNT(*) FROM jobs GROUP BY type, status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var jobType, status string
+		var count int
+		if err := rows.Scan(&jobType, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to read job count: %w", err)
+		}
+		if counts[jobType] == nil {
+			counts[jobType] = make(map[string]int)
+		}
+		counts[jobType][status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// PurgeDead removes dead jobs that failed before the given time
+func (s *JobQueueStorage) PurgeDead(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.store.db.ExecContext(ctx, "DELETE FROM jobs WHERE status = ? AND run_at < ?", domain.JobDead, before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead jobs: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead jobs: %w", err)
+	}
+	return int(purged), nil
+}
+
+// runAtColumn returns the run_at column value of a job. Dead jobs never run
+// again, so like the Redis dead set they are indexed by when they failed.
+func runAtColumn(job *domain.QueuedJob) int64 {
+	if job.Status == domain.JobDead {
+		return job.UpdatedAt.UnixNano()
+	}
+	return job.RunAt.UnixNano()
+}
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestJobQueueStorage_EnqueueClaim(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage(newTestStore(t))
+	now := time.Now()
+
+	first := domain.NewQueuedJob(domain.JobTypeReader, "https://example.com/a", now.Add(-time.Minute))
+	second := domain.NewQueuedJob(domain.JobTypeReader, "https://example.com/b", now)
+	for _, job := range []*domain.QueuedJob{second, first} {
+		if added, err := storage.Enqueue(ctx, job); !added || err != nil {
+			t.Fatalf("Enqueue returned %v, %v", added, err)
+		}
+	}
+
+	if added, err := storage.Enqueue(ctx, domain.NewQueuedJob(domain.JobTypeReader, "https://example.com/a", now)); added || err != nil {
+		t.Errorf("expected duplicate job to be ignored, got %v, %v", added, err)
+	}
+
+	claimed, err := storage.Claim(ctx, domain.JobTypeReader, now, time.Minute)
+	if err != nil || claimed == nil || claimed.ID != first.ID || claimed.Status != domain.JobRunning || claimed.Attempts != 1 {
+		t.Fatalf("unexpected claimed job: %+v, %v", claimed, err)
+	}
+
+	// The claim is persisted
+	stored, _ := storage.Get(ctx, first.ID)
+	if stored == nil || stored.Status != domain.JobRunning {
+		t.Errorf("expected stored job to be running, got %+v", stored)
+	}
+
+	if next, _ := storage.Claim(ctx, domain.JobTypeReader, now.Add(time.Second), time.Minute); next == nil || next.ID != second.ID {
+		t.Fatalf("expected second job, got %+v", next)
+	}
+	if job, _ := storage.Claim(ctx, domain.JobTypeReader, now, time.Minute); job != nil {
+		t.Errorf("expected no due job, got %+v", job)
+	}
+
+	// Jobs whose lease expired are claimed again
+	expired, _ := storage.Claim(ctx, domain.JobTypeReader, now.Add(2*time.Minute), time.Minute)
+	if expired == nil || expired.ID != first.ID || expired.Attempts != 2 {
+		t.Errorf("expected expired lease to be claimed again, got %+v", expired)
+	}
+}
+
+func TestJobQueueStorage_ConcurrentClaims(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage(newTestStore(t))
+	now := time.Now()
+	_, _ = storage.Enqueue(ctx, domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/a.jpg", now))
+
+	var mu sync.Mutex
+	claims := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if job, err := storage.Claim(ctx, domain.JobTypeColor, now, time.Minute); err == nil && job != nil {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claims != 1 {
+		t.Errorf("expected the job to be claimed once, got %d claims", claims)
+	}
+}
+
+func TestJobQueueStorage_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+	job := domain.NewQueuedJob(domain.JobTypeMetadata, "https://example.com/post", time.Now())
+	job.Status = domain.JobDead
+	job.LastError = "status 503"
+	storage := NewJobQueueStorage(store)
+	_, _ = storage.Enqueue(ctx, job)
+	_ = store.Close()
+
+	store, err = NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+	defer store.Close()
+	storage = NewJobQueueStorage(store)
+
+	dead, err := storage.List(ctx, domain.JobDead)
+	if err != nil || len(dead) != 1 || dead[0].LastError != "status 503" {
+		t.Errorf("unexpected dead jobs: %+v, %v", dead, err)
+	}
+
+	if err := storage.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if all, _ := storage.List(ctx, ""); len(all) != 0 {
+		t.Errorf("expected no jobs, got %+v", all)
+	}
+}
+
+func TestJobQueueStorage_CountPurgeDead(t *testing.T) {
+	ctx := context.Background()
+	storage := NewJobQueueStorage(newTestStore(t))
+	now := time.Now()
+
+	old := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/old.jpg", now)
+	recent := domain.NewQueuedJob(domain.JobTypeColor, "https://example.com/recent.jpg", now)
+	pending := domain.NewQueuedJob(domain.JobTypeReader, "https://example.com/post", now.Add(-3*time.Hour))
+	for _, job := range []*domain.QueuedJob{old, recent, pending} {
+		_, _ = storage.Enqueue(ctx, job)
+	}
+	old.Status, old.UpdatedAt = domain.JobDead, now.Add(-2*time.Hour)
+	recent.Status, recent.UpdatedAt = domain.JobDead, now
+	_ = storage.Save(ctx, old)
+	_ = storage.Save(ctx, recent)
+
+	counts, err := storage.Count(ctx)
+	if err != nil || counts[domain.JobTypeColor][domain.JobDead] != 2 || counts[domain.JobTypeReader][domain.JobPending] != 1 {
+		t.Errorf("unexpected counts: %v, %v", counts, err)
+	}
+
+	// Only dead jobs are purged, by when they failed
+	if purged, err := storage.PurgeDead(ctx, now.Add(-time.Hour)); purged != 1 || err != nil {
+		t.Fatalf("PurgeDead returned %d, %v", purged, err)
+	}
+	if got, _ := storage.Get(ctx, old.ID); got != nil {
+		t.Errorf("expected the old dead job to be purged, got %+v", got)
+	}
+	if got, _ := storage.Get(ctx, recent.ID); got == nil {
+		t.Error("expected the recent dead job to be kept")
+	}
+	if got, _ := storage.Get(ctx, pending.ID); got == nil {
+		t.Error("expected the pending job to be kept")
+	}
+}
@@ -0,0 +1,79 @@
+// ABOUTME: SQLite storage for JSON API mappings
+// ABOUTME: Persists mappings as JSON so they survive restarts
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"digests-app-api/core/domain"
+)
+
+// jsonMappingKind is the record kind used for JSON API mappings
+const jsonMappingKind = "jsonmapping"
+
+// JSONMappingStorage implements interfaces.JSONMappingStorage on top of a Store
+type JSONMappingStorage struct {
+	store *Store
+}
+
+// NewJSONMappingStorage creates JSON mapping storage backed by the given store
+func NewJSONMappingStorage(store *Store) *JSONMappingStorage {
+	return &JSONMappingStorage{store: store}
+}
+
+// Save persists a mapping
+func (s *JSONMappingStorage) Save(ctx context.Context, mapping *domain.JSONMapping) error {
+	if mapping == nil || mapping.ID == "" {
+		return errors.New("mapping must have an ID")
+	}
+
+	data, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to encode mapping: %w", err)
+	}
+
+	return s.store.put(ctx, jsonMappingKind, mapping.ID, data, mapping.CreatedAt)
+}
+
+// Get returns a mapping, or nil if it does not exist
+func (s *JSONMappingStorage) Get(ctx context.Context, id string) (*domain.JSONMapping, error) {
+	data, err := s.store.get(ctx, jsonMappingKind, id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var mapping domain.JSONMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode mapping: %w", err)
+	}
+
+	return &mapping, nil
+}
+
+// List returns all mappings, oldest first
+func (s *JSONMappingStorage) List(ctx context.Context) ([]*domain.JSONMapping, error) {
+	records, err := s.store.list(ctx, jsonMappingKind)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]*domain.JSONMapping, 0, len(records))
+	for _, data := range records {
+		var mapping domain.JSONMapping
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return nil, fmt.Errorf("failed to decode mapping: %w", err)
+		}
+		mappings = append(mappings, &mapping)
+	}
+
+	return mappings, nil
+}
+
+// Delete removes a mapping
+func (s *JSONMappingStorage) Delete(ctx context.Context, id string) error {
+	return s.store.delete(ctx, jsonMappingKind, id)
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestJSONMappingStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	storage := NewJSONMappingStorage(store)
+
+	mapping := &domain.JSONMapping{
+		ID:        "posts",
+		URL:       "https://api.example.com/posts",
+		ItemsPath: "$.data[*]",
+		TitlePath: "$.title",
+		CreatedAt: time.Now(),
+	}
+	if err := storage.Save(ctx, mapping); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	got, err := storage.Get(ctx, "posts")
+	if err != nil || got == nil || got.ItemsPath != "$.data[*]" {
+		t.Fatalf("unexpected mapping: %+v, %v", got, err)
+	}
+
+	// Records of other kinds don't leak into the list
+	_ = NewScraperStorage(store).Save(ctx, &domain.ScraperDefinition{ID: "posts", CreatedAt: time.Now()})
+	mappings, err := storage.List(ctx)
+	if err != nil || len(mappings) != 1 {
+		t.Errorf("expected one mapping, got %d (%v)", len(mappings), err)
+	}
+
+	_ = storage.Delete(ctx, "posts")
+	if got, _ := storage.Get(ctx, "posts"); got != nil {
+		t.Errorf("expected deleted mapping to be gone, got %+v", got)
+	}
+}
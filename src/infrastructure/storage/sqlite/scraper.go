@@ -0,0 +1,79 @@
+// ABOUTME: SQLite storage for scraper definitions
+// ABOUTME: Persists definitions as JSON so they survive restarts
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"digests-app-api/core/domain"
+)
+
+// scraperKind is the record kind used for scraper definitions
+const scraperKind = "scraper"
+
+// ScraperStorage implements interfaces.ScraperStorage on top of a Store
+type ScraperStorage struct {
+	store *Store
+}
+
+// NewScraperStorage creates scraper storage backed by the given store
+func NewScraperStorage(store *Store) *ScraperStorage {
+	return &ScraperStorage{store: store}
+}
+
+// Save persists a definition
+func (s *ScraperStorage) Save(ctx context.Context, def *domain.ScraperDefinition) error {
+	if def == nil || def.ID == "" {
+		return errors.New("scraper definition must have an ID")
+	}
+
+	data, err := json.Marshal(def)
+	if err != nil {
+		return fmt.Errorf("failed to encode scraper definition: %w", err)
+	}
+
+	return s.store.put(ctx, scraperKind, def.ID, data, def.CreatedAt)
+}
+
+// Get returns a definition, or nil if it does not exist
+func (s *ScraperStorage) Get(ctx context.Context, id string) (*domain.ScraperDefinition, error) {
+	data, err := s.store.get(ctx, scraperKind, id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var def domain.ScraperDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to decode scraper definition: %w", err)
+	}
+
+	return &def, nil
+}
+
+// List returns all definitions, oldest first
+func (s *ScraperStorage) List(ctx context.Context) ([]*domain.ScraperDefinition, error) {
+	records, err := s.store.list(ctx, scraperKind)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make([]*domain.ScraperDefinition, 0, len(records))
+	for _, data := range records {
+		var def domain.ScraperDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("failed to decode scraper definition: %w", err)
+		}
+		defs = append(defs, &def)
+	}
+
+	return defs, nil
+}
+
+// Delete removes a definition
+func (s *ScraperStorage) Delete(ctx context.Context, id string) error {
+	return s.store.delete(ctx, scraperKind, id)
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "storage.db"))
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+func TestScraperStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage := NewScraperStorage(newTestStore(t))
+
+	first := &domain.ScraperDefinition{
+		ID:           "first",
+		Name:         "Changelog",
+		URL:          "https://example.com/changelog",
+		ItemSelector: "li.release",
+		DateSelector: "time",
+		CreatedAt:    time.Now().Add(-time.Hour),
+	}
+	second := &domain.ScraperDefinition{
+		ID:           "second",
+		URL:          "https://example.com/press",
+		ItemSelector: "article",
+		CreatedAt:    time.Now(),
+	}
+
+	for _, def := range []*domain.ScraperDefinition{second, first} {
+		if err := storage.Save(ctx, def); err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+	}
+
+	got, err := storage.Get(ctx, "first")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got == nil || got.Name != "Changelog" || got.DateSelector != "time" {
+		t.Errorf("unexpected definition: %+v", got)
+	}
+
+	defs, err := storage.List(ctx)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(defs) != 2 || defs[0].ID != "first" {
+		t.Errorf("expected two definitions oldest first, got %+v", defs)
+	}
+
+	if err := storage.Delete(ctx, "first"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if got, _ := storage.Get(ctx, "first"); got != nil {
+		t.Errorf("expected deleted definition to be gone, got %+v", got)
+	}
+}
+
+func TestScraperStorage_RejectsMissingID(t *testing.T) {
+	storage := NewScraperStorage(newTestStore(t))
+
+	if err := storage.Save(context.Background(), &domain.ScraperDefinition{}); err == nil {
+		t.Error("expected error for definition without ID")
+	}
+}
@@ -0,0 +1,55 @@
+// ABOUTME: SQLite storage for shares
+// ABOUTME: Persists shares as JSON so share links survive restarts
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"digests-app-api/core/domain"
+)
+
+// shareKind is the record kind used for shares
+const shareKind = "share"
+
+// ShareStorage implements interfaces.ShareStorage on top of a Store
+type ShareStorage struct {
+	store *Store
+}
+
+// NewShareStorage creates share storage backed by the given store
+func NewShareStorage(store *Store) *ShareStorage {
+	return &ShareStorage{store: store}
+}
+
+// Save persists a share
+func (s *ShareStorage) Save(ctx context.Context, share *domain.Share) error {
+	if share == nil || share.ID == "" {
+		return errors.New("share must have an ID")
+	}
+
+	data, err := json.Marshal(share)
+	if err != nil {
+		return fmt.Errorf("failed to encode share: %w", err)
+	}
+
+	return s.store.put(ctx, shareKind, share.ID, data, share.CreatedAt)
+}
+
+// Get returns a share, or nil if it does not exist
+func (s *ShareStorage) Get(ctx context.Context, id string) (*domain.Share, error) {
+	data, err := s.store.get(ctx, shareKind, id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var share domain.Share
+	if err := json.Unmarshal(data, &share); err != nil {
+		return nil, fmt.Errorf("failed to decode share: %w", err)
+	}
+
+	return &share, nil
+}
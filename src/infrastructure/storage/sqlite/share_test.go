@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"digests-app-api/core/domain"
+)
+
+func TestShareStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage := NewShareStorage(newTestStore(t))
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	share := &domain.Share{
+		ID:          "550e8400-e29b-41d4-a716-446655440000",
+		URLs:        []string{"https://example.com/feed", "https://example.org/rss"},
+		Title:       "Reading list",
+		Description: "Feeds worth following",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   &expiresAt,
+	}
+	if err := storage.Save(ctx, share); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	got, err := storage.Get(ctx, share.ID)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got == nil || got.Title != "Reading list" || len(got.URLs) != 2 || got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected share: %+v", got)
+	}
+
+	if missing, err := storage.Get(ctx, "missing"); missing != nil || err != nil {
+		t.Errorf("expected nil for missing share, got %+v, %v", missing, err)
+	}
+}
@@ -0,0 +1,144 @@
+// ABOUTME: SQLite-backed storage for persisted domain entities
+// ABOUTME: Stores entities as JSON records keyed by kind and ID, and queued jobs in their own table
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store is a SQLite database holding JSON records grouped by kind
+type Store struct {
+	db       *sql.DB
+	filePath string
+}
+
+// NewStore opens (or creates) a SQLite storage database
+func NewStore(filePath string) (*Store, error) {
+	if filePath == "" {
+		filePath = "storage.db"
+	}
+
+	db, err := sql.Open("sqlite3", filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to SQLite database: %w", err)
+	}
+
+	store := &Store{
+		db:       db,
+		filePath: filePath,
+	}
+
+	if err := store.initSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return store, nil
+}
+
+// initSchema creates the records and jobs tables if they don't exist. Jobs
+// keep the fields they are claimed by in columns so claims use an index.
+func (s *Store) initSchema() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS records (
+			kind TEXT NOT NULL,
+			id TEXT NOT NULL,
+			data BLOB NOT NULL,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (kind, id)
+		);
+
+		CREATE TABLE IF NOT EXISTS jobs (
+			id TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			status TEXT NOT NULL,
+			run_at INTEGER NOT NULL,
+			data BLOB NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(type, status, run_at);
+	`
+
+	_, err := s.db.Exec(query)
+	return err
+}
+
+// put inserts or replaces a record
+func (s *Store) put(ctx context.Context, kind, id string, data []byte, createdAt time.Time) error {
+	if id == "" {
+		return errors.New("record ID cannot be empty")
+	}
+
+	query := `
+		INSERT OR REPLACE INTO records (kind, id, data, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, kind, id, data, createdAt.UnixNano()); err != nil {
+		return fmt.Errorf("failed to save %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+// get returns a record's data, or nil if it does not exist
+func (s *Store) get(ctx context.Context, kind, id string) ([]byte, error) {
+	var data []byte
+
+	query := "SELECT data FROM records WHERE kind = ? AND id = ?"
+	err := s.db.QueryRowContext(ctx, query, kind, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+
+	return data, nil
+}
+
+// list returns the data of all records of a kind, oldest first
+func (s *Store) list(ctx context.Context, kind string) ([][]byte, error) {
+	query := "SELECT data FROM records WHERE kind = ? ORDER BY created_at"
+	rows, err := s.db.QueryContext(ctx, query, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	var records [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+		}
+		records = append(records, data)
+	}
+
+	return records, rows.Err()
+}
+
+// delete removes a record
+func (s *Store) delete(ctx context.Context, kind, id string) error {
+	query := "DELETE FROM records WHERE kind = ? AND id = ?"
+	if _, err := s.db.ExecContext(ctx, query, kind, id); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -5,8 +5,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all application configuration
@@ -16,6 +18,30 @@ type Config struct {
 
 	// Cache contains cache configuration
 	Cache CacheConfig
+
+	// Storage contains persistent storage configuration
+	Storage StorageConfig
+
+	// Search contains feed directory search configuration
+	Search SearchConfig
+
+	// Reader contains reader view configuration
+	Reader ReaderConfig
+
+	// SiteRules contains per-site extraction rule configuration
+	SiteRules SiteRulesConfig
+
+	// Summary contains extractive summarization configuration
+	Summary SummaryConfig
+
+	// AutoTag contains keyword auto-tagging configuration
+	AutoTag AutoTagConfig
+
+	// Cluster contains near-duplicate story clustering configuration
+	Cluster ClusterConfig
+
+	// Queue contains background enrichment job queue configuration
+	Queue QueueConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -43,6 +69,105 @@ type CacheConfig struct {
 	
 	// ColorCacheDays is the number of days to cache thumbnail colors
 	ColorCacheDays int
+
+	// ImageCacheDays is the number of days to cache images processed by
+	// the image proxy
+	ImageCacheDays int
+}
+
+// StorageConfig holds persistent storage configuration for user-defined
+// entities such as scraper definitions
+type StorageConfig struct {
+	// Type specifies the storage backend (memory/sqlite); empty means memory
+	Type string
+
+	// ShareType specifies the share storage backend (memory/sqlite/redis);
+	// empty means the same backend as Type. Redis uses the cache's Redis settings.
+	ShareType string
+
+	// SQLite contains SQLite-specific configuration
+	SQLite SQLiteConfig
+}
+
+// SearchConfig holds feed directory search configuration
+type SearchConfig struct {
+	// Providers lists the enabled providers (itunes/podcastindex/directory)
+	Providers []string
+
+	// ITunesBaseURL is the base URL of an iTunes Search-style API
+	ITunesBaseURL string
+
+	// PodcastIndex contains Podcast Index-style API configuration
+	PodcastIndex PodcastIndexConfig
+
+	// DirectoryPath is an OPML or JSON file of curated feeds
+	DirectoryPath string
+}
+
+// ReaderConfig holds reader view configuration
+type ReaderConfig struct {
+	// MaxPages is how many pages of a paginated article are stitched together
+	MaxPages int
+}
+
+// SiteRulesConfig holds per-site extraction rule configuration
+type SiteRulesConfig struct {
+	// Dir is a directory of YAML/JSON rule files; empty disables site rules
+	Dir string
+
+	// ReloadSeconds is how often the directory is checked for changes; 0 only reloads on SIGHUP
+	ReloadSeconds int
+}
+
+// SummaryConfig holds extractive summarization configuration
+type SummaryConfig struct {
+	// Sentences is the summary length when a request does not ask for one
+	Sentences int
+}
+
+// AutoTagConfig holds keyword auto-tagging configuration
+type AutoTagConfig struct {
+	// MaxTags is the number of tags per item when a request does not ask for one
+	MaxTags int
+
+	// CorpusSize is how many recently tagged items term frequencies come from
+	CorpusSize int
+}
+
+// ClusterConfig holds near-duplicate story clustering configuration
+type ClusterConfig struct {
+	// MaxDistance is how many of the 64 SimHash bits items in a story may differ by
+	MaxDistance int
+}
+
+// QueueConfig holds background enrichment job queue configuration
+type QueueConfig struct {
+	// Type specifies the queue backend (memory/sqlite/redis); empty means
+	// the same backend as Storage.Type. Redis uses the cache's Redis settings.
+	Type string
+
+	// ColorWorkers, MetadataWorkers and ReaderWorkers are how many jobs of
+	// each type run at once
+	ColorWorkers    int
+	MetadataWorkers int
+	ReaderWorkers   int
+
+	// MaxAttempts is how often a job is tried before it is kept as failed
+	MaxAttempts int
+
+	// AdminToken is the bearer token GET /admin/queue requires; the endpoint
+	// is not registered when it is empty
+	AdminToken string
+}
+
+// PodcastIndexConfig holds Podcast Index-style API configuration
+type PodcastIndexConfig struct {
+	// BaseURL is the API base URL
+	BaseURL string
+
+	// APIKey and APISecret sign requests; both empty sends unsigned requests
+	APIKey    string
+	APISecret string
 }
 
 // SQLiteConfig holds SQLite-specific configuration
@@ -90,6 +215,49 @@ func LoadFromEnv() (*Config, error) {
 				FilePath: getEnvOrDefault("SQLITE_CACHE_PATH", "cache.db"),
 			},
 			ColorCacheDays: getEnvAsIntOrDefault("COLOR_CACHE_DAYS", 7),
+			ImageCacheDays: getEnvAsIntOrDefault("IMAGE_CACHE_DAYS", 30),
+		},
+		Storage: StorageConfig{
+			Type:      getEnvOrDefault("STORAGE_TYPE", "memory"),
+			ShareType: getEnvOrDefault("SHARE_STORAGE_TYPE", ""),
+			SQLite: SQLiteConfig{
+				FilePath: getEnvOrDefault("SQLITE_STORAGE_PATH", "storage.db"),
+			},
+		},
+		Search: SearchConfig{
+			Providers:     getEnvAsListOrDefault("SEARCH_PROVIDERS", []string{"itunes", "podcastindex", "directory"}),
+			ITunesBaseURL: getEnvOrDefault("ITUNES_SEARCH_BASE_URL", "https://itunes.apple.com"),
+			PodcastIndex: PodcastIndexConfig{
+				BaseURL:   getEnvOrDefault("PODCASTINDEX_BASE_URL", "https://api.podcastindex.org"),
+				APIKey:    getEnvOrDefault("PODCASTINDEX_API_KEY", ""),
+				APISecret: getEnvOrDefault("PODCASTINDEX_API_SECRET", ""),
+			},
+			DirectoryPath: getEnvOrDefault("SEARCH_DIRECTORY_PATH", ""),
+		},
+		Reader: ReaderConfig{
+			MaxPages: getEnvAsIntOrDefault("READER_MAX_PAGES", 5),
+		},
+		SiteRules: SiteRulesConfig{
+			Dir:           getEnvOrDefault("SITE_RULES_DIR", ""),
+			ReloadSeconds: getEnvAsIntOrDefault("SITE_RULES_RELOAD_SECONDS", 60),
+		},
+		Summary: SummaryConfig{
+			Sentences: getEnvAsIntOrDefault("SUMMARY_SENTENCES", 3),
+		},
+		AutoTag: AutoTagConfig{
+			MaxTags:    getEnvAsIntOrDefault("AUTOTAG_MAX_TAGS", 5),
+			CorpusSize: getEnvAsIntOrDefault("AUTOTAG_CORPUS_SIZE", 5000),
+		},
+		Cluster: ClusterConfig{
+			MaxDistance: getEnvAsIntOrDefault("CLUSTER_MAX_DISTANCE", 6),
+		},
+		Queue: QueueConfig{
+			Type:            getEnvOrDefault("QUEUE_TYPE", ""),
+			ColorWorkers:    getEnvAsIntOrDefault("QUEUE_COLOR_WORKERS", 5),
+			MetadataWorkers: getEnvAsIntOrDefault("QUEUE_METADATA_WORKERS", 2),
+			ReaderWorkers:   getEnvAsIntOrDefault("QUEUE_READER_WORKERS", 2),
+			MaxAttempts:     getEnvAsIntOrDefault("QUEUE_MAX_ATTEMPTS", 5),
+			AdminToken:      getEnvOrDefault("QUEUE_ADMIN_TOKEN", ""),
 		},
 	}
 
@@ -114,6 +282,22 @@ func getEnvAsIntOrDefault(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsListOrDefault returns a comma-separated environment variable as a list or a default
+func getEnvAsListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.Server.Port == "" {
@@ -132,5 +316,71 @@ func (c *Config) Validate() error {
 		return errors.New("redis address cannot be empty when using redis cache")
 	}
 
+	if c.Storage.Type != "" && c.Storage.Type != "memory" && c.Storage.Type != "sqlite" {
+		return errors.New("storage type must be 'memory' or 'sqlite'")
+	}
+
+	switch c.Storage.ShareType {
+	case "", "memory", "sqlite":
+	case "redis":
+		if c.Cache.Redis.Address == "" {
+			return errors.New("redis address cannot be empty when using redis share storage")
+		}
+	default:
+		return errors.New("share storage type must be 'memory', 'sqlite' or 'redis'")
+	}
+
+	if c.Cache.ImageCacheDays < 0 {
+		return errors.New("image cache days cannot be negative")
+	}
+
+	for _, provider := range c.Search.Providers {
+		if provider != "itunes" && provider != "podcastindex" && provider != "directory" {
+			return fmt.Errorf("unknown search provider %q: must be 'itunes', 'podcastindex' or 'directory'", provider)
+		}
+	}
+
+	if c.Reader.MaxPages < 0 || c.Reader.MaxPages > 20 {
+		return errors.New("reader max pages must be between 1 and 20")
+	}
+
+	if c.SiteRules.ReloadSeconds < 0 {
+		return errors.New("site rules reload interval cannot be negative")
+	}
+
+	if c.Summary.Sentences < 0 || c.Summary.Sentences > 10 {
+		return errors.New("summary sentences must be between 1 and 10")
+	}
+
+	if c.AutoTag.MaxTags < 0 || c.AutoTag.MaxTags > 10 {
+		return errors.New("auto-tag max tags must be between 1 and 10")
+	}
+
+	if c.AutoTag.CorpusSize < 0 {
+		return errors.New("auto-tag corpus size cannot be negative")
+	}
+
+	if c.Cluster.MaxDistance < 0 || c.Cluster.MaxDistance > 12 {
+		return errors.New("cluster max distance must be between 1 and 12")
+	}
+
+	switch c.Queue.Type {
+	case "", "memory", "sqlite":
+	case "redis":
+		if c.Cache.Redis.Address == "" {
+			return errors.New("redis address cannot be empty when using a redis queue")
+		}
+	default:
+		return errors.New("queue type must be 'memory', 'sqlite' or 'redis'")
+	}
+
+	if c.Queue.ColorWorkers < 0 || c.Queue.MetadataWorkers < 0 || c.Queue.ReaderWorkers < 0 {
+		return errors.New("queue workers cannot be negative")
+	}
+
+	if c.Queue.MaxAttempts < 0 {
+		return errors.New("queue max attempts cannot be negative")
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -172,6 +172,174 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "redis address cannot be empty when using redis cache",
 		},
+		{
+			name: "invalid storage type",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Storage: StorageConfig{
+					Type: "postgres",
+				},
+			},
+			wantErr: true,
+			errMsg:  "storage type must be 'memory' or 'sqlite'",
+		},
+		{
+			name: "invalid share storage type",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Storage: StorageConfig{
+					ShareType: "postgres",
+				},
+			},
+			wantErr: true,
+			errMsg:  "share storage type must be 'memory', 'sqlite' or 'redis'",
+		},
+		{
+			name: "invalid queue type",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Queue: QueueConfig{
+					Type: "rabbitmq",
+				},
+			},
+			wantErr: true,
+			errMsg:  "queue type must be 'memory', 'sqlite' or 'redis'",
+		},
+		{
+			name: "negative queue workers",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Queue: QueueConfig{
+					ReaderWorkers: -1,
+				},
+			},
+			wantErr: true,
+			errMsg:  "queue workers cannot be negative",
+		},
+		{
+			name: "negative image cache days",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type:           "memory",
+					ImageCacheDays: -1,
+				},
+			},
+			wantErr: true,
+			errMsg:  "image cache days cannot be negative",
+		},
+		{
+			name: "unknown search provider",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Search: SearchConfig{
+					Providers: []string{"itunes", "feedly"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "unknown search provider \"feedly\": must be 'itunes', 'podcastindex' or 'directory'",
+		},
+		{
+			name: "too many reader pages",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Reader: ReaderConfig{
+					MaxPages: 50,
+				},
+			},
+			wantErr: true,
+			errMsg:  "reader max pages must be between 1 and 20",
+		},
+		{
+			name: "too many summary sentences",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Summary: SummaryConfig{
+					Sentences: 25,
+				},
+			},
+			wantErr: true,
+			errMsg:  "summary sentences must be between 1 and 10",
+		},
+		{
+			name: "negative auto-tag corpus size",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				AutoTag: AutoTagConfig{
+					CorpusSize: -1,
+				},
+			},
+			wantErr: true,
+			errMsg:  "auto-tag corpus size cannot be negative",
+		},
+		{
+			name: "cluster max distance too large",
+			config: Config{
+				Server: ServerConfig{
+					Port:         "8000",
+					RefreshTimer: 60,
+				},
+				Cache: CacheConfig{
+					Type: "memory",
+				},
+				Cluster: ClusterConfig{
+					MaxDistance: 32,
+				},
+			},
+			wantErr: true,
+			errMsg:  "cluster max distance must be between 1 and 12",
+		},
 	}
 
 	for _, tt := range tests {
@@ -18,6 +18,7 @@ var timeFormats = []string{
 	time.RFC822Z,
 	"2006-01-02T15:04:05Z07:00",
 	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00", // W3C datetime without seconds (sitemaps)
 	"2006-01-02 15:04:05",
 	"2006-01-02",
 	"02 Jan 2006 15:04:05 MST",
@@ -26,6 +27,10 @@ var timeFormats = []string{
 	"Mon, 02 Jan 2006 15:04:05 -0700",
 	"Mon, 2 Jan 2006 15:04:05 MST",
 	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"January 2, 2006", // human-readable dates on scraped pages
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
 }
 
 // ParseFlexibleTime attempts to parse a time string using various formats